@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runBranches implements "gitversion branches report"
+func runBranches(args []string) {
+	if len(args) == 0 || args[0] != "report" {
+		fail(fmt.Errorf("usage: %s branches report [-c FILE] [-o text|json]", ScriptName))
+	}
+
+	fs := flag.NewFlagSet("branches report", flag.ExitOnError)
+	errorFormatVar(fs)
+	configFile := fs.String("c", "", "Path to configuration file")
+	configFileLong := fs.String("config", "", "Path to configuration file")
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	parseFlags(fs, args[1:])
+
+	configPath := *configFile
+	if *configFileLong != "" {
+		configPath = *configFileLong
+	}
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+
+	client, err := v1.New(v1.Options{ConfigFile: configPath, Debug: os.Getenv("DEBUG") == "true"})
+	if err != nil {
+		fail(err)
+	}
+
+	report, err := client.BranchReport()
+	if err != nil {
+		fail(err)
+	}
+
+	if v1.OutputFormat(outputFormat) == v1.JSON {
+		printJSON(report)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRANCH\tCONFIG\tSTATE\tDETAIL")
+	for _, status := range report {
+		key := status.ConfigKey
+		if key == "" {
+			key = "(default)"
+		}
+		detail := ""
+		switch status.State {
+		case v1.BranchReleased:
+			detail = "released in " + status.ReleasedIn
+		case v1.BranchMerged:
+			detail = "merged into " + status.MergedInto
+		}
+		if status.SafeToDelete {
+			detail += ", safe to delete"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Branch, key, status.State, detail)
+	}
+	w.Flush()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// cacheStats summarizes the calculation cache for "gitversion cache stats"
+type cacheStats struct {
+	Dir     string `json:"Dir"`
+	Entries int    `json:"Entries"`
+	Size    int64  `json:"Size"`
+	// Oldest and Newest are unset for an empty cache
+	Oldest  *time.Time `json:"Oldest,omitempty"`
+	Newest  *time.Time `json:"Newest,omitempty"`
+	MaxAge  string     `json:"MaxAge"`
+	MaxSize int64      `json:"MaxSize"`
+}
+
+// runCache implements "gitversion cache list|clear|stats", which inspect
+// and purge the calculation cache in the git directory
+func runCache(args []string) {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "clear" && args[0] != "stats") {
+		fail(fmt.Errorf("usage: %s cache list [-o text|json] | cache clear [--older-than DURATION] | cache stats [-c FILE] [-o text|json]", ScriptName))
+	}
+
+	fs := flag.NewFlagSet("cache "+args[0], flag.ExitOnError)
+	errorFormatVar(fs)
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	olderThan := fs.Duration("older-than", 0, "Only clear entries written longer ago than this, e.g. 168h")
+	configPaths := configVar(fs)
+	parseFlags(fs, args[1:])
+
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+	asJSON := gitversion.OutputFormat(outputFormat) == gitversion.JSON
+
+	dir, err := gitversion.CacheDir("")
+	if err != nil {
+		fail(err)
+	}
+
+	switch args[0] {
+	case "list":
+		entries, err := gitversion.ListCache(dir)
+		if err != nil {
+			fail(err)
+		}
+		if asJSON {
+			if entries == nil {
+				entries = []*gitversion.CacheEntry{}
+			}
+			printJSON(entries)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMMIT\tVERSION\tBRANCH\tWRITTEN\tSIZE")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%.7s\t%s\t%s\t%s\t%d\n", entry.Commit, entry.Version, entry.Branch, entry.Written.Format(time.RFC3339), entry.Size)
+		}
+		w.Flush()
+
+	case "clear":
+		removed, err := gitversion.ClearCache(dir, *olderThan)
+		if err != nil {
+			fail(err)
+		}
+		logInfo("Removed %d cache entries from %s", removed, dir)
+
+	case "stats":
+		client, err := v1.New(v1.Options{ConfigFiles: *configPaths, Debug: os.Getenv("DEBUG") == "true"})
+		if err != nil {
+			fail(err)
+		}
+		var cacheConfig *config.CacheConfig
+		if cc := client.Config().Cache; cc != nil {
+			cacheConfig = &config.CacheConfig{MaxAge: cc.MaxAge, MaxSize: cc.MaxSize}
+		}
+		policy, err := gitversion.NewCachePolicy(cacheConfig)
+		if err != nil {
+			fail(err)
+		}
+		entries, err := gitversion.ListCache(dir)
+		if err != nil {
+			fail(err)
+		}
+
+		stats := cacheStats{Dir: dir, Entries: len(entries), MaxAge: policy.MaxAge.String(), MaxSize: policy.MaxSize}
+		for _, entry := range entries {
+			stats.Size += entry.Size
+		}
+		// Entries are listed newest first
+		if len(entries) > 0 {
+			stats.Newest = &entries[0].Written
+			stats.Oldest = &entries[len(entries)-1].Written
+		}
+		if asJSON {
+			printJSON(stats)
+			return
+		}
+		fmt.Printf("Directory:   %s\n", stats.Dir)
+		fmt.Printf("Entries:     %d\n", stats.Entries)
+		fmt.Printf("Size:        %d bytes (limit %d)\n", stats.Size, stats.MaxSize)
+		fmt.Printf("Max age:     %s\n", stats.MaxAge)
+		if stats.Oldest != nil {
+			fmt.Printf("Oldest:      %s\n", stats.Oldest.Format(time.RFC3339))
+			fmt.Printf("Newest:      %s\n", stats.Newest.Format(time.RFC3339))
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+)
+
+// runConfig implements "gitversion config show|validate|schema"
+func runConfig(args []string) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	switch args[0] {
+	case "show":
+		runConfigShow(args[1:])
+	case "validate":
+		runConfigValidate(args[1:])
+	case "schema":
+		os.Stdout.Write(config.Schema())
+	default:
+		fail(fmt.Errorf("usage: %s config show [-c FILE] [--project DIR] [-b BRANCH] [-o yaml|json] | config validate [-c FILE] | config schema", ScriptName))
+	}
+}
+
+// runConfigShow prints the effective configuration with the source of
+// every value and the branch configuration that applies to the current or
+// given branch.
+func runConfigShow(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	errorFormatVar(fs)
+	configPaths := configVar(fs)
+	branch := fs.String("b", "", "Branch to resolve [default: current branch]")
+	branchLong := fs.String("branch", "", "Branch to resolve [default: current branch]")
+	output := fs.String("o", "yaml", "Output format (yaml|json)")
+	outputLong := fs.String("output", "yaml", "Output format (yaml|json)")
+	project := fs.String("project", "", "Layer the config file in this directory over the root config")
+	parseFlags(fs, args)
+
+	targetBranch := *branch
+	if *branchLong != "" {
+		targetBranch = *branchLong
+	}
+	outputFormat := *output
+	if *outputLong != "yaml" {
+		outputFormat = *outputLong
+	}
+
+	files := *configPaths
+	if *project != "" {
+		root, err := git.NewRepository().GetRootDir()
+		if err == nil {
+			files, err = config.ProjectFiles(root, *project, files)
+		}
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	effective, err := config.LoadEffectiveConfig(files...)
+	if err != nil {
+		fail(err)
+	}
+
+	if targetBranch == "" {
+		if current, err := git.NewRepository().GetCurrentBranch(); err == nil && current != "HEAD" {
+			targetBranch = current
+		}
+	}
+	if targetBranch != "" {
+		effective.ResolveBranch(targetBranch)
+	}
+
+	if gitversion.OutputFormat(outputFormat) == gitversion.JSON {
+		printJSON(effective)
+		return
+	}
+	text, err := effective.YAML()
+	if err != nil {
+		fail(err)
+	}
+	fmt.Print(text)
+}
+
+// runConfigValidate checks a configuration file for unknown keys, invalid
+// values and broken regular expressions, printing one line per problem.
+func runConfigValidate(args []string) {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	errorFormatVar(fs)
+	var configPaths listFlag
+	fs.Var(&configPaths, "c", "Path to configuration file; repeat to check layered files [default: GitVersion.yml in the current directory]")
+	fs.Var(&configPaths, "config", "Path to configuration file; repeat to check layered files [default: GitVersion.yml in the current directory]")
+	parseFlags(fs, args)
+
+	if len(configPaths) == 0 {
+		if path := config.Find("."); path != "" {
+			configPaths = append(configPaths, path)
+		}
+	}
+	if len(configPaths) == 0 {
+		failWith(exitConfig, errors.New("no configuration file found; pass one with -c"))
+	}
+
+	if _, err := config.LoadConfigStrict(configPaths...); err != nil {
+		var problems config.ValidationErrors
+		if !errors.As(err, &problems) {
+			fail(err)
+		}
+		for _, problem := range problems {
+			reportError(exitConfig, problem)
+		}
+		os.Exit(exitConfig)
+	}
+	for _, path := range configPaths {
+		fmt.Printf("%s is valid\n", path)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/crosscheck"
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runCrosscheck implements "gitversion crosscheck". It exits with status 1
+// when any field GitVersion reports differs or is missing.
+func runCrosscheck(args []string) {
+	fs := flag.NewFlagSet("crosscheck", flag.ExitOnError)
+	errorFormatVar(fs)
+	tool := fs.String("tool", "", "Path to GitVersion [default: search PATH]")
+	configPaths := configVar(fs)
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	all := fs.Bool("all", false, "List matching fields too")
+	parseFlags(fs, args)
+
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+
+	toolPath := *tool
+	if toolPath == "" {
+		self, _ := os.Executable()
+		var err error
+		if toolPath, err = crosscheck.Find(self); err != nil {
+			fail(err)
+		}
+	}
+
+	result, err := v1.Calculate(v1.Options{
+		ConfigFiles: *configPaths,
+		Debug:       os.Getenv("DEBUG") == "true",
+	})
+	if err != nil {
+		fail(err)
+	}
+	ours, err := variablesMap(&result.Variables)
+	if err != nil {
+		fail(err)
+	}
+
+	theirs, err := crosscheck.Run(toolPath)
+	if err != nil {
+		fail(err)
+	}
+
+	report := crosscheck.Compare(ours, theirs)
+
+	if gitversion.OutputFormat(outputFormat) == gitversion.JSON {
+		printJSON(report)
+	} else {
+		printCrosscheck(report, toolPath, *all)
+	}
+
+	if !report.Compatible() {
+		os.Exit(1)
+	}
+}
+
+// variablesMap decodes the variables the way GitVersion's JSON is decoded
+func variablesMap(variables *v1.Variables) (map[string]interface{}, error) {
+	data, err := json.Marshal(variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	decoded := make(map[string]interface{})
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func printCrosscheck(report *crosscheck.Report, tool string, all bool) {
+	fmt.Printf("Compared with %s\n\n", tool)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tSTATUS\tGITVERSION-GO\tGITVERSION")
+	for _, f := range report.Fields {
+		if f.Status == crosscheck.Match && !all {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Name, f.Status, f.Ours, f.GitVersion)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d matching, %d differing, %d missing, %d extra\n",
+		report.Count(crosscheck.Match), report.Count(crosscheck.Differs),
+		report.Count(crosscheck.Missing), report.Count(crosscheck.Extra))
+}
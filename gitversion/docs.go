@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// commandDoc describes a command of the CLI for "gitversion docs"
+type commandDoc struct {
+	// name is the command line after the program name, e.g. "cache list"
+	name    string
+	summary string
+	// run and args run the command up to parsing its flags, nil for a
+	// command without flags
+	run  func(args []string)
+	args []string
+}
+
+// commandDocs returns the commands the docs cover, in the order they are
+// listed
+func commandDocs() []commandDoc {
+	return []commandDoc{
+		{"calculate", "Calculate the version of the current commit; the default command", runCalculate, nil},
+		{"tag", "Create an annotated tag for the calculated version on HEAD", runTag, nil},
+		{"release-notes", "Render the changes since the previous version tag", runReleaseNotes, nil},
+		{"update-files", "Write the calculated version into the files of the file-updates configuration", runUpdateFiles, nil},
+		{"branches report", "List merged, released and stale branches", runBranches, []string{"report"}},
+		{"notes list", "List the versions recorded as git notes", runNotes, []string{"list"}},
+		{"notes show", "Show the version recorded on a commit", runNotes, []string{"show"}},
+		{"cache list", "List the cached calculations", runCache, []string{"list"}},
+		{"cache stats", "Summarize the calculation cache and its limits", runCache, []string{"stats"}},
+		{"cache clear", "Remove cached calculations", runCache, []string{"clear"}},
+		{"crosscheck", "Compare the calculated variables with an installed GitVersion", runCrosscheck, nil},
+		{"doctor", "Check for shallow clones, missing tags and other problems", runDoctor, nil},
+		{"normalize", "Fetch the history, branches and tags a CI checkout lacks and attach a detached HEAD", runNormalize, nil},
+		{"lint-commit", "Check commit messages against Conventional Commits and report the increment each asks for", runLintCommit, nil},
+		{"serve", "Answer version requests over HTTP", runServe, nil},
+		{"init", "Write a commented configuration file", runInit, nil},
+		{"config show", "Print the effective configuration and where each value came from", runConfigShow, nil},
+		{"config validate", "Check configuration files for unknown keys and invalid values", runConfigValidate, nil},
+		{"config schema", "Print the JSON schema of the configuration file", nil, nil},
+		{"docs", "Generate manual pages and reference documentation", runDocs, nil},
+	}
+}
+
+// exitCodeDocs are the exit codes the docs list
+var exitCodeDocs = []struct {
+	code    int
+	meaning string
+}{
+	{0, "Success"},
+	{exitFailure, "Any other failure"},
+	{2, "Invalid command line flags"},
+	{exitNotRepo, "Not inside a git repository"},
+	{exitConfig, "Invalid configuration"},
+	{exitGit, "A git command failed"},
+	{exitPrerelease, "The version is a prerelease and --require-stable is set"},
+}
+
+// describing receives the flag set of a command instead of it being
+// parsed while the docs are generated
+var describing func(fs *flag.FlagSet)
+
+// parseFlags parses the flags of a command. While the docs are generated it
+// hands fs to describing and stops the command before it does anything.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	if describing != nil {
+		describing(fs)
+		runtime.Goexit()
+	}
+	fs.Parse(args)
+}
+
+// commandFlags returns the flags cmd defines, nil when it has none
+func commandFlags(cmd commandDoc) *flag.FlagSet {
+	if cmd.run == nil {
+		return nil
+	}
+	var fs *flag.FlagSet
+	describing = func(defined *flag.FlagSet) { fs = defined }
+	defer func() { describing = nil }()
+
+	// Goexit ends the goroutine the command runs in once its flags are
+	// defined
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cmd.run(cmd.args)
+	}()
+	<-done
+	return fs
+}
+
+// flagDoc is a flag and its aliases, e.g. -b and --branch
+type flagDoc struct {
+	names []string
+	// value names the argument, "" for a boolean flag
+	value        string
+	usage        string
+	defaultValue string
+}
+
+// String renders the flag as used on the command line, e.g.
+// "-b, --branch string"
+func (f *flagDoc) String() string {
+	names := make([]string, len(f.names))
+	for i, name := range f.names {
+		if len(name) == 1 {
+			names[i] = "-" + name
+		} else {
+			names[i] = "--" + name
+		}
+	}
+	s := strings.Join(names, ", ")
+	if f.value != "" {
+		s += " " + f.value
+	}
+	return s
+}
+
+// flagDocs lists the flags of fs, aliases sharing their usage and default
+// merged into one entry
+func flagDocs(fs *flag.FlagSet) []*flagDoc {
+	var docs []*flagDoc
+	byUsage := map[string]*flagDoc{}
+	fs.VisitAll(func(f *flag.Flag) {
+		value, usage := flag.UnquoteUsage(f)
+		if value == "value" {
+			value = "VALUE"
+		}
+		defaultValue := f.DefValue
+		// Zero durations and empty lists do not limit anything
+		if defaultValue == "false" || defaultValue == "[]" || defaultValue == "0s" {
+			defaultValue = ""
+		}
+		key := usage + "\x00" + defaultValue
+		if doc, ok := byUsage[key]; ok {
+			doc.names = append(doc.names, f.Name)
+			return
+		}
+		doc := &flagDoc{names: []string{f.Name}, value: value, usage: usage, defaultValue: defaultValue}
+		byUsage[key] = doc
+		docs = append(docs, doc)
+	})
+	for _, doc := range docs {
+		sort.SliceStable(doc.names, func(i, j int) bool { return len(doc.names[i]) < len(doc.names[j]) })
+	}
+	return docs
+}
+
+// pageName returns the file name of the page of a command without its
+// extension, e.g. "gitversion-cache-list"
+func pageName(cmd commandDoc) string {
+	return ScriptName + "-" + strings.ReplaceAll(cmd.name, " ", "-")
+}
+
+// runDocs implements "gitversion docs", which writes manual pages and
+// Markdown reference pages generated from the flag definitions of every
+// command, so the docs cannot drift from the CLI
+func runDocs(args []string) {
+	fs := flag.NewFlagSet("docs", flag.ExitOnError)
+	errorFormatVar(fs)
+	man := fs.Bool("man", false, "Write manual pages, gitversion.1 and one per command")
+	markdown := fs.Bool("markdown", false, "Write Markdown reference pages, gitversion.md and one per command")
+	dir := fs.String("dir", ".", "Directory to write the pages to")
+	parseFlags(fs, args)
+
+	if !*man && !*markdown {
+		fail(errors.New("usage: gitversion docs --man|--markdown [--dir DIR]"))
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		fail(err)
+	}
+
+	commands := commandDocs()
+	pages := map[string]string{}
+	flags := make([][]*flagDoc, len(commands))
+	for i, cmd := range commands {
+		if fs := commandFlags(cmd); fs != nil {
+			flags[i] = flagDocs(fs)
+		}
+	}
+	if *man {
+		pages[ScriptName+".1"] = manIndex(commands, flags[0])
+		for i, cmd := range commands {
+			pages[pageName(cmd)+".1"] = manPage(cmd, flags[i])
+		}
+	}
+	if *markdown {
+		pages[ScriptName+".md"] = markdownIndex(commands, flags[0])
+		for i, cmd := range commands {
+			pages[pageName(cmd)+".md"] = markdownPage(cmd, flags[i])
+		}
+	}
+
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(*dir, name), []byte(content), 0o644); err != nil {
+			fail(fmt.Errorf("failed to write %s: %w", name, err))
+		}
+	}
+	logInfo("Wrote %d pages to %s", len(pages), *dir)
+}
+
+// manEscape escapes text for roff
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// manHeader starts the manual page of title
+func manHeader(b *strings.Builder, title, summary string) {
+	fmt.Fprintf(b, ".TH %s 1 \"\" \"%s %s\" \"%s manual\"\n", strings.ToUpper(manEscape(title)), ScriptName, Version, ScriptName)
+	fmt.Fprintf(b, ".SH NAME\n%s \\- %s\n", manEscape(title), manEscape(summary))
+}
+
+// manOptions writes the OPTIONS section of a manual page
+func manOptions(b *strings.Builder, flags []*flagDoc) {
+	if len(flags) == 0 {
+		return
+	}
+	b.WriteString(".SH OPTIONS\n")
+	for _, f := range flags {
+		fmt.Fprintf(b, ".TP\n.B %s\n%s", manEscape(f.String()), manEscape(f.usage))
+		if f.defaultValue != "" {
+			fmt.Fprintf(b, " (default: %s)", manEscape(f.defaultValue))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// manIndex renders gitversion.1, which documents the calculate flags as
+// the options of the program
+func manIndex(commands []commandDoc, flags []*flagDoc) string {
+	var b strings.Builder
+	manHeader(&b, ScriptName, "GitVersion Go implementation")
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B %s\n[\\fICOMMAND\\fR] [\\fIOPTIONS\\fR]\n", ScriptName)
+	b.WriteString(".SH DESCRIPTION\nCalculates semantic versions from the git history, compatible with GitVersion. Without a command it calculates the version of the current commit.\n")
+	manOptions(&b, flags)
+	b.WriteString(".SH COMMANDS\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, ".TP\n.BR %s (1)\n%s\n", manEscape(pageName(cmd)), manEscape(cmd.summary))
+	}
+	b.WriteString(".SH EXIT STATUS\n")
+	for _, exit := range exitCodeDocs {
+		fmt.Fprintf(&b, ".TP\n.B %d\n%s\n", exit.code, manEscape(exit.meaning))
+	}
+	return b.String()
+}
+
+// manPage renders the manual page of cmd
+func manPage(cmd commandDoc, flags []*flagDoc) string {
+	var b strings.Builder
+	manHeader(&b, pageName(cmd), cmd.summary)
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B %s %s\n", ScriptName, manEscape(cmd.name))
+	if len(flags) > 0 {
+		b.WriteString("[\\fIOPTIONS\\fR]\n")
+	}
+	manOptions(&b, flags)
+	fmt.Fprintf(&b, ".SH SEE ALSO\n.BR %s (1)\n", ScriptName)
+	return b.String()
+}
+
+// markdownOptions writes the options table of a Markdown page
+func markdownOptions(b *strings.Builder, flags []*flagDoc) {
+	if len(flags) == 0 {
+		return
+	}
+	b.WriteString("\n## Options\n\n| Option | Description | Default |\n|--------|-------------|---------|\n")
+	for _, f := range flags {
+		defaultValue := ""
+		if f.defaultValue != "" {
+			defaultValue = "`" + f.defaultValue + "`"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s |\n", f, strings.ReplaceAll(f.usage, "|", `\|`), defaultValue)
+	}
+}
+
+// markdownIndex renders gitversion.md, the counterpart of manIndex
+func markdownIndex(commands []commandDoc, flags []*flagDoc) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nCalculates semantic versions from the git history, compatible with GitVersion. Without a command it calculates the version of the current commit.\n", ScriptName)
+	fmt.Fprintf(&b, "\n## Synopsis\n\n    %s [COMMAND] [OPTIONS]\n", ScriptName)
+	markdownOptions(&b, flags)
+	b.WriteString("\n## Commands\n\n| Command | Description |\n|---------|-------------|\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "| [%s](%s.md) | %s |\n", cmd.name, pageName(cmd), cmd.summary)
+	}
+	b.WriteString("\n## Exit Codes\n\n| Code | Meaning |\n|------|---------|\n")
+	for _, exit := range exitCodeDocs {
+		fmt.Fprintf(&b, "| %d | %s |\n", exit.code, exit.meaning)
+	}
+	return b.String()
+}
+
+// markdownPage renders the Markdown page of cmd
+func markdownPage(cmd commandDoc, flags []*flagDoc) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s\n\n%s\n\n## Synopsis\n\n    %s %s", ScriptName, cmd.name, cmd.summary, ScriptName, cmd.name)
+	if len(flags) > 0 {
+		b.WriteString(" [OPTIONS]")
+	}
+	b.WriteString("\n")
+	markdownOptions(&b, flags)
+	fmt.Fprintf(&b, "\nSee [%s](%s.md) for the commands and exit codes.\n", ScriptName, ScriptName)
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/doctor"
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+)
+
+// runDoctor implements "gitversion doctor". It exits with status 1 when a
+// check finds that the calculated version would be unreliable.
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	errorFormatVar(fs)
+	configFile := fs.String("c", "", "Path to configuration file")
+	configFileLong := fs.String("config", "", "Path to configuration file")
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	parseFlags(fs, args)
+
+	configPath := *configFile
+	if *configFileLong != "" {
+		configPath = *configFileLong
+	}
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+
+	report := doctor.Run(git.NewRepository(), configPath, os.Getenv)
+
+	if gitversion.OutputFormat(outputFormat) == gitversion.JSON {
+		printJSON(report)
+	} else {
+		printDoctor(report)
+	}
+
+	if !report.Healthy() {
+		os.Exit(1)
+	}
+}
+
+func printDoctor(report *doctor.Report) {
+	for _, f := range report.Findings {
+		fmt.Printf("[%s] %s: %s\n", strings.ToUpper(string(f.Status)), f.Check, f.Message)
+		if f.Fix != "" {
+			fmt.Printf("    fix: %s\n", f.Fix)
+		}
+	}
+	fmt.Printf("\n%d errors, %d warnings\n", report.Count(doctor.Error), report.Count(doctor.Warning))
+}
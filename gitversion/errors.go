@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// Exit codes, so scripts can tell failures apart. The flag package exits
+// with 2 on invalid flags.
+const (
+	exitFailure    = 1 // a failure without a code of its own
+	exitNotRepo    = 3 // not inside a git repository
+	exitConfig     = 4 // the configuration is invalid
+	exitGit        = 5 // a git command failed
+	exitPrerelease = 6 // --require-stable and the version is a prerelease
+)
+
+// errorFormat is how failures are reported on stderr: text for
+// "[ERROR] message" lines, json for an errorRecord per line
+var errorFormat = "text"
+
+// errorFormatVar defines --error-format on fs
+func errorFormatVar(fs *flag.FlagSet) {
+	fs.Func("error-format", "Format of errors on stderr (text|json) [default: text]", func(value string) error {
+		if value != "text" && value != "json" {
+			return fmt.Errorf("invalid error format %q (use text or json)", value)
+		}
+		errorFormat = value
+		return nil
+	})
+}
+
+// errorRecord is a failure as --error-format json prints it
+type errorRecord struct {
+	// Code names the kind of failure, e.g. "not-a-repository"
+	Code     string `json:"Code"`
+	ExitCode int    `json:"ExitCode"`
+	Message  string `json:"Message"`
+	// Hint suggests how to fix the failure
+	Hint string `json:"Hint,omitempty"`
+	// Field is the configuration key at fault
+	Field string `json:"Field,omitempty"`
+}
+
+// exitCode returns the exit code for err
+func exitCode(err error) int {
+	gitErr := gitError(err)
+	var problem *config.ValidationError
+	var problems config.ValidationErrors
+	switch {
+	case errors.Is(err, v1.ErrNotARepository):
+		return exitNotRepo
+	case errors.Is(err, v1.ErrInvalidConfig), errors.As(err, &problem), errors.As(err, &problems):
+		return exitConfig
+	case gitErr != nil:
+		// Commands run outside a repository fail rather than report it
+		if strings.Contains(gitErr.Stderr, "not a git repository") {
+			return exitNotRepo
+		}
+		return exitGit
+	}
+	return exitFailure
+}
+
+// gitError returns the failed git command behind err, nil for other
+// failures. The v1 API reports one as a GitError, the commands that run git
+// themselves as a git.CommandError.
+func gitError(err error) *v1.GitError {
+	var gitErr *v1.GitError
+	if errors.As(err, &gitErr) {
+		return gitErr
+	}
+	var commandErr *git.CommandError
+	if errors.As(err, &commandErr) {
+		return &v1.GitError{Args: commandErr.Args, Stderr: commandErr.Stderr, Err: commandErr.Err}
+	}
+	return nil
+}
+
+// newErrorRecord describes err, which exits with code
+func newErrorRecord(code int, err error) *errorRecord {
+	record := &errorRecord{Code: "failure", ExitCode: code, Message: err.Error()}
+	var configErr *v1.ConfigError
+	var problem *config.ValidationError
+	gitErr := gitError(err)
+	switch {
+	case code == exitNotRepo:
+		record.Code, record.Hint = "not-a-repository", "run gitversion inside a git working tree"
+	case code == exitConfig:
+		record.Code, record.Hint = "invalid-config", "run 'gitversion config validate' to list every problem"
+		if errors.As(err, &configErr) {
+			record.Field = configErr.Field
+		} else if errors.As(err, &problem) {
+			record.Field = problem.Path
+		}
+	case code == exitPrerelease:
+		record.Code, record.Hint = "prerelease", "run on a branch that produces stable versions, or drop --require-stable"
+	case errors.Is(err, v1.ErrShallowClone):
+		record.Code, record.Hint = "shallow-clone", "run 'git fetch --unshallow' or clone with full history (actions/checkout: fetch-depth: 0)"
+	case errors.Is(err, v1.ErrNoCommits):
+		record.Code, record.Hint = "no-commits", "commit before tagging or recording a version"
+	case errors.Is(err, context.DeadlineExceeded):
+		record.Code, record.Hint = "timeout", "raise --timeout, or check whether git is waiting for credentials"
+	case code == exitGit && gitErr != nil:
+		record.Code, record.Hint = "git-failed", fmt.Sprintf("check that 'git %s' succeeds in this repository", strings.Join(gitErr.Args, " "))
+	}
+	return record
+}
+
+// reportError prints err, which exits with code, on stderr in the error
+// format
+func reportError(code int, err error) {
+	if errorFormat != "json" {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return
+	}
+	data, _ := json.Marshal(newErrorRecord(code, err))
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// fail reports err and exits with its exit code
+func fail(err error) {
+	failWith(exitCode(err), err)
+}
+
+// failWith reports err and exits with code
+func failWith(code int, err error) {
+	reportError(code, err)
+	os.Exit(code)
+}
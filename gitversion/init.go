@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// runInit implements "gitversion init". It asks for the workflow, tag
+// prefix and main branch and writes a commented configuration file. With
+// --defaults nothing is asked and the defaults, or the answers given as
+// flags, are used.
+func runInit(args []string) {
+	defaults := config.DefaultInitOptions()
+	if git.NewRepository().RefExists("refs/heads/master") && !git.NewRepository().RefExists("refs/heads/main") {
+		defaults.MainBranch = "master"
+	}
+
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	errorFormatVar(fs)
+	useDefaults := fs.Bool("defaults", false, "Do not ask; use the defaults and the answers given as flags")
+	workflow := fs.String("workflow", defaults.Workflow, "Workflow (gitflow|githubflow|trunk)")
+	tagPrefix := fs.String("tag-prefix", defaults.TagPrefix, "Prefix of version tags")
+	mainBranch := fs.String("main-branch", defaults.MainBranch, "Name of the main branch")
+	output := fs.String("o", "GitVersion.yml", "File to write")
+	outputLong := fs.String("output", "GitVersion.yml", "File to write")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	parseFlags(fs, args)
+
+	path := *output
+	if *outputLong != "GitVersion.yml" {
+		path = *outputLong
+	}
+	if _, err := os.Stat(path); err == nil && !*force {
+		fail(fmt.Errorf("%s already exists (use --force to overwrite)", path))
+	}
+
+	opts := config.InitOptions{Workflow: *workflow, TagPrefix: *tagPrefix, MainBranch: *mainBranch}
+	if !*useDefaults {
+		in := bufio.NewReader(os.Stdin)
+		opts.Workflow = ask(in, "Workflow (gitflow|githubflow|trunk)", opts.Workflow)
+		opts.TagPrefix = ask(in, "Tag prefix", opts.TagPrefix)
+		opts.MainBranch = ask(in, "Main branch", opts.MainBranch)
+	}
+	opts.Workflow = strings.ToLower(opts.Workflow)
+
+	content, err := config.GenerateYAML(opts)
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		fail(fmt.Errorf("failed to write %s: %w", path, err))
+	}
+	logInfo("Wrote %s; run gitversion -c %s", path, path)
+}
+
+// ask prompts on stderr, keeping stdout clean, and returns the answer or
+// def for an empty answer
+func ask(in *bufio.Reader, question, def string) string {
+	fmt.Fprintf(os.Stderr, "%s [%s]: ", question, def)
+	answer, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return def
+	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/commitlint"
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runLintCommit implements "gitversion lint-commit", which checks commit
+// messages from a commit-msg hook or a pull request check. It exits with
+// status 1 when a message has problems.
+func runLintCommit(args []string) {
+	fs := flag.NewFlagSet("lint-commit", flag.ExitOnError)
+	errorFormatVar(fs)
+	configPaths := configVar(fs)
+	file := fs.String("file", "", "Commit message file to lint, e.g. the one a commit-msg hook gets; - reads stdin")
+	revisions := fs.String("range", "", "Commits to lint, e.g. origin/main..HEAD")
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	parseFlags(fs, args)
+
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+	if (*file == "") == (*revisions == "") {
+		fail(fmt.Errorf("usage: %s lint-commit --file MSG | --range A..B [-c FILE] [-o text|json]", ScriptName))
+	}
+
+	cfg, err := config.LoadConfig(*configPaths...)
+	if err != nil {
+		fail(err)
+	}
+	linter, err := commitlint.New(cfg)
+	if err != nil {
+		failWith(exitConfig, err)
+	}
+
+	var report *commitlint.Report
+	if *file != "" {
+		message, err := readMessage(*file)
+		if err != nil {
+			fail(err)
+		}
+		report = &commitlint.Report{Results: []*commitlint.Result{linter.Lint(commitlint.CleanMessage(message))}}
+	} else {
+		repo := git.NewRepository()
+		if !repo.IsRepository() {
+			fail(v1.ErrNotARepository)
+		}
+		if report, err = linter.LintRange(repo, *revisions); err != nil {
+			fail(err)
+		}
+	}
+
+	if v1.OutputFormat(outputFormat) == v1.JSON {
+		printJSON(report)
+	} else {
+		printLintCommit(report)
+	}
+	if !report.Valid() {
+		os.Exit(exitFailure)
+	}
+}
+
+// readMessage reads the message file path, or stdin for "-"
+func readMessage(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the commit message: %w", err)
+	}
+	return string(data), nil
+}
+
+func printLintCommit(report *commitlint.Report) {
+	if len(report.Results) == 0 {
+		fmt.Println("No commits to lint")
+		return
+	}
+	for _, result := range report.Results {
+		status := "OK"
+		if !result.Valid() {
+			status = "FAIL"
+		}
+		commit := ""
+		if len(result.SHA) >= 7 {
+			commit = result.SHA[:7] + " "
+		}
+		fmt.Printf("[%s] %s%s\n", status, commit, result.Subject)
+		fmt.Printf("    increment: %s (%s)\n", result.Increment, result.Cause)
+		for _, problem := range result.Problems {
+			fmt.Printf("    problem: %s\n", problem)
+		}
+	}
+	fmt.Printf("\n%d of %d messages have problems\n", report.Invalid(), len(report.Results))
+}
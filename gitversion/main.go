@@ -1,40 +1,236 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
-	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/gomod"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/fileupdate"
 	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
+	"github.com/VirtuallyScott/gitversion-go/pkg/publish"
 )
 
-const (
-	Version    = "1.0.0"
-	ScriptName = "gitversion"
-)
+// Version is set at build time with -ldflags "-X main.Version=..."
+var Version = "1.0.0"
+
+const ScriptName = "gitversion"
+
+// quiet suppresses informational messages. Results are the only thing ever
+// written to stdout; messages always go to stderr.
+var quiet bool
+
+// listFlag collects the values of a repeatable flag, in order
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// listVar defines a repeatable flag on fs
+func listVar(fs *flag.FlagSet, name, usage string) *listFlag {
+	values := &listFlag{}
+	fs.Var(values, name, usage)
+	return values
+}
+
+// configVar defines -c and --config on fs, collecting both into one list.
+// Later files are layered over earlier ones.
+func configVar(fs *flag.FlagSet) *listFlag {
+	paths := &listFlag{}
+	fs.Var(paths, "c", "Path or URL of configuration file; repeat to layer files")
+	fs.Var(paths, "config", "Path or URL of configuration file; repeat to layer files")
+	return paths
+}
+
+// logInfo reports progress of side effects such as tag creation on stderr
+func logInfo(format string, args ...interface{}) {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "[INFO] "+format+"\n", args...)
+	}
+}
+
+// requireStable fails with exitPrerelease when the version of result, of
+// the module or project name when set, is a prerelease
+func requireStable(name string, result *v1.Result) {
+	if result.PreReleaseTag == "" {
+		return
+	}
+	err := fmt.Errorf("version %s is a prerelease (--require-stable)", result.FullSemVer)
+	if name != "" {
+		err = fmt.Errorf("%s: %w", name, err)
+	}
+	failWith(exitPrerelease, err)
+}
+
+// verbosityLevels maps the GitVersion verbosity names to the lowest level
+// logged. quiet and minimal also suppress [INFO] messages; detailed adds the
+// candidate base versions, diagnostic every git command.
+var verbosityLevels = map[string]slog.Level{
+	"quiet":      slog.LevelError,
+	"minimal":    slog.LevelWarn,
+	"normal":     slog.LevelWarn,
+	"detailed":   slog.LevelInfo,
+	"diagnostic": slog.LevelDebug,
+}
+
+// verbosityLevel returns the lowest level logged at verbosity, normal when
+// empty
+func verbosityLevel(verbosity string) (slog.Level, error) {
+	if verbosity == "" {
+		verbosity = "normal"
+	}
+	level, ok := verbosityLevels[verbosity]
+	if !ok {
+		return 0, fmt.Errorf("invalid verbosity %q (use quiet, minimal, normal, detailed or diagnostic)", verbosity)
+	}
+	return level, nil
+}
+
+// newLogger returns a logger writing records to stderr in format, text or
+// json. level overrides the verbosity's fallback level when set.
+func newLogger(format, level string, fallback slog.Level) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: fallback}
+	if level != "" {
+		var parsed slog.Level
+		if err := parsed.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q (use debug, info, warn or error)", level)
+		}
+		options.Level = parsed
+	}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, options)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q (use text or json)", format)
+}
 
 func main() {
+	// Subcommands take their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "branches":
+			runBranches(os.Args[2:])
+			return
+		case "notes":
+			runNotes(os.Args[2:])
+			return
+		case "crosscheck":
+			runCrosscheck(os.Args[2:])
+			return
+		case "tag":
+			runTag(os.Args[2:])
+			return
+		case "release-notes":
+			runReleaseNotes(os.Args[2:])
+			return
+		case "update-files":
+			runUpdateFiles(os.Args[2:])
+			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		case "normalize":
+			runNormalize(os.Args[2:])
+			return
+		case "lint-commit":
+			runLintCommit(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "cache":
+			runCache(os.Args[2:])
+			return
+		case "init":
+			runInit(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "docs":
+			runDocs(os.Args[2:])
+			return
+		case "calculate":
+			// Calculation is the default; the name is accepted for symmetry
+			runCalculate(os.Args[2:])
+			return
+		}
+	}
+	runCalculate(os.Args[1:])
+}
+
+// runCalculate implements "gitversion [calculate]", which calculates the
+// version of the current commit
+func runCalculate(args []string) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	var (
-		help           = flag.Bool("h", false, "Show help message")
-		helpLong       = flag.Bool("help", false, "Show help message")
-		ver            = flag.Bool("v", false, "Show version information")
-		versionLong    = flag.Bool("version", false, "Show version information")
-		output         = flag.String("o", "text", "Output format (json|text|AssemblySemVer|AssemblySemFileVer)")
-		outputLong     = flag.String("output", "text", "Output format (json|text|AssemblySemVer|AssemblySemFileVer)")
-		configFile     = flag.String("c", "", "Path to configuration file")
-		configFileLong = flag.String("config", "", "Path to configuration file")
-		branch         = flag.String("b", "", "Target branch")
-		branchLong     = flag.String("branch", "", "Target branch")
-		workflow       = flag.String("w", "gitflow", "Workflow type (gitflow|githubflow|trunk)")
-		workflowLong   = flag.String("workflow", "gitflow", "Workflow type (gitflow|githubflow|trunk)")
-		major          = flag.Bool("major", false, "Force major version increment")
-		minor          = flag.Bool("minor", false, "Force minor version increment")
-		patch          = flag.Bool("patch", false, "Force patch version increment")
-		nextVersion    = flag.String("next-version", "", "Override next version")
+		help           = fs.Bool("h", false, "Show help message")
+		helpLong       = fs.Bool("help", false, "Show help message")
+		ver            = fs.Bool("v", false, "Show version information")
+		versionLong    = fs.Bool("version", false, "Show version information")
+		output         = fs.String("o", "text", "Output format (json|text|AssemblySemVer|AssemblySemFileVer|buildserver|githubactions|describe|env|shell|goldflags|docker)")
+		outputLong     = fs.String("output", "text", "Output format (json|text|AssemblySemVer|AssemblySemFileVer|buildserver|githubactions|describe|env|shell|goldflags|docker)")
+		configFileList = configVar(fs)
+		overrideConfig = listVar(fs, "override-config", "Set a config value, e.g. branches.main.label=stable; repeatable")
+		includePaths   = listVar(fs, "include-path", "Only count commits touching this directory; repeatable")
+		branch         = fs.String("b", "", "Target branch")
+		branchLong     = fs.String("branch", "", "Target branch")
+		workflow       = fs.String("w", "gitflow", "Workflow type (gitflow|githubflow|trunk)")
+		workflowLong   = fs.String("workflow", "gitflow", "Workflow type (gitflow|githubflow|trunk)")
+		major          = fs.Bool("major", false, "Force major version increment")
+		minor          = fs.Bool("minor", false, "Force minor version increment")
+		patch          = fs.Bool("patch", false, "Force patch version increment")
+		nextVersion    = fs.String("next-version", "", "Override next version")
+		strategies     = fs.String("strategies", "", "Comma-separated list of version strategies")
+		progressMode   = fs.String("progress", "auto", "Progress reporting (auto|plain|none)")
+		explain        = fs.Bool("explain", false, "Explain how the version was calculated")
+		apply          = fs.Bool("apply", false, "Create the version tag if the branch allows auto-tag")
+		publishFlag    = fs.Bool("publish", false, "Run the publishers configured in the config file")
+		module         = fs.String("module", "", "Calculate the version of the Go module in this directory")
+		project        = fs.String("project", "", "Layer the config file in this directory over the root config")
+		component      = fs.String("component", "", "Version the component with this name; its tags are prefixed name/")
+		goModules      = fs.Bool("go-modules", false, "Calculate the version of every Go module in the repository")
+		allProjects    = fs.Bool("all-projects", false, "Calculate the version of every project and print them as a JSON map")
+		maxDuration    = fs.Duration("max-duration", 0, "Time budget for version strategies (e.g. 30s)")
+		timeout        = fs.Duration("timeout", 0, "Fail when the calculation takes longer, killing hung git commands (e.g. 2m)")
+		logFormat      = fs.String("log-format", "text", "Log record format (text|json)")
+		logLevel       = fs.String("log-level", "", "Lowest level logged (debug|info|warn|error) [default: set by --verbosity]")
+		verbosity      = fs.String("verbosity", "", "How much is printed to stderr (quiet|minimal|normal|detailed|diagnostic) [default: normal]")
+		recordNote     = fs.Bool("record-note", false, "Record the version and its provenance as a git note on HEAD")
+		updateAssembly = fs.Bool("update-assembly-info", false, "Write the version into the AssemblyInfo files of the repository")
+		assemblyInfo   = listVar(fs, "assembly-info", "AssemblyInfo file to update instead of all of them; repeatable")
+		ensureAssembly = fs.Bool("ensure-assembly-info", false, "Create the --assembly-info files that do not exist")
+		goPackage      = fs.String("package", "", "Package whose version, commit and date -o goldflags sets [default: main]")
+		dockerLabels   = fs.Bool("labels", false, "With -o docker, print OCI label arguments for docker build instead of the tag")
+		showVariable   = fs.String("show-variable", "", "Print only the named variable, e.g. SemVer")
+		strictConfig   = fs.Bool("strict-config", false, "Reject unknown keys and invalid values in the config file")
+		noCache        = fs.Bool("no-cache", false, "Recalculate instead of reusing a cached result")
+		fetch          = fs.Bool("fetch", false, "Fetch tags and the branches the calculation looks at before calculating")
+		noFetch        = fs.Bool("no-fetch", false, "Calculate from the local refs without fetching; the default, overrides --fetch")
+		allowDeepen    = fs.Bool("allow-deepen", false, "Deepen a shallow clone until a version tag is reachable, or fetch its full history")
+		remote         = fs.String("remote", "", "Primary remote, also fetched from by --fetch and --allow-deepen [default: remote-name, else origin]")
+		stableOnly     = fs.Bool("require-stable", false, "Fail with exit code 6 when the version is a prerelease")
+		quietFlag      = fs.Bool("q", false, "Print only the result; suppress informational messages")
+		quietLong      = fs.Bool("quiet", false, "Print only the result; suppress informational messages")
 	)
-
-	flag.Parse()
+	errorFormatVar(fs)
+	parseFlags(fs, args)
 
 	if *help || *helpLong {
 		showHelp()
@@ -47,26 +243,37 @@ func main() {
 	}
 
 	debug := os.Getenv("DEBUG") == "true"
+	// DEBUG=true predates --verbosity and stands for its most verbose level
+	verbosityName := strings.ToLower(*verbosity)
+	if verbosityName == "" && debug {
+		verbosityName = "diagnostic"
+	}
+	level, err := verbosityLevel(verbosityName)
+	if err != nil {
+		fail(err)
+	}
+	quiet = *quietFlag || *quietLong || verbosityName == "quiet" || verbosityName == "minimal"
 
 	outputFormat := *output
 	if *outputLong != "text" {
 		outputFormat = *outputLong
 	}
 
-	configPath := *configFile
-	if *configFileLong != "" {
-		configPath = *configFileLong
-	}
-
 	targetBranch := *branch
 	if *branchLong != "" {
 		targetBranch = *branchLong
 	}
 
-	workflowType := *workflow
-	if *workflowLong != "gitflow" {
-		workflowType = *workflowLong
-	}
+	// Without -w the workflow of the configuration file applies
+	var workflowType string
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "w":
+			workflowType = *workflow
+		case "workflow":
+			workflowType = *workflowLong
+		}
+	})
 
 	var forceIncrement string
 	if *major {
@@ -77,61 +284,442 @@ func main() {
 		forceIncrement = "patch"
 	}
 
-	opts := &gitversion.Options{
-		OutputFormat:   gitversion.OutputFormat(outputFormat),
-		ConfigFile:     configPath,
+	var strategyList []string
+	if *strategies != "" {
+		for _, s := range strings.Split(*strategies, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				strategyList = append(strategyList, s)
+			}
+		}
+	}
+
+	mode := progress.Mode(*progressMode)
+	if quiet {
+		mode = progress.ModeNone
+	}
+	reporter, err := progress.NewForMode(mode, os.Stderr)
+	if err != nil {
+		fail(err)
+	}
+	logger, err := newLogger(*logFormat, *logLevel, level)
+	if err != nil {
+		fail(err)
+	}
+
+	opts := v1.Options{
+		OutputFormat:   v1.OutputFormat(outputFormat),
 		TargetBranch:   targetBranch,
-		Workflow:       version.WorkflowType(workflowType),
+		Workflow:       workflowType,
 		ForceIncrement: forceIncrement,
 		NextVersion:    *nextVersion,
+		Strategies:     strategyList,
+		Module:         *module,
+		Project:        *project,
+		IncludePaths:   *includePaths,
+		Component:      *component,
+		MaxDuration:    *maxDuration,
+		Progress:       reporter,
 		Debug:          debug,
+		Logger:         logger,
+		StrictConfig:   *strictConfig,
+		ConfigFiles:    *configFileList,
+		OverrideConfig: *overrideConfig,
+		GoPackage:      *goPackage,
+		DockerLabels:   *dockerLabels,
+		NoCache:        *noCache,
+		Fetch:          *fetch && !*noFetch,
+		AllowDeepen:    *allowDeepen,
+		Remote:         *remote,
+	}
+
+	// Unlike --max-duration, which settles for a partial result, the
+	// timeout fails the run, e.g. when git waits for credentials
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *goModules {
+		runGoModules(ctx, opts, *apply, *stableOnly)
+		return
+	}
+	if *allProjects {
+		runAllProjects(ctx, opts, *apply, *stableOnly)
+		return
+	}
+
+	client, err := v1.New(opts)
+	if err != nil {
+		fail(err)
+	}
+
+	result, err := client.CalculateContext(ctx)
+	if err != nil {
+		fail(err)
+	}
+	warnPartial(logger, result)
+	warnShallow(logger, result)
+	if *stableOnly {
+		requireStable("", result)
+	}
+
+	if *explain {
+		runExplain(result, opts.OutputFormat)
+		return
+	}
+
+	if *apply {
+		applyTag("", result)
+	}
+
+	if *recordNote {
+		note, err := result.RecordNote()
+		if err != nil {
+			fail(err)
+		}
+		logInfo("Recorded %s on %.7s in %s", note.Version, note.Commit, gitversion.NotesRef)
+	}
+
+	if *updateAssembly || len(*assemblyInfo) > 0 || *ensureAssembly {
+		runUpdateAssemblyInfo(result, *assemblyInfo, *ensureAssembly)
+	}
+
+	if *publishFlag {
+		runPublish(client, result)
+	}
+
+	if *showVariable != "" {
+		value, err := result.Variables.Variable(*showVariable)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(value)
+		return
+	}
+
+	rendered, err := result.Format(opts.OutputFormat)
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Print(rendered)
+}
+
+// warnPartial logs a warning when --max-duration cut the calculation short
+func warnPartial(logger *slog.Logger, result *v1.Result) {
+	if diagnostics := result.Diagnostics(); diagnostics.Partial {
+		logger.Warn("--max-duration exceeded, version is based on partial analysis",
+			"skipped", strings.Join(diagnostics.SkippedStrategies, ", "))
+	}
+}
+
+// warnShallow logs a warning when the calculation ran in a shallow clone
+func warnShallow(logger *slog.Logger, result *v1.Result) {
+	if result.Diagnostics().Shallow {
+		logger.Warn("the clone is shallow, so commit counts and the version may be wrong; " +
+			"fetch the full history (git fetch --unshallow, actions/checkout fetch-depth: 0) or pass --allow-deepen")
+	}
+}
+
+func runPublish(client *v1.Client, result *v1.Result) {
+	var publishers []config.PublisherConfig
+	for _, publisher := range client.Config().Publishers {
+		publishers = append(publishers, config.PublisherConfig{Name: publisher.Name, Options: publisher.Options})
+	}
+	if len(publishers) == 0 {
+		fail(errors.New("no publishers configured"))
+	}
+
+	diagnostics := result.Diagnostics()
+	publishResult := &publish.Result{
+		Variables: &result.Variables,
+		Tag:       result.TagName(),
+		AutoTag:   diagnostics.BranchConfig != nil && diagnostics.BranchConfig.AutoTag,
+	}
+
+	if err := publish.Run(client.RetryContext(context.Background()), publishers, publishResult); err != nil {
+		fail(err)
+	}
+}
+
+// runUpdateAssemblyInfo writes the result into AssemblyInfo files, like
+// GitVersion's /updateassemblyinfo
+func runUpdateAssemblyInfo(result *v1.Result, files []string, ensure bool) {
+	root, err := git.NewRepository().GetRootDir()
+	if err != nil {
+		fail(err)
+	}
+	updates, err := fileupdate.AssemblyInfoUpdates(root, files, ensure)
+	if err != nil {
+		fail(err)
+	}
+	changes, err := fileupdate.Plan(root, updates, &result.Variables)
+	if err == nil {
+		err = fileupdate.Write(changes)
+	}
+	if err != nil {
+		fail(err)
+	}
+	for _, change := range changes {
+		if change.Changed() {
+			logInfo("Updated %s", change.Path)
+		}
+	}
+}
+
+// moduleVersion is the JSON output of --go-modules
+type moduleVersion struct {
+	Module     string `json:"Module"`
+	Dir        string `json:"Dir"`
+	Tag        string `json:"Tag"`
+	FullSemVer string `json:"FullSemVer"`
+}
+
+func runGoModules(ctx context.Context, opts v1.Options, apply, stableOnly bool) {
+	root, err := git.NewRepository().GetRootDir()
+	if err != nil {
+		fail(err)
+	}
+	modules, err := gomod.Discover(root)
+	if err != nil {
+		fail(err)
+	}
+	if len(modules) == 0 {
+		fail(fmt.Errorf("no Go modules found in %s", root))
+	}
+
+	var versions []moduleVersion
+	for _, m := range modules {
+		opts.Module = m.Dir
+		result, err := v1.CalculateContext(ctx, opts)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", m.Dir, err))
+		}
+		warnPartial(opts.Logger, result)
+		warnShallow(opts.Logger, result)
+		if stableOnly {
+			requireStable(m.Dir, result)
+		}
+
+		if apply {
+			applyTag(m.Dir, result)
+		}
+
+		versions = append(versions, moduleVersion{
+			Module:     m.Path,
+			Dir:        m.Dir,
+			Tag:        result.TagName(),
+			FullSemVer: result.FullSemVer,
+		})
+	}
+
+	if opts.OutputFormat == v1.JSON {
+		data, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			fail(fmt.Errorf("failed to marshal JSON: %w", err))
+		}
+		fmt.Println(string(data))
+		return
 	}
 
-	gv, err := gitversion.New(opts)
+	for _, v := range versions {
+		fmt.Printf("%s %s (%s)\n", v.Dir, v.FullSemVer, v.Tag)
+	}
+}
+
+// runAllProjects prints the variables of every project as a JSON object
+// keyed by component, or by directory for projects without one
+func runAllProjects(ctx context.Context, opts v1.Options, apply, stableOnly bool) {
+	results, err := v1.CalculateProjectsContext(ctx, opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
-		os.Exit(1)
+		fail(err)
 	}
 
-	result, err := gv.Calculate(opts)
+	variables := make(map[string]*v1.Variables, len(results))
+	for name, result := range results {
+		warnPartial(opts.Logger, result)
+		warnShallow(opts.Logger, result)
+		if stableOnly {
+			requireStable(name, result)
+		}
+		if apply {
+			applyTag(name, result)
+		}
+		variables[name] = &result.Variables
+	}
+
+	data, err := json.MarshalIndent(variables, "", "  ")
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
-		os.Exit(1)
+		fail(fmt.Errorf("failed to marshal JSON: %w", err))
 	}
+	fmt.Println(string(data))
+}
 
-	fmt.Print(result)
+// applyTag implements --apply for the result of the module or project
+// name, "" for the repository. Branches without auto-tag are skipped, so
+// the version is still printed.
+func applyTag(name string, result *v1.Result) {
+	tagResult, err := result.ApplyTag()
+	if err != nil {
+		if name != "" {
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+		fail(err)
+	}
+	switch {
+	case tagResult.Skipped:
+		logInfo("Skipping the tag: auto-tag is off for branch %s", result.BranchName)
+	case tagResult.Created:
+		logInfo("Created tag %s", tagResult.Tag)
+	default:
+		logInfo("Tag %s already exists", tagResult.Tag)
+	}
+}
+
+func runExplain(result *v1.Result, format v1.OutputFormat) {
+	diagnostics := result.Diagnostics()
+
+	if format == v1.JSON {
+		data, err := json.MarshalIndent(diagnostics, "", "  ")
+		if err != nil {
+			fail(fmt.Errorf("failed to marshal JSON: %w", err))
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Print(diagnostics.String())
 }
 
 func showHelp() {
-	fmt.Printf(`%s v%s - GitVersion Go implementation
+	fmt.Printf(`%[1]s v%[2]s - GitVersion Go implementation
 
 USAGE:
-    %s [OPTIONS]
+    %[1]s [calculate] [OPTIONS]
+    %[1]s branches report [-c FILE] [-o text|json]
+    %[1]s notes list|show [-o text|json] [REV]
+    %[1]s cache list [-o text|json] | cache stats [-c FILE] [-o text|json] | cache clear [--older-than DURATION]
+    %[1]s crosscheck [--tool PATH] [--all] [-c FILE] [-o text|json]
+    %[1]s tag [--prefix PREFIX] [--component NAME] [--allow-prerelease] [--sign] [--push [--remote NAME]] [--timeout DURATION] [-b BRANCH] [-c FILE]
+    %[1]s release-notes [--template FILE] [-c FILE]
+    %[1]s update-files [--dry-run] [--diff] [-b BRANCH] [-c FILE]
+    %[1]s doctor [-c FILE] [-o text|json]
+    %[1]s normalize [--remote NAME] [-c FILE] [--dry-run] [-o text|json]
+    %[1]s lint-commit --file MSG | --range A..B [-c FILE] [-o text|json]
+    %[1]s serve [--listen ADDR | --socket PATH] [--root DIR]... [-c FILE] [--max-duration DURATION] [--watch [--poll-interval DURATION]] [--remote NAME]
+    %[1]s init [--defaults] [--workflow TYPE] [--tag-prefix PREFIX] [--main-branch NAME] [-o FILE] [--force]
+    %[1]s config show [-c FILE] [--project DIR] [-b BRANCH] [-o yaml|json]
+    %[1]s config validate [-c FILE]
+    %[1]s config schema
+    %[1]s docs --man|--markdown [--dir DIR]
 
 OPTIONS:
     -h, --help              Show this help message
     -v, --version           Show version information
-    -o, --output FORMAT     Output format (json|text|AssemblySemVer|AssemblySemFileVer) [default: text]
-    -c, --config FILE       Path to configuration file
+    -q, --quiet             Print only the result; informational messages are suppressed
+    -o, --output FORMAT     Output format (json|text|AssemblySemVer|AssemblySemFileVer|buildserver|githubactions|describe|env|shell|goldflags|docker) [default: text]
+    -c, --config FILE       Path or URL of configuration file; repeat to layer files
     -b, --branch BRANCH     Target branch [default: current branch]
-    -w, --workflow TYPE     Workflow type (gitflow|githubflow|trunk) [default: gitflow]
+    -w, --workflow TYPE     Workflow type (gitflow|githubflow|trunk) [default: from config, else gitflow]
     --major                 Force major version increment
     --minor                 Force minor version increment
     --patch                 Force patch version increment
     --next-version VERSION  Override next version
+    --strategies LIST       Comma-separated version strategies [default: from config]
+    --progress MODE         Progress reporting (auto|plain|none) [default: auto]
+    --explain               Explain how the version was calculated
+    --apply                 Create the version tag if the branch allows auto-tag
+    --publish               Run the publishers configured in the config file
+    --module DIR            Version the Go module in DIR (relative to the repository root)
+    --project DIR           Layer DIR's config file over the root config (DIR relative to the repository root)
+    --include-path DIR      Only count commits touching DIR (relative to the repository root); repeatable
+    --component NAME        Version the component NAME, whose tags are prefixed NAME/, e.g. api/v1.2.3
+    --go-modules            Version every Go module in the repository
+    --all-projects          Version every project; prints a JSON map of component to variables
+    --max-duration DURATION Time budget for strategies, e.g. 30s; partial results are flagged
+    --timeout DURATION      Fail when calculating takes longer, killing hung git commands, e.g. 2m
+    --log-format FORMAT     Format of log records on stderr: text or json [default: text]
+    --error-format FORMAT   Format of errors on stderr: text or json [default: text]
+    --log-level LEVEL       Lowest level logged: debug, info, warn or error [default: set by --verbosity]
+    --verbosity LEVEL       quiet, minimal, normal, detailed or diagnostic [default: normal]
+    --record-note           Record the version and its provenance as a git note on HEAD
+    --update-assembly-info  Write the version into every AssemblyInfo.cs and AssemblyInfo.vb
+    --assembly-info FILE    Update FILE instead (relative to the repository root); repeatable
+    --ensure-assembly-info  Create the --assembly-info files that do not exist
+    --show-variable NAME    Print only the named variable, e.g. SemVer
+    --package PKG           Package whose version, commit and date -o goldflags sets [default: main]
+    --labels                With -o docker, print OCI label arguments for docker build instead of the tag
+    --strict-config         Reject unknown keys, invalid values and bad regexes in the config file
+    --no-cache              Recalculate instead of reusing a cached result
+    --fetch                 Fetch tags and the target and source branches before calculating
+    --no-fetch              Use the local refs only [default]; overrides --fetch
+    --allow-deepen          Deepen a shallow clone until a version tag is reachable, or fetch all of it
+    --remote NAME           Primary remote, fetched from by --fetch and --allow-deepen [default: remote-name, else origin]
+    --require-stable        Fail with exit code 6 when the version is a prerelease
+    --override-config KEY=VALUE
+                            Set a config value after loading, e.g. branches.main.label=stable; repeatable
 
 EXAMPLES:
-    %s                    # Calculate version for current branch
-    %s -o json            # Output as JSON
-    %s -o AssemblySemVer  # Output AssemblySemVer only
-    %s -o AssemblySemFileVer # Output AssemblySemFileVer only
-    %s -b main            # Calculate version for main branch
-    %s --major            # Force major increment
+    %[1]s                    # Calculate version for current branch
+    %[1]s -o json            # Output as JSON
+    %[1]s -o AssemblySemVer  # Output AssemblySemVer only
+    %[1]s -o AssemblySemFileVer # Output AssemblySemFileVer only
+    %[1]s -o buildserver     # Set variables in the detected CI system
+    %[1]s -o githubactions   # Write GitVersion_* to $GITHUB_OUTPUT and $GITHUB_ENV
+    %[1]s -q --show-variable SemVer # Just the value, safe in $(...)
+    %[1]s -o describe        # git describe style output, e.g. v1.2.3-14-gabc1234
+    %[1]s -o env > .env      # Raw GITVERSION_SEMVER=... lines for docker --env-file
+    eval "$(%[1]s -o shell)" # Export GITVERSION_SEMVER=... into the shell
+    %[1]s -o goldflags       # -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+    %[1]s -o docker          # Image tag without +, e.g. 1.2.3-beta.4-5
+    %[1]s -b main            # Calculate version for main branch
+    %[1]s --major            # Force major increment
+    %[1]s --strategies TaggedCommit,Mainline # Only use selected strategies
+    %[1]s --explain          # Show candidate base versions and the chosen one
+    %[1]s --module tools     # Version the tools module from tools/vX.Y.Z tags
+    %[1]s --go-modules --apply # Tag every Go module that allows auto-tag
+    %[1]s branches report    # List merged/released branches and stale release branches
+    %[1]s --record-note      # Keep an audit trail in refs/notes/gitversion
+    %[1]s notes show v1.2.0  # Show the version recorded for a commit
+    %[1]s crosscheck         # Diff against an installed GitVersion
+    %[1]s tag                # Create an annotated tag such as v1.4.0 on HEAD
+    %[1]s tag --push         # ... and push it to origin
+    %[1]s release-notes > NOTES.md # Changes since the previous release
+    %[1]s update-files --dry-run # Show the version changes file-updates would make
+    %[1]s --fetch            # Fetch tags first, so CI never versions from stale tags
+    %[1]s doctor             # Check for shallow clones, missing tags and more
+    %[1]s normalize          # Unshallow a CI checkout, fetch its branches and tags, attach HEAD
+    %[1]s lint-commit --range origin/main..HEAD # Check the commit messages of a pull request
+    %[1]s serve --root /srv/repos # Answer GET /version?repo=...&branch=... over HTTP
+    %[1]s serve --watch --socket /tmp/gitversion.sock # Serve a warm version to a dev loop
+    %[1]s cache clear        # Purge cached calculations, e.g. after a history rewrite
+    %[1]s init               # Write a commented GitVersion.yml
+    %[1]s config show -b feature/x # Effective configuration and where it came from
+    %[1]s docs --man --dir man/man1 # Manual pages generated from the flag definitions
 
 ENVIRONMENT VARIABLES:
-    DEBUG=true              Enable debug logging
+    DEBUG=true              Enable debug logging, as --verbosity diagnostic does
+    GITVERSION_REMOTE_TOKEN Token for tag --push, --fetch and normalize over HTTPS [default: git credential helpers]
+    GITVERSION_REMOTE_PASSWORD Password sent when no token is set
+    GITVERSION_REMOTE_USERNAME User name sent with the token or password [default: x-access-token]
+    GITVERSION_SSH_IDENTITY_FILE Private key for SSH remotes [default: ssh-agent and ssh's configuration]
+    GITVERSION_SSH_STRICT_HOST_KEY_CHECKING Host key checking for SSH remotes (yes|accept-new|no)
+    GITVERSION_SSH_KNOWN_HOSTS_FILE known_hosts file for SSH remotes [default: ~/.ssh/known_hosts]
+    GITVERSION_SERVE_AUTH   user:password required by serve as HTTP basic auth
+    GITVERSION_WEBHOOK_SECRET Secret of the push webhooks serve accepts at /webhook
+
+EXIT CODES:
+    0                       Success
+    1                       Any other failure
+    2                       Invalid command line flags
+    3                       Not inside a git repository
+    4                       Invalid configuration
+    5                       A git command failed
+    6                       The version is a prerelease and --require-stable is set
 
-`, ScriptName, Version, ScriptName, ScriptName, ScriptName, ScriptName, ScriptName, ScriptName, ScriptName)
+`, ScriptName, Version)
 }
 
 func showVersion() {
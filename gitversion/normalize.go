@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/normalize"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runNormalize implements "gitversion normalize", which turns a CI checkout
+// into one versioning can trust, fetching over HTTPS with the
+// GITVERSION_REMOTE_* variables when they are set.
+func runNormalize(args []string) {
+	fs := flag.NewFlagSet("normalize", flag.ExitOnError)
+	errorFormatVar(fs)
+	configPaths := configVar(fs)
+	remote := fs.String("remote", "", "Remote to fetch branches and tags from [default: remote-name, else origin]")
+	dryRun := fs.Bool("dry-run", false, "Print the steps without changing the repository")
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	parseFlags(fs, args)
+
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+
+	repo := git.NewRepository()
+	if !repo.IsRepository() {
+		fail(v1.ErrNotARepository)
+	}
+	if *remote == "" {
+		cfg, err := config.LoadConfig(*configPaths...)
+		if err != nil {
+			fail(err)
+		}
+		*remote = cfg.RemoteName
+	}
+	auth, err := git.RemoteAuthFromEnv(os.Getenv)
+	if err != nil {
+		fail(err)
+	}
+	opts := normalize.Options{
+		Remote: *remote,
+		Auth:   auth,
+		DryRun: *dryRun,
+	}
+	report, err := normalize.Run(repo, opts, os.Getenv)
+	if v1.OutputFormat(outputFormat) == v1.JSON {
+		printJSON(report)
+	} else {
+		printNormalize(report)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func printNormalize(report *normalize.Report) {
+	if report == nil {
+		return
+	}
+	for _, step := range report.Steps {
+		fmt.Printf("[%s] %s\n", step.Action, step.Detail)
+	}
+	switch {
+	case len(report.Steps) == 0:
+		fmt.Println("Nothing to do")
+	case report.DryRun:
+		fmt.Println("\nDry run; the repository was not changed")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runNotes implements "gitversion notes list" and "gitversion notes show"
+func runNotes(args []string) {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "show") {
+		fail(fmt.Errorf("usage: %s notes list|show [-o text|json] [REV]", ScriptName))
+	}
+
+	fs := flag.NewFlagSet("notes "+args[0], flag.ExitOnError)
+	errorFormatVar(fs)
+	output := fs.String("o", "text", "Output format (text|json)")
+	outputLong := fs.String("output", "text", "Output format (text|json)")
+	parseFlags(fs, args[1:])
+
+	outputFormat := *output
+	if *outputLong != "text" {
+		outputFormat = *outputLong
+	}
+	asJSON := gitversion.OutputFormat(outputFormat) == gitversion.JSON
+
+	client, err := v1.New(v1.Options{Debug: os.Getenv("DEBUG") == "true"})
+	if err != nil {
+		fail(err)
+	}
+
+	if args[0] == "list" {
+		notes, err := client.Notes()
+		if err != nil {
+			fail(err)
+		}
+		if asJSON {
+			printJSON(notes)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMMIT\tVERSION\tBRANCH\tRECORDED")
+		for _, note := range notes {
+			fmt.Fprintf(w, "%.7s\t%s\t%s\t%s\n", note.Commit, note.Version, note.Branch, note.RecordedAt.Format(time.RFC3339))
+		}
+		w.Flush()
+		return
+	}
+
+	rev := "HEAD"
+	if fs.NArg() > 0 {
+		rev = fs.Arg(0)
+	}
+	note, err := client.Note(rev)
+	if err != nil {
+		fail(err)
+	}
+	if note == nil {
+		fail(fmt.Errorf("no version recorded for %s", rev))
+	}
+	if asJSON {
+		printJSON(note)
+		return
+	}
+
+	fmt.Printf("Commit:      %s\n", note.Commit)
+	fmt.Printf("Recorded:    %s\n", note.RecordedAt.Format(time.RFC3339))
+	fmt.Printf("Tag:         %s\n", note.Tag)
+	if note.Diagnostics != nil {
+		fmt.Print(note.Diagnostics.String())
+	} else {
+		fmt.Printf("Version:     %s\n", note.Version)
+	}
+}
+
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fail(fmt.Errorf("failed to marshal JSON: %w", err))
+	}
+	fmt.Println(string(data))
+}
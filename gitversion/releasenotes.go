@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+	"github.com/VirtuallyScott/gitversion-go/pkg/releasenotes"
+)
+
+// runReleaseNotes implements "gitversion release-notes"
+func runReleaseNotes(args []string) {
+	fs := flag.NewFlagSet("release-notes", flag.ExitOnError)
+	errorFormatVar(fs)
+	configPaths := configVar(fs)
+	templateFile := fs.String("template", "", "Go template file [default: release-notes.template from config]")
+	parseFlags(fs, args)
+
+	client, err := v1.New(v1.Options{
+		ConfigFiles: *configPaths,
+		Debug:       os.Getenv("DEBUG") == "true",
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	templatePath := *templateFile
+	if templatePath == "" {
+		templatePath = client.Config().ReleaseNotes.Template
+	}
+	text, err := releasenotes.LoadTemplate(templatePath)
+	if err != nil {
+		fail(err)
+	}
+
+	result, err := client.Calculate()
+	if err != nil {
+		fail(err)
+	}
+	notes, err := result.ReleaseNotes()
+	if err != nil {
+		fail(err)
+	}
+	rendered, err := notes.Render(text)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Print(rendered)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/server"
+)
+
+// runServe implements "gitversion serve", which answers version requests
+// over HTTP until it is stopped
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	errorFormatVar(fs)
+	listen := fs.String("listen", ":8080", "Address to listen on")
+	socket := fs.String("socket", "", "Unix socket to listen on instead of --listen")
+	roots := listVar(fs, "root", "Directory whose repositories may be queried; repeatable [default: .]")
+	configFiles := configVar(fs)
+	maxDuration := fs.Duration("max-duration", 0, "Time budget for the strategies of each calculation (e.g. 30s)")
+	watch := fs.Bool("watch", false, "Keep the version of the first root warm, recalculating when its refs change")
+	interval := fs.Duration("poll-interval", server.DefaultPollInterval, "How often --watch looks for changed refs")
+	remote := fs.String("remote", server.DefaultRemote, "Remote webhooks fetch from and push release tags to")
+	parseFlags(fs, args)
+
+	// Credentials come from the environment so they stay out of ps output
+	opts := server.Options{
+		Roots:         *roots,
+		ConfigFiles:   *configFiles,
+		MaxDuration:   *maxDuration,
+		WebhookSecret: os.Getenv("GITVERSION_WEBHOOK_SECRET"),
+		Remote:        *remote,
+	}
+	if auth := os.Getenv("GITVERSION_SERVE_AUTH"); auth != "" {
+		username, password, ok := strings.Cut(auth, ":")
+		if !ok || username == "" {
+			fail(errors.New("GITVERSION_SERVE_AUTH must be user:password"))
+		}
+		opts.Username, opts.Password = username, password
+	}
+
+	handler, err := server.New(opts)
+	if err != nil {
+		fail(err)
+	}
+
+	if *watch {
+		go func() {
+			if err := handler.Watch(context.Background(), *interval); err != nil {
+				fail(err)
+			}
+		}()
+	}
+
+	network, address := "tcp", *listen
+	if *socket != "" {
+		// A socket left behind by a previous run would fail the listen
+		network, address = "unix", *socket
+		if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(address)
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		fail(err)
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logInfo("Listening on %s", address)
+	if err := srv.Serve(listener); err != nil {
+		fail(err)
+	}
+}
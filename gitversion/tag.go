@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runTag implements "gitversion tag". It prints the tag name, so pipelines
+// can pick it up for later steps. With --push the tag is published as well,
+// authenticating with the GITVERSION_REMOTE_* variables when they are set.
+func runTag(args []string) {
+	fs := flag.NewFlagSet("tag", flag.ExitOnError)
+	errorFormatVar(fs)
+	configPaths := configVar(fs)
+	branch := fs.String("b", "", "Target branch")
+	branchLong := fs.String("branch", "", "Target branch")
+	prefix := fs.String("prefix", "", "Tag name prefix [default: from tag-prefix, else v]")
+	component := fs.String("component", "", "Tag the component with this name, e.g. api for api/v1.2.3")
+	allowPrerelease := fs.Bool("allow-prerelease", false, "Allow tagging prerelease versions")
+	sign := fs.Bool("sign", false, "Sign the tag with the configured gpg or ssh key")
+	push := fs.Bool("push", false, "Push the tag to the remote")
+	remote := fs.String("remote", "", "Remote to push the tag to [default: remote-name, else origin]")
+	timeout := fs.Duration("timeout", 0, "Fail when tagging and pushing take longer, killing hung git commands (e.g. 2m)")
+	parseFlags(fs, args)
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	targetBranch := *branch
+	if *branchLong != "" {
+		targetBranch = *branchLong
+	}
+
+	client, err := v1.New(v1.Options{
+		ConfigFiles:  *configPaths,
+		TargetBranch: targetBranch,
+		Component:    *component,
+		Remote:       *remote,
+		Debug:        os.Getenv("DEBUG") == "true",
+	})
+	if err != nil {
+		fail(err)
+	}
+	result, err := client.CalculateContext(ctx)
+	if err != nil {
+		fail(err)
+	}
+
+	tagResult, err := result.CreateTag(v1.TagOptions{Prefix: *prefix, AllowPrerelease: *allowPrerelease, Sign: *sign})
+	if err != nil {
+		fail(err)
+	}
+	if tagResult.Created {
+		logInfo("Created tag %s", tagResult.Tag)
+	} else {
+		logInfo("Tag %s already exists", tagResult.Tag)
+	}
+
+	if *push {
+		if err := client.PushTagContext(ctx, client.Remote(), tagResult.Tag); err != nil {
+			fail(err)
+		}
+		logInfo("Pushed tag %s to %s", tagResult.Tag, client.Remote())
+	}
+	fmt.Println(tagResult.Tag)
+}
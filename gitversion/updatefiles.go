@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/fileupdate"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// runUpdateFiles implements "gitversion update-files". It writes the
+// calculated version into the files of the file-updates configuration;
+// with --dry-run it only prints the changes as a diff.
+func runUpdateFiles(args []string) {
+	fs := flag.NewFlagSet("update-files", flag.ExitOnError)
+	errorFormatVar(fs)
+	configPaths := configVar(fs)
+	branch := fs.String("b", "", "Target branch")
+	branchLong := fs.String("branch", "", "Target branch")
+	dryRun := fs.Bool("dry-run", false, "Print the changes as a diff without writing them")
+	diff := fs.Bool("diff", false, "Print the changes as a diff")
+	parseFlags(fs, args)
+
+	targetBranch := *branch
+	if *branchLong != "" {
+		targetBranch = *branchLong
+	}
+
+	client, err := v1.New(v1.Options{
+		ConfigFiles:  *configPaths,
+		TargetBranch: targetBranch,
+		Debug:        os.Getenv("DEBUG") == "true",
+	})
+	if err != nil {
+		fail(err)
+	}
+	var updates []config.FileUpdateConfig
+	for _, update := range client.Config().FileUpdates {
+		updates = append(updates, config.FileUpdateConfig{Path: update.Path, Writer: update.Writer, Options: update.Options, Create: update.Create})
+	}
+	if len(updates) == 0 {
+		fail(errors.New("no file-updates configured"))
+	}
+
+	result, err := client.Calculate()
+	if err != nil {
+		fail(err)
+	}
+	root, err := git.NewRepository().GetRootDir()
+	if err != nil {
+		fail(err)
+	}
+	changes, err := fileupdate.Plan(root, updates, &result.Variables)
+	if err != nil {
+		fail(err)
+	}
+
+	if *dryRun || *diff {
+		for _, change := range changes {
+			fmt.Print(change.Diff())
+		}
+	}
+	if *dryRun {
+		return
+	}
+	if err := fileupdate.Write(changes); err != nil {
+		fail(err)
+	}
+	for _, change := range changes {
+		if change.Changed() {
+			logInfo("Updated %s to %s", change.Path, result.SemVer)
+		} else {
+			logInfo("%s is up to date", change.Path)
+		}
+	}
+}
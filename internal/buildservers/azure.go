@@ -0,0 +1,47 @@
+package buildservers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// azurePipelines sets variables with ##vso logging commands on stdout
+type azurePipelines struct{}
+
+func (azurePipelines) Name() string { return "Azure Pipelines" }
+
+func (azurePipelines) CanApply(env Env) bool {
+	return strings.EqualFold(env("TF_BUILD"), "true")
+}
+
+func (azurePipelines) Branch(env Env) string {
+	return firstBranch(env, "SYSTEM_PULLREQUEST_SOURCEBRANCH", "BUILD_SOURCEBRANCH")
+}
+
+func (azurePipelines) PullRequest(env Env) string {
+	// The number is only set for GitHub repositories, the ID for Azure Repos
+	if number := env("SYSTEM_PULLREQUEST_PULLREQUESTNUMBER"); number != "" {
+		return number
+	}
+	return env("SYSTEM_PULLREQUEST_PULLREQUESTID")
+}
+
+func (azurePipelines) WriteOutput(env Env, w io.Writer, output *Output) error {
+	for _, v := range output.Variables {
+		if _, err := fmt.Fprintf(w, "##vso[task.setvariable variable=GitVersion.%s]%s\n", v.Name, azureEscape(v.Value)); err != nil {
+			return err
+		}
+	}
+	if output.BuildNumber != "" {
+		if _, err := fmt.Fprintf(w, "##vso[build.updatebuildnumber]%s\n", azureEscape(output.BuildNumber)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// azureEscape escapes characters that end a logging command
+func azureEscape(s string) string {
+	return strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
@@ -0,0 +1,25 @@
+package buildservers
+
+import "io"
+
+// bitbucketPipelines writes a shell script of exports, which later steps
+// source after passing it along as an artifact.
+type bitbucketPipelines struct{}
+
+func (bitbucketPipelines) Name() string { return "Bitbucket Pipelines" }
+
+func (bitbucketPipelines) CanApply(env Env) bool {
+	return env("BITBUCKET_BUILD_NUMBER") != ""
+}
+
+func (bitbucketPipelines) Branch(env Env) string {
+	return firstBranch(env, "BITBUCKET_BRANCH")
+}
+
+func (bitbucketPipelines) PullRequest(env Env) string {
+	return env("BITBUCKET_PR_ID")
+}
+
+func (bitbucketPipelines) WriteOutput(env Env, w io.Writer, output *Output) error {
+	return writeFile(propertiesFile, shellExportLines("GITVERSION_", output.Variables))
+}
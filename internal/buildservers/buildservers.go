@@ -0,0 +1,171 @@
+// Package buildservers detects the CI system a build runs on, reads branch
+// and pull request information from its environment and publishes version
+// variables through the system's native mechanism.
+package buildservers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Env looks up an environment variable, returning "" when it is unset.
+// os.Getenv satisfies it; tests pass a map lookup.
+type Env func(key string) string
+
+// Variable is a single version variable such as FullSemVer
+type Variable struct {
+	Name  string
+	Value string
+}
+
+// Output is what gets published to a build server
+type Output struct {
+	Variables []Variable
+	// BuildNumber replaces the build number of the running build when set
+	BuildNumber string
+}
+
+// BuildServer is a CI system
+type BuildServer interface {
+	// Name returns a human readable name such as "GitHub Actions"
+	Name() string
+	// CanApply reports whether the build is running on this system
+	CanApply(env Env) bool
+	// Branch returns the branch being built, "" for tag builds or when unknown.
+	// Pull request builds report the source branch.
+	Branch(env Env) string
+	// PullRequest returns the pull request number, "" outside pull requests
+	PullRequest(env Env) string
+	// WriteOutput publishes output. Systems driven by log commands write
+	// them to w; others write to files named by their environment.
+	WriteOutput(env Env, w io.Writer, output *Output) error
+}
+
+// servers is ordered by precedence. Branch inference without a detected
+// system falls back to this order as well.
+var servers = []BuildServer{
+	gitHubActions{},
+	gitLabCI{},
+	azurePipelines{},
+	jenkins{},
+	bitbucketPipelines{},
+	circleCI{},
+	travisCI{},
+	buildkite{},
+	teamCity{},
+}
+
+// All returns every supported build server
+func All() []BuildServer {
+	return append([]BuildServer(nil), servers...)
+}
+
+// Detect returns the build server the process runs on, or nil
+func Detect(env Env) BuildServer {
+	for _, s := range servers {
+		if s.CanApply(env) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Branch returns the branch reported by the detected build server. When no
+// build server is detected, as in containers that only pass some variables
+// through, the branch variables of every build server are tried in order.
+func Branch(env Env) string {
+	if s := Detect(env); s != nil {
+		return s.Branch(env)
+	}
+	for _, s := range servers {
+		if branch := s.Branch(env); branch != "" {
+			return branch
+		}
+	}
+	return ""
+}
+
+// NormalizeBranchRef turns a full or remote ref into a branch name. Tag and
+// pull request refs are not branches and yield "".
+func NormalizeBranchRef(ref string) string {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/remotes/"):
+		ref = strings.TrimPrefix(ref, "refs/remotes/")
+		if i := strings.Index(ref, "/"); i >= 0 {
+			ref = ref[i+1:]
+		}
+		if ref == "HEAD" {
+			return ""
+		}
+		return ref
+	case strings.HasPrefix(ref, "refs/"):
+		return ""
+	}
+	return strings.TrimPrefix(ref, "origin/")
+}
+
+// firstBranch returns the first of the named variables holding a branch
+func firstBranch(env Env, names ...string) string {
+	for _, name := range names {
+		if branch := NormalizeBranchRef(env(name)); branch != "" {
+			return branch
+		}
+	}
+	return ""
+}
+
+// propertiesFile is written by build servers that pick variables up from
+// a file in the working directory, e.g. as a GitLab dotenv artifact.
+const propertiesFile = "gitversion.properties"
+
+// appendFile appends lines to the file at path, creating it if needed
+func appendFile(path string, lines []string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(f, line); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// writeFile replaces the file at path with lines
+func writeFile(path string, lines []string) error {
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// envFileLines renders variables as NAME=value lines
+func envFileLines(prefix string, variables []Variable) []string {
+	lines := make([]string, 0, len(variables))
+	for _, v := range variables {
+		lines = append(lines, prefix+v.Name+"="+v.Value)
+	}
+	return lines
+}
+
+// shellExportLines renders variables as shell export statements
+func shellExportLines(prefix string, variables []Variable) []string {
+	lines := make([]string, 0, len(variables))
+	for _, v := range variables {
+		lines = append(lines, "export "+prefix+v.Name+"="+shellQuote(v.Value))
+	}
+	return lines
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,216 @@
+package buildservers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func envOf(vars map[string]string) Env {
+	return func(key string) string { return vars[key] }
+}
+
+var testOutput = &Output{
+	Variables: []Variable{
+		{Name: "SemVer", Value: "1.2.3-beta.1"},
+		{Name: "BranchName", Value: "feature/it's"},
+	},
+	BuildNumber: "1.2.3-beta.1+4",
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		server      string
+		branch      string
+		pullRequest string
+	}{
+		{
+			name:   "GitHub Actions push",
+			env:    map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/main"},
+			server: "GitHub Actions",
+			branch: "main",
+		},
+		{
+			name:        "GitHub Actions pull request",
+			env:         map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/pull/42/merge", "GITHUB_HEAD_REF": "feature/x"},
+			server:      "GitHub Actions",
+			branch:      "feature/x",
+			pullRequest: "42",
+		},
+		{
+			name:        "GitLab merge request",
+			env:         map[string]string{"GITLAB_CI": "true", "CI_COMMIT_REF_NAME": "main", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature/y", "CI_MERGE_REQUEST_IID": "7"},
+			server:      "GitLab CI",
+			branch:      "feature/y",
+			pullRequest: "7",
+		},
+		{
+			name:   "GitLab tag",
+			env:    map[string]string{"GITLAB_CI": "true", "CI_COMMIT_REF_NAME": "v1.0.0", "CI_COMMIT_TAG": "v1.0.0"},
+			server: "GitLab CI",
+		},
+		{
+			name:        "Azure Pipelines pull request",
+			env:         map[string]string{"TF_BUILD": "True", "BUILD_SOURCEBRANCH": "refs/pull/5/merge", "SYSTEM_PULLREQUEST_SOURCEBRANCH": "refs/heads/hotfix/z", "SYSTEM_PULLREQUEST_PULLREQUESTID": "5"},
+			server:      "Azure Pipelines",
+			branch:      "hotfix/z",
+			pullRequest: "5",
+		},
+		{
+			name:        "Jenkins multibranch pull request",
+			env:         map[string]string{"JENKINS_URL": "https://ci", "BRANCH_NAME": "PR-3", "CHANGE_BRANCH": "feature/j", "CHANGE_ID": "3"},
+			server:      "Jenkins",
+			branch:      "feature/j",
+			pullRequest: "3",
+		},
+		{
+			name:   "TeamCity",
+			env:    map[string]string{"TEAMCITY_VERSION": "2024.1", "Git_Branch": "refs/heads/develop"},
+			server: "TeamCity",
+			branch: "develop",
+		},
+		{
+			name:        "Bitbucket Pipelines",
+			env:         map[string]string{"BITBUCKET_BUILD_NUMBER": "9", "BITBUCKET_BRANCH": "release/2.0", "BITBUCKET_PR_ID": "11"},
+			server:      "Bitbucket Pipelines",
+			branch:      "release/2.0",
+			pullRequest: "11",
+		},
+		{
+			name:        "CircleCI",
+			env:         map[string]string{"CIRCLECI": "true", "CIRCLE_BRANCH": "feature/c", "CIRCLE_PULL_REQUEST": "https://github.com/o/r/pull/8"},
+			server:      "CircleCI",
+			branch:      "feature/c",
+			pullRequest: "8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := envOf(tt.env)
+			server := Detect(env)
+			if server == nil {
+				t.Fatalf("Expected %s to be detected", tt.server)
+			}
+			if server.Name() != tt.server {
+				t.Errorf("Detect() = %s, want %s", server.Name(), tt.server)
+			}
+			if branch := server.Branch(env); branch != tt.branch {
+				t.Errorf("Branch() = %q, want %q", branch, tt.branch)
+			}
+			if pr := server.PullRequest(env); pr != tt.pullRequest {
+				t.Errorf("PullRequest() = %q, want %q", pr, tt.pullRequest)
+			}
+		})
+	}
+
+	if server := Detect(envOf(nil)); server != nil {
+		t.Errorf("Expected no build server, got %s", server.Name())
+	}
+}
+
+func TestBranchWithoutDetectedServer(t *testing.T) {
+	env := envOf(map[string]string{"GIT_BRANCH": "origin/feature/z"})
+	if branch := Branch(env); branch != "feature/z" {
+		t.Errorf("Branch() = %q, want feature/z", branch)
+	}
+}
+
+func TestAzurePipelinesOutput(t *testing.T) {
+	var b strings.Builder
+	if err := (azurePipelines{}).WriteOutput(envOf(nil), &b, testOutput); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "##vso[task.setvariable variable=GitVersion.SemVer]1.2.3-beta.1\n" +
+		"##vso[task.setvariable variable=GitVersion.BranchName]feature/it's\n" +
+		"##vso[build.updatebuildnumber]1.2.3-beta.1+4\n"
+	if b.String() != expected {
+		t.Errorf("Output = %q, want %q", b.String(), expected)
+	}
+}
+
+func TestTeamCityOutput(t *testing.T) {
+	var b strings.Builder
+	if err := (teamCity{}).WriteOutput(envOf(nil), &b, testOutput); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(b.String(), "##teamcity[setParameter name='GitVersion.BranchName' value='feature/it|'s']\n") {
+		t.Errorf("Expected escaped parameter, got %q", b.String())
+	}
+	if !strings.HasSuffix(b.String(), "##teamcity[buildNumber '1.2.3-beta.1+4']\n") {
+		t.Errorf("Expected build number update, got %q", b.String())
+	}
+}
+
+func TestGitHubActionsOutput(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, "github_env")
+	outputPath := filepath.Join(dir, "github_output")
+	if err := os.WriteFile(envPath, []byte("EXISTING=1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	env := envOf(map[string]string{"GITHUB_ENV": envPath, "GITHUB_OUTPUT": outputPath})
+	if err := (gitHubActions{}).WriteOutput(env, nil, testOutput); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(envPath)
+	expected := "EXISTING=1\nGitVersion_SemVer=1.2.3-beta.1\nGitVersion_BranchName=feature/it's\n"
+	if string(data) != expected {
+		t.Errorf("GITHUB_ENV = %q, want %q", data, expected)
+	}
+	data, _ = os.ReadFile(outputPath)
+	if string(data) != "GitVersion_SemVer=1.2.3-beta.1\nGitVersion_BranchName=feature/it's\n" {
+		t.Errorf("GITHUB_OUTPUT = %q", data)
+	}
+
+	if err := (gitHubActions{}).WriteOutput(envOf(nil), nil, testOutput); err == nil {
+		t.Errorf("Expected error without GITHUB_OUTPUT and GITHUB_ENV")
+	}
+}
+
+func TestWriteGitHubActionsMultiline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github_output")
+	// A value cannot end itself early with a delimiter it guesses
+	value := "line one\nghadelimiter_gitversion\nGitVersion_SemVer=9.9.9"
+	output := &Output{Variables: []Variable{{Name: "Notes", Value: value}, {Name: "Other", Value: "a\nb"}}}
+
+	if err := WriteGitHubActions(envOf(map[string]string{"GITHUB_OUTPUT": path}), output); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("GITHUB_OUTPUT = %q, want two heredocs", data)
+	}
+	notes, ok := strings.CutPrefix(lines[0], "GitVersion_Notes<<ghadelimiter_")
+	if !ok || len(notes) != 32 || lines[4] != "ghadelimiter_"+notes {
+		t.Fatalf("GITHUB_OUTPUT = %q, want Notes ended by a random delimiter", data)
+	}
+	if got := strings.Join(lines[1:4], "\n"); got != value {
+		t.Errorf("Notes = %q, want %q", got, value)
+	}
+	if other, _ := strings.CutPrefix(lines[5], "GitVersion_Other<<ghadelimiter_"); other == notes || lines[8] != "ghadelimiter_"+other {
+		t.Errorf("GITHUB_OUTPUT = %q, want a new delimiter for each value", data)
+	}
+}
+
+func TestCircleCIOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bash_env")
+	env := envOf(map[string]string{"BASH_ENV": path})
+	if err := (circleCI{}).WriteOutput(env, nil, testOutput); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `export GitVersion_BranchName='feature/it'\''s'`) {
+		t.Errorf("Expected quoted export, got %q", data)
+	}
+}
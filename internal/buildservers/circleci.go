@@ -0,0 +1,40 @@
+package buildservers
+
+import (
+	"fmt"
+	"io"
+	"path"
+)
+
+// circleCI appends exports to the file named by BASH_ENV, which every
+// later step sources.
+type circleCI struct{}
+
+func (circleCI) Name() string { return "CircleCI" }
+
+func (circleCI) CanApply(env Env) bool {
+	return env("CIRCLECI") == "true"
+}
+
+func (circleCI) Branch(env Env) string {
+	return firstBranch(env, "CIRCLE_BRANCH")
+}
+
+func (circleCI) PullRequest(env Env) string {
+	if number := env("CIRCLE_PR_NUMBER"); number != "" {
+		return number
+	}
+	// CIRCLE_PULL_REQUEST is the pull request URL for non-fork builds
+	if url := env("CIRCLE_PULL_REQUEST"); url != "" {
+		return path.Base(url)
+	}
+	return ""
+}
+
+func (circleCI) WriteOutput(env Env, w io.Writer, output *Output) error {
+	path := env("BASH_ENV")
+	if path == "" {
+		return fmt.Errorf("BASH_ENV is not set")
+	}
+	return appendFile(path, shellExportLines("GitVersion_", output.Variables))
+}
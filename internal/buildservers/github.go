@@ -0,0 +1,96 @@
+package buildservers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// gitHubActions appends variables to the files named by GITHUB_OUTPUT and
+// GITHUB_ENV, making them step outputs and environment variables of all
+// later steps of the job.
+type gitHubActions struct{}
+
+func (gitHubActions) Name() string { return "GitHub Actions" }
+
+func (gitHubActions) CanApply(env Env) bool {
+	return env("GITHUB_ACTIONS") == "true"
+}
+
+func (gitHubActions) Branch(env Env) string {
+	return firstBranch(env, "GITHUB_HEAD_REF", "GITHUB_REF")
+}
+
+func (gitHubActions) PullRequest(env Env) string {
+	// Pull request builds check out refs/pull/<number>/merge
+	ref := env("GITHUB_REF")
+	if !strings.HasPrefix(ref, "refs/pull/") {
+		return ""
+	}
+	number, _, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+	return number
+}
+
+func (gitHubActions) WriteOutput(env Env, w io.Writer, output *Output) error {
+	return WriteGitHubActions(env, output)
+}
+
+// WriteGitHubActions writes the variables as GitVersion_<Name> to the files
+// named by GITHUB_OUTPUT and GITHUB_ENV. Either may be unset, e.g. in
+// composite actions, but not both.
+func WriteGitHubActions(env Env, output *Output) error {
+	outputFile, envFile := env("GITHUB_OUTPUT"), env("GITHUB_ENV")
+	if outputFile == "" && envFile == "" {
+		return fmt.Errorf("neither GITHUB_OUTPUT nor GITHUB_ENV is set")
+	}
+
+	lines, err := gitHubFileLines("GitVersion_", output.Variables)
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{outputFile, envFile} {
+		if path == "" {
+			continue
+		}
+		if err := appendFile(path, lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitHubDelimiter returns a random delimiter to end a multiline value in
+// GitHub's file commands. A fixed one would let a value containing it, such
+// as a commit message, end the value early and set outputs of its own.
+func gitHubDelimiter(value string) (string, error) {
+	for {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return "", fmt.Errorf("failed to generate a delimiter: %w", err)
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(random)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+// gitHubFileLines renders variables in GitHub's file command syntax, using
+// the heredoc form for values spanning several lines.
+func gitHubFileLines(prefix string, variables []Variable) ([]string, error) {
+	lines := make([]string, 0, len(variables))
+	for _, v := range variables {
+		if strings.ContainsAny(v.Value, "\r\n") {
+			delimiter, err := gitHubDelimiter(v.Value)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, prefix+v.Name+"<<"+delimiter, v.Value, delimiter)
+			continue
+		}
+		lines = append(lines, prefix+v.Name+"="+v.Value)
+	}
+	return lines, nil
+}
@@ -0,0 +1,32 @@
+package buildservers
+
+import "io"
+
+// gitLabCI writes a dotenv file that jobs can publish with
+// artifacts:reports:dotenv to pass the variables to later jobs.
+type gitLabCI struct{}
+
+func (gitLabCI) Name() string { return "GitLab CI" }
+
+func (gitLabCI) CanApply(env Env) bool {
+	return env("GITLAB_CI") == "true"
+}
+
+func (gitLabCI) Branch(env Env) string {
+	if branch := firstBranch(env, "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"); branch != "" {
+		return branch
+	}
+	// Tag pipelines report the tag name in CI_COMMIT_REF_NAME
+	if env("CI_COMMIT_TAG") != "" {
+		return ""
+	}
+	return firstBranch(env, "CI_COMMIT_REF_NAME")
+}
+
+func (gitLabCI) PullRequest(env Env) string {
+	return env("CI_MERGE_REQUEST_IID")
+}
+
+func (gitLabCI) WriteOutput(env Env, w io.Writer, output *Output) error {
+	return writeFile(propertiesFile, envFileLines("GitVersion_", output.Variables))
+}
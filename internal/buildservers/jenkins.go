@@ -0,0 +1,27 @@
+package buildservers
+
+import "io"
+
+// jenkins writes a properties file for the EnvInject plugin or a
+// readProperties pipeline step.
+type jenkins struct{}
+
+func (jenkins) Name() string { return "Jenkins" }
+
+func (jenkins) CanApply(env Env) bool {
+	return env("JENKINS_URL") != ""
+}
+
+func (jenkins) Branch(env Env) string {
+	// Multibranch pipelines set CHANGE_BRANCH for pull requests, where
+	// BRANCH_NAME is "PR-<number>"; GIT_BRANCH comes from the Git plugin.
+	return firstBranch(env, "CHANGE_BRANCH", "BRANCH_NAME", "GIT_BRANCH")
+}
+
+func (jenkins) PullRequest(env Env) string {
+	return env("CHANGE_ID")
+}
+
+func (jenkins) WriteOutput(env Env, w io.Writer, output *Output) error {
+	return writeFile(propertiesFile, envFileLines("GitVersion_", output.Variables))
+}
@@ -0,0 +1,61 @@
+package buildservers
+
+import (
+	"fmt"
+	"io"
+)
+
+// travisCI and buildkite only provide branch information; they have no
+// mechanism for passing variables to later steps.
+type travisCI struct{}
+
+func (travisCI) Name() string { return "Travis CI" }
+
+func (travisCI) CanApply(env Env) bool {
+	return env("TRAVIS") == "true"
+}
+
+func (travisCI) Branch(env Env) string {
+	if branch := firstBranch(env, "TRAVIS_PULL_REQUEST_BRANCH"); branch != "" {
+		return branch
+	}
+	// Tag builds report the tag name in TRAVIS_BRANCH
+	if env("TRAVIS_TAG") != "" {
+		return ""
+	}
+	return firstBranch(env, "TRAVIS_BRANCH")
+}
+
+func (travisCI) PullRequest(env Env) string {
+	if number := env("TRAVIS_PULL_REQUEST"); number != "false" {
+		return number
+	}
+	return ""
+}
+
+func (s travisCI) WriteOutput(env Env, w io.Writer, output *Output) error {
+	return fmt.Errorf("%s does not support setting variables", s.Name())
+}
+
+type buildkite struct{}
+
+func (buildkite) Name() string { return "Buildkite" }
+
+func (buildkite) CanApply(env Env) bool {
+	return env("BUILDKITE") == "true"
+}
+
+func (buildkite) Branch(env Env) string {
+	return firstBranch(env, "BUILDKITE_BRANCH")
+}
+
+func (buildkite) PullRequest(env Env) string {
+	if number := env("BUILDKITE_PULL_REQUEST"); number != "false" {
+		return number
+	}
+	return ""
+}
+
+func (s buildkite) WriteOutput(env Env, w io.Writer, output *Output) error {
+	return fmt.Errorf("%s does not support setting variables", s.Name())
+}
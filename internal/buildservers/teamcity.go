@@ -0,0 +1,49 @@
+package buildservers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// teamCity sets build parameters with ##teamcity service messages
+type teamCity struct{}
+
+func (teamCity) Name() string { return "TeamCity" }
+
+func (teamCity) CanApply(env Env) bool {
+	return env("TEAMCITY_VERSION") != ""
+}
+
+func (teamCity) Branch(env Env) string {
+	// TeamCity does not export the branch by default; GitVersion's
+	// convention is an env.Git_Branch parameter set to %teamcity.build.vcs.branch.<id>%
+	return firstBranch(env, "Git_Branch")
+}
+
+func (teamCity) PullRequest(env Env) string {
+	return ""
+}
+
+func (teamCity) WriteOutput(env Env, w io.Writer, output *Output) error {
+	for _, v := range output.Variables {
+		value := teamCityEscape(v.Value)
+		if _, err := fmt.Fprintf(w, "##teamcity[setParameter name='GitVersion.%s' value='%s']\n", v.Name, value); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "##teamcity[setParameter name='system.GitVersion.%s' value='%s']\n", v.Name, value); err != nil {
+			return err
+		}
+	}
+	if output.BuildNumber != "" {
+		if _, err := fmt.Fprintf(w, "##teamcity[buildNumber '%s']\n", teamCityEscape(output.BuildNumber)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// teamCityEscape escapes values inside service message attributes
+func teamCityEscape(s string) string {
+	return strings.NewReplacer("|", "||", "'", "|'", "[", "|[", "]", "|]", "\n", "|n", "\r", "|r").Replace(s)
+}
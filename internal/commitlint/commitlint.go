@@ -0,0 +1,149 @@
+// Package commitlint checks commit messages against Conventional Commits
+// and the bump messages of the configuration, and reports the increment
+// each message asks for.
+package commitlint
+
+import (
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// generatedPattern matches subjects git writes itself, which are not linted
+var generatedPattern = lazyregexp.New(`^(Merge |Revert "|fixup! |squash! |amend! )`)
+
+// Result is the outcome of linting one commit message
+type Result struct {
+	// SHA is empty for a message that is not a commit yet
+	SHA     string `json:"sha,omitempty"`
+	Subject string `json:"subject"`
+	// Increment is the increment the message asks for; Inherit when it
+	// asks for none and the branch's increment applies
+	Increment config.IncrementStrategy `json:"increment"`
+	Cause     string                   `json:"cause"`
+	Problems  []string                 `json:"problems,omitempty"`
+}
+
+// Valid reports whether the message has no problems
+func (r *Result) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// Report holds the results of every message linted, newest commit first
+type Report struct {
+	Results []*Result `json:"results"`
+}
+
+// Valid reports whether no message has problems
+func (r *Report) Valid() bool {
+	return r.Invalid() == 0
+}
+
+// Invalid returns the number of messages with problems
+func (r *Report) Invalid() int {
+	count := 0
+	for _, result := range r.Results {
+		if !result.Valid() {
+			count++
+		}
+	}
+	return count
+}
+
+// Linter lints commit messages with the bump messages of a configuration.
+// The increments it reports are the ones the calculator applies.
+type Linter struct {
+	rules *version.MessageRules
+}
+
+// New returns a linter using the bump messages of cfg
+func New(cfg *config.Config) (*Linter, error) {
+	rules, err := version.NewMessageRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Linter{rules: rules}, nil
+}
+
+// Lint checks a whole commit message, the subject and the body
+func (l *Linter) Lint(message string) *Result {
+	message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(message, "\n")
+	result := &Result{Subject: subject}
+	if subject == "" {
+		result.Increment = config.IncrementInherit
+		result.Cause = "empty message"
+		result.Problems = append(result.Problems, "the message is empty")
+		return result
+	}
+	if body != "" && strings.TrimSpace(strings.SplitN(body, "\n", 2)[0]) != "" {
+		result.Problems = append(result.Problems, "separate the body from the subject with a blank line")
+	}
+
+	header := version.ConventionalHeader(subject)
+	switch {
+	case generatedPattern.MatchString(subject):
+		// Merges, reverts and fixups keep the subjects git gave them
+	case header != nil:
+		result.Problems = append(result.Problems, headerProblems(header)...)
+	case !l.rules.Bumps(message):
+		result.Problems = append(result.Problems, `not a conventional commit; expected "type(scope): description", e.g. "fix(api): handle empty pages"`)
+	}
+
+	result.Increment, result.Cause = l.rules.Increment(message)
+	return result
+}
+
+// headerProblems checks the parts of a conventional commit subject
+func headerProblems(header []string) []string {
+	var problems []string
+	if strings.HasPrefix(header[0][len(header[1]):], "()") {
+		problems = append(problems, "the scope is empty; name it or leave out the parentheses")
+	}
+	description := header[4]
+	switch {
+	case strings.TrimSpace(description) == "":
+		problems = append(problems, "the description after the colon is missing")
+	case !strings.HasPrefix(description, " "):
+		problems = append(problems, "put a space after the colon")
+	}
+	return problems
+}
+
+// LintRange lints the commits of revisions, e.g. main..HEAD, newest first
+func (l *Linter) LintRange(repo *git.Repository, revisions string) (*Report, error) {
+	report := &Report{}
+	err := repo.ForEachCommit(revisions, func(commit *git.Commit) bool {
+		result := l.Lint(commit.FullMessage())
+		result.SHA = commit.SHA
+		report.Results = append(report.Results, result)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// scissorsLine marks the end of the message in a file git commit --verbose
+// prepared
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// CleanMessage strips what git commit strips from a message file before
+// committing: the lines starting with # and everything below the scissors
+// line
+func CleanMessage(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimRight(line, "\r") == scissorsLine {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,164 @@
+package commitlint
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func newLinter(t *testing.T) *Linter {
+	t.Helper()
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	linter, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return linter
+}
+
+func TestLint(t *testing.T) {
+	linter := newLinter(t)
+	tests := []struct {
+		message   string
+		increment config.IncrementStrategy
+		valid     bool
+	}{
+		{"feat(api): add pagination", config.IncrementMinor, true},
+		{"fix: handle empty pages", config.IncrementPatch, true},
+		{"feat(api)!: drop v1 endpoints", config.IncrementMajor, true},
+		{"fix: parse dates in UTC\n\nBREAKING CHANGE: local times are rejected", config.IncrementMajor, true},
+		{"docs: typo", config.IncrementInherit, true},
+		{"chore(deps): bump yaml +semver: minor", config.IncrementMinor, true},
+		// Bump messages alone are enough
+		{"Tidy up the build +semver: none", config.IncrementNone, true},
+		{"Merge branch 'feature/login'", config.IncrementInherit, true},
+		{"Added some stuff", config.IncrementInherit, false},
+		{"fix:no space", config.IncrementPatch, false},
+		{"feat(): empty scope", config.IncrementMinor, false},
+		{"feat: ", config.IncrementMinor, false},
+		{"fix: subject\nbody without a blank line", config.IncrementPatch, false},
+		{"", config.IncrementInherit, false},
+	}
+	for _, tt := range tests {
+		result := linter.Lint(tt.message)
+		if result.Increment != tt.increment {
+			t.Errorf("Lint(%q) increment = %s (%s), want %s", tt.message, result.Increment, result.Cause, tt.increment)
+		}
+		if result.Valid() != tt.valid {
+			t.Errorf("Lint(%q) problems = %v, want valid %t", tt.message, result.Problems, tt.valid)
+		}
+	}
+}
+
+func TestLintConfiguredBumpMessages(t *testing.T) {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.MinorVersionBumpMessage = `^\[feature\]`
+	linter, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if result := linter.Lint("[feature] search"); result.Increment != config.IncrementMinor || !result.Valid() {
+		t.Errorf("Lint() = %s with problems %v, want a valid minor", result.Increment, result.Problems)
+	}
+
+	cfg.MajorVersionBumpMessage = `(`
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with an invalid major-version-bump-message should fail")
+	}
+}
+
+func TestCleanMessage(t *testing.T) {
+	content := "feat: search  \n\nFinds things.\n# Please enter the commit message for your changes.\n" +
+		"# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got, want := CleanMessage(content), "feat: search\n\nFinds things."; got != want {
+		t.Errorf("CleanMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestLintRange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", "-b", "main")
+	runGit("commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit("commit", "-q", "--allow-empty", "-m", "feat: search", "-m", "BREAKING CHANGE: the old query syntax is gone")
+	runGit("commit", "-q", "--allow-empty", "-m", "wip")
+
+	report, err := newLinter(t).LintRange(git.NewRepositoryAt(dir), "HEAD~2..HEAD")
+	if err != nil {
+		t.Fatalf("LintRange() error = %v", err)
+	}
+	var subjects []string
+	var increments []config.IncrementStrategy
+	for _, result := range report.Results {
+		subjects = append(subjects, result.Subject)
+		increments = append(increments, result.Increment)
+		if len(result.SHA) != 40 {
+			t.Errorf("SHA of %q = %q, want the commit", result.Subject, result.SHA)
+		}
+	}
+	if want := []string{"wip", "feat: search"}; !reflect.DeepEqual(subjects, want) {
+		t.Errorf("LintRange() subjects = %v, want %v", subjects, want)
+	}
+	if want := []config.IncrementStrategy{config.IncrementInherit, config.IncrementMajor}; !reflect.DeepEqual(increments, want) {
+		t.Errorf("LintRange() increments = %v, want %v", increments, want)
+	}
+	if report.Valid() || report.Invalid() != 1 {
+		t.Errorf("LintRange() has %d invalid messages, want 1", report.Invalid())
+	}
+
+	if _, err := newLinter(t).LintRange(git.NewRepositoryAt(dir), "nosuchref..HEAD"); err == nil {
+		t.Error("LintRange() of an unknown revision should fail")
+	}
+}
+
+func TestLintMatchesCalculator(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", "-b", "main")
+	runGit("commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit("tag", "v1.0.0")
+	runGit("commit", "-q", "--allow-empty", "-m", "feat: search")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if result := newLinter(t).Lint("feat: search"); result.Increment != config.IncrementMinor {
+		t.Fatalf("Lint() increment = %s, want minor", result.Increment)
+	}
+	diagnostics, err := version.NewCalculator(git.NewRepositoryAt(dir), cfg).Explain("", version.GitFlow, "", "")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if got := diagnostics.Version.MajorMinorPatch(); got != "1.1.0" {
+		t.Errorf("version after a commit linted as minor = %s (%s), want 1.1.0", got, diagnostics.IncrementCause)
+	}
+}
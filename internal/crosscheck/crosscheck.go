@@ -0,0 +1,175 @@
+// Package crosscheck compares gitversion-go's variables with the output of
+// an installed GitTools GitVersion, field by field.
+package crosscheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// toolNames are the executable names GitVersion is installed under: the
+// Windows binary, the dotnet global tool and the Homebrew/Linux binary.
+var toolNames = []string{"GitVersion.exe", "dotnet-gitversion", "gitversion", "GitVersion"}
+
+// Find returns the path of an installed GitVersion. self is the path of
+// the running executable; it is skipped because gitversion-go installs
+// under the same name as GitVersion.
+func Find(self string) (string, error) {
+	selfPath := canonical(self)
+	for _, name := range toolNames {
+		path, err := exec.LookPath(name)
+		if err != nil || canonical(path) == selfPath {
+			continue
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("GitVersion not found on PATH (tried %v)", toolNames)
+}
+
+func canonical(path string) string {
+	if path == "" {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+// Run executes GitVersion in the working directory and decodes its JSON
+// variables.
+func Run(tool string) (map[string]interface{}, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(tool, "/output", "json")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", tool, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	variables := make(map[string]interface{})
+	if err := json.Unmarshal(stdout.Bytes(), &variables); err != nil {
+		return nil, fmt.Errorf("%s did not print JSON variables: %w", tool, err)
+	}
+	return variables, nil
+}
+
+// Status classifies one field of a comparison
+type Status string
+
+const (
+	// Match marks fields both tools report with the same value
+	Match Status = "match"
+	// Differs marks fields both tools report with different values
+	Differs Status = "differs"
+	// Missing marks GitVersion fields gitversion-go does not report
+	Missing Status = "missing"
+	// Extra marks gitversion-go fields GitVersion does not report
+	Extra Status = "extra"
+)
+
+// Field is the comparison of one variable
+type Field struct {
+	Name       string `json:"Name"`
+	Status     Status `json:"Status"`
+	Ours       string `json:"Ours,omitempty"`
+	GitVersion string `json:"GitVersion,omitempty"`
+}
+
+// Report is a field by field comparison, in field name order
+type Report struct {
+	Fields []Field `json:"Fields"`
+}
+
+// Compatible reports whether every field GitVersion reports matches.
+// Extra fields do not affect compatibility.
+func (r *Report) Compatible() bool {
+	for _, f := range r.Fields {
+		if f.Status == Differs || f.Status == Missing {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of fields with the given status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, f := range r.Fields {
+		if f.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Compare compares two sets of decoded JSON variables. Scalars are compared
+// by their rendering, so 3 and "3" match; nested objects are skipped since
+// GitVersion has none.
+func Compare(ours, theirs map[string]interface{}) *Report {
+	names := make(map[string]bool, len(ours)+len(theirs))
+	for name := range ours {
+		names[name] = true
+	}
+	for name := range theirs {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	report := &Report{}
+	for _, name := range sorted {
+		ourValue, inOurs := lookup(ours, name)
+		theirValue, inTheirs := lookup(theirs, name)
+
+		field := Field{Name: name, Ours: ourValue, GitVersion: theirValue}
+		switch {
+		case inOurs && inTheirs && ourValue == theirValue:
+			field.Status = Match
+		case inOurs && inTheirs:
+			field.Status = Differs
+		case inTheirs:
+			field.Status = Missing
+		case inOurs:
+			field.Status = Extra
+		default:
+			continue
+		}
+		report.Fields = append(report.Fields, field)
+	}
+	return report
+}
+
+// lookup renders the scalar variable name. GitVersion reports absent
+// values such as BuildMetaData as null, which compares equal to "". ok is
+// false for missing variables and nested objects or arrays.
+func lookup(variables map[string]interface{}, name string) (string, bool) {
+	value, ok := variables[name]
+	if !ok {
+		return "", false
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,104 @@
+package crosscheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	ours := map[string]interface{}{
+		"Major":         float64(1),
+		"SemVer":        "1.2.3",
+		"BuildMetaData": "",
+		"BranchName":    "main",
+		"Describe":      "v1.2.3",
+		"BranchConfig":  map[string]interface{}{"Key": "main"},
+	}
+	theirs := map[string]interface{}{
+		"Major":            "1",
+		"SemVer":           "1.2.4",
+		"BuildMetaData":    nil,
+		"BranchName":       "main",
+		"PreReleaseNumber": nil,
+	}
+
+	report := Compare(ours, theirs)
+
+	expected := []Field{
+		{Name: "BranchName", Status: Match, Ours: "main", GitVersion: "main"},
+		{Name: "BuildMetaData", Status: Match},
+		{Name: "Describe", Status: Extra, Ours: "v1.2.3"},
+		{Name: "Major", Status: Match, Ours: "1", GitVersion: "1"},
+		{Name: "PreReleaseNumber", Status: Missing},
+		{Name: "SemVer", Status: Differs, Ours: "1.2.3", GitVersion: "1.2.4"},
+	}
+	if !reflect.DeepEqual(report.Fields, expected) {
+		t.Errorf("Compare() fields = %+v\nwant %+v", report.Fields, expected)
+	}
+	if report.Compatible() {
+		t.Errorf("Compatible() = true with differing and missing fields")
+	}
+	if got := report.Count(Match); got != 3 {
+		t.Errorf("Count(Match) = %d, want 3", got)
+	}
+
+	if !Compare(map[string]interface{}{"Extra": "x"}, map[string]interface{}{}).Compatible() {
+		t.Errorf("Extra fields should not break compatibility")
+	}
+}
+
+func TestFindSkipsSelf(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+
+	selfDir, toolDir := t.TempDir(), t.TempDir()
+	self := writeScript(t, selfDir, "gitversion", "")
+	tool := writeScript(t, toolDir, "GitVersion", "")
+	t.Setenv("PATH", selfDir+string(os.PathListSeparator)+toolDir)
+
+	got, err := Find(self)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if got != tool {
+		t.Errorf("Find() = %s, want %s", got, tool)
+	}
+
+	t.Setenv("PATH", selfDir)
+	if _, err := Find(self); err == nil {
+		t.Errorf("Find() should fail when only the running executable is on PATH")
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+
+	tool := writeScript(t, t.TempDir(), "GitVersion", `echo '{"SemVer":"1.2.3","Major":1}'`)
+	variables, err := Run(tool)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if variables["SemVer"] != "1.2.3" || variables["Major"] != float64(1) {
+		t.Errorf("Run() = %v", variables)
+	}
+
+	failing := writeScript(t, t.TempDir(), "GitVersion", `echo "not a repository" >&2; exit 1`)
+	if _, err := Run(failing); err == nil {
+		t.Errorf("Run() should fail when GitVersion fails")
+	}
+}
+
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
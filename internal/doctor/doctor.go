@@ -0,0 +1,197 @@
+// Package doctor checks a repository for the conditions that make version
+// calculation unreliable, such as shallow clones or missing tags, and
+// suggests how to fix each of them.
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/buildservers"
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	OK      Status = "ok"
+	Warning Status = "warning"
+	// Error means the calculated version cannot be trusted
+	Error Status = "error"
+)
+
+// Finding is the result of one check
+type Finding struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// Fix is the suggested remedy, empty for passing checks
+	Fix string `json:"fix,omitempty"`
+}
+
+// Report holds the findings of every check in the order they ran
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Healthy reports whether no check failed. Warnings do not count.
+func (r *Report) Healthy() bool {
+	return r.Count(Error) == 0
+}
+
+// Count returns the number of findings with the given status
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, f := range r.Findings {
+		if f.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Report) add(check string, status Status, message, fix string) {
+	r.Findings = append(r.Findings, Finding{Check: check, Status: status, Message: message, Fix: fix})
+}
+
+// Run checks the repository in the working directory. configPath is the
+// configuration file versioning runs with, "" for the defaults; env supplies
+// the CI environment.
+func Run(repo *git.Repository, configPath string, env buildservers.Env) *Report {
+	report := &Report{}
+	if !repo.IsRepository() {
+		report.add("repository", Error, "not inside a git repository",
+			"run gitversion from a git working tree")
+		return report
+	}
+
+	checkWorktree(report, repo)
+	checkShallow(report, repo)
+	checkTags(report, repo)
+	branch := checkHead(report, repo, env)
+	if cfg := checkConfig(report, repo, configPath); cfg != nil {
+		checkBranch(report, cfg, branch)
+	}
+	return report
+}
+
+// checkWorktree notes a linked worktree, which shares the refs, tags and
+// calculation cache of the main working tree
+func checkWorktree(report *Report, repo *git.Repository) {
+	linked, err := repo.IsLinkedWorktree()
+	if err != nil || !linked {
+		return
+	}
+	_, commonDir, _ := repo.GetGitDirs()
+	report.add("worktree", OK, fmt.Sprintf("linked worktree; refs, tags and the cache are shared through %s", commonDir), "")
+}
+
+func checkShallow(report *Report, repo *git.Repository) {
+	shallow, err := repo.IsShallow()
+	switch {
+	case err != nil:
+		report.add("shallow-clone", Warning, fmt.Sprintf("could not tell whether the clone is shallow: %v", err), "")
+	case shallow:
+		report.add("shallow-clone", Error,
+			"the clone is shallow; older tags and commits are invisible, so versions and commit counts are wrong",
+			"run 'git fetch --unshallow', clone with full history (actions/checkout: fetch-depth: 0) or calculate with --allow-deepen")
+	default:
+		report.add("shallow-clone", OK, "full history is available", "")
+	}
+}
+
+func checkTags(report *Report, repo *git.Repository) {
+	if tag, _ := repo.GetLatestVersionTag(); tag != "" {
+		report.add("tags", OK, fmt.Sprintf("latest version tag reachable from HEAD is %s", tag), "")
+		return
+	}
+	if tags, _ := repo.GetAllTags(); len(tags) > 0 {
+		report.add("tags", Warning,
+			fmt.Sprintf("%d version tags exist but none is reachable from HEAD", len(tags)),
+			"merge or fetch the branch the release tags are on; versions start from the fallback until then")
+		return
+	}
+	report.add("tags", Warning,
+		"no version tags found; if the remote has release tags they were not fetched",
+		"run 'git fetch --tags' (actions/checkout: fetch-tags: true), or ignore this for a repository without releases")
+}
+
+// checkHead returns the branch versioning runs for
+func checkHead(report *Report, repo *git.Repository, env buildservers.Env) string {
+	branch, _ := repo.GetCurrentBranch()
+	if !repo.IsDetachedHead() {
+		report.add("head", OK, fmt.Sprintf("on branch %s", branch), "")
+		return branch
+	}
+
+	if ci := buildservers.Branch(env); ci != "" {
+		report.add("head", OK, fmt.Sprintf("HEAD is detached; the CI environment names branch %s", ci), "")
+		return ci
+	}
+	if branch == "" || branch == "HEAD" {
+		report.add("head", Error,
+			"HEAD is detached, no CI variable names the branch and no branch contains HEAD",
+			"pass the branch with gitversion -b BRANCH, check out a branch, or fetch the branch that contains the commit")
+		return ""
+	}
+	report.add("head", Warning,
+		fmt.Sprintf("HEAD is detached and no CI variable names the branch; guessed %s from the branches containing HEAD", branch),
+		"pass the branch with gitversion -b BRANCH so the guess cannot pick the wrong branch")
+	return branch
+}
+
+func checkConfig(report *Report, repo *git.Repository, configPath string) *config.Config {
+	if configPath == "" {
+		if root, err := repo.GetRootDir(); err == nil {
+			// Configuration files are only used when passed with -c
+			if path := config.Find(root); path != "" {
+				name := filepath.Base(path)
+				report.add("config", Warning,
+					fmt.Sprintf("%s exists but is not used; the built-in defaults apply", name),
+					fmt.Sprintf("pass it with -c %s", name))
+				configPath = path
+			}
+		}
+		if configPath == "" {
+			report.add("config", OK, "using the built-in defaults", "")
+			cfg, _ := config.LoadConfig("")
+			return cfg
+		}
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		report.add("config", Error, err.Error(), "fix the configuration file or pass the right one with -c")
+		return nil
+	}
+	report.add("config", OK, fmt.Sprintf("%s parses", configPath), "")
+
+	for _, deprecation := range cfg.Deprecations {
+		report.add("config-deprecated", Warning, deprecation, "replace the GitVersion 5 key as the message says")
+	}
+
+	for _, overlap := range cfg.BranchOverlaps() {
+		report.add("branch-overlap", Warning,
+			fmt.Sprintf("branch configurations %s all match %s; only %s applies",
+				strings.Join(overlap.Keys, ", "), strings.Join(overlap.Examples, ", "), overlap.Winner),
+			"narrow the regexes so each branch matches one configuration")
+	}
+	return cfg
+}
+
+func checkBranch(report *Report, cfg *config.Config, branch string) {
+	if branch == "" {
+		return
+	}
+	key, _ := cfg.ResolveBranchConfiguration(branch)
+	if key == "" {
+		report.add("branch", Warning,
+			fmt.Sprintf("branch %s matches no configured branch regex; the fallback configuration applies", branch),
+			"add a branch configuration whose regex matches it")
+		return
+	}
+	report.add("branch", OK, fmt.Sprintf("branch %s uses the %s configuration", branch, key), "")
+}
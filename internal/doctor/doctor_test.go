@@ -0,0 +1,171 @@
+package doctor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+)
+
+func setupTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	chdir(t, dir)
+	runGit(t, "init", "-q", "-b", "main")
+	runGit(t, "config", "user.name", "Test User")
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "config", "commit.gpgsign", "false")
+	runGit(t, "config", "tag.gpgsign", "false")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+func noEnv(string) string { return "" }
+
+// finding returns the finding of check, failing the test when it is missing
+func finding(t *testing.T, report *Report, check string) Finding {
+	t.Helper()
+	for _, f := range report.Findings {
+		if f.Check == check {
+			return f
+		}
+	}
+	t.Fatalf("No %s finding in %+v", check, report.Findings)
+	return Finding{}
+}
+
+func TestHealthyRepository(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "tag", "v1.0.0")
+
+	report := Run(git.NewRepository(), "", noEnv)
+	if !report.Healthy() || report.Count(Warning) != 0 {
+		t.Errorf("Expected no errors or warnings, got %+v", report.Findings)
+	}
+	if f := finding(t, report, "branch"); !strings.Contains(f.Message, "main configuration") {
+		t.Errorf("branch finding = %+v", f)
+	}
+}
+
+func TestShallowClone(t *testing.T) {
+	origin := setupTestRepo(t)
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "second")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, "clone", "-q", "--depth", "1", "file://"+origin, clone)
+	chdir(t, clone)
+
+	report := Run(git.NewRepository(), "", noEnv)
+	if f := finding(t, report, "shallow-clone"); f.Status != Error || !strings.Contains(f.Fix, "--unshallow") {
+		t.Errorf("shallow-clone finding = %+v", f)
+	}
+	if f := finding(t, report, "tags"); f.Status != Warning {
+		t.Errorf("tags finding = %+v", f)
+	}
+	if report.Healthy() {
+		t.Errorf("Expected a shallow clone to be unhealthy")
+	}
+}
+
+func TestDetachedHead(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "--detach")
+
+	report := Run(git.NewRepository(), "", func(key string) string {
+		return map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/main"}[key]
+	})
+	if f := finding(t, report, "head"); f.Status != OK || !strings.Contains(f.Message, "main") {
+		t.Errorf("head finding with CI branch = %+v", f)
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITHUB_REF", "")
+	report = Run(git.NewRepository(), "", noEnv)
+	if f := finding(t, report, "head"); f.Status != Warning || !strings.Contains(f.Message, "guessed main") {
+		t.Errorf("head finding without CI branch = %+v", f)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	dir := setupTestRepo(t)
+	runGit(t, "tag", "v1.0.0")
+
+	broken := filepath.Join(dir, "GitVersion.yml")
+	if err := os.WriteFile(broken, []byte("branches: [unclosed\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	report := Run(git.NewRepository(), broken, noEnv)
+	if f := finding(t, report, "config"); f.Status != Error {
+		t.Errorf("config finding = %+v", f)
+	}
+	if report.Healthy() {
+		t.Errorf("Expected an unparsable config to be unhealthy")
+	}
+
+	report = Run(git.NewRepository(), "", noEnv)
+	if f := finding(t, report, "config"); f.Status != Warning || !strings.Contains(f.Fix, "-c GitVersion.yml") {
+		t.Errorf("Expected a warning about the unused config file, got %+v", f)
+	}
+}
+
+func TestUnmatchedBranch(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "-b", "experiment")
+
+	report := Run(git.NewRepository(), "", noEnv)
+	if f := finding(t, report, "branch"); f.Status != Warning || !strings.Contains(f.Message, "experiment") {
+		t.Errorf("branch finding = %+v", f)
+	}
+	if !report.Healthy() {
+		t.Errorf("Expected an unmatched branch to be a warning only, got %+v", report.Findings)
+	}
+}
+
+func TestLinkedWorktree(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "tag", "v1.0.0")
+	if report := Run(git.NewRepository(), "", noEnv); len(report.Findings) > 0 && report.Findings[0].Check == "worktree" {
+		t.Errorf("Expected no worktree finding in the main working tree, got %+v", report.Findings[0])
+	}
+
+	linked := filepath.Join(t.TempDir(), "linked")
+	runGit(t, "worktree", "add", "-q", "-b", "feature/x", linked)
+	chdir(t, linked)
+
+	report := Run(git.NewRepository(), "", noEnv)
+	if f := finding(t, report, "worktree"); f.Status != OK || !strings.Contains(f.Message, "linked worktree") {
+		t.Errorf("worktree finding = %+v", f)
+	}
+	if f := finding(t, report, "head"); !strings.Contains(f.Message, "feature/x") {
+		t.Errorf("head finding = %+v", f)
+	}
+}
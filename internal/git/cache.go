@@ -0,0 +1,87 @@
+package git
+
+import (
+	"strings"
+	"sync"
+)
+
+// commandCache remembers the output of the read-only git commands run by
+// the repositories sharing it
+type commandCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedOutput
+}
+
+// cachedOutput is the output of a command, run once however many callers
+// ask for it at the same time
+type cachedOutput struct {
+	once   sync.Once
+	output []byte
+	err    error
+}
+
+// NewCachedRepository returns a repository for the working tree in dir,
+// "" for the current directory, that runs each read-only git command once,
+// for calculating several versions of one working tree in a row. Copies
+// made by WithPaths and WithTagPrefix share the cache, so the history they
+// have in common is only read once. Creating a tag and Refresh clear it.
+func NewCachedRepository(dir string) *Repository {
+	return &Repository{dir: dir, cache: &commandCache{entries: map[string]*cachedOutput{}}}
+}
+
+// output runs a read-only git command, answering from the cache when the
+// repository has one. Failures are *CommandError.
+func (r *Repository) output(args ...string) ([]byte, error) {
+	if r.cache == nil {
+		return r.run(args...)
+	}
+
+	// The lock is not held while git runs, so different commands of
+	// concurrent strategies run side by side
+	key := strings.Join(args, "\x00")
+	r.cache.mu.Lock()
+	cached, ok := r.cache.entries[key]
+	if !ok {
+		cached = &cachedOutput{}
+		r.cache.entries[key] = cached
+	}
+	r.cache.mu.Unlock()
+
+	cached.once.Do(func() {
+		cached.output, cached.err = r.run(args...)
+	})
+	// A command killed by its context says nothing about the repository
+	if cached.err != nil && r.ctx != nil && r.ctx.Err() != nil {
+		r.cache.mu.Lock()
+		if r.cache.entries[key] == cached {
+			delete(r.cache.entries, key)
+		}
+		r.cache.mu.Unlock()
+	}
+	return cached.output, cached.err
+}
+
+// run runs a git command and returns its output
+func (r *Repository) run(args ...string) ([]byte, error) {
+	output, err := r.command(args...).Output()
+	if err != nil {
+		return output, commandError(args, err)
+	}
+	return output, nil
+}
+
+// Refresh forgets the cached output, so the commands that follow see
+// commits, refs and working tree changes made since they last ran
+func (r *Repository) Refresh() {
+	r.invalidate()
+}
+
+// invalidate forgets cached output after the repository changed
+func (r *Repository) invalidate() {
+	if r.cache == nil {
+		return
+	}
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+	r.cache.entries = map[string]*cachedOutput{}
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/buildservers"
+)
+
+// branchFromEnvironment returns the branch a CI system reports for the
+// current build, or "" when none of the known variables names a branch.
+func branchFromEnvironment(getenv func(string) string) string {
+	return buildservers.Branch(getenv)
+}
+
+// branchContainingHead returns a branch containing HEAD, preferring local
+// branches over remote tracking branches. When several branches qualify the
+// first in name order is used.
+func (r *Repository) branchContainingHead() string {
+	output, err := r.command("branch", "--all", "--contains", "HEAD", "--format=%(refname)").Output()
+	if err != nil {
+		return ""
+	}
+
+	var local, remote []string
+	for _, line := range strings.Split(string(output), "\n") {
+		ref := strings.TrimSpace(line)
+		branch := buildservers.NormalizeBranchRef(ref)
+		if branch == "" {
+			continue // detached HEAD entry or origin/HEAD
+		}
+		if strings.HasPrefix(ref, "refs/heads/") {
+			local = append(local, branch)
+		} else {
+			remote = append(remote, branch)
+		}
+	}
+
+	for _, branches := range [][]string{local, remote} {
+		if len(branches) > 0 {
+			sort.Strings(branches)
+			return branches[0]
+		}
+	}
+	return ""
+}
+
+// inferDetachedBranch names the logical branch of a detached HEAD, first
+// from the CI environment and then from the branches containing HEAD.
+func (r *Repository) inferDetachedBranch() string {
+	if branch := branchFromEnvironment(os.Getenv); branch != "" {
+		return branch
+	}
+	return r.branchContainingHead()
+}
@@ -0,0 +1,85 @@
+package git
+
+import "testing"
+
+func TestBranchFromEnvironment(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "GitHub push",
+			env:      map[string]string{"GITHUB_REF": "refs/heads/feature/login"},
+			expected: "feature/login",
+		},
+		{
+			name:     "GitHub pull request uses head ref",
+			env:      map[string]string{"GITHUB_REF": "refs/pull/12/merge", "GITHUB_HEAD_REF": "feature/login"},
+			expected: "feature/login",
+		},
+		{
+			name:     "GitHub tag is not a branch",
+			env:      map[string]string{"GITHUB_REF": "refs/tags/v1.0.0"},
+			expected: "",
+		},
+		{
+			name:     "GitLab branch",
+			env:      map[string]string{"CI_COMMIT_REF_NAME": "develop"},
+			expected: "develop",
+		},
+		{
+			name:     "GitLab tag pipeline",
+			env:      map[string]string{"CI_COMMIT_REF_NAME": "v1.0.0", "CI_COMMIT_TAG": "v1.0.0"},
+			expected: "",
+		},
+		{
+			name:     "GitLab merge request",
+			env:      map[string]string{"CI_COMMIT_REF_NAME": "main", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature/x"},
+			expected: "feature/x",
+		},
+		{
+			name:     "Azure DevOps",
+			env:      map[string]string{"BUILD_SOURCEBRANCH": "refs/heads/release/1.2"},
+			expected: "release/1.2",
+		},
+		{
+			name:     "Azure DevOps pull request",
+			env:      map[string]string{"BUILD_SOURCEBRANCH": "refs/pull/3/merge", "SYSTEM_PULLREQUEST_SOURCEBRANCH": "refs/heads/hotfix/crash"},
+			expected: "hotfix/crash",
+		},
+		{
+			name:     "Jenkins remote branch",
+			env:      map[string]string{"GIT_BRANCH": "origin/main"},
+			expected: "main",
+		},
+		{
+			name:     "No CI",
+			env:      map[string]string{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if branch := branchFromEnvironment(getenv); branch != tt.expected {
+				t.Errorf("branchFromEnvironment() = %q, want %q", branch, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBranchContainingHead(t *testing.T) {
+	setupTestRepo(t)
+
+	commit(t, "initial commit")
+	runGit(t, "branch", "-M", "main")
+	runGit(t, "checkout", "-q", "-b", "feature/a")
+	commit(t, "feat: a")
+	runGit(t, "checkout", "-q", "--detach", "HEAD")
+
+	if branch := NewRepository().branchContainingHead(); branch != "feature/a" {
+		t.Errorf("branchContainingHead() = %q, want feature/a", branch)
+	}
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag is a tag and the commit it points at
+type Tag struct {
+	Name string
+	// SHA is the tagged commit, annotated tags peeled
+	SHA string
+	// Annotated is set for tag objects, unset for lightweight tags
+	Annotated bool
+}
+
+// GetTagCommits returns the tags carrying the repository's tag prefix with
+// the commit each points at, sorted like GetAllTags, leaving out those the
+// tag selection ignores. A single for-each-ref replaces resolving every tag
+// with a rev-list of its own.
+func (r *Repository) GetTagCommits() ([]*Tag, error) {
+	output, err := r.output("for-each-ref", "--format=%(refname) %(objectname) %(*objectname) %(*objecttype)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	commits := map[string]string{}
+	annotated := map[string]bool{}
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], "refs/tags/")
+		if !strings.HasPrefix(name, r.tagPrefix) {
+			continue
+		}
+		sha := fields[1]
+		if len(fields) == 4 {
+			annotated[name] = true
+			sha = fields[2]
+			// for-each-ref peels a single level; tags of tags are rare
+			// enough to resolve one at a time
+			if fields[3] == "tag" {
+				if sha, err = r.GetCommitSHAForTag(name); err != nil {
+					continue
+				}
+			}
+		}
+		commits[name] = sha
+		names = append(names, name)
+	}
+
+	sortTags(names, r.ParseTag)
+	tags := make([]*Tag, len(names))
+	for i, name := range names {
+		tags[i] = &Tag{Name: name, SHA: commits[name], Annotated: annotated[name]}
+	}
+	return r.selectTags(tags), nil
+}
+
+// CommitGraph is the history of HEAD and the tags, read in a single git log
+// pass, so ancestry questions about many commits need no process each
+type CommitGraph struct {
+	parents map[string][]string
+	// reachable holds the commits reachable from HEAD
+	reachable map[string]bool
+}
+
+// GetCommitGraph reads the commits reachable from HEAD or any tag with their
+// parents. The graph of an unborn branch is empty.
+func (r *Repository) GetCommitGraph() (*CommitGraph, error) {
+	graph := &CommitGraph{parents: map[string][]string{}, reachable: map[string]bool{}}
+	head, err := r.output("rev-parse", "HEAD")
+	if err != nil {
+		return graph, nil
+	}
+	output, err := r.output("log", "--format=%H %P", "HEAD", "--tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			graph.parents[fields[0]] = fields[1:]
+		}
+	}
+
+	pending := []string{strings.TrimSpace(string(head))}
+	for len(pending) > 0 {
+		sha := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if graph.reachable[sha] {
+			continue
+		}
+		graph.reachable[sha] = true
+		pending = append(pending, graph.parents[sha]...)
+	}
+	return graph, nil
+}
+
+// Parents returns the parents of a commit in the graph
+func (g *CommitGraph) Parents(sha string) []string {
+	return g.parents[sha]
+}
+
+// ReachableFromHead reports whether sha is HEAD or one of its ancestors
+func (g *CommitGraph) ReachableFromHead(sha string) bool {
+	return g.reachable[sha]
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RemoteExists reports whether the repository has a remote of that name
+func (r *Repository) RemoteExists(remote string) bool {
+	_, err := r.output("config", "--get", "remote."+remote+".url")
+	return err == nil
+}
+
+// FetchAll fetches every branch and tag of remote into its tracking
+// branches, pruning tracking branches deleted on the remote. unshallow
+// fetches the history a shallow clone is missing as well.
+func (r *Repository) FetchAll(remote string, unshallow bool, auth RemoteAuth) error {
+	args := []string{"fetch", "--quiet", "--prune", "--tags"}
+	if unshallow {
+		args = append(args, "--unshallow")
+	}
+	args = append(args, remote, fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", remote))
+	cmd := r.command(args...)
+	cmd.Env = append(r.environ(), auth.env()...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to fetch from %s: %s", remote, strings.TrimSpace(string(output)))
+	}
+	r.invalidate()
+	return nil
+}
+
+// RemoteBranches returns the names of the tracking branches of remote,
+// without the remote prefix and its HEAD
+func (r *Repository) RemoteBranches(remote string) ([]string, error) {
+	prefix := "refs/remotes/" + remote + "/"
+	output, err := r.output("for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches of %s: %w", remote, err)
+	}
+	var branches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if branch := strings.TrimPrefix(strings.TrimSpace(line), prefix); branch != "" && branch != "HEAD" {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// BranchesAtHead returns the local branches pointing at HEAD
+func (r *Repository) BranchesAtHead() ([]string, error) {
+	output, err := r.output("for-each-ref", "--points-at", "HEAD", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches at HEAD: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// CreateBranch creates branch at start without checking it out
+func (r *Repository) CreateBranch(branch, start string) error {
+	output, err := r.command("branch", "--quiet", "--no-track", branch, start).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %s", branch, strings.TrimSpace(string(output)))
+	}
+	r.invalidate()
+	return nil
+}
+
+// CheckoutBranchAtHead checks out branch, creating it or moving it to the
+// commit HEAD points at. The working tree is left as it is.
+func (r *Repository) CheckoutBranchAtHead(branch string) error {
+	output, err := r.command("checkout", "--quiet", "-B", branch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to check out %s: %s", branch, strings.TrimSpace(string(output)))
+	}
+	r.invalidate()
+	return nil
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// ResolveCommit returns the full SHA of the commit rev names
+func (r *Repository) ResolveCommit(rev string) (string, error) {
+	output, err := r.command("rev-parse", "-q", "--verify", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %s", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AddNote attaches message to commit under the notes ref, replacing any
+// note the commit already has there.
+func (r *Repository) AddNote(ref, commit, message string) error {
+	output, err := r.command("notes", "--ref", ref, "add", "-f", "-m", message, commit).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add note to %s: %s", commit, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetNote returns the note attached to commit under the notes ref. ok is
+// false when the commit has no note.
+func (r *Repository) GetNote(ref, commit string) (note string, ok bool, err error) {
+	if r.noteObject(ref, commit) == "" {
+		return "", false, nil
+	}
+	output, err := r.command("notes", "--ref", ref, "show", commit).Output()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read note of %s: %w", commit, err)
+	}
+	return string(output), true, nil
+}
+
+// GetNotedCommits lists the commits with a note under the notes ref
+func (r *Repository) GetNotedCommits(ref string) ([]string, error) {
+	if !r.RefExists(ref) {
+		return nil, nil
+	}
+	output, err := r.command("notes", "--ref", ref, "list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+
+	var commits []string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		// Each line is "<note object> <annotated commit>"
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 {
+			commits = append(commits, fields[1])
+		}
+	}
+	return commits, nil
+}
+
+// noteObject returns the object holding commit's note, "" when it has none
+func (r *Repository) noteObject(ref, commit string) string {
+	output, err := r.command("notes", "--ref", ref, "list", commit).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
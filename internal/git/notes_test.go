@@ -0,0 +1,66 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	setupTestRepo(t)
+	repo := NewRepository()
+	const ref = "refs/notes/test"
+
+	commit(t, "initial commit")
+	first := runGit(t, "rev-parse", "HEAD")
+	commit(t, "feat: second")
+	second := runGit(t, "rev-parse", "HEAD")
+
+	if commits, err := repo.GetNotedCommits(ref); err != nil || len(commits) != 0 {
+		t.Fatalf("GetNotedCommits() without notes = %v, %v; want none", commits, err)
+	}
+	if _, ok, err := repo.GetNote(ref, first); ok || err != nil {
+		t.Fatalf("GetNote() without notes = %v, %v; want no note", ok, err)
+	}
+
+	if err := repo.AddNote(ref, first, "one"); err != nil {
+		t.Fatalf("AddNote() error = %v", err)
+	}
+	// Adding again replaces the note
+	if err := repo.AddNote(ref, first, "uno"); err != nil {
+		t.Fatalf("AddNote() replacing error = %v", err)
+	}
+
+	note, ok, err := repo.GetNote(ref, first)
+	if err != nil || !ok || note != "uno\n" {
+		t.Errorf("GetNote(first) = %q, %v, %v; want \"uno\\n\"", note, ok, err)
+	}
+	if _, ok, err := repo.GetNote(ref, second); ok || err != nil {
+		t.Errorf("GetNote(second) = %v, %v; want no note", ok, err)
+	}
+
+	commits, err := repo.GetNotedCommits(ref)
+	if err != nil {
+		t.Fatalf("GetNotedCommits() error = %v", err)
+	}
+	if !reflect.DeepEqual(commits, []string{first}) {
+		t.Errorf("GetNotedCommits() = %v, want [%s]", commits, first)
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	setupTestRepo(t)
+	repo := NewRepository()
+
+	commit(t, "initial commit")
+	runGit(t, "tag", "-a", "-m", "Release v1.0.0", "v1.0.0")
+	head := runGit(t, "rev-parse", "HEAD")
+
+	for _, rev := range []string{"HEAD", "v1.0.0", head[:7]} {
+		if got, err := repo.ResolveCommit(rev); err != nil || got != head {
+			t.Errorf("ResolveCommit(%q) = %q, %v; want %s", rev, got, err, head)
+		}
+	}
+	if _, err := repo.ResolveCommit("missing"); err == nil {
+		t.Errorf("ResolveCommit(missing) should fail")
+	}
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment variables RemoteAuthFromEnv reads
+const (
+	// RemoteTokenEnv holds a token sent as the password, e.g. a GitHub
+	// token; it takes precedence over RemotePasswordEnv
+	RemoteTokenEnv = "GITVERSION_REMOTE_TOKEN"
+	// RemoteUsernameEnv holds the user name sent with the token or password,
+	// x-access-token when unset
+	RemoteUsernameEnv = "GITVERSION_REMOTE_USERNAME"
+	// RemotePasswordEnv holds a password
+	RemotePasswordEnv = "GITVERSION_REMOTE_PASSWORD"
+)
+
+// RemoteAuth holds the credentials for talking to a remote: HTTP
+// credentials and the SSH options. The zero value leaves authentication to
+// git, its credential helpers and ssh.
+type RemoteAuth struct {
+	// Username defaults to x-access-token, which GitHub accepts for tokens
+	Username string
+	Token    string
+	// Password is sent when Token is empty
+	Password string
+	SSH      SSHOptions
+}
+
+// RemoteAuthFromEnv returns the credentials the environment variables
+// hold, the zero value when they hold none
+func RemoteAuthFromEnv(getenv func(string) string) (RemoteAuth, error) {
+	auth := RemoteAuth{
+		Username: getenv(RemoteUsernameEnv),
+		Token:    getenv(RemoteTokenEnv),
+		Password: getenv(RemotePasswordEnv),
+		SSH: SSHOptions{
+			IdentityFile:          getenv(SSHIdentityFileEnv),
+			StrictHostKeyChecking: getenv(SSHStrictHostKeyCheckingEnv),
+			KnownHostsFile:        getenv(SSHKnownHostsFileEnv),
+		},
+	}
+	if err := auth.SSH.validate(); err != nil {
+		return RemoteAuth{}, fmt.Errorf("%s: %w", SSHStrictHostKeyCheckingEnv, err)
+	}
+	return auth, nil
+}
+
+// credentialHelper answers git's credential requests from the environment
+// of the git process, so the secret is neither in a remote URL, nor in the
+// configuration, nor on a command line
+const credentialHelper = `!f() { test "$1" = get || exit 0; echo "username=$GITVERSION_CREDENTIAL_USERNAME"; echo "password=$GITVERSION_CREDENTIAL_PASSWORD"; }; f`
+
+// env returns the environment that makes git authenticate with the
+// credentials: ssh runs with the SSH options, HTTPS through an ephemeral
+// credential helper. The helper replaces the configured ones for the one
+// command through GIT_CONFIG_*, leaving the configuration files alone.
+// Prompts are disabled either way, a CI job has nobody to answer them.
+func (a RemoteAuth) env() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if command := a.SSH.command(); command != "" {
+		env = append(env, "GIT_SSH_COMMAND="+command)
+	}
+	secret := a.Token
+	if secret == "" {
+		secret = a.Password
+	}
+	if secret == "" {
+		return env
+	}
+	username := a.Username
+	if username == "" {
+		username = "x-access-token"
+	}
+	return append(env,
+		"GITVERSION_CREDENTIAL_USERNAME="+username,
+		"GITVERSION_CREDENTIAL_PASSWORD="+secret,
+		"GIT_CONFIG_COUNT=2",
+		// An empty helper clears the list, so no other helper is asked or
+		// stores the credentials
+		"GIT_CONFIG_KEY_0=credential.helper",
+		"GIT_CONFIG_VALUE_0=",
+		"GIT_CONFIG_KEY_1=credential.helper",
+		"GIT_CONFIG_VALUE_1="+credentialHelper,
+	)
+}
+
+// PushTag pushes tag to remote
+func (r *Repository) PushTag(remote, tag string, auth RemoteAuth) error {
+	cmd := r.command("push", remote, "refs/tags/"+tag)
+	cmd.Env = append(r.environ(), auth.env()...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to push tag %s to %s: %s", tag, remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPushTag(t *testing.T) {
+	setupTestRepo(t)
+	remote := t.TempDir()
+	runGit(t, "init", "-q", "--bare", remote)
+	runGit(t, "remote", "add", "origin", remote)
+
+	commit(t, "initial commit")
+	runGit(t, "tag", "-a", "v1.0.0", "-m", "Release v1.0.0")
+
+	repo := NewRepository()
+	if err := repo.PushTag("origin", "v1.0.0", RemoteAuth{}); err != nil {
+		t.Fatalf("PushTag() error = %v", err)
+	}
+	if refs := runGit(t, "ls-remote", "--tags", "origin"); !strings.Contains(refs, "refs/tags/v1.0.0") {
+		t.Errorf("remote tags = %q, want v1.0.0", refs)
+	}
+
+	if err := repo.PushTag("origin", "v9.9.9", RemoteAuth{}); err == nil {
+		t.Errorf("Expected pushing a missing tag to fail")
+	}
+}
+
+func TestRemoteAuthEnv(t *testing.T) {
+	if env := (RemoteAuth{}).env(); len(env) != 1 || env[0] != "GIT_TERMINAL_PROMPT=0" {
+		t.Errorf("env() without token = %v, want only GIT_TERMINAL_PROMPT=0", env)
+	}
+
+	env := RemoteAuth{Token: "s3cret"}.env()
+	joined := strings.Join(env, "\n")
+	if !strings.Contains(joined, "GITVERSION_CREDENTIAL_USERNAME=x-access-token\n") || !strings.Contains(joined, "GITVERSION_CREDENTIAL_PASSWORD=s3cret") {
+		t.Errorf("env() = %q, want credentials for x-access-token", joined)
+	}
+	// The secret must not end up in the configuration git sees
+	for _, entry := range env {
+		if strings.HasPrefix(entry, "GIT_CONFIG_") && strings.Contains(entry, "s3cret") {
+			t.Errorf("env() puts the secret into %q", entry)
+		}
+	}
+
+	joined = strings.Join(RemoteAuth{Username: "oauth2", Password: "pa55"}.env(), "\n")
+	if !strings.Contains(joined, "GITVERSION_CREDENTIAL_USERNAME=oauth2") || !strings.Contains(joined, "GITVERSION_CREDENTIAL_PASSWORD=pa55") {
+		t.Errorf("env() = %q, want the password for oauth2", joined)
+	}
+
+	joined = strings.Join(RemoteAuth{Token: "token", Password: "pa55"}.env(), "\n")
+	if !strings.Contains(joined, "GITVERSION_CREDENTIAL_PASSWORD=token") {
+		t.Errorf("env() = %q, want the token to win over the password", joined)
+	}
+}
+
+func TestRemoteAuthFromEnv(t *testing.T) {
+	vars := map[string]string{RemoteUsernameEnv: "ci", RemotePasswordEnv: "pa55", SSHStrictHostKeyCheckingEnv: "accept-new"}
+	getenv := func(name string) string { return vars[name] }
+	auth, err := RemoteAuthFromEnv(getenv)
+	if err != nil || auth != (RemoteAuth{Username: "ci", Password: "pa55", SSH: SSHOptions{StrictHostKeyChecking: "accept-new"}}) {
+		t.Errorf("RemoteAuthFromEnv() = %+v, %v", auth, err)
+	}
+
+	vars[SSHStrictHostKeyCheckingEnv] = "ask"
+	if _, err := RemoteAuthFromEnv(getenv); err == nil {
+		t.Error("Expected an error for host key checking mode ask")
+	}
+}
+
+// TestRemoteAuthOverHTTP pushes and fetches through git http-backend behind
+// basic auth, the way a hosted remote asks for credentials
+func TestRemoteAuthOverHTTP(t *testing.T) {
+	setupTestRepo(t)
+	backend := filepath.Join(runGit(t, "--exec-path"), "git-http-backend")
+	if _, err := os.Stat(backend); err != nil {
+		t.Skip("git-http-backend not available")
+	}
+	root := t.TempDir()
+	runGit(t, "init", "-q", "--bare", filepath.Join(root, "repo.git"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "ci" || password != "s3cret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler := &cgi.Handler{
+			Path: backend,
+			Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1", "REMOTE_USER=ci"},
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	runGit(t, "remote", "add", "origin", server.URL+"/repo.git")
+	// A helper configured by the user is neither asked nor told to store
+	// the credentials
+	stored := filepath.Join(root, "stored")
+	runGit(t, "config", "credential.helper",
+		`!f() { test "$1" = get && echo username=ci && echo password=wrong; test "$1" = store && touch '`+stored+`'; exit 0; }; f`)
+
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	repo := NewRepository()
+	if err := repo.PushTag("origin", "v1.0.0", RemoteAuth{}); err == nil {
+		t.Fatal("Expected pushing without credentials to fail")
+	}
+	if err := repo.PushTag("origin", "v1.0.0", RemoteAuth{Username: "ci", Password: "wrong"}); err == nil {
+		t.Fatal("Expected pushing with a wrong password to fail")
+	}
+	auth := RemoteAuth{Username: "ci", Token: "s3cret"}
+	if err := repo.PushTag("origin", "v1.0.0", auth); err != nil {
+		t.Fatalf("PushTag() error = %v", err)
+	}
+
+	runGit(t, "tag", "-d", "v1.0.0")
+	if err := repo.FetchAll("origin", false, auth); err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	if !repo.RefExists("v1.0.0") {
+		t.Error("Expected the fetch to bring back v1.0.0")
+	}
+	if _, err := os.Stat(stored); err == nil {
+		t.Error("The configured credential helper was asked to store the credentials")
+	}
+	if url := runGit(t, "remote", "get-url", "origin"); strings.Contains(url, "s3cret") {
+		t.Errorf("The remote URL %s holds the secret", url)
+	}
+}
@@ -2,53 +2,512 @@ package git
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
 
 // Commit represents a git commit
 type Commit struct {
-	SHA     string
+	SHA string
+	// Message is the subject, the first line of the commit message
 	Message string
 	Date    string
+	// Body is the rest of the commit message, footers included
+	Body string
 }
 
-type Repository struct{}
+// FullMessage returns the whole commit message, the subject and the body
+// separated by a blank line as git writes them
+func (c *Commit) FullMessage() string {
+	if c.Body == "" {
+		return c.Message
+	}
+	return c.Message + "\n\n" + c.Body
+}
+
+type Repository struct {
+	// dir is the working tree git runs in; "" is the current directory.
+	dir string
+	// tagPrefix restricts version tags to those starting with the prefix,
+	// e.g. "tools/" for the module in the tools directory.
+	tagPrefix string
+	// versionPrefix matches the configured tag-prefix between tagPrefix
+	// and the version, e.g. "v" of v1.2.3; nil accepts an optional v
+	versionPrefix *regexp.Regexp
+	// tagSelection decides between annotated and lightweight version tags
+	tagSelection TagSelection
+	// latestTag decides which reachable version tag is the latest
+	latestTag LatestTag
+	// stableTags ignores version tags with a prerelease
+	stableTags bool
+	// paths are pathspecs that scope commit history and counts.
+	paths []string
+	// component is the monorepo component versioned, and scopeComponents
+	// maps conventional commit scopes to components, so that commits
+	// scoped to other components are left out of history and counts
+	component       string
+	scopeComponents map[string]string
+	// remote is the primary remote, whose tracking branches stand in for
+	// missing local branches; "" is DefaultRemote.
+	remote string
+	// cache holds command output shared with copies of the repository;
+	// nil runs every command.
+	cache *commandCache
+	// ctx kills the git commands still running when it is done; nil never
+	// does
+	ctx context.Context
+	// logger records every git command at debug level; nil records nothing
+	logger *slog.Logger
+}
+
+// DefaultRemote is the primary remote of a repository unless configured
+// otherwise
+const DefaultRemote = "origin"
 
 func NewRepository() *Repository {
 	return &Repository{}
 }
 
+// NewRepositoryAt returns the repository of the working tree in dir, so
+// that several repositories can be used at once without changing the
+// working directory of the process
+func NewRepositoryAt(dir string) *Repository {
+	return &Repository{dir: dir}
+}
+
+// NewScopedRepository returns a repository that only considers version tags
+// starting with tagPrefix and commits touching the given pathspecs.
+func NewScopedRepository(tagPrefix string, paths []string) *Repository {
+	return NewRepository().WithScope(tagPrefix, paths)
+}
+
+// WithScope returns a copy of the repository that only considers version
+// tags starting with tagPrefix and commits touching the given pathspecs,
+// in place of its own
+func (r *Repository) WithScope(tagPrefix string, paths []string) *Repository {
+	scoped := *r
+	scoped.tagPrefix = tagPrefix
+	scoped.paths = paths
+	return &scoped
+}
+
+// WithContext returns a copy of the repository whose git commands are
+// killed once ctx is done, so a hung command such as a credential prompt
+// cannot block forever
+func (r *Repository) WithContext(ctx context.Context) *Repository {
+	scoped := *r
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// WithRemote returns a copy of the repository whose primary remote is
+// remote, e.g. upstream in a fork whose origin is the fork
+func (r *Repository) WithRemote(remote string) *Repository {
+	scoped := *r
+	scoped.remote = remote
+	return &scoped
+}
+
+// Remote returns the primary remote of the repository
+func (r *Repository) Remote() string {
+	if r.remote == "" {
+		return DefaultRemote
+	}
+	return r.remote
+}
+
+// WithLogger returns a copy of the repository that logs the git commands it
+// runs to logger
+func (r *Repository) WithLogger(logger *slog.Logger) *Repository {
+	scoped := *r
+	scoped.logger = logger
+	return &scoped
+}
+
+// commandsRun counts the git commands prepared by every repository
+var commandsRun atomic.Int64
+
+// CommandsRun returns the number of git subprocesses the process has run
+func CommandsRun() int64 {
+	return commandsRun.Load()
+}
+
+// repositoryEnv are the variables that tell git where the repository is,
+// as hooks and deployment tools set them
+var repositoryEnv = map[string]bool{
+	"GIT_DIR":                          true,
+	"GIT_WORK_TREE":                    true,
+	"GIT_COMMON_DIR":                   true,
+	"GIT_INDEX_FILE":                   true,
+	"GIT_OBJECT_DIRECTORY":             true,
+	"GIT_ALTERNATE_OBJECT_DIRECTORIES": true,
+	"GIT_SHALLOW_FILE":                 true,
+	"GIT_GRAFT_FILE":                   true,
+	"GIT_IMPLICIT_WORK_TREE":           true,
+	"GIT_PREFIX":                       true,
+}
+
+// environ returns the environment git runs with. A repository of the
+// working directory, from NewRepository, passes GIT_DIR, GIT_WORK_TREE and
+// the like on to git, so a separated git directory is found as git itself
+// finds it. A repository at an explicit directory drops them: they name
+// the repository of the process, not the one in that directory.
+func (r *Repository) environ() []string {
+	env := os.Environ()
+	if r.dir == "" {
+		return env
+	}
+	var kept []string
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if !repositoryEnv[name] {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// command prepares a git command that runs in the repository's working tree
+func (r *Repository) command(args ...string) *exec.Cmd {
+	commandsRun.Add(1)
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	if r.dir != "" {
+		cmd.Env = r.environ()
+	}
+	if r.logger != nil {
+		r.logger.Debug("running git", "args", args, "dir", r.dir)
+	}
+	return cmd
+}
+
+// CommandError is a git command that failed, with what it printed on stderr
+type CommandError struct {
+	// Args are the arguments git ran with
+	Args []string
+	// Stderr is the trimmed error output of git, e.g. "fatal: bad revision"
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("git %s: %v", e.Args[0], e.Err)
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// commandError wraps the error of the git command run with args
+func commandError(args []string, err error) error {
+	commandErr := &CommandError{Args: args, Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		commandErr.Stderr = strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return commandErr
+}
+
+// TagPrefix returns the prefix version tags must carry in this repository
+func (r *Repository) TagPrefix() string {
+	return r.tagPrefix
+}
+
+// WithPaths returns a copy of the repository whose commit history and
+// counts also consider commits touching dirs, directories relative to the
+// repository root. A directory starting with "!" is excluded instead.
+// Scoped repositories keep their pathspecs, so the directories add to them.
+func (r *Repository) WithPaths(dirs []string) *Repository {
+	scoped := *r
+	scoped.paths = append([]string(nil), r.paths...)
+	for _, dir := range dirs {
+		magic := "top"
+		if strings.HasPrefix(dir, "!") {
+			magic = "top,exclude"
+			dir = dir[1:]
+		}
+		dir = strings.Trim(path.Clean(filepath.ToSlash(dir)), "/")
+		// ":(top)" alone selects the whole tree; ":(top)." matches nothing
+		if dir == "." {
+			dir = ""
+		}
+		scoped.paths = append(scoped.paths, ":("+magic+")"+dir)
+	}
+	return &scoped
+}
+
+// WithTagPrefix returns a copy of the repository that only considers
+// version tags starting with prefix, e.g. "api/" for api/v1.2.3, in place
+// of its own prefix. Its pathspecs are kept.
+func (r *Repository) WithTagPrefix(prefix string) *Repository {
+	scoped := *r
+	scoped.tagPrefix = prefix
+	return &scoped
+}
+
+// ParseTag parses a version tag, stripping the repository's tag prefix.
+// Tags without the prefix are rejected.
+func (r *Repository) ParseTag(tag string) (*semver.Version, error) {
+	if !strings.HasPrefix(tag, r.tagPrefix) {
+		return nil, fmt.Errorf("tag %s does not have prefix %s", tag, r.tagPrefix)
+	}
+	version := strings.TrimPrefix(tag, r.tagPrefix)
+	if r.versionPrefix == nil {
+		return semver.Parse(version)
+	}
+	if loc := r.versionPrefix.FindStringIndex(version); loc != nil {
+		version = version[loc[1]:]
+	}
+	// Whatever the prefix did not match is not part of the version
+	if version == "" || version[0] < '0' || version[0] > '9' {
+		return nil, fmt.Errorf("tag %s does not match the tag prefix %s", tag, r.versionPrefix)
+	}
+	return semver.Parse(version)
+}
+
+// WithVersionPrefix returns a copy of the repository whose version tags
+// may start with a match of pattern, the tag-prefix regular expression,
+// e.g. [vV] or release-. Tags starting with anything else are no version
+// tags.
+func (r *Repository) WithVersionPrefix(pattern string) (*Repository, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")")
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag-prefix %q: %w", pattern, err)
+	}
+	scoped := *r
+	scoped.versionPrefix = re
+	return &scoped, nil
+}
+
+// GetRootDir returns the top level directory of the working tree
+func (r *Repository) GetRootDir() (string, error) {
+	output, err := r.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetGitDirs returns the absolute git directory of the working tree, which
+// holds its HEAD, and the common directory holding the refs it shares with
+// other worktrees. Outside a linked worktree both are the same.
+func (r *Repository) GetGitDirs() (gitDir, commonDir string, err error) {
+	output, err := r.output("rev-parse", "--absolute-git-dir", "--git-common-dir")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find git directory: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return "", "", fmt.Errorf("failed to find git directory: unexpected output %q", output)
+	}
+	gitDir, commonDir = lines[0], lines[1]
+	if !filepath.IsAbs(commonDir) {
+		// The common directory is relative to where git ran
+		if commonDir, err = filepath.Abs(filepath.Join(r.dir, commonDir)); err != nil {
+			return "", "", err
+		}
+	}
+	return gitDir, commonDir, nil
+}
+
+// IsLinkedWorktree reports whether the working tree is a linked worktree,
+// added with git worktree add, whose .git is a file pointing into the git
+// directory of the main working tree
+func (r *Repository) IsLinkedWorktree() (bool, error) {
+	gitDir, commonDir, err := r.GetGitDirs()
+	if err != nil {
+		return false, err
+	}
+	return !samePath(gitDir, commonDir), nil
+}
+
+// samePath reports whether a and b name the same directory, following
+// symbolic links, as git resolves some and not others
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// withPaths appends the repository's pathspecs to a git command line
+func (r *Repository) withPaths(args ...string) []string {
+	if len(r.paths) == 0 {
+		return args
+	}
+	args = append(args, "--")
+	return append(args, r.paths...)
+}
+
+// filterTags drops tags that do not carry the repository's tag prefix
+func (r *Repository) filterTags(tags []string) []string {
+	if r.tagPrefix == "" {
+		return tags
+	}
+	filtered := tags[:0]
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, r.tagPrefix) {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered
+}
+
+// IsRepository reports whether the directory is inside a working tree or
+// git directory. A linked worktree whose git directory was pruned is not.
 func (r *Repository) IsRepository() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd := r.command("rev-parse", "--git-dir")
 	err := cmd.Run()
 	return err == nil
 }
 
+// GetCurrentBranch returns the checked out branch. For a detached HEAD, as
+// in most CI checkouts, the branch is inferred from the CI environment or
+// from the branches containing HEAD; "HEAD" is returned if that fails.
 func (r *Repository) GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmd.Output()
+	output, err := r.output("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "HEAD", nil
 	}
-	return strings.TrimSpace(string(output)), nil
+
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		if inferred := r.inferDetachedBranch(); inferred != "" {
+			return inferred, nil
+		}
+	}
+	return branch, nil
 }
 
 func (r *Repository) GetLatestTag() (string, error) {
-	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
-	output, err := cmd.Output()
+	output, err := r.output("describe", "--tags", "--abbrev=0")
 	if err != nil {
 		return "", nil
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
+// maxDescribeAttempts bounds how many non-version tags are skipped when
+// looking for the nearest version tag.
+const maxDescribeAttempts = 50
+
+// GetLatestVersionTag returns the nearest tag reachable from HEAD that is a
+// valid semantic version, skipping tags such as "deploy-2024-01", and
+// prerelease tags too with WithoutPreReleaseTags. Like describe, it prefers
+// an annotated tag to a lightweight one on the same commit, and only
+// considers annotated tags with AnnotatedTagsOnly. With HighestTag it
+// returns the reachable version tag with the highest version instead.
+func (r *Repository) GetLatestVersionTag() (string, error) {
+	if r.latestTag == HighestTag {
+		return r.highestVersionTag()
+	}
+	args := []string{"describe", "--abbrev=0"}
+	if r.tagSelection != AnnotatedTagsOnly {
+		args = append(args, "--tags")
+	}
+	if r.tagPrefix != "" {
+		args = append(args, "--match", r.tagPrefix+"*")
+	}
+	for i := 0; i < maxDescribeAttempts; i++ {
+		output, err := r.output(args...)
+		if err != nil {
+			return "", nil
+		}
+
+		tag := strings.TrimSpace(string(output))
+		if version, err := r.ParseTag(tag); err == nil && !r.ignoresVersion(version) {
+			return tag, nil
+		}
+		args = append(args, "--exclude", tag)
+	}
+	return "", nil
+}
+
 func (r *Repository) GetTagsOnCurrentBranch() ([]string, error) {
-	cmd := exec.Command("git", "tag", "--merged", "HEAD")
-	output, err := cmd.Output()
+	output, err := r.output("tag", "--merged", "HEAD")
+	if err != nil {
+		return []string{}, nil
+	}
+
+	var tags []string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		tag := strings.TrimSpace(scanner.Text())
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	tags = r.filterTags(tags)
+	sortTags(tags, r.ParseTag)
+	return tags, nil
+}
+
+// GetVersionTagsAt returns the version tags pointing at commit that the tag
+// selection keeps, in ascending version order
+func (r *Repository) GetVersionTagsAt(commit string) ([]string, error) {
+	output, err := r.output("for-each-ref", "--points-at", commit, "--format=%(refname:strip=2) %(objecttype)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", commit, err)
+	}
+
+	var versionTags []*Tag
+	for _, line := range strings.Split(string(output), "\n") {
+		name, objectType, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if _, err := r.ParseTag(name); err == nil {
+			versionTags = append(versionTags, &Tag{Name: name, SHA: commit, Annotated: objectType == "tag"})
+		}
+	}
+
+	var tags []string
+	for _, tag := range r.selectTags(versionTags) {
+		tags = append(tags, tag.Name)
+	}
+	sortTags(tags, r.ParseTag)
+	return tags, nil
+}
+
+// ListRefs returns every ref with the object it points at, one
+// "<object> <refname>" line each
+func (r *Repository) ListRefs() (string, error) {
+	output, err := r.output("for-each-ref", "--format=%(objectname) %(refname)")
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs: %w", err)
+	}
+	return string(output), nil
+}
+
+// GetAllTags returns every tag in the repository, sorted like GetTagsOnCurrentBranch
+func (r *Repository) GetAllTags() ([]string, error) {
+	output, err := r.output("tag")
 	if err != nil {
 		return []string{}, nil
 	}
@@ -62,83 +521,314 @@ func (r *Repository) GetTagsOnCurrentBranch() ([]string, error) {
 		}
 	}
 
+	tags = r.filterTags(tags)
+	sortTags(tags, r.ParseTag)
 	return tags, nil
 }
 
+// GetCommitParents returns the parent SHAs of a commit
+func (r *Repository) GetCommitParents(sha string) ([]string, error) {
+	output, err := r.output("rev-list", "--parents", "-n", "1", sha)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields[1:], nil
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant
+func (r *Repository) IsAncestor(ancestor, descendant string) bool {
+	cmd := r.command("merge-base", "--is-ancestor", ancestor, descendant)
+	return cmd.Run() == nil
+}
+
 func (r *Repository) GetCommitSHAForTag(tag string) (string, error) {
-	cmd := exec.Command("git", "rev-list", "-n", "1", tag)
-	output, err := cmd.Output()
+	output, err := r.output("rev-list", "-n", "1", tag)
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetBranches returns the branches of the primary remote, without the
+// remote prefix. Tracking branches of other remotes are left out.
 func (r *Repository) GetBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "-r")
-	output, err := cmd.Output()
+	branches, err := r.RemoteBranches(r.Remote())
 	if err != nil {
 		return []string{}, err
 	}
+	sortBranches(branches)
+	return branches, nil
+}
+
+// Branch is a local or remote tracking branch and the commit at its tip
+type Branch struct {
+	// Name is the branch name without the remote, e.g. "release/1.2.0"
+	Name string
+	// Remote is the remote of a remote tracking branch, "" for local branches
+	Remote string
+	SHA    string
+}
+
+// ShortRef returns the branch as git abbreviates it, e.g. "origin/main"
+func (b *Branch) ShortRef() string {
+	if b.Remote == "" {
+		return b.Name
+	}
+	return b.Remote + "/" + b.Name
+}
+
+// GetBranchTips lists local branches followed by remote tracking branches,
+// each in name order. Symbolic refs such as origin/HEAD are skipped.
+func (r *Repository) GetBranchTips() ([]*Branch, error) {
+	output, err := r.output("for-each-ref", "--format=%(refname) %(objectname) %(symref)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
 
-	var branches []string
+	var local, remote []*Branch
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && !strings.Contains(line, "->") {
-			// Remove "origin/" prefix and get just branch name
-			branch := strings.TrimPrefix(line, "origin/")
-			branches = append(branches, branch)
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue // empty line or symbolic ref
+		}
+		ref, sha := fields[0], fields[1]
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			local = append(local, &Branch{Name: name, SHA: sha})
+			continue
+		}
+		remoteName, name, ok := strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+		if ok {
+			remote = append(remote, &Branch{Name: name, Remote: remoteName, SHA: sha})
 		}
 	}
 
-	return branches, nil
+	for _, branches := range [][]*Branch{local, remote} {
+		sort.SliceStable(branches, func(i, j int) bool {
+			return branches[i].ShortRef() < branches[j].ShortRef()
+		})
+	}
+	return append(local, remote...), nil
+}
+
+// GetVersionTagsContaining returns the version tags whose commit contains
+// sha, oldest version first.
+func (r *Repository) GetVersionTagsContaining(sha string) ([]string, error) {
+	output, err := r.output("tag", "--contains", sha)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, line := range strings.Split(string(output), "\n") {
+		tag := strings.TrimSpace(line)
+		if tag == "" {
+			continue
+		}
+		if _, err := r.ParseTag(tag); err == nil {
+			tags = append(tags, tag)
+		}
+	}
+
+	sortTags(tags, r.ParseTag)
+	return tags, nil
+}
+
+// sortTags orders tags by ascending semantic version precedence so results
+// do not depend on git's locale-sensitive output. Tags that parse does not
+// take for versions sort after all versions, bytewise.
+func sortTags(tags []string, parse func(string) (*semver.Version, error)) {
+	versions := make(map[string]*semver.Version, len(tags))
+	for _, tag := range tags {
+		if v, err := parse(tag); err == nil {
+			versions[tag] = v
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, iok := versions[tags[i]]
+		vj, jok := versions[tags[j]]
+		switch {
+		case iok && jok:
+			if c := vi.Compare(vj); c != 0 {
+				return c < 0
+			}
+			return tags[i] < tags[j]
+		case iok != jok:
+			return iok
+		default:
+			return tags[i] < tags[j]
+		}
+	})
+}
+
+// sortBranches orders branch names bytewise, independent of locale
+func sortBranches(branches []string) {
+	sort.Strings(branches)
+}
+
+// RefExists reports whether ref resolves to a commit
+func (r *Repository) RefExists(ref string) bool {
+	_, err := r.output("rev-parse", "-q", "--verify", ref+"^{commit}")
+	return err == nil
 }
 
 func (r *Repository) GetMergeBase(branch1, branch2 string) (string, error) {
-	cmd := exec.Command("git", "merge-base", branch1, branch2)
-	output, err := cmd.Output()
+	output, err := r.output("merge-base", branch1, branch2)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetLastCommitForPath returns the last commit on HEAD that changed path,
+// relative to the repository root, or "" when no commit has it
+func (r *Repository) GetLastCommitForPath(path string) (string, error) {
+	output, err := r.output("log", "-1", "--format=%H", "--", ":(top,literal)"+path)
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetCommitHistory returns the newest limit commits with their subjects and
+// bodies, so footers such as "BREAKING CHANGE:" are not lost
 func (r *Repository) GetCommitHistory(limit int) ([]*Commit, error) {
-	cmd := exec.Command("git", "log", "--format=%H|%s|%ci", fmt.Sprintf("-%d", limit))
-	output, err := cmd.Output()
+	output, err := r.output(r.withPaths("log", commitWithBodyFormat, fmt.Sprintf("-%d", limit))...)
 	if err != nil {
 		return []*Commit{}, err
 	}
+	return r.filterCommits(parseCommitsWithBody(string(output))), nil
+}
+
+// GetCommitsWithBodySinceTag returns the commits since tag, or all commits
+// when tag is empty, newest first and without merge commits
+func (r *Repository) GetCommitsWithBodySinceTag(tag string) ([]*Commit, error) {
+	revision := "HEAD"
+	if tag != "" {
+		revision = tag + "..HEAD"
+	}
+	output, err := r.output(r.withPaths("log", "--no-merges", commitWithBodyFormat, revision)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits since %s: %w", tag, err)
+	}
+	return r.filterCommits(parseCommitsWithBody(string(output))), nil
+}
+
+// GetMergeCommitsSinceTag returns the merge commits since tag, or all merge
+// commits when tag is empty, newest first
+func (r *Repository) GetMergeCommitsSinceTag(tag string) ([]*Commit, error) {
+	revision := "HEAD"
+	if tag != "" {
+		revision = tag + "..HEAD"
+	}
+	output, err := r.output(r.withPaths("log", "--merges", commitWithBodyFormat, revision)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge commits since %s: %w", tag, err)
+	}
+	return r.filterCommits(parseCommitsWithBody(string(output))), nil
+}
+
+// commitWithBodyFormat separates fields with NUL and commits with RS, since
+// bodies span lines
+const commitWithBodyFormat = "--format=%H%x00%s%x00%ci%x00%b%x1e"
 
+func parseCommitsWithBody(output string) []*Commit {
 	var commits []*Commit
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for _, record := range strings.Split(output, "\x1e") {
+		if commit := parseCommitRecord(record); commit != nil {
+			commits = append(commits, commit)
+		}
+	}
+	return commits
+}
+
+// parseCommitRecord parses a commit in commitWithBodyFormat, nil for
+// anything else
+func parseCommitRecord(record string) *Commit {
+	record = strings.TrimLeft(record, "\n")
+	parts := strings.SplitN(record, "\x00", 4)
+	if len(parts) != 4 {
+		return nil
+	}
+	return &Commit{
+		SHA:     parts[0],
+		Message: parts[1],
+		Date:    parts[2],
+		Body:    strings.TrimSpace(parts[3]),
+	}
+}
+
+// maxCommitRecord bounds the size of a single commit ForEachCommit reads
+const maxCommitRecord = 16 << 20
+
+// ForEachCommit calls fn with the commits reachable from ref, newest first
+// and with their bodies, until fn returns false. Unlike GetCommitHistory it
+// streams git log, so no more history is read than fn asks for.
+func (r *Repository) ForEachCommit(ref string, fn func(*Commit) bool) error {
+	cmd := r.command(r.withPaths("log", commitWithBodyFormat, ref)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to read history of %s: %w", ref, err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, maxCommitRecord)
+	scanner.Split(splitCommitRecords)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			parts := strings.SplitN(line, "|", 3)
-			if len(parts) == 3 {
-				commits = append(commits, &Commit{
-					SHA:     parts[0],
-					Message: parts[1],
-					Date:    parts[2],
-				})
-			}
+		commit := parseCommitRecord(scanner.Text())
+		if commit == nil || r.ignoresCommit(commit.Message) {
+			continue
 		}
+		if !fn(commit) {
+			// The rest of the history is not wanted
+			cmd.Process.Kill()
+			cmd.Wait()
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("failed to read history of %s: %w", ref, err)
 	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to read history of %s: %w", ref, err)
+	}
+	return nil
+}
 
-	return commits, nil
+// splitCommitRecords is a bufio.SplitFunc for the RS separated commits of
+// commitWithBodyFormat
+func splitCommitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\x1e'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
 }
 
 func (r *Repository) GetCommitCountSinceTag(tag string) (int, error) {
-	var cmd *exec.Cmd
+	revision := "HEAD"
 	if tag != "" {
-		cmd = exec.Command("git", "rev-list", "--count", fmt.Sprintf("%s..HEAD", tag))
-	} else {
-		cmd = exec.Command("git", "rev-list", "--count", "HEAD")
+		revision = fmt.Sprintf("%s..HEAD", tag)
+	}
+
+	if r.filtersScopes() {
+		return r.countScopedCommits(revision), nil
 	}
 
-	output, err := cmd.Output()
+	output, err := r.output(r.withPaths("rev-list", "--count", revision)...)
 	if err != nil {
 		return 0, nil
 	}
@@ -151,15 +841,60 @@ func (r *Repository) GetCommitCountSinceTag(tag string) (int, error) {
 	return count, nil
 }
 
+// countScopedCommits counts the commits of revision that are not scoped to
+// other components, reading their subjects since rev-list cannot tell
+func (r *Repository) countScopedCommits(revision string) int {
+	output, err := r.output(r.withPaths("log", "--format=%H %s", revision)...)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if sha, subject, _ := strings.Cut(line, " "); sha != "" && !r.ignoresCommit(subject) {
+			count++
+		}
+	}
+	return count
+}
+
+// GetChangedFilesSince returns the files each commit since revision changed,
+// relative to the repository root, one list per commit and newest first.
+// revision "" lists every commit. Merge commits are left out; the commits
+// they merge are listed themselves.
+func (r *Repository) GetChangedFilesSince(revision string) ([][]string, error) {
+	rangeSpec := "HEAD"
+	if revision != "" {
+		rangeSpec = revision + "..HEAD"
+	}
+	output, err := r.output(r.withPaths("log", "--no-merges", "--name-only", "--format=%x1e%s", rangeSpec)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files since %s: %w", revision, err)
+	}
+
+	var changes [][]string
+	for _, record := range strings.Split(string(output), "\x1e")[1:] {
+		lines := strings.Split(record, "\n")
+		if r.ignoresCommit(lines[0]) {
+			continue
+		}
+		files := []string{}
+		for _, line := range lines[1:] {
+			if line != "" {
+				files = append(files, line)
+			}
+		}
+		changes = append(changes, files)
+	}
+	return changes, nil
+}
+
 func (r *Repository) GetCommitsSinceTag(tag string) ([]string, error) {
-	var cmd *exec.Cmd
+	revision := "HEAD"
 	if tag != "" {
-		cmd = exec.Command("git", "log", "--oneline", fmt.Sprintf("%s..HEAD", tag))
-	} else {
-		cmd = exec.Command("git", "log", "--oneline", "HEAD")
+		revision = fmt.Sprintf("%s..HEAD", tag)
 	}
 
-	output, err := cmd.Output()
+	output, err := r.output(r.withPaths("log", "--oneline", revision)...)
 	if err != nil {
 		return []string{}, nil
 	}
@@ -168,7 +903,7 @@ func (r *Repository) GetCommitsSinceTag(tag string) ([]string, error) {
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
+		if _, subject, _ := strings.Cut(line, " "); line != "" && !r.ignoresCommit(subject) {
 			commits = append(commits, line)
 		}
 	}
@@ -176,69 +911,98 @@ func (r *Repository) GetCommitsSinceTag(tag string) ([]string, error) {
 	return commits, nil
 }
 
-func (r *Repository) GetShortSHA() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
-	output, err := cmd.Output()
-	if err != nil {
-		return "unknown", nil
-	}
-	return strings.TrimSpace(string(output)), nil
+// TagExists reports whether a tag with the given name exists
+func (r *Repository) TagExists(tag string) bool {
+	cmd := r.command("rev-parse", "-q", "--verify", "refs/tags/"+tag)
+	return cmd.Run() == nil
 }
 
-func (r *Repository) GetSHA() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	output, err := cmd.Output()
+// CreateTag creates a tag on HEAD. An annotated tag is created when message
+// is not empty, otherwise a lightweight tag.
+func (r *Repository) CreateTag(tag, message string) error {
+	args := []string{"tag"}
+	if message != "" {
+		args = append(args, "-a", "-m", message)
+	}
+	args = append(args, tag, "HEAD")
+
+	defer r.invalidate()
+	output, err := r.command(args...).CombinedOutput()
 	if err != nil {
-		return "unknown", nil
+		return fmt.Errorf("failed to create tag %s: %s", tag, strings.TrimSpace(string(output)))
 	}
-	return strings.TrimSpace(string(output)), nil
+	return nil
 }
 
-func (r *Repository) GetCommitDate() (string, error) {
-	cmd := exec.Command("git", "log", "-1", "--format=%ci", "HEAD")
-	output, err := cmd.Output()
+// CreateSignedTag creates a signed annotated tag on HEAD with the signing
+// key git is configured with, gpg or ssh according to gpg.format.
+func (r *Repository) CreateSignedTag(tag, message string) error {
+	defer r.invalidate()
+	output, err := r.command("tag", "-s", "-m", message, tag, "HEAD").CombinedOutput()
 	if err != nil {
-		return "unknown", nil
+		return fmt.Errorf("failed to create signed tag %s: %s", tag, strings.TrimSpace(string(output)))
 	}
-	return strings.TrimSpace(string(output)), nil
+	return nil
 }
 
-type IncrementType string
-
-const (
-	IncrementPatch IncrementType = "patch"
-	IncrementMinor IncrementType = "minor"
-	IncrementMajor IncrementType = "major"
-)
+// VerifyTag reports whether tag carries a signature git can verify.
+// Lightweight and unsigned annotated tags do not.
+func (r *Repository) VerifyTag(tag string) bool {
+	return r.command("verify-tag", tag).Run() == nil
+}
 
-func (r *Repository) DetectVersionIncrement(tag string) (IncrementType, error) {
-	commits, err := r.GetCommitsSinceTag(tag)
+// IsShallow reports whether the repository is a shallow clone, whose
+// truncated history hides older tags and commits from the strategies
+func (r *Repository) IsShallow() (bool, error) {
+	output, err := r.output("rev-parse", "--is-shallow-repository")
 	if err != nil {
-		return IncrementPatch, err
+		return false, err
 	}
+	return strings.TrimSpace(string(output)) == "true", nil
+}
 
-	increment := IncrementPatch
+// IsDetachedHead reports whether HEAD points at a commit instead of a branch
+func (r *Repository) IsDetachedHead() bool {
+	return r.command("symbolic-ref", "-q", "HEAD").Run() != nil
+}
 
-	semverMajorPattern := regexp.MustCompile(`(?i)\+semver:\s*(breaking|major)`)
-	semverMinorPattern := regexp.MustCompile(`(?i)\+semver:\s*(feature|minor)`)
-	breakingChangePattern := regexp.MustCompile(`(?i)BREAKING\s*CHANGE`)
-	conventionalBreakingPattern := regexp.MustCompile(`(?i)^feat(\(.+\))?!:`)
-	conventionalFeaturePattern := regexp.MustCompile(`(?i)^feat(\(.+\))?:`)
+// GetUncommittedChanges counts the changed and untracked files in the
+// working tree
+func (r *Repository) GetUncommittedChanges() (int, error) {
+	output, err := r.output(r.withPaths("status", "--porcelain")...)
+	if err != nil {
+		return 0, err
+	}
 
-	for _, commit := range commits {
-		if semverMajorPattern.MatchString(commit) ||
-			breakingChangePattern.MatchString(commit) ||
-			conventionalBreakingPattern.MatchString(commit) {
-			return IncrementMajor, nil
+	count := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
 		}
+	}
+	return count, nil
+}
 
-		if semverMinorPattern.MatchString(commit) ||
-			conventionalFeaturePattern.MatchString(commit) {
-			if increment != IncrementMajor {
-				increment = IncrementMinor
-			}
-		}
+func (r *Repository) GetShortSHA() (string, error) {
+	output, err := r.output("rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "unknown", nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (r *Repository) GetSHA() (string, error) {
+	output, err := r.output("rev-parse", "HEAD")
+	if err != nil {
+		return "unknown", nil
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	return increment, nil
+func (r *Repository) GetCommitDate() (string, error) {
+	output, err := r.output("log", "-1", "--format=%ci", "HEAD")
+	if err != nil {
+		return "unknown", nil
+	}
+	return strings.TrimSpace(string(output)), nil
 }
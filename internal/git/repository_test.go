@@ -1,127 +1,533 @@
 package git
 
 import (
-	"regexp"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
-var (
-	semverMajorPattern          = regexp.MustCompile(`(?i)\+semver:\s*(breaking|major)`)
-	semverMinorPattern          = regexp.MustCompile(`(?i)\+semver:\s*(feature|minor)`)
-	breakingChangePattern       = regexp.MustCompile(`(?i)BREAKING\s*CHANGE`)
-	conventionalBreakingPattern = regexp.MustCompile(`(?i)^feat(\(.+\))?!:`)
-	conventionalFeaturePattern  = regexp.MustCompile(`(?i)^feat(\(.+\))?:`)
-)
+func TestSortTags(t *testing.T) {
+	tags := []string{"v1.10.0", "deploy-2024-01", "v1.2.0", "v1.0.0-beta.10", "v1.0.0-beta.2", "1.2.0", "archive"}
+	sortTags(tags, NewRepository().ParseTag)
+
+	expected := []string{"v1.0.0-beta.2", "v1.0.0-beta.10", "1.2.0", "v1.2.0", "v1.10.0", "archive", "deploy-2024-01"}
+	for i := range expected {
+		if tags[i] != expected[i] {
+			t.Errorf("sortTags()[%d] = %s, want %s", i, tags[i], expected[i])
+		}
+	}
+}
+
+func TestSortBranches(t *testing.T) {
+	branches := []string{"release/1.0", "Develop", "feature/b", "feature/a", "main"}
+	sortBranches(branches)
+
+	expected := []string{"Develop", "feature/a", "feature/b", "main", "release/1.0"}
+	for i := range expected {
+		if branches[i] != expected[i] {
+			t.Errorf("sortBranches()[%d] = %s, want %s", i, branches[i], expected[i])
+		}
+	}
+}
+
+func setupTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+
+	runGit(t, "init", "-q")
+	runGit(t, "config", "user.name", "Test User")
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "config", "commit.gpgsign", "false")
+	runGit(t, "config", "tag.gpgsign", "false")
+	return dir
+}
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func commit(t *testing.T, message string) {
+	t.Helper()
+	runGit(t, "commit", "-q", "--allow-empty", "-m", message)
+}
+
+func TestGetLatestVersionTagSkipsNonVersionTags(t *testing.T) {
+	setupTestRepo(t)
+	repo := NewRepository()
+
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	commit(t, "fix: one")
+	runGit(t, "tag", "deploy-2024-01")
+	commit(t, "fix: two")
+	runGit(t, "tag", "-a", "nightly", "-m", "nightly build")
+	commit(t, "fix: three")
+
+	tag, err := repo.GetLatestVersionTag()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tag != "v1.0.0" {
+		t.Errorf("GetLatestVersionTag() = %s, want v1.0.0", tag)
+	}
+
+	count, _ := repo.GetCommitCountSinceTag(tag)
+	if count != 3 {
+		t.Errorf("GetCommitCountSinceTag(%s) = %d, want 3", tag, count)
+	}
+
+	// git describe alone would stop at the non-version tag
+	latest, _ := repo.GetLatestTag()
+	if latest != "nightly" {
+		t.Errorf("GetLatestTag() = %s, want nightly", latest)
+	}
+}
+
+func TestGetLatestVersionTagWithoutVersionTags(t *testing.T) {
+	setupTestRepo(t)
+	repo := NewRepository()
+
+	commit(t, "initial commit")
+	runGit(t, "tag", "deploy-2024-01")
+
+	tag, err := repo.GetLatestVersionTag()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tag != "" {
+		t.Errorf("GetLatestVersionTag() = %s, want empty", tag)
+	}
+}
+
+func TestScopedRepository(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	writeAndCommit := func(file, message string) {
+		t.Helper()
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(message), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		runGit(t, "add", file)
+		runGit(t, "commit", "-q", "-m", message)
+	}
+
+	writeAndCommit("go.mod", "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	writeAndCommit("tools/go.mod", "feat: add tools module")
+	runGit(t, "tag", "tools/v0.1.0")
+	writeAndCommit("tools/main.go", "fix: tools bug")
+	writeAndCommit("main.go", "fix: root bug")
+
+	repo := NewScopedRepository("tools/", []string{":(top)tools"})
+
+	tags, _ := repo.GetTagsOnCurrentBranch()
+	if len(tags) != 1 || tags[0] != "tools/v0.1.0" {
+		t.Errorf("GetTagsOnCurrentBranch() = %v, want [tools/v0.1.0]", tags)
+	}
+
+	tag, _ := repo.GetLatestVersionTag()
+	if tag != "tools/v0.1.0" {
+		t.Errorf("GetLatestVersionTag() = %s, want tools/v0.1.0", tag)
+	}
+	if v, err := repo.ParseTag(tag); err != nil || v.String() != "0.1.0" {
+		t.Errorf("ParseTag(%s) = %v, %v", tag, v, err)
+	}
+	if _, err := repo.ParseTag("v1.0.0"); err == nil {
+		t.Errorf("Expected ParseTag to reject tag without prefix")
+	}
+
+	// Only the tools commit after the tag counts, not the root one
+	count, _ := repo.GetCommitCountSinceTag(tag)
+	if count != 1 {
+		t.Errorf("GetCommitCountSinceTag(%s) = %d, want 1", tag, count)
+	}
+
+	root := NewScopedRepository("", []string{":(top)", ":(top,exclude)tools"})
+	count, _ = root.GetCommitCountSinceTag("v1.0.0")
+	if count != 1 {
+		t.Errorf("root GetCommitCountSinceTag(v1.0.0) = %d, want 1", count)
+	}
+
+	// Extra directories add to the module's pathspecs
+	shared := repo.WithPaths([]string{"./"})
+	if count, _ = shared.GetCommitCountSinceTag(tag); count != 2 {
+		t.Errorf("WithPaths GetCommitCountSinceTag(%s) = %d, want 2", tag, count)
+	}
+	if count, _ = repo.GetCommitCountSinceTag(tag); count != 1 {
+		t.Errorf("WithPaths changed the original repository")
+	}
+
+	// Component tags are only seen with their prefix
+	if tag, _ := NewRepository().WithTagPrefix("tools/").GetLatestVersionTag(); tag != "tools/v0.1.0" {
+		t.Errorf("WithTagPrefix GetLatestVersionTag() = %s, want tools/v0.1.0", tag)
+	}
+	if tag, _ := NewRepository().GetLatestVersionTag(); tag != "v1.0.0" {
+		t.Errorf("GetLatestVersionTag() = %s, want the unprefixed v1.0.0", tag)
+	}
+}
+
+func TestRepositoryWithPaths(t *testing.T) {
+	repo := NewRepository().WithPaths([]string{"services/api/", "./shared", "!services/api/docs", "."})
+	want := []string{":(top)services/api", ":(top)shared", ":(top,exclude)services/api/docs", ":(top)"}
+	if !reflect.DeepEqual(repo.paths, want) {
+		t.Errorf("WithPaths() pathspecs = %v, want %v", repo.paths, want)
+	}
+
+	dir := setupTestRepo(t)
+	for _, file := range []string{"README.md", "services/api/main.go", "services/api/docs/api.md", "services/web/main.go"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		runGit(t, "add", file)
+		runGit(t, "commit", "-q", "-m", "update "+file)
+	}
+
+	api := NewRepository().WithPaths([]string{"services/api", "!services/api/docs"})
+	if count, _ := api.GetCommitCountSinceTag(""); count != 1 {
+		t.Errorf("GetCommitCountSinceTag() = %d, want only the api commit", count)
+	}
+	commits, _ := api.GetCommitsSinceTag("")
+	if len(commits) != 1 || !strings.Contains(commits[0], "services/api/main.go") {
+		t.Errorf("GetCommitsSinceTag() = %v", commits)
+	}
+}
+
+func TestGetUncommittedChanges(t *testing.T) {
+	dir := setupTestRepo(t)
+	repo := NewRepository()
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatalf("Failed to write a.txt: %v", err)
+	}
+	runGit(t, "add", "a.txt")
+	commit(t, "initial commit")
+
+	if count, _ := repo.GetUncommittedChanges(); count != 0 {
+		t.Errorf("GetUncommittedChanges() = %d on a clean tree, want 0", count)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed"), 0o644)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("untracked"), 0o644)
+	if count, _ := repo.GetUncommittedChanges(); count != 2 {
+		t.Errorf("GetUncommittedChanges() = %d, want 2", count)
+	}
+}
+
+func TestSignedTags(t *testing.T) {
+	dir := setupTestRepo(t)
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	key := filepath.Join(dir, ".git", "signing-key")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, output)
+	}
+	publicKey, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatalf("Failed to read public key: %v", err)
+	}
+	allowedSigners := filepath.Join(dir, ".git", "allowed-signers")
+	if err := os.WriteFile(allowedSigners, []byte("test@example.com "+string(publicKey)), 0o644); err != nil {
+		t.Fatalf("Failed to write allowed signers: %v", err)
+	}
+	runGit(t, "config", "gpg.format", "ssh")
+	runGit(t, "config", "user.signingkey", key)
+	runGit(t, "config", "gpg.ssh.allowedSignersFile", allowedSigners)
+
+	repo := NewRepository()
+	commit(t, "initial commit")
+
+	if err := repo.CreateSignedTag("v1.0.0", "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateSignedTag() error = %v", err)
+	}
+	if !repo.VerifyTag("v1.0.0") {
+		t.Errorf("VerifyTag(v1.0.0) = false for a signed tag")
+	}
 
-func TestIncrementType(t *testing.T) {
-	tests := []struct {
-		name              string
-		commitMessages    []string
-		expectedIncrement IncrementType
-	}{
-		{
-			name: "Patch increment for fix",
-			commitMessages: []string{
-				"fix: resolve login issue",
-			},
-			expectedIncrement: IncrementPatch,
-		},
-		{
-			name: "Minor increment for feature",
-			commitMessages: []string{
-				"feat: add user authentication",
-			},
-			expectedIncrement: IncrementMinor,
-		},
-		{
-			name: "Major increment for breaking change",
-			commitMessages: []string{
-				"feat!: redesign API",
-			},
-			expectedIncrement: IncrementMajor,
-		},
-		{
-			name: "Major increment for semver tag",
-			commitMessages: []string{
-				"fix: critical bug +semver: major",
-			},
-			expectedIncrement: IncrementMajor,
-		},
-		{
-			name: "Minor increment for semver tag",
-			commitMessages: []string{
-				"update: improve performance +semver: minor",
-			},
-			expectedIncrement: IncrementMinor,
-		},
-		{
-			name: "Major increment for BREAKING CHANGE",
-			commitMessages: []string{
-				"feat: new feature",
-				"",
-				"BREAKING CHANGE: API has changed",
-			},
-			expectedIncrement: IncrementMajor,
-		},
-		{
-			name: "Major takes precedence",
-			commitMessages: []string{
-				"feat: add feature",
-				"fix: bug fix +semver: major",
-				"feat: another feature",
-			},
-			expectedIncrement: IncrementMajor,
-		},
-		{
-			name: "Minor takes precedence over patch",
-			commitMessages: []string{
-				"fix: bug fix",
-				"feat: new feature",
-				"fix: another bug fix",
-			},
-			expectedIncrement: IncrementMinor,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := &Repository{}
-			increment := repo.analyzeCommitMessages(tt.commitMessages)
-
-			if increment != tt.expectedIncrement {
-				t.Errorf("Expected %s, got %s", tt.expectedIncrement, increment)
-			}
-		})
-	}
-}
-
-func (r *Repository) analyzeCommitMessages(messages []string) IncrementType {
-	increment := IncrementPatch
-
-	for _, message := range messages {
-		detected := r.detectIncrementFromMessage(message)
-		if detected == IncrementMajor {
-			return IncrementMajor
+	if err := repo.CreateTag("v1.0.1", "Release v1.0.1"); err != nil {
+		t.Fatalf("CreateTag() error = %v", err)
+	}
+	runGit(t, "tag", "v1.0.2")
+	for _, tag := range []string{"v1.0.1", "v1.0.2"} {
+		if repo.VerifyTag(tag) {
+			t.Errorf("VerifyTag(%s) = true for an unsigned tag", tag)
 		}
-		if detected == IncrementMinor && increment != IncrementMajor {
-			increment = IncrementMinor
+	}
+}
+
+func TestCachedRepository(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+
+	repo := NewCachedRepository("")
+	scoped := repo.WithTagPrefix("api/")
+	if tags, _ := repo.GetAllTags(); len(tags) != 1 {
+		t.Fatalf("GetAllTags() = %v, want [v1.0.0]", tags)
+	}
+
+	// Copies share the cache, so a tag made behind its back goes unseen
+	runGit(t, "tag", "api/v2.0.0")
+	if tags, _ := scoped.GetAllTags(); len(tags) != 0 {
+		t.Errorf("scoped GetAllTags() = %v, want the cached tag list", tags)
+	}
+
+	if err := repo.CreateTag("v1.1.0", ""); err != nil {
+		t.Fatalf("CreateTag() error = %v", err)
+	}
+	if tags, _ := scoped.GetAllTags(); len(tags) != 1 || tags[0] != "api/v2.0.0" {
+		t.Errorf("scoped GetAllTags() = %v, want [api/v2.0.0] after the cache was cleared", tags)
+	}
+}
+
+func TestGetTagCommits(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	first := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "tag", "v1.0.0")
+	commit(t, "fix: one")
+	second := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "tag", "-a", "v1.1.0", "-m", "release 1.1.0")
+	runGit(t, "tag", "-a", "release-1.1.0", "-m", "tag of a tag", "v1.1.0")
+	runGit(t, "tag", "api/v2.0.0", first)
+
+	tags, err := NewRepository().GetTagCommits()
+	if err != nil {
+		t.Fatalf("GetTagCommits() error = %v", err)
+	}
+	var got []Tag
+	for _, tag := range tags {
+		got = append(got, *tag)
+	}
+	want := []Tag{{"v1.0.0", first, false}, {"v1.1.0", second, true}, {"api/v2.0.0", first, false}, {"release-1.1.0", second, true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTagCommits() = %v, want %v", got, want)
+	}
+
+	tags, _ = NewScopedRepository("api/", nil).GetTagCommits()
+	if len(tags) != 1 || tags[0].Name != "api/v2.0.0" {
+		t.Errorf("scoped GetTagCommits() = %v, want api/v2.0.0", tags)
+	}
+}
+
+func TestCommitGraph(t *testing.T) {
+	setupTestRepo(t)
+	repo := NewRepository()
+
+	graph, err := repo.GetCommitGraph()
+	if err != nil || graph.ReachableFromHead("HEAD") {
+		t.Fatalf("GetCommitGraph() of an unborn branch = %v, %v; want an empty graph", graph, err)
+	}
+
+	commit(t, "initial commit")
+	base := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "checkout", "-q", "-b", "develop")
+	commit(t, "feat: one")
+	feature := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "checkout", "-q", "-")
+	runGit(t, "merge", "-q", "--no-ff", "-m", "merge develop", "develop")
+	merge := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "develop")
+
+	graph, err = repo.GetCommitGraph()
+	if err != nil {
+		t.Fatalf("GetCommitGraph() error = %v", err)
+	}
+	for sha, want := range map[string]bool{base: true, feature: true, merge: false} {
+		if got := graph.ReachableFromHead(sha); got != want {
+			t.Errorf("ReachableFromHead(%.7s) = %v, want %v", sha, got, want)
 		}
 	}
+	// The tagged merge is off HEAD, but the tags bring it into the graph
+	if parents := graph.Parents(merge); !reflect.DeepEqual(parents, []string{base, feature}) {
+		t.Errorf("Parents(merge) = %v, want [%s %s]", parents, base, feature)
+	}
+}
+
+func TestForEachCommit(t *testing.T) {
+	setupTestRepo(t)
+	repo := NewRepository()
+	commit(t, "first")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "second", "-m", "with a body")
+	commit(t, "third")
 
-	return increment
+	var messages []string
+	err := repo.ForEachCommit("HEAD", func(c *Commit) bool {
+		messages = append(messages, c.Message)
+		if c.Message == "second" && c.Body != "with a body" {
+			t.Errorf("Body = %q, want %q", c.Body, "with a body")
+		}
+		return true
+	})
+	if err != nil || !reflect.DeepEqual(messages, []string{"third", "second", "first"}) {
+		t.Errorf("ForEachCommit() visited %v, %v; want newest first", messages, err)
+	}
+
+	// Returning false stops the walk
+	visited := 0
+	if err := repo.ForEachCommit("HEAD", func(*Commit) bool { visited++; return false }); err != nil || visited != 1 {
+		t.Errorf("ForEachCommit() visited %d commits after stopping, %v; want 1", visited, err)
+	}
+
+	if err := repo.ForEachCommit("does-not-exist", func(*Commit) bool { return true }); err == nil {
+		t.Errorf("ForEachCommit() of a missing ref succeeded")
+	}
+}
+
+func TestRepositoryWithContext(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+
+	repo := NewCachedRepository("")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.WithContext(ctx).ListRefs(); err == nil {
+		t.Fatalf("ListRefs() with a cancelled context succeeded")
+	}
+	// The failure is not cached for calculations with a live context
+	if _, err := repo.ListRefs(); err != nil {
+		t.Errorf("ListRefs() error = %v after a cancelled call", err)
+	}
 }
 
-func (r *Repository) detectIncrementFromMessage(message string) IncrementType {
-	if semverMajorPattern.MatchString(message) ||
-		breakingChangePattern.MatchString(message) ||
-		conventionalBreakingPattern.MatchString(message) {
-		return IncrementMajor
+func TestCommandError(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+
+	_, err := NewRepository().output("rev-parse", "--verify", "no-such-ref")
+	var commandErr *CommandError
+	if !errors.As(err, &commandErr) {
+		t.Fatalf("output() error = %v, want a *CommandError", err)
+	}
+	if commandErr.Args[0] != "rev-parse" || !strings.HasPrefix(commandErr.Stderr, "fatal:") {
+		t.Errorf("CommandError = %+v, want the arguments and stderr of rev-parse", commandErr)
 	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Errorf("output() error = %v, want it to wrap the exit error", err)
+	}
+}
 
-	if semverMinorPattern.MatchString(message) ||
-		conventionalFeaturePattern.MatchString(message) {
-		return IncrementMinor
+func TestGitDirEnvironment(t *testing.T) {
+	dir := setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+
+	// A separated git directory, as deployment tools set up
+	separated := t.TempDir()
+	gitDir := filepath.Join(separated, "repo.git")
+	workTree := filepath.Join(separated, "tree")
+	if err := os.Mkdir(workTree, 0o755); err != nil {
+		t.Fatal(err)
 	}
+	runGit(t, "--git-dir", gitDir, "--work-tree", workTree, "init", "-q")
+	runGit(t, "--git-dir", gitDir, "--work-tree", workTree, "-c", "user.name=Test User", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "--git-dir", gitDir, "tag", "v2.0.0")
 
-	return IncrementPatch
+	t.Setenv("GIT_DIR", gitDir)
+	t.Setenv("GIT_WORK_TREE", workTree)
+	repo := NewRepository()
+	if !repo.IsRepository() {
+		t.Fatal("IsRepository() with GIT_DIR = false")
+	}
+	if tag, err := repo.GetLatestVersionTag(); err != nil || tag != "v2.0.0" {
+		t.Errorf("GetLatestVersionTag() with GIT_DIR = %s, %v; want v2.0.0", tag, err)
+	}
+	if root, err := repo.GetRootDir(); err != nil || !samePath(root, workTree) {
+		t.Errorf("GetRootDir() with GIT_WORK_TREE = %s, %v; want %s", root, err, workTree)
+	}
+	if _, commonDir, err := repo.GetGitDirs(); err != nil || !samePath(commonDir, gitDir) {
+		t.Errorf("GetGitDirs() with GIT_DIR = %s, %v; want %s", commonDir, err, gitDir)
+	}
+
+	// A repository at an explicit directory is the one in that directory
+	if tag, err := NewRepositoryAt(dir).GetLatestVersionTag(); err != nil || tag != "v1.0.0" {
+		t.Errorf("GetLatestVersionTag() at %s with GIT_DIR = %s, %v; want v1.0.0", dir, tag, err)
+	}
+}
+
+func TestGetBranchesOfPrimaryRemote(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	for _, ref := range []string{"origin/main", "origin/feature/fork-only", "upstream/main", "upstream/release/2.0.0"} {
+		runGit(t, "update-ref", "refs/remotes/"+ref, "HEAD")
+	}
+	runGit(t, "symbolic-ref", "refs/remotes/upstream/HEAD", "refs/remotes/upstream/main")
+
+	repo := NewRepository()
+	if remote := repo.Remote(); remote != DefaultRemote {
+		t.Errorf("Remote() = %q, want %q", remote, DefaultRemote)
+	}
+	branches, err := repo.GetBranches()
+	if err != nil {
+		t.Fatalf("GetBranches failed: %v", err)
+	}
+	if want := []string{"feature/fork-only", "main"}; !reflect.DeepEqual(branches, want) {
+		t.Errorf("GetBranches() = %v, want %v", branches, want)
+	}
+
+	// A fork's upstream is its primary remote; copies keep it
+	upstream := repo.WithRemote("upstream").WithTagPrefix("api/")
+	if remote := upstream.Remote(); remote != "upstream" {
+		t.Errorf("Remote() = %q, want upstream", remote)
+	}
+	branches, err = upstream.GetBranches()
+	if err != nil {
+		t.Fatalf("GetBranches failed: %v", err)
+	}
+	if want := []string{"main", "release/2.0.0"}; !reflect.DeepEqual(branches, want) {
+		t.Errorf("GetBranches() = %v, want %v", branches, want)
+	}
+}
+
+func TestCommitHistoryReadsBodies(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: parse dates in UTC", "-m", "BREAKING CHANGE: local times are no longer accepted")
+
+	repo := NewRepository()
+	history, err := repo.GetCommitHistory(1)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("GetCommitHistory(1) = %v, %v", history, err)
+	}
+	if got := history[0]; got.Message != "fix: parse dates in UTC" || got.Body != "BREAKING CHANGE: local times are no longer accepted" {
+		t.Errorf("GetCommitHistory(1) = %q / %q, want the subject and the footer", got.Message, got.Body)
+	}
+	if want := "fix: parse dates in UTC\n\nBREAKING CHANGE: local times are no longer accepted"; history[0].FullMessage() != want {
+		t.Errorf("FullMessage() = %q, want %q", history[0].FullMessage(), want)
+	}
 }
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+)
+
+// scopePattern matches the scopes of a conventional commit subject, e.g.
+// "api" of "feat(api): add pagination" or "api,web" of "fix(api,web)!: ..."
+var scopePattern = lazyregexp.New(`^\w+\(([^)]*)\)!?:`)
+
+// WithCommitScopes returns a copy of the repository that versions
+// component and ignores commits scoped to other components. scopes maps
+// conventional commit scopes to the components they belong to; a commit
+// counts unless every scope it names that is mapped belongs to another
+// component. Unscoped commits and unmapped scopes are left to the paths.
+func (r *Repository) WithCommitScopes(component string, scopes map[string]string) *Repository {
+	scoped := *r
+	scoped.component = component
+	scoped.scopeComponents = scopes
+	return &scoped
+}
+
+// filtersScopes reports whether commits are filtered by their scope
+func (r *Repository) filtersScopes() bool {
+	return r.component != "" && len(r.scopeComponents) > 0
+}
+
+// ignoresCommit reports whether the commit with subject is scoped to other
+// components only
+func (r *Repository) ignoresCommit(subject string) bool {
+	if !r.filtersScopes() {
+		return false
+	}
+	matches := scopePattern.FindStringSubmatch(subject)
+	if matches == nil {
+		return false
+	}
+
+	mapped := false
+	for _, scope := range strings.Split(matches[1], ",") {
+		component, ok := r.scopeComponents[strings.TrimSpace(scope)]
+		if !ok {
+			continue
+		}
+		if component == r.component {
+			return false
+		}
+		mapped = true
+	}
+	return mapped
+}
+
+// filterCommits drops the commits scoped to other components, keeping the
+// order
+func (r *Repository) filterCommits(commits []*Commit) []*Commit {
+	if !r.filtersScopes() {
+		return commits
+	}
+	kept := commits[:0]
+	for _, commit := range commits {
+		if !r.ignoresCommit(commit.Message) {
+			kept = append(kept, commit)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithCommitScopes(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	commit(t, "feat(ui): dark mode")
+	commit(t, "fix(api): pagination")
+	commit(t, "feat(ui,api)!: rename sessions")
+	commit(t, "chore(deps): bump yaml")
+	commit(t, "docs: typo")
+
+	scopes := map[string]string{"api": "api", "client": "api", "ui": "web"}
+	subjects := func(repo *Repository) []string {
+		commits, err := repo.GetCommitsWithBodySinceTag("v1.0.0")
+		if err != nil {
+			t.Fatalf("GetCommitsWithBodySinceTag() error = %v", err)
+		}
+		var subjects []string
+		for _, commit := range commits {
+			subjects = append(subjects, commit.Message)
+		}
+		return subjects
+	}
+
+	tests := []struct {
+		component string
+		subjects  []string
+	}{
+		// Without a component every commit counts
+		{"", []string{"docs: typo", "chore(deps): bump yaml", "feat(ui,api)!: rename sessions", "fix(api): pagination", "feat(ui): dark mode"}},
+		{"api", []string{"docs: typo", "chore(deps): bump yaml", "feat(ui,api)!: rename sessions", "fix(api): pagination"}},
+		{"web", []string{"docs: typo", "chore(deps): bump yaml", "feat(ui,api)!: rename sessions", "feat(ui): dark mode"}},
+		{"cli", []string{"docs: typo", "chore(deps): bump yaml"}},
+	}
+	for _, tt := range tests {
+		repo := NewRepository().WithCommitScopes(tt.component, scopes)
+		if got := subjects(repo); !reflect.DeepEqual(got, tt.subjects) {
+			t.Errorf("%q: commits = %v, want %v", tt.component, got, tt.subjects)
+		}
+		if count, _ := repo.GetCommitCountSinceTag("v1.0.0"); count != len(tt.subjects) {
+			t.Errorf("%q: GetCommitCountSinceTag() = %d, want %d", tt.component, count, len(tt.subjects))
+		}
+		if commits, _ := repo.GetCommitsSinceTag("v1.0.0"); len(commits) != len(tt.subjects) {
+			t.Errorf("%q: GetCommitsSinceTag() = %v, want %d commits", tt.component, commits, len(tt.subjects))
+		}
+	}
+
+	var seen []string
+	err := NewRepository().WithCommitScopes("web", scopes).ForEachCommit("v1.0.0..HEAD", func(commit *Commit) bool {
+		seen = append(seen, commit.Message)
+		return len(seen) < 3
+	})
+	if err != nil {
+		t.Fatalf("ForEachCommit() error = %v", err)
+	}
+	if want := []string{"docs: typo", "chore(deps): bump yaml", "feat(ui,api)!: rename sessions"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("ForEachCommit() = %v, want %v", seen, want)
+	}
+}
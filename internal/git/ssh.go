@@ -0,0 +1,81 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring SSH remotes, which RemoteAuthFromEnv
+// reads
+const (
+	// SSHIdentityFileEnv holds the private key to authenticate with; keys of
+	// a running ssh-agent are still offered when it matches one
+	SSHIdentityFileEnv = "GITVERSION_SSH_IDENTITY_FILE"
+	// SSHStrictHostKeyCheckingEnv holds the host key checking mode
+	SSHStrictHostKeyCheckingEnv = "GITVERSION_SSH_STRICT_HOST_KEY_CHECKING"
+	// SSHKnownHostsFileEnv holds the known_hosts file host keys are checked
+	// against instead of the user's
+	SSHKnownHostsFileEnv = "GITVERSION_SSH_KNOWN_HOSTS_FILE"
+)
+
+// Host key checking modes, as ssh's StrictHostKeyChecking option names them
+const (
+	// HostKeyYes only connects to hosts whose key is known
+	HostKeyYes = "yes"
+	// HostKeyAcceptNew records the keys of unknown hosts and rejects
+	// changed ones
+	HostKeyAcceptNew = "accept-new"
+	// HostKeyNo connects to any host; only for throwaway environments
+	HostKeyNo = "no"
+)
+
+// SSHOptions configure the ssh command git runs for ssh:// and scp-like
+// remotes. The zero value leaves ssh to its own configuration, including
+// the keys of ssh-agent.
+type SSHOptions struct {
+	IdentityFile string
+	// StrictHostKeyChecking is one of the HostKey* modes, ssh's own
+	// default when empty
+	StrictHostKeyChecking string
+	KnownHostsFile        string
+}
+
+// validate rejects host key checking modes ssh does not know or that
+// would prompt
+func (o SSHOptions) validate() error {
+	switch o.StrictHostKeyChecking {
+	case "", HostKeyYes, HostKeyAcceptNew, HostKeyNo:
+		return nil
+	}
+	return fmt.Errorf("invalid SSH host key checking mode %q (use yes, accept-new or no)", o.StrictHostKeyChecking)
+}
+
+// command returns the GIT_SSH_COMMAND running ssh with the options, built
+// on the command the environment already names, or "" for the zero value
+func (o SSHOptions) command() string {
+	if o == (SSHOptions{}) {
+		return ""
+	}
+	command := os.Getenv("GIT_SSH_COMMAND")
+	if command == "" {
+		command = "ssh"
+	}
+	// A job has nobody to type a passphrase or confirm a host
+	args := []string{command, "-o", "BatchMode=yes"}
+	if o.IdentityFile != "" {
+		args = append(args, "-i", shellQuote(o.IdentityFile), "-o", "IdentitiesOnly=yes")
+	}
+	if o.StrictHostKeyChecking != "" {
+		args = append(args, "-o", "StrictHostKeyChecking="+o.StrictHostKeyChecking)
+	}
+	if o.KnownHostsFile != "" {
+		args = append(args, "-o", shellQuote("UserKnownHostsFile="+o.KnownHostsFile))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for the shell git runs GIT_SSH_COMMAND with
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
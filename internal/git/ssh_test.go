@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSSHOptionsCommand(t *testing.T) {
+	t.Setenv("GIT_SSH_COMMAND", "")
+	if command := (SSHOptions{}).command(); command != "" {
+		t.Errorf("command() of the zero value = %q, want none", command)
+	}
+
+	command := SSHOptions{IdentityFile: "/keys/it's", StrictHostKeyChecking: HostKeyYes, KnownHostsFile: "/keys/known hosts"}.command()
+	want := `ssh -o BatchMode=yes -i '/keys/it'\''s' -o IdentitiesOnly=yes -o StrictHostKeyChecking=yes -o 'UserKnownHostsFile=/keys/known hosts'`
+	if command != want {
+		t.Errorf("command() = %q, want %q", command, want)
+	}
+
+	// The ssh command the environment names is kept
+	t.Setenv("GIT_SSH_COMMAND", "ssh -p 2222")
+	if command := (SSHOptions{StrictHostKeyChecking: HostKeyNo}).command(); !strings.HasPrefix(command, "ssh -p 2222 -o BatchMode=yes") {
+		t.Errorf("command() = %q, want it built on GIT_SSH_COMMAND", command)
+	}
+}
+
+// TestSSHOptionsReachGit fetches from an ssh remote through a stand-in for
+// ssh that records the arguments git runs it with
+func TestSSHOptionsReachGit(t *testing.T) {
+	setupTestRepo(t)
+	dir := t.TempDir()
+	record := filepath.Join(dir, "args")
+	fakeSSH := filepath.Join(dir, "ssh")
+	script := "#!/bin/sh\necho \"$@\" > '" + record + "'\nexit 1\n"
+	if err := os.WriteFile(fakeSSH, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_SSH_COMMAND", fakeSSH)
+	runGit(t, "remote", "add", "origin", "ssh://git@example.invalid/repo.git")
+
+	auth := RemoteAuth{SSH: SSHOptions{IdentityFile: "/keys/deploy", StrictHostKeyChecking: HostKeyAcceptNew}}
+	if err := NewRepository().FetchAll("origin", false, auth); err == nil {
+		t.Fatal("Expected the fetch through the stand-in to fail")
+	}
+	args, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("ssh was not run: %v", err)
+	}
+	for _, want := range []string{"-i /keys/deploy", "IdentitiesOnly=yes", "StrictHostKeyChecking=accept-new", "BatchMode=yes", "git@example.invalid"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("ssh arguments %q lack %q", args, want)
+		}
+	}
+}
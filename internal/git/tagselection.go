@@ -0,0 +1,139 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// TagSelection decides whether lightweight version tags count next to
+// annotated ones. Release tools create annotated tags, with a tagger and a
+// date, while lightweight tags are often throwaway markers.
+type TagSelection string
+
+const (
+	// AllTags counts annotated and lightweight tags alike; "" does too
+	AllTags TagSelection = "All"
+	// PreferAnnotatedTags ignores the lightweight version tags of a commit
+	// that also carries an annotated one
+	PreferAnnotatedTags TagSelection = "PreferAnnotated"
+	// AnnotatedTagsOnly ignores lightweight tags entirely
+	AnnotatedTagsOnly TagSelection = "AnnotatedOnly"
+)
+
+// LatestTag decides which version tag reachable from HEAD is the latest
+type LatestTag string
+
+const (
+	// NearestTag is the tag git describe finds, the fewest commits away
+	// from HEAD; "" is the nearest tag too
+	NearestTag LatestTag = "Nearest"
+	// HighestTag is the tag with the highest version, so a hotfix tag
+	// merged back into a branch with a newer release does not win
+	HighestTag LatestTag = "Highest"
+)
+
+// WithLatestTag returns a copy of the repository that takes the latest
+// version tag to be the one mode names
+func (r *Repository) WithLatestTag(mode LatestTag) *Repository {
+	scoped := *r
+	scoped.latestTag = mode
+	return &scoped
+}
+
+// highestVersionTag returns the version tag with the highest version among
+// those reachable from HEAD, or "" when there is none
+func (r *Repository) highestVersionTag() (string, error) {
+	tags, err := r.GetTagCommits()
+	if err != nil {
+		return "", err
+	}
+	graph, err := r.GetCommitGraph()
+	if err != nil {
+		return "", err
+	}
+
+	// Version tags sort in ascending order
+	highest := ""
+	for _, tag := range tags {
+		if _, err := r.ParseTag(tag.Name); err != nil {
+			continue
+		}
+		if graph.ReachableFromHead(tag.SHA) {
+			highest = tag.Name
+		}
+	}
+	return highest, nil
+}
+
+// WithTagSelection returns a copy of the repository whose version tags are
+// chosen by selection
+func (r *Repository) WithTagSelection(selection TagSelection) *Repository {
+	scoped := *r
+	scoped.tagSelection = selection
+	return &scoped
+}
+
+// WithoutPreReleaseTags returns a copy of the repository that ignores
+// version tags with a prerelease, such as v1.5.0-rc.1
+func (r *Repository) WithoutPreReleaseTags() *Repository {
+	scoped := *r
+	scoped.stableTags = true
+	return &scoped
+}
+
+// ignoresVersion reports whether a version tag of version is ignored
+// because it is a prerelease
+func (r *Repository) ignoresVersion(version *semver.Version) bool {
+	return r.stableTags && version.PreRelease != ""
+}
+
+// selectTags drops the tags the tag selection ignores, keeping the order
+func (r *Repository) selectTags(tags []*Tag) []*Tag {
+	if r.stableTags {
+		stable := tags[:0]
+		for _, tag := range tags {
+			if version, err := r.ParseTag(tag.Name); err != nil || !r.ignoresVersion(version) {
+				stable = append(stable, tag)
+			}
+		}
+		tags = stable
+	}
+
+	switch r.tagSelection {
+	case AnnotatedTagsOnly:
+		selected := tags[:0]
+		for _, tag := range tags {
+			if tag.Annotated {
+				selected = append(selected, tag)
+			}
+		}
+		return selected
+	case PreferAnnotatedTags:
+		annotated := map[string]bool{}
+		for _, tag := range tags {
+			if _, err := r.ParseTag(tag.Name); err == nil && tag.Annotated {
+				annotated[tag.SHA] = true
+			}
+		}
+		selected := tags[:0]
+		for _, tag := range tags {
+			if tag.Annotated || !annotated[tag.SHA] {
+				selected = append(selected, tag)
+			}
+		}
+		return selected
+	}
+	return tags
+}
+
+// GetTaggerDate returns the date an annotated tag was created, formatted
+// like GetCommitDate, or "" for a lightweight tag
+func (r *Repository) GetTaggerDate(tag string) (string, error) {
+	output, err := r.output("for-each-ref", "--format=%(taggerdate:iso)", "refs/tags/"+tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to read tag %s: %w", tag, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,192 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagSelection(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "-a", "v1.0.0", "-m", "release 1.0.0")
+	commit(t, "fix: one")
+	runGit(t, "tag", "-a", "v1.1.0", "-m", "release 1.1.0")
+	runGit(t, "tag", "v1.1.1")
+	commit(t, "fix: two")
+	head := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "tag", "v1.2.0")
+
+	tests := []struct {
+		selection TagSelection
+		tags      []string
+		latest    string
+		atHead    []string
+	}{
+		{"", []string{"v1.0.0", "v1.1.0", "v1.1.1", "v1.2.0"}, "v1.2.0", []string{"v1.2.0"}},
+		{AllTags, []string{"v1.0.0", "v1.1.0", "v1.1.1", "v1.2.0"}, "v1.2.0", []string{"v1.2.0"}},
+		// Only v1.1.1 shares its commit with an annotated tag
+		{PreferAnnotatedTags, []string{"v1.0.0", "v1.1.0", "v1.2.0"}, "v1.2.0", []string{"v1.2.0"}},
+		{AnnotatedTagsOnly, []string{"v1.0.0", "v1.1.0"}, "v1.1.0", nil},
+	}
+	for _, tt := range tests {
+		repo := NewRepository().WithTagSelection(tt.selection)
+		tags, err := repo.GetTagCommits()
+		if err != nil {
+			t.Fatalf("GetTagCommits() error = %v", err)
+		}
+		var names []string
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		if !reflect.DeepEqual(names, tt.tags) {
+			t.Errorf("%q: GetTagCommits() = %v, want %v", tt.selection, names, tt.tags)
+		}
+		if latest, _ := repo.GetLatestVersionTag(); latest != tt.latest {
+			t.Errorf("%q: GetLatestVersionTag() = %q, want %q", tt.selection, latest, tt.latest)
+		}
+		if atHead, _ := repo.GetVersionTagsAt(head); !reflect.DeepEqual(atHead, tt.atHead) {
+			t.Errorf("%q: GetVersionTagsAt(HEAD) = %v, want %v", tt.selection, atHead, tt.atHead)
+		}
+	}
+
+	// Both tags at the middle commit are kept unless annotated ones are preferred
+	runGit(t, "checkout", "-q", "v1.1.0")
+	for selection, want := range map[TagSelection][]string{
+		AllTags:             {"v1.1.0", "v1.1.1"},
+		PreferAnnotatedTags: {"v1.1.0"},
+	} {
+		if tags, _ := NewRepository().WithTagSelection(selection).GetVersionTagsAt("HEAD"); !reflect.DeepEqual(tags, want) {
+			t.Errorf("%q: GetVersionTagsAt(v1.1.0) = %v, want %v", selection, tags, want)
+		}
+	}
+}
+
+func TestGetTaggerDate(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "-a", "v1.0.0", "-m", "release")
+	runGit(t, "tag", "v1.0.1")
+
+	repo := NewRepository()
+	// The tagger date is when the tag was made, which the environment fixes
+	t.Setenv("GIT_COMMITTER_DATE", "2024-03-01T12:00:00+0100")
+	runGit(t, "tag", "-a", "v1.0.2", "-m", "release")
+	if date, err := repo.GetTaggerDate("v1.0.2"); err != nil || date != "2024-03-01 12:00:00 +0100" {
+		t.Errorf("GetTaggerDate(v1.0.2) = %q, %v, want 2024-03-01 12:00:00 +0100", date, err)
+	}
+	if date, _ := repo.GetTaggerDate("v1.0.0"); date == "" {
+		t.Error("GetTaggerDate(v1.0.0) is empty for an annotated tag")
+	}
+	if date, _ := repo.GetTaggerDate("v1.0.1"); date != "" {
+		t.Errorf("GetTaggerDate(v1.0.1) = %q, want empty for a lightweight tag", date)
+	}
+}
+
+func TestLatestTagHighest(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.5.0")
+	trunk := runGit(t, "symbolic-ref", "--short", "HEAD")
+	runGit(t, "branch", "hotfix")
+	commit(t, "feat: two")
+	runGit(t, "tag", "v2.0.0")
+	commit(t, "feat: more")
+	commit(t, "feat: even more")
+	runGit(t, "checkout", "-q", "-b", "next")
+	commit(t, "feat: unreleased")
+	runGit(t, "tag", "v9.0.0")
+	runGit(t, "checkout", "-q", "hotfix")
+	commit(t, "fix: one")
+	commit(t, "fix: two")
+	runGit(t, "tag", "v1.5.1")
+	runGit(t, "checkout", "-q", trunk)
+	runGit(t, "merge", "-q", "--no-ff", "-m", "Merge hotfix", "hotfix")
+
+	// The back-merged hotfix tag is fewer commits away than the release
+	if tag, _ := NewRepository().GetLatestVersionTag(); tag != "v1.5.1" {
+		t.Errorf("GetLatestVersionTag() = %q, want the nearest v1.5.1", tag)
+	}
+	if tag, _ := NewRepository().WithLatestTag(NearestTag).GetLatestVersionTag(); tag != "v1.5.1" {
+		t.Errorf("Nearest GetLatestVersionTag() = %q, want v1.5.1", tag)
+	}
+	// v9.0.0 is higher still but not reachable from HEAD
+	if tag, _ := NewRepository().WithLatestTag(HighestTag).GetLatestVersionTag(); tag != "v2.0.0" {
+		t.Errorf("Highest GetLatestVersionTag() = %q, want v2.0.0", tag)
+	}
+
+	runGit(t, "checkout", "-q", "--orphan", "empty")
+	if tag, err := NewRepository().WithLatestTag(HighestTag).GetLatestVersionTag(); err != nil || tag != "" {
+		t.Errorf("Highest GetLatestVersionTag() on an unborn branch = %q, %v, want none", tag, err)
+	}
+}
+
+func TestWithoutPreReleaseTags(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	commit(t, "feat: next")
+	runGit(t, "tag", "v1.1.0-rc.1")
+	runGit(t, "tag", "deploy-1")
+
+	repo := NewRepository().WithoutPreReleaseTags()
+	tags, err := repo.GetTagCommits()
+	if err != nil {
+		t.Fatalf("GetTagCommits() error = %v", err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if want := []string{"v1.0.0", "deploy-1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetTagCommits() = %v, want %v", names, want)
+	}
+	if tag, _ := repo.GetLatestVersionTag(); tag != "v1.0.0" {
+		t.Errorf("GetLatestVersionTag() = %q, want v1.0.0", tag)
+	}
+	if tag, _ := repo.WithLatestTag(HighestTag).GetLatestVersionTag(); tag != "v1.0.0" {
+		t.Errorf("Highest GetLatestVersionTag() = %q, want v1.0.0", tag)
+	}
+	if tag, _ := NewRepository().GetLatestVersionTag(); tag != "v1.1.0-rc.1" {
+		t.Errorf("GetLatestVersionTag() = %q, want v1.1.0-rc.1 without the option", tag)
+	}
+}
+
+func TestWithVersionPrefix(t *testing.T) {
+	setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v0.5.0")
+	commit(t, "fix: one")
+	runGit(t, "tag", "release-1.2.0")
+	commit(t, "fix: two")
+	runGit(t, "tag", "1.1.0")
+
+	repo, err := NewRepository().WithVersionPrefix("release-")
+	if err != nil {
+		t.Fatalf("WithVersionPrefix() error = %v", err)
+	}
+	for tag, want := range map[string]string{"release-1.2.0": "1.2.0", "1.1.0": "1.1.0", "v0.5.0": "", "release-": ""} {
+		version, err := repo.ParseTag(tag)
+		switch {
+		case want == "" && err == nil:
+			t.Errorf("ParseTag(%q) = %s, want an error", tag, version)
+		case want != "" && (err != nil || version.String() != want):
+			t.Errorf("ParseTag(%q) = %v, %v, want %s", tag, version, err, want)
+		}
+	}
+
+	// v0.5.0 is no version tag, so it sorts last
+	tags, err := repo.GetAllTags()
+	if err != nil {
+		t.Fatalf("GetAllTags() error = %v", err)
+	}
+	if want := []string{"1.1.0", "release-1.2.0", "v0.5.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("GetAllTags() = %v, want %v", tags, want)
+	}
+	if latest, _ := repo.WithLatestTag(HighestTag).GetLatestVersionTag(); latest != "release-1.2.0" {
+		t.Errorf("GetLatestVersionTag() with Highest = %q, want release-1.2.0", latest)
+	}
+
+	if _, err := NewRepository().WithVersionPrefix("["); err == nil {
+		t.Error("WithVersionPrefix() of an invalid pattern should fail")
+	}
+}
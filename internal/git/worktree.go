@@ -0,0 +1,95 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FetchBranch fetches branch and the tags of remote into the remote's
+// tracking branch
+func (r *Repository) FetchBranch(remote, branch string, auth RemoteAuth) error {
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch)
+	cmd := r.command("fetch", "--quiet", "--tags", remote, refspec)
+	cmd.Env = append(r.environ(), auth.env()...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %s", branch, remote, strings.TrimSpace(string(output)))
+	}
+	r.invalidate()
+	return nil
+}
+
+// FetchBranches fetches branches and the tags of remote into the remote's
+// tracking branches in one fetch
+func (r *Repository) FetchBranches(remote string, branches []string, auth RemoteAuth) error {
+	args := []string{"fetch", "--quiet", "--tags", remote}
+	for _, branch := range branches {
+		args = append(args, fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch))
+	}
+	cmd := r.command(args...)
+	cmd.Env = append(r.environ(), auth.env()...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to fetch from %s: %s", remote, strings.TrimSpace(string(output)))
+	}
+	r.invalidate()
+	return nil
+}
+
+// Deepen fetches by more commits of the history of a shallow clone from
+// remote, and its tags; by 0 fetches all of the history
+func (r *Repository) Deepen(remote string, by int, auth RemoteAuth) error {
+	deepen := "--unshallow"
+	if by > 0 {
+		deepen = fmt.Sprintf("--deepen=%d", by)
+	}
+	// Tags are fetched explicitly, clones made with --no-tags do not
+	// follow them
+	cmd := r.command("fetch", "--quiet", "--tags", deepen, remote)
+	cmd.Env = append(r.environ(), auth.env()...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to deepen the clone from %s: %s", remote, strings.TrimSpace(string(output)))
+	}
+	r.invalidate()
+	return nil
+}
+
+// RemoteHeads returns the names of the branches on remote, asking the
+// remote rather than reading its tracking branches
+func (r *Repository) RemoteHeads(remote string, auth RemoteAuth) ([]string, error) {
+	args := []string{"ls-remote", "--heads", remote}
+	cmd := r.command(args...)
+	cmd.Env = append(r.environ(), auth.env()...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(args, err)
+	}
+	var heads []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if _, ref, ok := strings.Cut(strings.TrimSpace(line), "\t"); ok {
+			heads = append(heads, strings.TrimPrefix(ref, "refs/heads/"))
+		}
+	}
+	return heads, nil
+}
+
+// AddWorktree checks out commit, detached, in a new linked worktree at
+// path, which must not exist or be empty. The worktree shares the
+// repository's objects, refs and tags.
+func (r *Repository) AddWorktree(path, commit string) error {
+	output, err := r.command("worktree", "add", "--quiet", "--detach", path, commit).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to check out %s: %s", commit, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RemoveWorktree deletes the linked worktree at path and its files
+func (r *Repository) RemoveWorktree(path string) error {
+	output, err := r.command("worktree", "remove", "--force", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %s", path, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
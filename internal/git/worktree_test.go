@@ -0,0 +1,64 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinkedWorktree(t *testing.T) {
+	mainDir := setupTestRepo(t)
+	commit(t, "initial commit")
+	runGit(t, "tag", "v1.0.0")
+
+	linked := filepath.Join(t.TempDir(), "linked")
+	runGit(t, "worktree", "add", "-q", "-b", "feature/x", linked)
+	if data, err := os.ReadFile(filepath.Join(linked, ".git")); err != nil || !strings.HasPrefix(string(data), "gitdir: ") {
+		t.Fatalf("Expected .git of the worktree to be a gitdir file, got %q (%v)", data, err)
+	}
+
+	if linkedWorktree, err := NewRepositoryAt(mainDir).IsLinkedWorktree(); err != nil || linkedWorktree {
+		t.Errorf("IsLinkedWorktree() of the main working tree = %t, %v", linkedWorktree, err)
+	}
+
+	sub := filepath.Join(linked, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mainGitDir, _, err := NewRepositoryAt(mainDir).GetGitDirs()
+	if err != nil {
+		t.Fatalf("GetGitDirs() error = %v", err)
+	}
+	for _, dir := range []string{linked, sub} {
+		repo := NewRepositoryAt(dir)
+		if !repo.IsRepository() {
+			t.Errorf("IsRepository() in %s = false", dir)
+		}
+		if linkedWorktree, err := repo.IsLinkedWorktree(); err != nil || !linkedWorktree {
+			t.Errorf("IsLinkedWorktree() in %s = %t, %v", dir, linkedWorktree, err)
+		}
+		gitDir, commonDir, err := repo.GetGitDirs()
+		if err != nil {
+			t.Fatalf("GetGitDirs() in %s error = %v", dir, err)
+		}
+		// The worktree keeps its HEAD apart and shares the refs
+		if !samePath(commonDir, mainGitDir) || !strings.HasPrefix(gitDir, filepath.Join(commonDir, "worktrees")+string(filepath.Separator)) {
+			t.Errorf("GetGitDirs() in %s = %s, %s, want a worktree directory of %s", dir, gitDir, commonDir, mainGitDir)
+		}
+		if branch, err := repo.GetCurrentBranch(); err != nil || branch != "feature/x" {
+			t.Errorf("GetCurrentBranch() in %s = %s, %v", dir, branch, err)
+		}
+		if tag, err := repo.GetLatestVersionTag(); err != nil || tag != "v1.0.0" {
+			t.Errorf("GetLatestVersionTag() in %s = %s, %v", dir, tag, err)
+		}
+	}
+
+	// A worktree whose git directory is gone is no repository
+	if err := os.RemoveAll(filepath.Join(mainGitDir, "worktrees")); err != nil {
+		t.Fatal(err)
+	}
+	if NewRepositoryAt(linked).IsRepository() {
+		t.Error("IsRepository() in a pruned worktree = true")
+	}
+}
@@ -0,0 +1,140 @@
+// Package gomod discovers the Go modules of a multi-module repository and
+// maps each one to its tag prefix and the paths its history is scoped to.
+package gomod
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Module is a Go module found in the repository
+type Module struct {
+	// Dir is the slash separated module directory relative to the
+	// repository root, "." for the root module.
+	Dir string
+	// Path is the module path declared in go.mod
+	Path string
+}
+
+// TagPrefix returns the prefix of the module's version tags following the
+// Go convention: "" for the root module and "dir/" for nested modules, so
+// the module in tools/lint is released as tools/lint/v1.2.3.
+func (m Module) TagPrefix() string {
+	if m.Dir == "." {
+		return ""
+	}
+	return m.Dir + "/"
+}
+
+// Pathspecs returns git pathspecs selecting the files that belong to the
+// module: its directory minus the directories of modules nested in it.
+func (m Module) Pathspecs(modules []Module) []string {
+	// ":(top)" alone selects the whole tree; ":(top)." matches nothing
+	top := ":(top)"
+	if m.Dir != "." {
+		top += m.Dir
+	}
+	specs := []string{top}
+	for _, other := range modules {
+		if other.Dir != m.Dir && contains(m.Dir, other.Dir) {
+			specs = append(specs, ":(top,exclude)"+other.Dir)
+		}
+	}
+	return specs
+}
+
+func contains(dir, sub string) bool {
+	return dir == "." || strings.HasPrefix(sub, dir+"/")
+}
+
+// Discover walks root and returns every module, sorted by directory.
+// Directories the go command ignores (vendor, testdata and names starting
+// with "." or "_") are skipped.
+func Discover(root string) ([]Module, error) {
+	var modules []Module
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "go.mod" {
+			return nil
+		}
+
+		modulePath, err := readModulePath(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		modules = append(modules, Module{Dir: filepath.ToSlash(rel), Path: modulePath})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover modules: %w", err)
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Dir < modules[j].Dir
+	})
+	return modules, nil
+}
+
+// Find returns the module in dir, which is relative to the repository root.
+// A module path such as example.com/repo/tools is accepted as well.
+func Find(modules []Module, dir string) (Module, error) {
+	clean := path.Clean(filepath.ToSlash(dir))
+	for _, m := range modules {
+		if m.Dir == clean || m.Path == dir {
+			return m, nil
+		}
+	}
+	return Module{}, fmt.Errorf("no Go module found at %s", dir)
+}
+
+// readModulePath returns the module path declared in a go.mod file
+func readModulePath(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		modulePath := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if unquoted, err := strconv.Unquote(modulePath); err == nil {
+			modulePath = unquoted
+		}
+		if modulePath != "" {
+			return modulePath, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s: missing module directive", file)
+}
@@ -0,0 +1,90 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/repo\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(root, "tools", "go.mod"), "module \"example.com/repo/tools\" // tooling\n")
+	writeFile(t, filepath.Join(root, "tools", "lint", "go.mod"), "module example.com/repo/tools/lint/v2\n")
+	writeFile(t, filepath.Join(root, "vendor", "x", "go.mod"), "module example.com/x\n")
+	writeFile(t, filepath.Join(root, "testdata", "go.mod"), "module example.com/testdata\n")
+	writeFile(t, filepath.Join(root, ".git", "go.mod"), "module example.com/hidden\n")
+
+	modules, err := Discover(root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Module{
+		{Dir: ".", Path: "example.com/repo"},
+		{Dir: "tools", Path: "example.com/repo/tools"},
+		{Dir: "tools/lint", Path: "example.com/repo/tools/lint/v2"},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("Discover() = %+v, want %+v", modules, expected)
+	}
+}
+
+func TestDiscoverMissingModuleDirective(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "go 1.21\n")
+
+	if _, err := Discover(root); err == nil {
+		t.Errorf("Expected error for go.mod without module directive")
+	}
+}
+
+func TestModuleScope(t *testing.T) {
+	modules := []Module{
+		{Dir: ".", Path: "example.com/repo"},
+		{Dir: "tools", Path: "example.com/repo/tools"},
+		{Dir: "tools/lint", Path: "example.com/repo/tools/lint"},
+		{Dir: "toolsx", Path: "example.com/repo/toolsx"},
+	}
+
+	tests := []struct {
+		dir       string
+		prefix    string
+		pathspecs []string
+	}{
+		{".", "", []string{":(top)", ":(top,exclude)tools", ":(top,exclude)tools/lint", ":(top,exclude)toolsx"}},
+		{"tools", "tools/", []string{":(top)tools", ":(top,exclude)tools/lint"}},
+		{"tools/lint/", "tools/lint/", []string{":(top)tools/lint"}},
+		{"example.com/repo/toolsx", "toolsx/", []string{":(top)toolsx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			m, err := Find(modules, tt.dir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if m.TagPrefix() != tt.prefix {
+				t.Errorf("TagPrefix() = %q, want %q", m.TagPrefix(), tt.prefix)
+			}
+			if got := m.Pathspecs(modules); !reflect.DeepEqual(got, tt.pathspecs) {
+				t.Errorf("Pathspecs() = %v, want %v", got, tt.pathspecs)
+			}
+		})
+	}
+
+	if _, err := Find(modules, "missing"); err == nil {
+		t.Errorf("Expected error for unknown module")
+	}
+}
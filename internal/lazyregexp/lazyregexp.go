@@ -0,0 +1,66 @@
+// Package lazyregexp defers compiling package level regular expressions
+// until their first use, so they cost nothing at program start for the
+// commands that never need them.
+package lazyregexp
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Regexp is a regexp.Regexp compiled on first use
+type Regexp struct {
+	str  string
+	once sync.Once
+	rx   *regexp.Regexp
+}
+
+// New returns a Regexp for str. Like regexp.MustCompile it panics if str
+// does not compile, but only when the expression is first used.
+func New(str string) *Regexp {
+	return &Regexp{str: str}
+}
+
+// Regexp returns the compiled expression
+func (r *Regexp) Regexp() *regexp.Regexp {
+	r.once.Do(func() {
+		r.rx = regexp.MustCompile(r.str)
+	})
+	return r.rx
+}
+
+func (r *Regexp) MatchString(s string) bool {
+	return r.Regexp().MatchString(s)
+}
+
+func (r *Regexp) Match(b []byte) bool {
+	return r.Regexp().Match(b)
+}
+
+func (r *Regexp) FindSubmatch(b []byte) [][]byte {
+	return r.Regexp().FindSubmatch(b)
+}
+
+func (r *Regexp) FindSubmatchIndex(b []byte) []int {
+	return r.Regexp().FindSubmatchIndex(b)
+}
+
+func (r *Regexp) FindStringSubmatch(s string) []string {
+	return r.Regexp().FindStringSubmatch(s)
+}
+
+func (r *Regexp) FindAllStringSubmatch(s string) [][]string {
+	return r.Regexp().FindAllStringSubmatch(s, -1)
+}
+
+func (r *Regexp) ReplaceAllString(src, repl string) string {
+	return r.Regexp().ReplaceAllString(src, repl)
+}
+
+func (r *Regexp) ReplaceAllStringFunc(src string, repl func(string) string) string {
+	return r.Regexp().ReplaceAllStringFunc(src, repl)
+}
+
+func (r *Regexp) String() string {
+	return r.str
+}
@@ -0,0 +1,42 @@
+package lazyregexp
+
+import "testing"
+
+func TestRegexpCompilesOnFirstUse(t *testing.T) {
+	r := New(`^v(\d+)$`)
+	if r.rx != nil {
+		t.Fatalf("New() should not compile the expression")
+	}
+
+	if !r.MatchString("v1") {
+		t.Errorf("MatchString(v1) = false, want true")
+	}
+	if m := r.FindStringSubmatch("v42"); len(m) != 2 || m[1] != "42" {
+		t.Errorf("FindStringSubmatch(v42) = %v", m)
+	}
+	if !r.Match([]byte("v1")) {
+		t.Errorf("Match(v1) = false, want true")
+	}
+	if m := r.FindSubmatch([]byte("v42")); len(m) != 2 || string(m[1]) != "42" {
+		t.Errorf("FindSubmatch(v42) = %q", m)
+	}
+	if m := r.FindSubmatchIndex([]byte("v42")); len(m) != 4 || m[2] != 1 || m[3] != 3 {
+		t.Errorf("FindSubmatchIndex(v42) = %v", m)
+	}
+	if got := New(`[^a-z]`).ReplaceAllString("a/b", "-"); got != "a-b" {
+		t.Errorf("ReplaceAllString() = %q, want a-b", got)
+	}
+	if r.String() != `^v(\d+)$` {
+		t.Errorf("String() = %q", r.String())
+	}
+}
+
+func TestInvalidRegexpPanicsOnUse(t *testing.T) {
+	r := New(`(`)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for an invalid expression")
+		}
+	}()
+	r.MatchString("x")
+}
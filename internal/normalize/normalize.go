@@ -0,0 +1,126 @@
+// Package normalize prepares a CI checkout for version calculation as
+// GitVersion's normalization does: it fetches the history, branches and
+// tags the checkout left out, and replaces a detached HEAD with the local
+// branch the build is for.
+package normalize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/buildservers"
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+)
+
+// Options configure a normalization
+type Options struct {
+	// Remote is the remote fetched from, git.DefaultRemote when empty
+	Remote string
+	Auth   git.RemoteAuth
+	// DryRun reports the steps without changing the repository
+	DryRun bool
+}
+
+// Step is one change to the repository
+type Step struct {
+	Action string `json:"action"`
+	Detail string `json:"detail"`
+}
+
+// Report holds the steps taken, in order, or those that would be taken in
+// a dry run
+type Report struct {
+	Steps  []Step `json:"steps"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+func (r *Report) add(action, format string, args ...interface{}) {
+	r.Steps = append(r.Steps, Step{Action: action, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Run normalizes the repository in the working directory. env supplies the
+// CI environment that names the branch of a detached HEAD.
+func Run(repo *git.Repository, opts Options, env buildservers.Env) (*Report, error) {
+	if opts.Remote == "" {
+		opts.Remote = git.DefaultRemote
+	}
+	report := &Report{DryRun: opts.DryRun}
+	if !repo.IsRepository() {
+		return nil, fmt.Errorf("not inside a git repository")
+	}
+
+	if err := fetch(report, repo, opts); err != nil {
+		return report, err
+	}
+	if err := createBranches(report, repo, opts); err != nil {
+		return report, err
+	}
+	return report, attachHead(report, repo, opts, env)
+}
+
+// fetch unshallows the clone and fetches every branch and tag of the remote
+func fetch(report *Report, repo *git.Repository, opts Options) error {
+	if !repo.RemoteExists(opts.Remote) {
+		report.add("skip-fetch", "no remote %s; only the local branches are used", opts.Remote)
+		return nil
+	}
+	shallow, err := repo.IsShallow()
+	if err != nil {
+		return fmt.Errorf("failed to tell whether the clone is shallow: %w", err)
+	}
+	if shallow {
+		report.add("unshallow", "fetch the full history from %s", opts.Remote)
+	}
+	report.add("fetch", "fetch the branches and tags of %s", opts.Remote)
+	if opts.DryRun {
+		return nil
+	}
+	return repo.FetchAll(opts.Remote, shallow, opts.Auth)
+}
+
+// createBranches creates a local branch for each remote branch without one
+func createBranches(report *Report, repo *git.Repository, opts Options) error {
+	branches, err := repo.RemoteBranches(opts.Remote)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		if repo.RefExists("refs/heads/" + branch) {
+			continue
+		}
+		report.add("create-branch", "create %s at %s/%s", branch, opts.Remote, branch)
+		if opts.DryRun {
+			continue
+		}
+		if err := repo.CreateBranch(branch, "refs/remotes/"+opts.Remote+"/"+branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachHead checks out the branch a detached HEAD is built for: the one
+// the CI environment names, else the only local branch at HEAD
+func attachHead(report *Report, repo *git.Repository, opts Options, env buildservers.Env) error {
+	if !repo.IsDetachedHead() {
+		return nil
+	}
+	branch := buildservers.Branch(env)
+	if branch == "" {
+		atHead, err := repo.BranchesAtHead()
+		if err != nil {
+			return err
+		}
+		if len(atHead) != 1 {
+			report.add("skip-checkout", "HEAD is detached, no CI variable names the branch and %d branches point at HEAD (%s); pass the branch with gitversion -b BRANCH",
+				len(atHead), strings.Join(atHead, ", "))
+			return nil
+		}
+		branch = atHead[0]
+	}
+	report.add("checkout", "check out %s at HEAD", branch)
+	if opts.DryRun {
+		return nil
+	}
+	return repo.CheckoutBranchAtHead(branch)
+}
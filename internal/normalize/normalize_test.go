@@ -0,0 +1,151 @@
+package normalize
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+)
+
+func setupTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	chdir(t, dir)
+	runGit(t, "init", "-q", "-b", "main")
+	runGit(t, "config", "user.name", "Test User")
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "config", "commit.gpgsign", "false")
+	runGit(t, "config", "tag.gpgsign", "false")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func envOf(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+// ciClone makes a depth 1 clone of origin, detached at develop as CI
+// checkouts are
+func ciClone(t *testing.T, origin string) {
+	t.Helper()
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "-b", "develop")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feature")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "another feature")
+	runGit(t, "checkout", "-q", "main")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, "clone", "-q", "--depth", "1", "--no-tags", "--single-branch", "--branch", "develop", "file://"+origin, clone)
+	chdir(t, clone)
+	runGit(t, "checkout", "-q", "--detach")
+	runGit(t, "branch", "-q", "-D", "develop")
+}
+
+func TestRun(t *testing.T) {
+	origin := setupTestRepo(t)
+	ciClone(t, origin)
+
+	env := envOf(map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/develop"})
+	report, err := Run(git.NewRepository(), Options{}, env)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if shallow := runGit(t, "rev-parse", "--is-shallow-repository"); shallow != "false" {
+		t.Errorf("Expected the clone to be unshallowed, is-shallow-repository = %s", shallow)
+	}
+	if tags := runGit(t, "tag", "--list"); tags != "v1.0.0" {
+		t.Errorf("Expected tag v1.0.0 to be fetched, got %q", tags)
+	}
+	if branch := runGit(t, "symbolic-ref", "--short", "HEAD"); branch != "develop" {
+		t.Errorf("Expected develop checked out, got %s", branch)
+	}
+	if !strings.Contains(runGit(t, "branch", "--list", "main"), "main") {
+		t.Error("Expected a local main branch")
+	}
+
+	var actions []string
+	for _, step := range report.Steps {
+		actions = append(actions, step.Action)
+	}
+	want := "unshallow fetch create-branch create-branch checkout"
+	if got := strings.Join(actions, " "); got != want {
+		t.Errorf("Steps = %q, want %q", got, want)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	origin := setupTestRepo(t)
+	ciClone(t, origin)
+
+	env := envOf(map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/develop"})
+	report, err := Run(git.NewRepository(), Options{DryRun: true}, env)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Steps) == 0 {
+		t.Error("Expected the steps a normalization would take")
+	}
+	if shallow := runGit(t, "rev-parse", "--is-shallow-repository"); shallow != "true" {
+		t.Error("Expected a dry run to leave the clone shallow")
+	}
+	if _, err := exec.Command("git", "symbolic-ref", "-q", "HEAD").Output(); err == nil {
+		t.Error("Expected a dry run to leave HEAD detached")
+	}
+}
+
+func TestRunDetachedWithoutCI(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "checkout", "-q", "--detach")
+
+	// Without a remote, the only branch at HEAD is checked out
+	report, err := Run(git.NewRepository(), Options{}, envOf(nil))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if branch := runGit(t, "symbolic-ref", "--short", "HEAD"); branch != "main" {
+		t.Errorf("Expected main checked out, got %s", branch)
+	}
+	if report.Steps[0].Action != "skip-fetch" {
+		t.Errorf("Expected the fetch to be skipped without a remote, got %+v", report.Steps)
+	}
+
+	// Two branches at HEAD are ambiguous
+	runGit(t, "branch", "other")
+	runGit(t, "checkout", "-q", "--detach")
+	report, err = Run(git.NewRepository(), Options{}, envOf(nil))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if last := report.Steps[len(report.Steps)-1]; last.Action != "skip-checkout" {
+		t.Errorf("Expected the checkout to be skipped, got %+v", report.Steps)
+	}
+}
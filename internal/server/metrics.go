@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the calculation
+// latency histogram; Prometheus client libraries use the same defaults
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// versionInfo is the last version calculated for a repository
+type versionInfo struct {
+	branch     string
+	semVer     string
+	fullSemVer string
+	sha        string
+	at         time.Time
+}
+
+// metrics collects what /metrics exposes. Prometheus' text format is small
+// enough to write by hand, which keeps a client library out of the build.
+type metrics struct {
+	mu sync.Mutex
+
+	// latencies counts calculations per bucket of latencyBuckets, the last
+	// entry counting those slower than every bucket
+	latencies  []uint64
+	latencySum float64
+	failures   uint64
+	// warmHits and warmMisses count version requests answered from the warm
+	// result of Watch and those calculated on request
+	warmHits   uint64
+	warmMisses uint64
+	versions   map[string]versionInfo
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		latencies: make([]uint64, len(latencyBuckets)+1),
+		versions:  map[string]versionInfo{},
+	}
+}
+
+// observeCalculation records a calculation in dir that took elapsed
+func (m *metrics) observeCalculation(dir string, elapsed time.Duration, result *v1.Result, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seconds := elapsed.Seconds()
+	bucket := sort.SearchFloat64s(latencyBuckets, seconds)
+	m.latencies[bucket]++
+	m.latencySum += seconds
+	if err != nil {
+		m.failures++
+		return
+	}
+	m.versions[dir] = versionInfo{
+		branch:     result.BranchName,
+		semVer:     result.SemVer,
+		fullSemVer: result.FullSemVer,
+		sha:        result.Sha,
+		at:         time.Now(),
+	}
+}
+
+// observeRequest records whether a version request was answered warm
+func (m *metrics) observeRequest(warm bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if warm {
+		m.warmHits++
+	} else {
+		m.warmMisses++
+	}
+}
+
+// write renders the metrics in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP gitversion_calculation_duration_seconds Time taken to calculate a version.")
+	fmt.Fprintln(w, "# TYPE gitversion_calculation_duration_seconds histogram")
+	var count uint64
+	for i, bound := range latencyBuckets {
+		count += m.latencies[i]
+		fmt.Fprintf(w, "gitversion_calculation_duration_seconds_bucket{le=\"%g\"} %d\n", bound, count)
+	}
+	count += m.latencies[len(latencyBuckets)]
+	fmt.Fprintf(w, "gitversion_calculation_duration_seconds_bucket{le=\"+Inf\"} %d\n", count)
+	fmt.Fprintf(w, "gitversion_calculation_duration_seconds_sum %g\n", m.latencySum)
+	fmt.Fprintf(w, "gitversion_calculation_duration_seconds_count %d\n", count)
+
+	fmt.Fprintln(w, "# HELP gitversion_calculation_failures_total Calculations that failed.")
+	fmt.Fprintln(w, "# TYPE gitversion_calculation_failures_total counter")
+	fmt.Fprintf(w, "gitversion_calculation_failures_total %d\n", m.failures)
+
+	fmt.Fprintln(w, "# HELP gitversion_git_commands_total Git subprocesses run.")
+	fmt.Fprintln(w, "# TYPE gitversion_git_commands_total counter")
+	fmt.Fprintf(w, "gitversion_git_commands_total %d\n", git.CommandsRun())
+
+	fmt.Fprintln(w, "# HELP gitversion_cache_requests_total Version requests by whether the warm result answered them.")
+	fmt.Fprintln(w, "# TYPE gitversion_cache_requests_total counter")
+	fmt.Fprintf(w, "gitversion_cache_requests_total{result=\"hit\"} %d\n", m.warmHits)
+	fmt.Fprintf(w, "gitversion_cache_requests_total{result=\"miss\"} %d\n", m.warmMisses)
+
+	dirs := make([]string, 0, len(m.versions))
+	for dir := range m.versions {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	fmt.Fprintln(w, "# HELP gitversion_version_info Last version calculated for each repository.")
+	fmt.Fprintln(w, "# TYPE gitversion_version_info gauge")
+	for _, dir := range dirs {
+		v := m.versions[dir]
+		fmt.Fprintf(w, "gitversion_version_info{repo=%s,branch=%s,semver=%s,full_semver=%s,sha=%s} 1\n",
+			labelValue(dir), labelValue(v.branch), labelValue(v.semVer), labelValue(v.fullSemVer), labelValue(v.sha))
+	}
+	fmt.Fprintln(w, "# HELP gitversion_version_timestamp_seconds When the last version of each repository was calculated.")
+	fmt.Fprintln(w, "# TYPE gitversion_version_timestamp_seconds gauge")
+	for _, dir := range dirs {
+		fmt.Fprintf(w, "gitversion_version_timestamp_seconds{repo=%s} %d\n", labelValue(dir), m.versions[dir].at.Unix())
+	}
+}
+
+// labelEscaper escapes a label value as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelValue quotes a label value
+func labelValue(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w)
+}
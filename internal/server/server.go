@@ -0,0 +1,293 @@
+// Package server exposes version calculation over HTTP for build farms and
+// internal tools that cannot shell out to the command line.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// Options configures a Server
+type Options struct {
+	// Roots are the directories whose repositories may be queried: a
+	// requested repository must be one of them or lie below one. The first
+	// is queried when a request names no repository.
+	Roots []string
+	// ConfigFiles are used, layered in order, for repositories without a
+	// configuration file of their own
+	ConfigFiles []string
+	// Username and Password require HTTP basic authentication for the
+	// version endpoint when Username is set
+	Username string
+	Password string
+	// MaxDuration bounds the strategies of each calculation; zero means
+	// no limit
+	MaxDuration time.Duration
+	// WebhookSecret enables POST /webhook for GitHub and GitLab push
+	// webhooks signed with, or carrying, this secret
+	WebhookSecret string
+	// Remote is the remote webhooks fetch from and push tags to,
+	// DefaultRemote when empty
+	Remote string
+}
+
+// unsafeFormats write to files or the environment of the server rather
+// than rendering the version
+var unsafeFormats = map[v1.OutputFormat]bool{
+	v1.BuildServer:   true,
+	v1.GitHubActions: true,
+}
+
+// errForbidden rejects repositories outside the roots
+var errForbidden = errors.New("repository is outside the served directories")
+
+// Server answers version requests:
+//
+//	GET /version?repo=/path&branch=main&format=json&variable=SemVer
+//	GET /metrics
+//	GET /healthz
+//	POST /webhook
+//
+// Each request calculates in its own repository; the working directory of
+// the process is never changed, so requests can run concurrently. While
+// Watch runs, requests for the first root are answered from a warm result.
+type Server struct {
+	opts  Options
+	roots []string
+	mux   *http.ServeMux
+
+	metrics *metrics
+
+	mu   sync.RWMutex
+	warm *warmResult
+
+	webhookMu sync.Mutex
+}
+
+// New creates a server for opts. Roots are resolved to absolute paths
+// without symbolic links, so links cannot lead requests outside them.
+func New(opts Options) (*Server, error) {
+	if len(opts.Roots) == 0 {
+		opts.Roots = []string{"."}
+	}
+	s := &Server{opts: opts, mux: http.NewServeMux(), metrics: newMetrics()}
+	for _, root := range opts.Roots {
+		resolved, err := resolvePath(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", root, err)
+		}
+		s.roots = append(s.roots, resolved)
+	}
+
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	s.mux.HandleFunc("/version", s.requireAuth(s.handleVersion))
+	s.mux.HandleFunc("/metrics", s.requireAuth(s.handleMetrics))
+	// Webhooks authenticate with their secret rather than basic auth
+	if opts.WebhookSecret != "" {
+		s.mux.HandleFunc("/webhook", s.handleWebhook)
+	}
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.URL.Path != "/webhook" {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// requireAuth wraps next with HTTP basic authentication when configured
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	if s.opts.Username == "" {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(s.opts.Username)) == 1
+		passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(s.opts.Password)) == 1
+		if !ok || !userMatch || !passwordMatch {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gitversion", charset="UTF-8"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("authentication required"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := v1.OutputFormat(query.Get("format"))
+	if format == "" {
+		format = v1.JSON
+	}
+	if unsafeFormats[format] {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("format %s is not available over HTTP", format))
+		return
+	}
+
+	dir, err := s.repository(query.Get("repo"))
+	switch {
+	case errors.Is(err, errForbidden):
+		writeError(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	branch := query.Get("branch")
+	result, err, ok := s.warmResult(dir, branch)
+	if !ok {
+		// A client that hangs up kills the git commands of its calculation
+		result, err = s.calculate(r.Context(), dir, branch, format)
+	}
+	s.metrics.observeRequest(ok)
+	var invalid *invalidOptionsError
+	switch {
+	case errors.As(err, &invalid):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if name := query.Get("variable"); name != "" {
+		value, err := result.Variable(name)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeText(w, value)
+		return
+	}
+	rendered, err := result.Format(format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if format == v1.JSON {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, rendered)
+		return
+	}
+	writeText(w, rendered)
+}
+
+// invalidOptionsError reports a request the calculation cannot be set up
+// for, such as an unknown format or an invalid configuration file
+type invalidOptionsError struct {
+	err error
+}
+
+func (e *invalidOptionsError) Error() string { return e.err.Error() }
+
+func (e *invalidOptionsError) Unwrap() error { return e.err }
+
+// calculate calculates the version of the repository in dir on branch,
+// the checked out branch when empty, until ctx is done
+func (s *Server) calculate(ctx context.Context, dir, branch string, format v1.OutputFormat) (*v1.Result, error) {
+	_, result, err := s.calculateIn(ctx, dir, dir, branch, format)
+	return result, err
+}
+
+// calculateIn calculates in the working tree dir, recording the result in
+// the metrics of repo, the repository dir checks out. The client is
+// returned for acting on the result.
+func (s *Server) calculateIn(ctx context.Context, repo, dir, branch string, format v1.OutputFormat) (*v1.Client, *v1.Result, error) {
+	opts := v1.Options{
+		OutputFormat: format,
+		TargetBranch: branch,
+		MaxDuration:  s.opts.MaxDuration,
+		Dir:          dir,
+	}
+	if path := config.Find(dir); path != "" {
+		opts.ConfigFile = path
+	} else {
+		opts.ConfigFiles = s.opts.ConfigFiles
+	}
+
+	client, err := v1.New(opts)
+	if err != nil {
+		return nil, nil, &invalidOptionsError{err}
+	}
+	start := time.Now()
+	result, err := client.CalculateContext(ctx)
+	s.metrics.observeCalculation(repo, time.Since(start), result, err)
+	return client, result, err
+}
+
+// repository resolves the repository a request names, the first root when
+// it names none, and checks that it lies within a root
+func (s *Server) repository(repo string) (string, error) {
+	if repo == "" {
+		return s.roots[0], nil
+	}
+	dir, err := resolvePath(repo)
+	if err != nil {
+		return "", fmt.Errorf("repository not found: %s", repo)
+	}
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return dir, nil
+		}
+	}
+	return "", errForbidden
+}
+
+// resolvePath returns the absolute path of an existing directory with
+// symbolic links evaluated
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	return resolved, nil
+}
+
+func writeText(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, strings.TrimRight(text, "\n"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
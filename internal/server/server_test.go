@@ -0,0 +1,333 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// configureUser sets the identity commits and annotated tags in dir need
+func configureUser(t *testing.T, dir string) {
+	t.Helper()
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "commit.gpgsign", "false")
+	runGit(t, dir, "config", "tag.gpgsign", "false")
+}
+
+// initRepo creates a repository in dir with one commit tagged tag
+func initRepo(t *testing.T, dir, tag string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create %s: %v", dir, err)
+	}
+	runGit(t, dir, "init", "-q", "-b", "main")
+	configureUser(t, dir)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, dir, "tag", tag)
+}
+
+func get(t *testing.T, handler http.Handler, target string, auth ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if len(auth) == 2 {
+		req.SetBasicAuth(auth[0], auth[1])
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	initRepo(t, filepath.Join(root, "app"), "v1.2.0")
+	initRepo(t, filepath.Join(root, "lib"), "v3.0.0")
+	outside := t.TempDir()
+
+	srv, err := New(Options{Roots: []string{root}, Username: "ci", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Each request calculates in the repository it names
+	majors := map[string]int{"app": 1, "lib": 3}
+	for name, want := range majors {
+		rec := get(t, srv, "/version?repo="+url.QueryEscape(filepath.Join(root, name)), "ci", "secret")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /version for %s = %d: %s", name, rec.Code, rec.Body)
+		}
+		var variables struct{ Major int }
+		if err := json.Unmarshal(rec.Body.Bytes(), &variables); err != nil {
+			t.Fatalf("Invalid JSON for %s: %v\n%s", name, err, rec.Body)
+		}
+		if variables.Major != want {
+			t.Errorf("%s Major = %d, want %d", name, variables.Major, want)
+		}
+	}
+
+	rec := get(t, srv, "/version?repo="+url.QueryEscape(filepath.Join(root, "lib"))+"&variable=Major", "ci", "secret")
+	if got := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusOK || got != "3" {
+		t.Errorf("GET /version?variable=Major = %d %q, want 200 3", rec.Code, got)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		auth   []string
+		status int
+	}{
+		{"health without auth", "/healthz", nil, http.StatusOK},
+		{"version without auth", "/version", nil, http.StatusUnauthorized},
+		{"wrong password", "/version", []string{"ci", "guess"}, http.StatusUnauthorized},
+		{"outside the roots", "/version?repo=" + url.QueryEscape(outside), []string{"ci", "secret"}, http.StatusForbidden},
+		{"escaping the root", "/version?repo=" + url.QueryEscape(filepath.Join(root, "app", "..", "..")), []string{"ci", "secret"}, http.StatusForbidden},
+		{"missing repository", "/version?repo=" + url.QueryEscape(filepath.Join(root, "nope")), []string{"ci", "secret"}, http.StatusNotFound},
+		{"not a repository", "/version", []string{"ci", "secret"}, http.StatusBadRequest},
+		{"build server format", "/version?repo=" + url.QueryEscape(filepath.Join(root, "app")) + "&format=buildserver", []string{"ci", "secret"}, http.StatusBadRequest},
+		{"unknown variable", "/version?repo=" + url.QueryEscape(filepath.Join(root, "app")) + "&variable=Nope", []string{"ci", "secret"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, srv, tt.target, tt.auth...)
+			if rec.Code != tt.status {
+				t.Errorf("GET %s = %d, want %d: %s", tt.target, rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/version", nil)
+	post := httptest.NewRecorder()
+	srv.ServeHTTP(post, req)
+	if post.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /version = %d, want %d", post.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	initRepo(t, root, "v1.0.0")
+
+	srv, err := New(Options{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- srv.Watch(ctx, 10*time.Millisecond) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch() error = %v", err)
+		}
+	}()
+
+	// waitFor polls until the warm result has the given major version
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			result, _, ok := srv.warmResult(srv.roots[0], "")
+			if ok && result != nil && fmt.Sprint(result.Major) == want {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("warm result never reached major version %s", want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		rec := get(t, srv, "/version?variable=Major")
+		if got := strings.TrimSpace(rec.Body.String()); got != want {
+			t.Errorf("GET /version?variable=Major = %q, want %q", got, want)
+		}
+	}
+	waitFor("1")
+
+	runGit(t, root, "tag", "v2.0.0")
+	waitFor("2")
+
+	// Other branches are calculated on request
+	if _, _, ok := srv.warmResult(srv.roots[0], "main"); ok {
+		t.Error("warm result used for a request naming a branch")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	initRepo(t, root, "v1.0.0")
+
+	srv, err := New(Options{Roots: []string{root}, Username: "ci", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if rec := get(t, srv, "/metrics"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /metrics without auth = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := get(t, srv, "/version", "ci", "secret"); rec.Code != http.StatusOK {
+		t.Fatalf("GET /version = %d: %s", rec.Code, rec.Body)
+	}
+	get(t, srv, "/version?variable=SemVer", "ci", "secret")
+
+	rec := get(t, srv, "/metrics", "ci", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d: %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"gitversion_calculation_duration_seconds_count 2\n",
+		`gitversion_calculation_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"gitversion_calculation_failures_total 0\n",
+		`gitversion_cache_requests_total{result="miss"} 2` + "\n",
+		`gitversion_cache_requests_total{result="hit"} 0` + "\n",
+		`gitversion_version_info{repo=` + labelValue(srv.roots[0]) + `,branch="main",semver="1.0.1`,
+		"# TYPE gitversion_git_commands_total counter\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	if got, want := labelValue("a\"b\\c\nd"), `"a\"b\\c\nd"`; got != want {
+		t.Errorf("labelValue() = %s, want %s", got, want)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	runGit(t, tmp, "init", "-q", "--bare", "-b", "main", origin)
+
+	// A developer pushes a tagged release with auto-tag enabled on main
+	dev := filepath.Join(tmp, "dev")
+	initRepo(t, dev, "v1.0.0")
+	if err := os.WriteFile(filepath.Join(dev, "GitVersion.yml"), []byte("branches:\n  main:\n    auto-tag: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dev, "add", "GitVersion.yml")
+	runGit(t, dev, "commit", "-q", "-m", "enable auto-tag")
+	runGit(t, dev, "remote", "add", "origin", origin)
+	runGit(t, dev, "push", "-q", "--tags", "origin", "main")
+
+	// The server's clone falls behind the next push
+	root := filepath.Join(tmp, "repos")
+	served := filepath.Join(root, "acme", "app")
+	if err := os.MkdirAll(filepath.Dir(served), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, tmp, "clone", "-q", origin, served)
+	configureUser(t, served)
+	servedHead := runGit(t, served, "rev-parse", "HEAD")
+
+	runGit(t, dev, "commit", "-q", "--allow-empty", "-m", "fix: a bug")
+	runGit(t, dev, "push", "-q", "origin", "main")
+	pushed := runGit(t, dev, "rev-parse", "HEAD")
+
+	const secret = "hook-secret"
+	srv, err := New(Options{Roots: []string{root}, WebhookSecret: secret})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	post := func(event, payload string, sign bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		req.Header.Set("X-GitHub-Event", event)
+		if sign {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte(payload))
+			req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	payload := fmt.Sprintf(`{"ref":"refs/heads/main","after":%q,"repository":{"name":"app","full_name":"acme/app"}}`, pushed)
+
+	if rec := post("push", payload, false); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned webhook = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := post("ping", "{}", true); rec.Code != http.StatusOK {
+		t.Errorf("ping = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := post("push", `{"ref":"refs/tags/v1.0.0","after":"`+pushed+`"}`, true); rec.Code != http.StatusAccepted {
+		t.Errorf("tag push = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	// Neither ROOT/acme/other nor ROOT/app exists
+	if rec := post("push", strings.Replace(payload, "acme/app", "acme/other", 1), true); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown repository = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec := post("push", payload, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("push webhook = %d: %s", rec.Code, rec.Body)
+	}
+	var result WebhookResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, rec.Body)
+	}
+	if !strings.HasPrefix(result.Tag, "v1.0.") || !result.Created || !result.Pushed || result.Commit != pushed {
+		t.Errorf("webhook result = %+v", result)
+	}
+	if tagged := runGit(t, origin, "rev-list", "-n", "1", result.Tag); tagged != pushed {
+		t.Errorf("%s in origin points at %s, want %s", result.Tag, tagged, pushed)
+	}
+	if head := runGit(t, served, "rev-parse", "HEAD"); head != servedHead {
+		t.Errorf("served checkout moved to %s", head)
+	}
+	if worktrees := runGit(t, served, "worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Errorf("worktree left behind:\n%s", worktrees)
+	}
+
+	// Redelivery finds the tag and pushes it again
+	if rec := post("push", payload, true); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"created":false`) {
+		t.Errorf("redelivered webhook = %d: %s", rec.Code, rec.Body)
+	}
+
+	// Without a secret there is no webhook endpoint
+	plain, err := New(Options{Roots: []string{root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+	plainRec := httptest.NewRecorder()
+	plain.ServeHTTP(plainRec, req)
+	if plainRec.Code != http.StatusNotFound {
+		t.Errorf("webhook without secret = %d, want %d", plainRec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// DefaultPollInterval is how often Watch looks for changed refs
+const DefaultPollInterval = 500 * time.Millisecond
+
+// warmResult is the last calculation for the first root and the state of
+// the refs it was calculated from
+type warmResult struct {
+	mu          sync.RWMutex
+	fingerprint string
+	result      *v1.Result
+	err         error
+}
+
+// get returns the warm result, and false until the first calculation ends
+func (w *warmResult) get() (*v1.Result, error, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.result, w.err, w.result != nil || w.err != nil
+}
+
+func (w *warmResult) set(fingerprint string, result *v1.Result, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fingerprint, w.result, w.err = fingerprint, result, err
+}
+
+// Watch keeps a warm result for the first root until ctx is done,
+// recalculating whenever its HEAD, refs or configuration file change, so
+// requests for that repository that name no branch are answered without
+// running git. Changes are found by polling every interval, or
+// DefaultPollInterval when interval is zero; the refs are only stat'ed, so
+// polling is cheap next to a calculation.
+func (s *Server) Watch(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	dir := s.roots[0]
+	gitDir, commonDir, err := git.NewRepositoryAt(dir).GetGitDirs()
+	if err != nil {
+		return err
+	}
+
+	warm := &warmResult{}
+	s.mu.Lock()
+	s.warm = warm
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.warm = nil
+		s.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// The fingerprint is taken before calculating, so a change made
+		// during a calculation is picked up by the next poll
+		fingerprint := refsFingerprint(gitDir, commonDir, s.configFiles(dir)...)
+		if fingerprint != warm.fingerprint {
+			result, err := s.calculate(ctx, dir, "", v1.JSON)
+			warm.set(fingerprint, result, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// warmResult returns the warm result for dir and branch, if there is one
+func (s *Server) warmResult(dir, branch string) (*v1.Result, error, bool) {
+	s.mu.RLock()
+	warm := s.warm
+	s.mu.RUnlock()
+	if warm == nil || branch != "" || dir != s.roots[0] {
+		return nil, nil, false
+	}
+	return warm.get()
+}
+
+// configFiles returns the configuration files a calculation in dir reads
+func (s *Server) configFiles(dir string) []string {
+	if path := config.Find(dir); path != "" {
+		return []string{path}
+	}
+	return s.opts.ConfigFiles
+}
+
+// refsFingerprint summarizes the size and modification time of HEAD, the
+// refs and the given files. Git replaces a ref file whenever it moves, so
+// any commit, checkout, tag or fetch changes the fingerprint.
+func refsFingerprint(gitDir, commonDir string, files ...string) string {
+	h := sha256.New()
+	stamp := func(path string, info fs.FileInfo) {
+		fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+
+	paths := []string{filepath.Join(gitDir, "HEAD"), filepath.Join(commonDir, "packed-refs")}
+	for _, path := range append(paths, files...) {
+		if info, err := os.Stat(path); err == nil {
+			stamp(path, info)
+		}
+	}
+	filepath.WalkDir(filepath.Join(commonDir, "refs"), func(path string, d fs.DirEntry, err error) error {
+		// Refs vanish while git packs or deletes them; skip them
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			stamp(path, info)
+		}
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil))
+}
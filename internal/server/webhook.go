@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// DefaultRemote is the remote webhooks fetch from and push tags to
+const DefaultRemote = "origin"
+
+// maxWebhookBody bounds the push payloads read; GitHub caps them at 25MB
+const maxWebhookBody = 25 << 20
+
+// commitSHA matches a full SHA-1 or SHA-256 object name
+var commitSHA = lazyregexp.New(`^[0-9a-f]{40}(?:[0-9a-f]{24})?$`)
+
+// pushEvent holds the fields of GitHub and GitLab push payloads the webhook
+// uses. Both name the pushed commit "after"; GitHub names the repository
+// owner/name in repository.full_name, GitLab in project.path_with_namespace.
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// WebhookResult reports what a push webhook did
+type WebhookResult struct {
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	Commit     string `json:"commit"`
+	Version    string `json:"version"`
+	// Tag is the release tag, empty when the branch does not auto-tag
+	Tag     string `json:"tag,omitempty"`
+	Created bool   `json:"created"`
+	Pushed  bool   `json:"pushed"`
+	// Skipped says why nothing was tagged
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// handleWebhook receives GitHub and GitLab push webhooks. The pushed commit
+// is fetched and checked out in a temporary worktree, so the checkout the
+// server answers version requests from is left alone, and its version is
+// calculated with the configuration of that commit. When the pushed
+// branch allows auto-tag, the release tag is created and pushed.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !s.verifyWebhook(r, body) {
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid webhook signature or token"))
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event") + r.Header.Get("X-Gitlab-Event"); event {
+	case "ping":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+		return
+	case "push", "Push Hook":
+	default:
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("event %q is not a push", event)})
+		return
+	}
+
+	var push pushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid push payload: %w", err))
+		return
+	}
+	branch, ok := strings.CutPrefix(push.Ref, "refs/heads/")
+	if !ok {
+		// Includes the tags the webhook pushes itself
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("%s is not a branch", push.Ref)})
+		return
+	}
+	if strings.Trim(push.After, "0") == "" {
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("branch %s was deleted", branch)})
+		return
+	}
+	if !commitSHA.MatchString(push.After) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid commit %q", push.After))
+		return
+	}
+
+	dir, err := s.webhookRepository(push)
+	switch {
+	case errors.Is(err, errForbidden):
+		writeError(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	// Webhooks for one repository race to create the same tags
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+	result, err := s.release(dir, branch, push.After)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// verifyWebhook checks GitHub's HMAC signature of the body or GitLab's
+// secret token against the webhook secret
+func (s *Server) verifyWebhook(r *http.Request, body []byte) bool {
+	secret := []byte(s.opts.WebhookSecret)
+	if signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256="); ok {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), secret) == 1
+	}
+	return false
+}
+
+// webhookRepository finds the working tree of the pushed repository:
+// ROOT/owner/name, then ROOT/name, for each root in turn
+func (s *Server) webhookRepository(push pushEvent) (string, error) {
+	fullName := push.Repository.FullName
+	if fullName == "" {
+		fullName = push.Project.PathWithNamespace
+	}
+	var candidates []string
+	if fullName != "" {
+		candidates = append(candidates, fullName)
+	}
+	if name := push.Repository.Name; name != "" && name != fullName {
+		candidates = append(candidates, name)
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("push payload names no repository")
+	}
+
+	for _, root := range s.roots {
+		for _, name := range candidates {
+			dir, err := s.repository(filepath.Join(root, filepath.FromSlash(name)))
+			if errors.Is(err, errForbidden) {
+				return "", err
+			}
+			if err == nil {
+				return dir, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("repository not found: %s", candidates[0])
+}
+
+// release calculates the version of commit on branch in the repository in
+// dir and, when the branch allows auto-tag, creates and pushes its tag
+func (s *Server) release(dir, branch, commit string) (*WebhookResult, error) {
+	remote := s.opts.Remote
+	if remote == "" {
+		remote = DefaultRemote
+	}
+	auth, err := git.RemoteAuthFromEnv(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	repo := git.NewRepositoryAt(dir)
+	if err := repo.FetchBranch(remote, branch, auth); err != nil {
+		return nil, err
+	}
+
+	worktree, err := os.MkdirTemp("", "gitversion-webhook-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(worktree)
+	if err := repo.AddWorktree(worktree, commit); err != nil {
+		return nil, err
+	}
+	defer repo.RemoveWorktree(worktree)
+
+	// The release goes on when the sender stops waiting for the response
+	client, result, err := s.calculateIn(context.Background(), dir, worktree, branch, v1.JSON)
+	if err != nil {
+		return nil, err
+	}
+	outcome := &WebhookResult{Repository: dir, Branch: branch, Commit: commit, Version: result.FullSemVer}
+	if bc := result.Diagnostics().BranchConfig; bc == nil || !bc.AutoTag {
+		outcome.Skipped = fmt.Sprintf("auto-tag is not enabled for branch %s", branch)
+		return outcome, nil
+	}
+
+	// A commit that is already released keeps its tag, which is pushed
+	// again in case an earlier push failed
+	tags, err := repo.GetVersionTagsAt(commit)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		outcome.Tag = tags[len(tags)-1]
+	} else {
+		tagResult, err := result.ApplyTag()
+		if err != nil {
+			return nil, err
+		}
+		outcome.Tag, outcome.Created = tagResult.Tag, tagResult.Created
+	}
+	if err := client.PushTag(remote, outcome.Tag); err != nil {
+		return nil, err
+	}
+	outcome.Pushed = true
+	return outcome, nil
+}
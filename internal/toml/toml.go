@@ -0,0 +1,588 @@
+// Package toml reads TOML documents into yaml.Node trees, so TOML
+// configuration files are decoded, migrated and validated like YAML ones,
+// keeping the line of every key for error messages. It covers TOML 1.0,
+// except that dates and times are kept as strings.
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a syntax error in a TOML document
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Parse returns the root table of the document as a mapping node
+func Parse(data []byte) (*yaml.Node, error) {
+	p := &parser{src: string(data), line: 1, column: 1, defined: map[*yaml.Node]bool{}}
+	p.root = mapping(1, 1)
+	p.table = p.root
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+type parser struct {
+	src    string
+	pos    int
+	line   int
+	column int
+	root   *yaml.Node
+	// table receives the key/value pairs, the table of the last header
+	table *yaml.Node
+	// defined holds the tables declared by a [header], which may not be
+	// declared again
+	defined map[*yaml.Node]bool
+}
+
+// keyPart is one part of a dotted key
+type keyPart struct {
+	name   string
+	line   int
+	column int
+}
+
+func (p *parser) parse() error {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil
+		}
+		switch c := p.peek(); {
+		case c == '#' || c == '\n' || c == '\r':
+		case c == '[':
+			if err := p.header(); err != nil {
+				return err
+			}
+		default:
+			if err := p.keyValue(p.table); err != nil {
+				return err
+			}
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// header reads a [table] or [[array of tables]] header and makes it the
+// current table
+func (p *parser) header() error {
+	line, column := p.line, p.column
+	p.advance()
+	array := p.peek() == '['
+	if array {
+		p.advance()
+	}
+	parts, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return p.errorf("expected %s", closing)
+	}
+	for range closing {
+		p.advance()
+	}
+
+	parent, err := p.descend(p.root, parts[:len(parts)-1], true)
+	if err != nil {
+		return err
+	}
+	last := parts[len(parts)-1]
+	existing := lookup(parent, last.name)
+
+	if array {
+		if existing == nil {
+			existing = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line, Column: column}
+			appendPair(parent, last, existing)
+		} else if existing.Kind != yaml.SequenceNode {
+			return p.errorAt(last.line, last.column, "%s is not an array of tables", last.name)
+		}
+		p.table = mapping(line, column)
+		existing.Content = append(existing.Content, p.table)
+		return nil
+	}
+
+	switch {
+	case existing == nil:
+		existing = mapping(line, column)
+		appendPair(parent, last, existing)
+	case existing.Kind != yaml.MappingNode:
+		return p.errorAt(last.line, last.column, "%s is not a table", last.name)
+	case p.defined[existing]:
+		return p.errorAt(last.line, last.column, "table %s is defined twice", last.name)
+	}
+	p.defined[existing] = true
+	p.table = existing
+	return nil
+}
+
+// keyValue reads "key = value" into table
+func (p *parser) keyValue(table *yaml.Node) error {
+	parts, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != '=' {
+		return p.errorf("expected = after key %s", parts[len(parts)-1].name)
+	}
+	p.advance()
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.descend(table, parts[:len(parts)-1], false)
+	if err != nil {
+		return err
+	}
+	last := parts[len(parts)-1]
+	if lookup(parent, last.name) != nil {
+		return p.errorAt(last.line, last.column, "duplicate key %s", last.name)
+	}
+	appendPair(parent, last, value)
+	return nil
+}
+
+// descend follows the parts of a dotted key from table, creating missing
+// tables. Headers may pass through arrays of tables to their last entry.
+func (p *parser) descend(table *yaml.Node, parts []keyPart, header bool) (*yaml.Node, error) {
+	for _, part := range parts {
+		next := lookup(table, part.name)
+		switch {
+		case next == nil:
+			next = mapping(part.line, part.column)
+			appendPair(table, part, next)
+		case next.Kind == yaml.SequenceNode && header && len(next.Content) > 0:
+			next = next.Content[len(next.Content)-1]
+		}
+		if next.Kind != yaml.MappingNode {
+			return nil, p.errorAt(part.line, part.column, "%s is not a table", part.name)
+		}
+		table = next
+	}
+	return table, nil
+}
+
+// key reads a dotted key of bare and quoted parts
+func (p *parser) key() ([]keyPart, error) {
+	var parts []keyPart
+	for {
+		p.skipSpace()
+		part := keyPart{line: p.line, column: p.column}
+		switch c := p.peek(); {
+		case c == '"':
+			name, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			part.name = name
+		case c == '\'':
+			name, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			part.name = name
+		case isBare(c):
+			start := p.pos
+			for isBare(p.peek()) {
+				p.advance()
+			}
+			part.name = p.src[start:p.pos]
+		default:
+			return nil, p.errorf("expected a key")
+		}
+		parts = append(parts, part)
+
+		p.skipSpace()
+		if p.peek() != '.' {
+			return parts, nil
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) value() (*yaml.Node, error) {
+	line, column := p.line, p.column
+	scalar := func(tag, value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value, Line: line, Column: column}
+	}
+
+	rest := p.src[p.pos:]
+	switch c := p.peek(); {
+	case strings.HasPrefix(rest, `"""`):
+		s, err := p.multilineString('"')
+		return scalar("!!str", s), err
+	case strings.HasPrefix(rest, `'''`):
+		s, err := p.multilineString('\'')
+		return scalar("!!str", s), err
+	case c == '"':
+		s, err := p.basicString()
+		return scalar("!!str", s), err
+	case c == '\'':
+		s, err := p.literalString()
+		return scalar("!!str", s), err
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	case strings.HasPrefix(rest, "true") && !isBare(byteAt(rest, 4)):
+		p.skip(4)
+		return scalar("!!bool", "true"), nil
+	case strings.HasPrefix(rest, "false") && !isBare(byteAt(rest, 5)):
+		p.skip(5)
+		return scalar("!!bool", "false"), nil
+	case c == '+' || c == '-' || c == 'i' || c == 'n' || isDigit(c):
+		token := p.token()
+		if isDateTime(token) {
+			// A space may separate the date from the time
+			if p.peek() == ' ' && isDigit(byteAt(p.src, p.pos+1)) && byteAt(p.src, p.pos+3) == ':' {
+				p.advance()
+				token += " " + p.token()
+			}
+			return scalar("!!str", token), nil
+		}
+		tag, value, ok := number(token)
+		if !ok {
+			return nil, p.errorAt(line, column, "invalid value %q", token)
+		}
+		return scalar(tag, value), nil
+	}
+	return nil, p.errorf("expected a value")
+}
+
+func (p *parser) array() (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: p.line, Column: p.column}
+	p.advance()
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.advance()
+			return node, nil
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, value)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.advance()
+		case ']':
+			p.advance()
+			return node, nil
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *parser) inlineTable() (*yaml.Node, error) {
+	node := mapping(p.line, p.column)
+	p.advance()
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.advance()
+		return node, nil
+	}
+	for {
+		if err := p.keyValue(node); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.advance()
+		case '}':
+			p.advance()
+			return node, nil
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+func (p *parser) basicString() (string, error) {
+	p.advance()
+	var b strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.eof() || c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '"':
+			p.advance()
+			return b.String(), nil
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.advance()
+		}
+	}
+}
+
+func (p *parser) literalString() (string, error) {
+	p.advance()
+	start := p.pos
+	for p.peek() != '\'' {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		p.advance()
+	}
+	s := p.src[start:p.pos]
+	p.advance()
+	return s, nil
+}
+
+// multilineString reads a multi-line basic or literal string, which
+// quote names by its character
+func (p *parser) multilineString(quote byte) (string, error) {
+	p.skip(3)
+	// A newline right after the opening quotes is trimmed
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.skip(2)
+	} else if p.peek() == '\n' {
+		p.advance()
+	}
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		if c == quote && strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3)) {
+			// Up to two quotes may directly precede the closing ones
+			n := 3
+			for n < 5 && byteAt(p.src, p.pos+n) == quote {
+				n++
+			}
+			b.WriteString(strings.Repeat(string(quote), n-3))
+			p.skip(n)
+			return b.String(), nil
+		}
+		if c == '\\' && quote == '"' {
+			if next := byteAt(p.src, p.pos+1); next == '\n' || next == '\r' || next == ' ' || next == '\t' {
+				// A line ending backslash trims the following whitespace
+				p.advance()
+				for c := p.peek(); c == ' ' || c == '\t' || c == '\n' || c == '\r'; c = p.peek() {
+					p.advance()
+				}
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.advance()
+	}
+}
+
+// escape reads an escape sequence of a basic string into b
+func (p *parser) escape(b *strings.Builder) error {
+	p.advance()
+	c := p.peek()
+	simple := map[byte]byte{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', '"': '"', '\\': '\\'}
+	if r, ok := simple[c]; ok {
+		b.WriteByte(r)
+		p.advance()
+		return nil
+	}
+	size := map[byte]int{'u': 4, 'U': 8}[c]
+	if size == 0 {
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	if p.pos+1+size > len(p.src) {
+		return p.errorf("invalid escape sequence")
+	}
+	code, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+size], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid escape sequence \\%c%s", c, p.src[p.pos+1:p.pos+1+size])
+	}
+	b.WriteRune(rune(code))
+	p.skip(1 + size)
+	return nil
+}
+
+// token reads a number, date or time
+func (p *parser) token() string {
+	start := p.pos
+	for c := p.peek(); isBare(c) || c == '+' || c == '.' || c == ':'; c = p.peek() {
+		p.advance()
+	}
+	return p.src[start:p.pos]
+}
+
+// endOfLine expects only a comment until the end of the line
+func (p *parser) endOfLine() error {
+	p.skipSpace()
+	if p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.advance()
+		}
+	}
+	switch {
+	case p.eof():
+	case strings.HasPrefix(p.src[p.pos:], "\r\n"):
+		p.skip(2)
+	case p.peek() == '\n':
+		p.advance()
+	default:
+		return p.errorf("expected the end of the line")
+	}
+	return nil
+}
+
+func (p *parser) skipSpace() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.advance()
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments, as arrays allow
+func (p *parser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n', '\r':
+			p.advance()
+		case '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	return byteAt(p.src, p.pos)
+}
+
+func (p *parser) advance() {
+	if p.eof() {
+		return
+	}
+	if p.src[p.pos] == '\n' {
+		p.line++
+		p.column = 1
+	} else {
+		p.column++
+	}
+	p.pos++
+}
+
+func (p *parser) skip(n int) {
+	for i := 0; i < n; i++ {
+		p.advance()
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.line, p.column, format, args...)
+}
+
+func (p *parser) errorAt(line, column int, format string, args ...interface{}) error {
+	return &Error{Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+// number converts a TOML integer or float to the tag and value YAML
+// decodes it from
+func number(token string) (string, string, bool) {
+	plain := strings.ReplaceAll(token, "_", "")
+	sign, digits := "", plain
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	switch digits {
+	case "inf":
+		return "!!float", strings.TrimPrefix(sign, "+") + ".inf", true
+	case "nan":
+		return "!!float", ".nan", true
+	}
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(digits, prefix) && sign == "" {
+			n, err := strconv.ParseInt(digits[2:], base, 64)
+			return "!!int", strconv.FormatInt(n, 10), err == nil
+		}
+	}
+	if n, err := strconv.ParseInt(plain, 10, 64); err == nil {
+		return "!!int", strconv.FormatInt(n, 10), true
+	}
+	if f, err := strconv.ParseFloat(plain, 64); err == nil && isDigit(byteAt(digits, 0)) {
+		return "!!float", strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return "", "", false
+}
+
+// isDateTime tells dates such as 1979-05-27 and times such as 07:32:00
+// from numbers
+func isDateTime(token string) bool {
+	return (len(token) >= 10 && token[4] == '-' && isDigit(token[0])) ||
+		(len(token) >= 8 && token[2] == ':' && isDigit(token[0]))
+}
+
+func mapping(line, column int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Column: column}
+}
+
+func appendPair(table *yaml.Node, key keyPart, value *yaml.Node) {
+	table.Content = append(table.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.name, Line: key.line, Column: key.column}, value)
+}
+
+func lookup(table *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(table.Content); i += 2 {
+		if table.Content[i].Value == name {
+			return table.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isBare(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_' || c == '-'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func byteAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package toml
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const document = `# GitVersion configuration
+next-version = "1.0.0"
+tag-prefix = '[vV]'
+count = 1_000
+hex = 0x1F
+ratio = 1.5e3
+negative = -inf
+released = 1979-05-27 07:32:00Z
+"quoted key" = "tab\tand \u00e9"
+message = """
+first line \
+  continued
+second \"line"""""
+literal = '''
+C:\path'''
+strategies = [
+  "Fallback",   # comments are allowed in arrays
+  "TaggedCommit",
+]
+retry.git = { max-attempts = 3, jitter = 0.2 }
+
+[branches.main]
+increment = "Patch"
+is-main-branch = true
+
+[branches.main.prevent-increment]
+of-merged-branch = false
+
+[[publishers]]
+name = "github-release"
+
+[[publishers]]
+name = "gitea-release"
+options.url = "https://gitea.example.com"
+`
+
+func TestParse(t *testing.T) {
+	root, err := Parse([]byte(document))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := root.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"next-version": "1.0.0",
+		"tag-prefix":   "[vV]",
+		"count":        1000,
+		"hex":          31,
+		"ratio":        1500.0,
+		"negative":     got["negative"],
+		"released":     "1979-05-27 07:32:00Z",
+		"quoted key":   "tab\tand é",
+		"message":      "first line continued\nsecond \"line\"\"",
+		"literal":      `C:\path`,
+		"strategies":   []interface{}{"Fallback", "TaggedCommit"},
+		"retry": map[string]interface{}{
+			"git": map[string]interface{}{"max-attempts": 3, "jitter": 0.2},
+		},
+		"branches": map[string]interface{}{
+			"main": map[string]interface{}{
+				"increment":         "Patch",
+				"is-main-branch":    true,
+				"prevent-increment": map[string]interface{}{"of-merged-branch": false},
+			},
+		},
+		"publishers": []interface{}{
+			map[string]interface{}{"name": "github-release"},
+			map[string]interface{}{
+				"name":    "gitea-release",
+				"options": map[string]interface{}{"url": "https://gitea.example.com"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%#v\nwant\n%#v", got, want)
+	}
+	if f, ok := got["negative"].(float64); !ok || f > 0 {
+		t.Errorf("negative = %v, want -Inf", got["negative"])
+	}
+
+	// Keys keep their position for error messages
+	if key := root.Content[0]; key.Value != "next-version" || key.Line != 2 || key.Column != 1 {
+		t.Errorf("First key %q at %d:%d", key.Value, key.Line, key.Column)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+		want  string
+	}{
+		{"a = 1\na = 2\n", 2, "duplicate key a"},
+		{"[t]\n[t]\n", 2, "defined twice"},
+		{"a = 1\n[a]\n", 2, "a is not a table"},
+		{"a = \"open\n", 1, "unterminated string"},
+		{"a = [1, 2\n", 2, "expected , or ]"},
+		{"a = 1 b = 2\n", 1, "end of the line"},
+		{"a = yes\n", 1, "expected a value"},
+		{"a = 12abc\n", 1, "invalid value"},
+		{"a = \"\\q\"\n", 1, "invalid escape"},
+		{"= 1\n", 1, "expected a key"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.input))
+		var parseErr *Error
+		if !errors.As(err, &parseErr) || parseErr.Line != tt.line || !strings.Contains(parseErr.Message, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q on line %d", tt.input, err, tt.want, tt.line)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package version
+
+import (
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+)
+
+// BranchState classifies a branch by how much of it has shipped
+type BranchState string
+
+const (
+	// BranchActive branches have commits not yet merged or released
+	BranchActive BranchState = "active"
+	// BranchMerged branches are fully merged into main or develop
+	BranchMerged BranchState = "merged"
+	// BranchReleased branches are contained in a version tag
+	BranchReleased BranchState = "released"
+)
+
+// BranchStatus describes one branch in a BranchReport
+type BranchStatus struct {
+	Branch    string      `json:"Branch"`
+	ConfigKey string      `json:"ConfigKey"` // "" for the built-in fallback
+	SHA       string      `json:"SHA"`
+	State     BranchState `json:"State"`
+	// MergedInto is the first target branch whose merge-base with the
+	// branch is the branch tip
+	MergedInto string `json:"MergedInto,omitempty"`
+	// ReleasedIn is the oldest version tag containing the branch tip
+	ReleasedIn string `json:"ReleasedIn,omitempty"`
+	// SafeToDelete is set for merged or released branches whose
+	// configuration marks them as release branches (release and hotfix)
+	SafeToDelete bool `json:"SafeToDelete"`
+}
+
+// reportTargets are the long-lived branches other branches are merged into
+var reportTargets = []string{"main", "develop"}
+
+// BranchReport classifies every local and remote tracking branch other
+// than main and develop. A branch is merged when its merge-base with main
+// or develop equals its tip, and released when a version tag contains its
+// tip.
+func (c *Calculator) BranchReport() ([]*BranchStatus, error) {
+	branches, err := c.repo.GetBranchTips()
+	if err != nil {
+		return nil, err
+	}
+
+	targets, targetNames := c.reportTargetRefs()
+
+	var report []*BranchStatus
+	for _, branch := range branches {
+		if targetNames[branch.Name] {
+			continue
+		}
+		report = append(report, c.branchStatus(branch, targets))
+	}
+	return report, nil
+}
+
+// reportTargetRefs resolves the report targets to existing refs, preferring
+// local branches, and returns every alias name so targets can be skipped.
+func (c *Calculator) reportTargetRefs() ([]string, map[string]bool) {
+	var refs []string
+	names := make(map[string]bool)
+
+	for _, target := range reportTargets {
+		found := false
+		for _, name := range sourceBranchAliases[target] {
+			names[name] = true
+			if found {
+				continue
+			}
+			for _, ref := range []string{name, c.repo.Remote() + "/" + name} {
+				if c.repo.RefExists(ref) {
+					refs = append(refs, ref)
+					found = true
+					break
+				}
+			}
+		}
+	}
+	return refs, names
+}
+
+func (c *Calculator) branchStatus(branch *git.Branch, targets []string) *BranchStatus {
+	key, branchConfig := c.config.ResolveBranchConfiguration(branch.Name)
+	status := &BranchStatus{
+		Branch:    branch.ShortRef(),
+		ConfigKey: key,
+		SHA:       branch.SHA,
+		State:     BranchActive,
+	}
+
+	for _, target := range targets {
+		if mergeBase, err := c.repo.GetMergeBase(branch.SHA, target); err == nil && mergeBase == branch.SHA {
+			status.MergedInto = target
+			status.State = BranchMerged
+			break
+		}
+	}
+
+	if tags, err := c.repo.GetVersionTagsContaining(branch.SHA); err == nil && len(tags) > 0 {
+		status.ReleasedIn = tags[0]
+		status.State = BranchReleased
+	}
+
+	status.SafeToDelete = status.State != BranchActive && branchConfig != nil && branchConfig.IsReleaseBranch
+	return status
+}
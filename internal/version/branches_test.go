@@ -0,0 +1,61 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func TestBranchReport(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "branch", "feature/done") // shipped in v1.0.0 through release/1.0.0
+
+	// Released from the release branch, never merged back
+	runGit(t, "checkout", "-q", "-b", "release/1.0.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "release fixes")
+	runGit(t, "tag", "v1.0.0")
+
+	// Merged into main but not yet tagged
+	runGit(t, "checkout", "-q", "-b", "hotfix/1.0.1", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+	runGit(t, "checkout", "-q", "main")
+	runGit(t, "merge", "-q", "--no-ff", "-m", "Merge branch 'hotfix/1.0.1'", "hotfix/1.0.1")
+
+	runGit(t, "checkout", "-q", "-b", "feature/wip")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: unfinished")
+	runGit(t, "checkout", "-q", "-b", "release/2.0.0", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "release: prepare 2.0.0")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	calculator := NewCalculator(git.NewRepository(), cfg)
+	report, err := calculator.BranchReport()
+	if err != nil {
+		t.Fatalf("BranchReport() error = %v", err)
+	}
+
+	expected := []BranchStatus{
+		{Branch: "feature/done", ConfigKey: "feature", State: BranchReleased, MergedInto: "main", ReleasedIn: "v1.0.0"},
+		{Branch: "feature/wip", ConfigKey: "feature", State: BranchActive},
+		{Branch: "hotfix/1.0.1", ConfigKey: "hotfix", State: BranchMerged, MergedInto: "main", SafeToDelete: true},
+		{Branch: "release/1.0.0", ConfigKey: "release", State: BranchReleased, ReleasedIn: "v1.0.0", SafeToDelete: true},
+		{Branch: "release/2.0.0", ConfigKey: "release", State: BranchActive},
+	}
+
+	if len(report) != len(expected) {
+		t.Fatalf("BranchReport() returned %d branches, want %d: %+v", len(report), len(expected), report)
+	}
+	for i, want := range expected {
+		got := *report[i]
+		got.SHA = ""
+		if got != want {
+			t.Errorf("report[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+}
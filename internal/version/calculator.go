@@ -1,11 +1,14 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/VirtuallyScott/gitversion-go/internal/git"
 	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
 	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
 
@@ -33,6 +36,8 @@ type Calculator struct {
 	repo            *git.Repository
 	config          *config.Config
 	strategyManager *StrategyManager
+	progress        progress.Reporter
+	maxDuration     time.Duration
 }
 
 func NewCalculator(repo *git.Repository, cfg *config.Config) *Calculator {
@@ -40,10 +45,60 @@ func NewCalculator(repo *git.Repository, cfg *config.Config) *Calculator {
 		repo:            repo,
 		config:          cfg,
 		strategyManager: NewStrategyManager(repo, cfg),
+		progress:        progress.Nop,
 	}
 }
 
+// SetProgress sets the reporter used for long running operations
+func (c *Calculator) SetProgress(reporter progress.Reporter) {
+	if reporter == nil {
+		reporter = progress.Nop
+	}
+	c.progress = reporter
+}
+
+// SetMaxDuration bounds the time spent running strategies. Once exceeded the
+// remaining strategies are skipped and the result is marked partial. Zero
+// disables the limit.
+func (c *Calculator) SetMaxDuration(d time.Duration) {
+	c.maxDuration = d
+}
+
 func (c *Calculator) CalculateVersion(branch string, workflow WorkflowType, forceIncrement string, nextVersion string) (*semver.Version, error) {
+	diagnostics, err := c.Explain(branch, workflow, forceIncrement, nextVersion)
+	if err != nil {
+		return nil, err
+	}
+	return diagnostics.Version, nil
+}
+
+// Explain calculates the version and records every decision taken along the
+// way: the candidate base versions, the selected one and the increment applied.
+func (c *Calculator) Explain(branch string, workflow WorkflowType, forceIncrement string, nextVersion string) (*Diagnostics, error) {
+	return c.ExplainContext(context.Background(), branch, workflow, forceIncrement, nextVersion)
+}
+
+// ExplainContext is like Explain but gives up once ctx is done, killing the
+// git commands still running
+func (c *Calculator) ExplainContext(ctx context.Context, branch string, workflow WorkflowType, forceIncrement string, nextVersion string) (*Diagnostics, error) {
+	// A copy bound to ctx, so concurrent calculations keep their own
+	bound := *c
+	bound.repo = c.repo.WithContext(ctx)
+	diagnostics, err := bound.explain(ctx, branch, workflow, forceIncrement, nextVersion)
+	// Killed git commands read as missing tags or history, so a result
+	// finished after ctx was done cannot be trusted
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return diagnostics, err
+}
+
+func (c *Calculator) explain(ctx context.Context, branch string, workflow WorkflowType, forceIncrement string, nextVersion string) (*Diagnostics, error) {
+	var deadline time.Time
+	if c.maxDuration > 0 {
+		deadline = time.Now().Add(c.maxDuration)
+	}
+
 	// Get current branch if not provided
 	if branch == "" {
 		currentBranch, err := c.repo.GetCurrentBranch()
@@ -59,41 +114,57 @@ func (c *Calculator) CalculateVersion(branch string, workflow WorkflowType, forc
 		return nil, fmt.Errorf("failed to get current commit: %w", err)
 	}
 
-	// Get branch configuration
-	branchConfig := c.config.GetBranchConfiguration(branch)
-	if branchConfig == nil {
-		// Fall back to default configuration based on branch type
-		branchType := c.getBranchType(branch, workflow)
-		branchConfig = c.getDefaultBranchConfig(branchType)
-	}
+	branchConfigKey, branchConfig := c.BranchConfiguration(branch, workflow)
 
 	// Use the strategies system for GitTools/GitVersion compatibility
-	var strategiesMask VersionStrategies
-
-	// Add configured version strategy if next version is provided
-	if nextVersion != "" {
-		strategiesMask |= ConfiguredNextVersion
+	strategiesMask, customStrategies, err := c.resolveStrategies(branchConfig, nextVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	// Add default strategies
-	strategiesMask |= TaggedCommit | MergeMessage | Fallback
+	// Prerelease tags the branch ignores are hidden from the strategies
+	strategyRepo := c.repo
+	if branchConfig != nil && branchConfig.IgnorePreReleaseTags {
+		strategyRepo = strategyRepo.WithoutPreReleaseTags()
+	}
 
 	// Create version context for strategies
-	ctx := &VersionContext{
-		Repository:    c.repo,
+	versionContext := &VersionContext{
+		Context:       ctx,
+		Repository:    strategyRepo,
 		Config:        c.config,
 		CurrentBranch: branch,
 		CurrentCommit: currentCommit,
 		BranchConfig:  branchConfig,
 		NextVersion:   nextVersion,
 		Strategies:    strategiesMask,
+		Custom:        customStrategies,
+		Progress:      c.progress,
+	}
+
+	diagnostics := &Diagnostics{
+		Branch:          branch,
+		Workflow:        workflow,
+		BranchConfig:    branchConfig,
+		BranchConfigKey: branchConfigKey,
+	}
+
+	enabled, err := c.strategyManager.EnabledStrategies(versionContext)
+	if err != nil {
+		return nil, err
+	}
+	for _, strategy := range enabled {
+		diagnostics.Strategies = append(diagnostics.Strategies, strategy.GetName())
 	}
 
 	// Calculate base versions using strategies
-	baseVersions, err := c.strategyManager.GetBaseVersions(ctx)
+	baseVersions, skipped, err := c.strategyManager.GetBaseVersionsWithin(versionContext, deadline)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get base versions: %w", err)
 	}
+	diagnostics.Candidates = baseVersions
+	diagnostics.Partial = len(skipped) > 0
+	diagnostics.SkippedStrategies = skipped
 
 	// Find the highest base version
 	var baseVersion *BaseVersion
@@ -112,49 +183,200 @@ func (c *Calculator) CalculateVersion(branch string, workflow WorkflowType, forc
 			ShouldIncrement:   true,
 			BaseVersionSource: "fallback",
 		}
+		diagnostics.SelectionReason = "no candidates, using 0.0.0"
+	} else {
+		diagnostics.SelectionReason = fmt.Sprintf("highest of %d candidates", len(baseVersions))
 	}
+	diagnostics.Selected = baseVersion
 
 	// Apply increments based on configuration
 	version := baseVersion.SemanticVersion.Copy()
 
 	// Handle force increment
 	if forceIncrement != "" {
-		switch forceIncrement {
-		case "major":
-			version.IncrementMajor()
-		case "minor":
-			version.IncrementMinor()
-		case "patch":
-			version.IncrementPatch()
-		}
+		diagnostics.Increment = applyIncrement(version, config.IncrementStrategy(forceIncrement))
+		diagnostics.IncrementCause = fmt.Sprintf("forced with --%s", forceIncrement)
 	} else if branchConfig.PreventIncrement == nil || (!branchConfig.PreventIncrement.OfMergedBranch && !branchConfig.PreventIncrement.WhenCurrentCommitTagged) {
-		// Apply default increment if not prevented
+		// Apply default increment if not prevented, unless the changed
+		// paths call for another
 		increment := branchConfig.Increment
-		switch increment {
-		case config.IncrementMajor:
-			version.IncrementMajor()
-		case config.IncrementMinor:
-			version.IncrementMinor()
-		case config.IncrementPatch, "":
-			version.IncrementPatch()
+		cause := fmt.Sprintf("branch configuration increment '%s'", increment)
+		if pathIncrement, ok := c.pathIncrement(baseVersion.BaseVersionSource, increment); ok {
+			increment = pathIncrement
+			cause = fmt.Sprintf("path-increments for the files changed since the version source, '%s'", increment)
+		}
+		// Commit messages can ask for a larger increment, never a smaller one
+		messageIncrement, messageCause, ok, err := c.messageIncrement(baseVersion.BaseVersionSource)
+		if err != nil {
+			return nil, err
 		}
+		if ok && rank(messageIncrement) > rank(increment) {
+			increment, cause = messageIncrement, messageCause
+		}
+		diagnostics.Increment = applyIncrement(version, increment)
+		diagnostics.IncrementCause = cause
+	} else {
+		diagnostics.Increment = "none"
+		diagnostics.IncrementCause = "prevented by branch prevent-increment configuration"
 	}
 
 	// Apply branch-specific versioning (prerelease, build metadata)
 	branchType := c.getBranchType(branch, workflow)
+	commitCount, commitCountSource := c.countCommits(branch, branchConfig, baseVersion)
+
+	sha, err := c.repo.GetShortSHA()
+	if err != nil {
+		sha = "unknown"
+	}
+
+	c.applyBranchSpecificVersioning(version, branch, branchType, commitCount, sha)
+
+	// Release candidates are numbered by build rather than by commit count
+	if usesReleaseIterations(branchType, branchConfig) && version.PreRelease != "" {
+		label := version.PreReleaseLabel()
+		diagnostics.Iteration, diagnostics.IterationSource = c.releaseIteration(version, label, currentCommit)
+		version.PreRelease = fmt.Sprintf("%s.%d", label, diagnostics.Iteration)
+	}
+
+	// Local builds from a modified tree must not pass for the clean CI build
+	if c.config.DirtyBuildMetadata {
+		if changes, err := c.repo.GetUncommittedChanges(); err == nil && changes > 0 {
+			version.Build += ".dirty"
+		}
+	}
+
+	diagnostics.BranchType = branchType
+	diagnostics.CommitCount = commitCount
+	diagnostics.CommitCountSource = commitCountSource
+	diagnostics.Version = version
+
+	return diagnostics, nil
+}
+
+// applyIncrement bumps version according to increment and returns the name of
+// the component that was incremented, or "none".
+func applyIncrement(version *semver.Version, increment config.IncrementStrategy) string {
+	switch strings.ToLower(string(increment)) {
+	case "major":
+		version.IncrementMajor()
+		return "major"
+	case "minor":
+		version.IncrementMinor()
+		return "minor"
+	case "patch", "":
+		version.IncrementPatch()
+		return "patch"
+	}
+	return "none"
+}
+
+// BranchConfiguration returns the configuration a calculation of branch
+// uses and its key, "" for the default of the branch type in workflow
+func (c *Calculator) BranchConfiguration(branch string, workflow WorkflowType) (string, *config.BranchConfiguration) {
+	key, branchConfig := c.config.ResolveBranchConfiguration(branch)
+	if branchConfig == nil {
+		// Fall back to default configuration based on branch type
+		branchType := c.getBranchType(branch, workflow)
+		branchConfig = c.getDefaultBranchConfig(branchType)
+	}
+	return key, branchConfig
+}
+
+// sourceBranchAliases lists the branch names tried for well-known source branches
+var sourceBranchAliases = map[string][]string{
+	"main":    {"main", "master"},
+	"develop": {"develop", "dev", "development"},
+}
+
+// SourceBranchNames returns the branch names tried for a source branch of
+// a branch configuration
+func SourceBranchNames(source string) []string {
+	if names, ok := sourceBranchAliases[source]; ok {
+		return names
+	}
+	return []string{source}
+}
+
+// countCommits counts the commits used for prerelease numbering according to
+// the branch's commits-since mode and describes where counting started.
+func (c *Calculator) countCommits(branch string, branchConfig *config.BranchConfiguration, baseVersion *BaseVersion) (int, string) {
+	switch branchConfig.CommitsSince {
+	case config.CountFromBranchPoint:
+		if branchPoint, source := c.findBranchPoint(branch, branchConfig.SourceBranches); branchPoint != "" {
+			count, _ := c.repo.GetCommitCountSinceTag(branchPoint)
+			return count, fmt.Sprintf("branch point with %s", source)
+		}
+	case config.CountFromVersionSource:
+		if source := baseVersion.BaseVersionSource; source != "" && c.repo.RefExists(source) {
+			count, _ := c.repo.GetCommitCountSinceTag(source)
+			return count, fmt.Sprintf("version source %s", source)
+		}
+	}
+
 	commitCount, err := c.repo.GetCommitCountSinceTag("")
 	if err != nil {
 		commitCount = 0
 	}
+	return commitCount, "repository root"
+}
 
-	sha, err := c.repo.GetShortSHA()
+// findBranchPoint returns the merge-base of HEAD with the nearest existing
+// source branch, along with that branch's name.
+func (c *Calculator) findBranchPoint(branch string, sourceBranches []string) (string, string) {
+	bestBase, bestSource := "", ""
+	bestCount := -1
+
+	for _, source := range sourceBranches {
+		for _, name := range SourceBranchNames(source) {
+			if name == branch {
+				continue
+			}
+			for _, ref := range []string{name, c.repo.Remote() + "/" + name} {
+				if !c.repo.RefExists(ref) {
+					continue
+				}
+				mergeBase, err := c.repo.GetMergeBase(ref, "HEAD")
+				if err != nil || mergeBase == "" {
+					continue
+				}
+				count, _ := c.repo.GetCommitCountSinceTag(mergeBase)
+				if bestCount == -1 || count < bestCount {
+					bestBase, bestSource, bestCount = mergeBase, ref, count
+				}
+				break
+			}
+		}
+	}
+
+	return bestBase, bestSource
+}
+
+// resolveStrategies resolves the enabled built-in and custom strategies from
+// configuration, falling back to the defaults when none are configured, and
+// applies the branch's overrides.
+func (c *Calculator) resolveStrategies(branchConfig *config.BranchConfiguration, nextVersion string) (VersionStrategies, []string, error) {
+	strategiesMask, custom, err := ResolveStrategies(c.config.Strategies)
 	if err != nil {
-		sha = "unknown"
+		return None, nil, err
+	}
+	if strategiesMask == None && len(custom) == 0 {
+		strategiesMask = GetDefaultStrategies()
 	}
 
-	c.applyBranchSpecificVersioning(version, branch, branchType, commitCount, sha)
+	// Branch overrides are merged over the global list
+	if branchConfig != nil && len(branchConfig.Strategies) > 0 {
+		strategiesMask, custom, err = ApplyStrategyOverrides(strategiesMask, custom, branchConfig.Strategies)
+		if err != nil {
+			return None, nil, fmt.Errorf("invalid strategies for branch: %w", err)
+		}
+	}
+
+	// Add configured version strategy if next version is provided
+	if nextVersion != "" {
+		strategiesMask |= ConfiguredNextVersion
+	}
 
-	return version, nil
+	return strategiesMask, custom, nil
 }
 
 func (c *Calculator) getBranchType(branch string, workflow WorkflowType) BranchType {
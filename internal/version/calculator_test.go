@@ -1,8 +1,12 @@
 package version
 
 import (
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
 	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
 
@@ -235,3 +239,283 @@ func TestApplyBranchSpecificVersioning(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveStrategies(t *testing.T) {
+	if err := RegisterStrategy("ChartVersion", &FallbackStrategy{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer UnregisterStrategy("ChartVersion")
+
+	tests := []struct {
+		name           string
+		strategies     []string
+		nextVersion    string
+		expected       VersionStrategies
+		expectedCustom []string
+		expectError    bool
+	}{
+		{
+			name:       "Configured strategies",
+			strategies: []string{"TaggedCommit", "Mainline"},
+			expected:   TaggedCommit | Mainline,
+		},
+		{
+			name:       "MergeMessage disabled",
+			strategies: []string{"Fallback", "TaggedCommit"},
+			expected:   Fallback | TaggedCommit,
+		},
+		{
+			name:       "Empty list uses defaults",
+			strategies: nil,
+			expected:   GetDefaultStrategies(),
+		},
+		{
+			name:        "Next version enables ConfiguredNextVersion",
+			strategies:  []string{"TaggedCommit"},
+			nextVersion: "2.0.0",
+			expected:    TaggedCommit | ConfiguredNextVersion,
+		},
+		{
+			name:           "Custom strategy alongside built-ins",
+			strategies:     []string{"TaggedCommit", "ChartVersion"},
+			expected:       TaggedCommit,
+			expectedCustom: []string{"ChartVersion"},
+		},
+		{
+			name:           "Only custom strategies",
+			strategies:     []string{"chartversion"},
+			expected:       None,
+			expectedCustom: []string{"chartversion"},
+		},
+		{
+			name:        "Unknown strategy",
+			strategies:  []string{"TaggedCommit", "DoesNotExist"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := &Calculator{config: &config.Config{Strategies: tt.strategies}}
+			result, custom, err := calculator.resolveStrategies(nil, tt.nextVersion)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("resolveStrategies() = %d, want %d", result, tt.expected)
+			}
+			if strings.Join(custom, ",") != strings.Join(tt.expectedCustom, ",") {
+				t.Errorf("custom strategies = %v, want %v", custom, tt.expectedCustom)
+			}
+		})
+	}
+}
+
+func TestCountCommits(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "checkout", "-q", "-b", "master")
+	for i := 0; i < 3; i++ {
+		runGit(t, "commit", "-q", "--allow-empty", "-m", "main commit")
+	}
+	runGit(t, "checkout", "-q", "-b", "feature/login")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: login form")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: login api")
+
+	calculator := NewCalculator(git.NewRepository(), &config.Config{})
+	baseVersion := &BaseVersion{BaseVersionSource: "fallback"}
+
+	tests := []struct {
+		name           string
+		branchConfig   *config.BranchConfiguration
+		expectedCount  int
+		expectedSource string
+	}{
+		{
+			name:           "Repository root by default",
+			branchConfig:   &config.BranchConfiguration{},
+			expectedCount:  5,
+			expectedSource: "repository root",
+		},
+		{
+			name: "Branch point with main alias",
+			branchConfig: &config.BranchConfiguration{
+				CommitsSince:   config.CountFromBranchPoint,
+				SourceBranches: []string{"develop", "main"},
+			},
+			expectedCount:  2,
+			expectedSource: "branch point with master",
+		},
+		{
+			name: "Branch point without existing source falls back",
+			branchConfig: &config.BranchConfiguration{
+				CommitsSince:   config.CountFromBranchPoint,
+				SourceBranches: []string{"develop"},
+			},
+			expectedCount:  5,
+			expectedSource: "repository root",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, source := calculator.countCommits("feature/login", tt.branchConfig, baseVersion)
+			if count != tt.expectedCount {
+				t.Errorf("countCommits() count = %d, want %d", count, tt.expectedCount)
+			}
+			if source != tt.expectedSource {
+				t.Errorf("countCommits() source = %s, want %s", source, tt.expectedSource)
+			}
+		})
+	}
+}
+
+func TestResolveStrategiesWithBranchOverrides(t *testing.T) {
+	if err := RegisterStrategy("ChartVersion", &FallbackStrategy{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer UnregisterStrategy("ChartVersion")
+
+	global := []string{"Fallback", "TaggedCommit", "MergeMessage", "ChartVersion"}
+
+	tests := []struct {
+		name           string
+		overrides      []string
+		expected       VersionStrategies
+		expectedCustom []string
+		expectError    bool
+	}{
+		{
+			name:           "No overrides",
+			expected:       Fallback | TaggedCommit | MergeMessage,
+			expectedCustom: []string{"ChartVersion"},
+		},
+		{
+			name:      "Replace",
+			overrides: []string{"TaggedCommit", "Fallback"},
+			expected:  TaggedCommit | Fallback,
+		},
+		{
+			name:           "Add",
+			overrides:      []string{"+TrackReleaseBranches"},
+			expected:       Fallback | TaggedCommit | MergeMessage | TrackReleaseBranches,
+			expectedCustom: []string{"ChartVersion"},
+		},
+		{
+			name:      "Remove",
+			overrides: []string{"-MergeMessage", "-chartversion"},
+			expected:  Fallback | TaggedCommit,
+		},
+		{
+			name:           "Replace then add",
+			overrides:      []string{"Mainline", "+ChartVersion"},
+			expected:       Mainline,
+			expectedCustom: []string{"ChartVersion"},
+		},
+		{
+			name:        "Unknown strategy",
+			overrides:   []string{"+DoesNotExist"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := &Calculator{config: &config.Config{Strategies: global}}
+			branchConfig := &config.BranchConfiguration{Strategies: tt.overrides}
+
+			result, custom, err := calculator.resolveStrategies(branchConfig, "")
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("resolveStrategies() = %d, want %d", result, tt.expected)
+			}
+			if strings.Join(custom, ",") != strings.Join(tt.expectedCustom, ",") {
+				t.Errorf("custom strategies = %v, want %v", custom, tt.expectedCustom)
+			}
+		})
+	}
+}
+
+func TestDirtyBuildMetadata(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.DirtyBuildMetadata = true
+	calculator := NewCalculator(git.NewRepository(), cfg)
+
+	build := func() string {
+		t.Helper()
+		diagnostics, err := calculator.Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		return diagnostics.Version.Build
+	}
+
+	if clean := build(); strings.HasSuffix(clean, ".dirty") {
+		t.Errorf("Build = %q on a clean tree, want no .dirty marker", clean)
+	}
+
+	if err := os.WriteFile("scratch.txt", []byte("work in progress"), 0o644); err != nil {
+		t.Fatalf("Failed to write scratch.txt: %v", err)
+	}
+	if dirty := build(); !strings.HasSuffix(dirty, ".dirty") {
+		t.Errorf("Build = %q with uncommitted changes, want a .dirty marker", dirty)
+	}
+
+	cfg.DirtyBuildMetadata = false
+	if unmarked := build(); strings.HasSuffix(unmarked, ".dirty") {
+		t.Errorf("Build = %q with dirty-build-metadata off, want no .dirty marker", unmarked)
+	}
+}
+
+func TestIgnorePreReleaseTags(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "release")
+	runGit(t, "tag", "v2.0.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: next")
+	runGit(t, "tag", "v2.1.0-rc.1")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: after the candidate")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	calculator := NewCalculator(git.NewRepository(), cfg)
+	selected := func() string {
+		t.Helper()
+		diagnostics, err := calculator.Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		return diagnostics.Selected.Tag
+	}
+
+	if tag := selected(); tag != "v2.1.0-rc.1" {
+		t.Errorf("Selected tag = %q, want the release candidate v2.1.0-rc.1", tag)
+	}
+	cfg.Branches["main"].IgnorePreReleaseTags = true
+	if tag := selected(); tag != "v2.0.0" {
+		t.Errorf("Selected tag with ignore-pre-release-tags = %q, want v2.0.0", tag)
+	}
+}
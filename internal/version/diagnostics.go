@@ -0,0 +1,90 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// Diagnostics records how a version was calculated
+type Diagnostics struct {
+	Branch          string                      `json:"Branch"`
+	BranchType      BranchType                  `json:"BranchType"`
+	Workflow        WorkflowType                `json:"Workflow"`
+	BranchConfig    *config.BranchConfiguration `json:"BranchConfig"`
+	BranchConfigKey string                      `json:"BranchConfigKey"` // "" for the built-in fallback
+	Strategies      []string                    `json:"Strategies"`
+	Candidates      []*BaseVersion              `json:"Candidates"`
+	// Partial is set when the time budget ran out before all strategies
+	// completed; SkippedStrategies lists the ones that did not.
+	Partial           bool            `json:"Partial"`
+	SkippedStrategies []string        `json:"SkippedStrategies,omitempty"`
+	Selected          *BaseVersion    `json:"Selected"`
+	SelectionReason   string          `json:"SelectionReason"`
+	Increment         string          `json:"Increment"`
+	IncrementCause    string          `json:"IncrementCause"`
+	CommitCount       int             `json:"CommitCount"`
+	CommitCountSource string          `json:"CommitCountSource"`
+	Version           *semver.Version `json:"Version"`
+
+	// Iteration is the release candidate number used as the prerelease
+	// number on ContinuousDelivery release branches, 0 elsewhere.
+	Iteration       int    `json:"Iteration,omitempty"`
+	IterationSource string `json:"IterationSource,omitempty"`
+
+	// Shallow is set when the clone is shallow, so the commits and tags
+	// beyond its depth were not seen and counts may be too low
+	Shallow bool `json:"Shallow,omitempty"`
+}
+
+// String renders the diagnostics as a human readable report
+func (d *Diagnostics) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Branch:      %s (%s, workflow %s)\n", d.Branch, d.BranchType, d.Workflow)
+	if bc := d.BranchConfig; bc != nil {
+		key := d.BranchConfigKey
+		if key == "" {
+			key = "(default)"
+		}
+		fmt.Fprintf(&b, "Config:      %s (label %q, increment %s, mode %s, weight %d)\n",
+			key, bc.Label, bc.Increment, bc.Mode, bc.PreReleaseWeight)
+	}
+	fmt.Fprintf(&b, "Strategies:  %s\n", strings.Join(d.Strategies, ", "))
+	b.WriteString("Candidates:\n")
+	if len(d.Candidates) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, bv := range d.Candidates {
+		fmt.Fprintf(&b, "  [%s] %s from %s (increment: %t", bv.Strategy, bv.SemanticVersion, bv.Source, bv.ShouldIncrement)
+		if bv.BaseVersionSource != "" {
+			fmt.Fprintf(&b, ", source: %s", bv.BaseVersionSource)
+		}
+		b.WriteString(")\n")
+	}
+	if d.Partial {
+		fmt.Fprintf(&b, "Partial:     time budget exceeded, skipped %s\n", strings.Join(d.SkippedStrategies, ", "))
+	}
+	if d.Shallow {
+		b.WriteString("Shallow:     the clone is shallow; history beyond its depth was not seen\n")
+	}
+	if d.Selected != nil {
+		fmt.Fprintf(&b, "Selected:    %s from %s (%s)\n", d.Selected.SemanticVersion, d.Selected.Source, d.SelectionReason)
+	}
+	fmt.Fprintf(&b, "Increment:   %s (%s)\n", d.Increment, d.IncrementCause)
+	fmt.Fprintf(&b, "Commits:     %d", d.CommitCount)
+	if d.CommitCountSource != "" {
+		fmt.Fprintf(&b, " (since %s)", d.CommitCountSource)
+	}
+	b.WriteString("\n")
+	if d.Iteration > 0 {
+		fmt.Fprintf(&b, "Iteration:   %d (%s)\n", d.Iteration, d.IterationSource)
+	}
+	if d.Version != nil {
+		fmt.Fprintf(&b, "Version:     %s\n", d.Version)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package version
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+func TestDiagnosticsString(t *testing.T) {
+	tagged := &BaseVersion{
+		SemanticVersion:   &semver.Version{Major: 1, Minor: 2, Patch: 0},
+		Source:            "Tag 'v1.2.0'",
+		ShouldIncrement:   true,
+		BaseVersionSource: "abc123",
+		Strategy:          "TaggedCommit",
+	}
+	diagnostics := &Diagnostics{
+		Branch:     "develop",
+		BranchType: Develop,
+		Workflow:   GitFlow,
+		BranchConfig: &config.BranchConfiguration{
+			Label:            "alpha",
+			Increment:        config.IncrementMinor,
+			Mode:             config.DeploymentContinuousDelivery,
+			PreReleaseWeight: 0,
+		},
+		BranchConfigKey: "develop",
+		Strategies:      []string{"TaggedCommit", "Fallback"},
+		Candidates:      []*BaseVersion{tagged},
+		Selected:        tagged,
+		SelectionReason: "highest of 1 candidates",
+		Increment:       "minor",
+		IncrementCause:  "branch configuration increment 'Minor'",
+		CommitCount:     3,
+		Version:         &semver.Version{Major: 1, Minor: 3, Patch: 0, PreRelease: "alpha.3"},
+	}
+
+	report := diagnostics.String()
+	expected := []string{
+		"Branch:      develop (develop, workflow gitflow)",
+		"Config:      develop (label \"alpha\", increment Minor, mode ContinuousDelivery, weight 0)",
+		"Strategies:  TaggedCommit, Fallback",
+		"[TaggedCommit] 1.2.0 from Tag 'v1.2.0' (increment: true, source: abc123)",
+		"Selected:    1.2.0 from Tag 'v1.2.0' (highest of 1 candidates)",
+		"Increment:   minor (branch configuration increment 'Minor')",
+		"Version:     1.3.0-alpha.3",
+	}
+	for _, line := range expected {
+		if !strings.Contains(report, line) {
+			t.Errorf("Report missing %q:\n%s", line, report)
+		}
+	}
+}
+
+func TestApplyIncrement(t *testing.T) {
+	tests := []struct {
+		increment config.IncrementStrategy
+		expected  string
+		applied   string
+	}{
+		{increment: config.IncrementMajor, expected: "2.0.0", applied: "major"},
+		{increment: config.IncrementMinor, expected: "1.3.0", applied: "minor"},
+		{increment: config.IncrementPatch, expected: "1.2.4", applied: "patch"},
+		{increment: "", expected: "1.2.4", applied: "patch"},
+		{increment: config.IncrementNone, expected: "1.2.3", applied: "none"},
+		{increment: config.IncrementInherit, expected: "1.2.3", applied: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.increment), func(t *testing.T) {
+			version := &semver.Version{Major: 1, Minor: 2, Patch: 3}
+			applied := applyIncrement(version, tt.increment)
+			if applied != tt.applied {
+				t.Errorf("applyIncrement() = %s, want %s", applied, tt.applied)
+			}
+			if version.String() != tt.expected {
+				t.Errorf("version = %s, want %s", version, tt.expected)
+			}
+		})
+	}
+}
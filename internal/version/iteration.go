@@ -0,0 +1,75 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// usesReleaseIterations reports whether prerelease numbers on the branch
+// count release candidates instead of commits. This is the case for release
+// branches in ContinuousDelivery mode, where every build is a candidate.
+// The candidate tags --apply creates are the only record of the counter, so
+// without auto-tag the numbers count commits, which always advance.
+func usesReleaseIterations(branchType BranchType, branchConfig *config.BranchConfiguration) bool {
+	if branchConfig.Mode != config.DeploymentContinuousDelivery || !branchConfig.AutoTag {
+		return false
+	}
+	return branchType == Release || branchConfig.IsReleaseBranch
+}
+
+// releaseIteration returns the release candidate number for version on the
+// commit head, along with where it came from. The prerelease tags of earlier
+// candidates persist the counter: a commit that already carries one keeps its
+// number, so rebuilds of the same commit do not burn numbers, and any other
+// commit gets one more than the highest candidate reachable from HEAD.
+func (c *Calculator) releaseIteration(version *semver.Version, label, head string) (int, string) {
+	tags, _ := c.repo.GetTagCommits()
+	graph, err := c.repo.GetCommitGraph()
+	if err != nil {
+		return 1, "first candidate"
+	}
+
+	highest, highestTag := 0, ""
+	for _, tag := range tags {
+		if !graph.ReachableFromHead(tag.SHA) {
+			continue
+		}
+		iteration, ok := c.tagIteration(tag.Name, version, label)
+		if !ok {
+			continue
+		}
+		if tag.SHA == head {
+			return iteration, fmt.Sprintf("tag %s on HEAD", tag.Name)
+		}
+		if iteration > highest {
+			highest, highestTag = iteration, tag.Name
+		}
+	}
+
+	if highestTag == "" {
+		return 1, "first candidate"
+	}
+	return highest + 1, fmt.Sprintf("after %s", highestTag)
+}
+
+// tagIteration extracts N from a tag of the form <prefix>X.Y.Z-<label>.N
+// matching the core version of version.
+func (c *Calculator) tagIteration(tag string, version *semver.Version, label string) (int, bool) {
+	tagged, err := c.repo.ParseTag(tag)
+	if err != nil || tagged.MajorMinorPatch() != version.MajorMinorPatch() {
+		return 0, false
+	}
+	number, ok := strings.CutPrefix(tagged.PreRelease, label+".")
+	if !ok {
+		return 0, false
+	}
+	iteration, err := strconv.Atoi(number)
+	if err != nil || iteration < 1 {
+		return 0, false
+	}
+	return iteration, true
+}
@@ -0,0 +1,94 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func TestReleaseIterationAdvancesOnlyWithNewCommits(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "-b", "release/1.1.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "release: prepare 1.1.0")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Branches["release"].Mode = config.DeploymentContinuousDelivery
+	cfg.Branches["release"].AutoTag = true
+	calculator := NewCalculator(git.NewRepository(), cfg)
+
+	explain := func(t *testing.T, expectedPreRelease, expectedSource string) string {
+		t.Helper()
+		diagnostics, err := calculator.Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		if diagnostics.Version.PreRelease != expectedPreRelease {
+			t.Errorf("PreRelease = %q, want %q", diagnostics.Version.PreRelease, expectedPreRelease)
+		}
+		if diagnostics.IterationSource != expectedSource {
+			t.Errorf("IterationSource = %q, want %q", diagnostics.IterationSource, expectedSource)
+		}
+		return "v" + diagnostics.Version.MajorMinorPatch() + "-" + diagnostics.Version.PreRelease
+	}
+
+	tag := explain(t, "beta.1", "first candidate")
+	runGit(t, "tag", tag)
+
+	// Rebuilding the tagged commit keeps its number
+	explain(t, "beta.1", "tag "+tag+" on HEAD")
+
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: release blocker")
+	explain(t, "beta.2", "after "+tag)
+
+	// Untagged rebuilds do not advance the counter either
+	explain(t, "beta.2", "after "+tag)
+}
+
+func TestReleaseIterationNeedsAutoTag(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "-b", "release/1.1.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "release: prepare 1.1.0")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	// Nothing tags the candidates, so successive commits must not share a
+	// number
+	cfg.Branches["release"].Mode = config.DeploymentContinuousDelivery
+	calculator := NewCalculator(git.NewRepository(), cfg)
+
+	number := func() int {
+		t.Helper()
+		diagnostics, err := calculator.Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		if diagnostics.IterationSource != "" {
+			t.Errorf("IterationSource = %q, want commit counting without auto-tag", diagnostics.IterationSource)
+		}
+		n, ok := diagnostics.Version.PreReleaseNumber()
+		if !ok {
+			t.Fatalf("PreRelease = %q, want a numbered prerelease", diagnostics.Version.PreRelease)
+		}
+		return n
+	}
+
+	first := number()
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: release blocker")
+	if second := number(); second != first+1 {
+		t.Errorf("next commit is candidate %d, want %d", second, first+1)
+	}
+}
@@ -0,0 +1,150 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+var (
+	// conventionalHeaderPattern splits a conventional commit subject into
+	// its type, scope, breaking marker and the text after the colon
+	conventionalHeaderPattern = lazyregexp.New(`^(\w+)(?:\(([^)]*)\))?(!)?:(.*)$`)
+	// breakingFooterPattern matches a BREAKING CHANGE footer
+	breakingFooterPattern = lazyregexp.New(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// ConventionalHeader splits a conventional commit subject into the whole
+// subject, its type, scope, breaking marker "!" and the text after the
+// colon; nil when subject is not a conventional commit
+func ConventionalHeader(subject string) []string {
+	return conventionalHeaderPattern.FindStringSubmatch(subject)
+}
+
+// MessageRules decide the increment a commit message asks for, from the
+// bump messages of a configuration and Conventional Commits
+type MessageRules struct {
+	major, minor, patch, none *regexp.Regexp
+}
+
+// NewMessageRules returns the rules for the bump messages of cfg
+func NewMessageRules(cfg *config.Config) (*MessageRules, error) {
+	r := &MessageRules{}
+	for _, bump := range []struct {
+		key     string
+		pattern string
+		re      **regexp.Regexp
+	}{
+		{"major-version-bump-message", cfg.MajorVersionBumpMessage, &r.major},
+		{"minor-version-bump-message", cfg.MinorVersionBumpMessage, &r.minor},
+		{"patch-version-bump-message", cfg.PatchVersionBumpMessage, &r.patch},
+		{"no-bump-message", cfg.NoBumpMessage, &r.none},
+	} {
+		if bump.pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(bump.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", bump.key, err)
+		}
+		*bump.re = re
+	}
+	return r, nil
+}
+
+// Bumps reports whether message matches one of the bump messages
+func (r *MessageRules) Bumps(message string) bool {
+	for _, re := range []*regexp.Regexp{r.major, r.minor, r.patch, r.none} {
+		if re != nil && re.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// Increment returns the increment a whole commit message asks for and why.
+// Bump messages and conventional commits are checked from major down, so
+// the largest increment asked for wins. Inherit means the message asks for
+// none and the branch's increment applies.
+func (r *MessageRules) Increment(message string) (config.IncrementStrategy, string) {
+	subject, _, _ := strings.Cut(message, "\n")
+	header := ConventionalHeader(subject)
+	commitType := ""
+	if header != nil {
+		commitType = strings.ToLower(header[1])
+	}
+	switch {
+	case r.major != nil && r.major.MatchString(message):
+		return config.IncrementMajor, "major-version-bump-message"
+	case header != nil && header[3] == "!":
+		return config.IncrementMajor, "breaking change marked with !"
+	case breakingFooterPattern.MatchString(message):
+		return config.IncrementMajor, "BREAKING CHANGE footer"
+	case r.minor != nil && r.minor.MatchString(message):
+		return config.IncrementMinor, "minor-version-bump-message"
+	case commitType == "feat":
+		return config.IncrementMinor, "feat commit"
+	case r.patch != nil && r.patch.MatchString(message):
+		return config.IncrementPatch, "patch-version-bump-message"
+	case commitType == "fix":
+		return config.IncrementPatch, "fix commit"
+	case r.none != nil && r.none.MatchString(message):
+		return config.IncrementNone, "no-bump-message"
+	}
+	return config.IncrementInherit, "no bump asked for; the branch's increment applies"
+}
+
+// Modes of commit-message-incrementing
+const (
+	messageIncrementsEnabled   = "Enabled"
+	messageIncrementsDisabled  = "Disabled"
+	messageIncrementsMergeOnly = "MergeMessageOnly"
+)
+
+// messageIncrement returns the largest increment the messages of the
+// commits since source ask for, "" for the whole history, with the commit
+// asking for it and why. commit-message-incrementing selects the commits:
+// all but merges, only merges with MergeMessageOnly, or none when it is
+// disabled. ok is false when no message asks for one, and the increment is
+// left to the branch.
+func (c *Calculator) messageIncrement(source string) (increment config.IncrementStrategy, cause string, ok bool, err error) {
+	settings := c.config.CommitMessageIncrement
+	if !settings.Enabled || strings.EqualFold(settings.IncrementMode, messageIncrementsDisabled) {
+		return "", "", false, nil
+	}
+	rules, err := NewMessageRules(c.config)
+	if err != nil {
+		return "", "", false, err
+	}
+	if source != "" && !c.repo.RefExists(source) {
+		source = ""
+	}
+	var commits []*git.Commit
+	switch mode := settings.IncrementMode; {
+	case strings.EqualFold(mode, messageIncrementsMergeOnly):
+		commits, err = c.repo.GetMergeCommitsSinceTag(source)
+	case mode == "" || strings.EqualFold(mode, messageIncrementsEnabled):
+		commits, err = c.repo.GetCommitsWithBodySinceTag(source)
+	default:
+		return "", "", false, fmt.Errorf("invalid commit-message-incrementing increment-mode %q: want %s, %s or %s",
+			mode, messageIncrementsEnabled, messageIncrementsMergeOnly, messageIncrementsDisabled)
+	}
+	if err != nil {
+		return "", "", false, nil
+	}
+
+	for _, commit := range commits {
+		commitIncrement, commitCause := rules.Increment(commit.FullMessage())
+		if commitIncrement == config.IncrementInherit {
+			continue
+		}
+		if !ok || rank(commitIncrement) > rank(increment) {
+			increment, cause, ok = commitIncrement, fmt.Sprintf("%s of commit %.7s", commitCause, commit.SHA), true
+		}
+	}
+	return increment, cause, ok, nil
+}
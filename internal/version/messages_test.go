@@ -0,0 +1,143 @@
+package version
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func TestMessageRules(t *testing.T) {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	rules, err := NewMessageRules(cfg)
+	if err != nil {
+		t.Fatalf("NewMessageRules() error = %v", err)
+	}
+
+	tests := []struct {
+		message   string
+		increment config.IncrementStrategy
+	}{
+		{"fix: resolve login issue", config.IncrementPatch},
+		{"feat: add user authentication", config.IncrementMinor},
+		{"feat!: redesign API", config.IncrementMajor},
+		{"fix: critical bug +semver: major", config.IncrementMajor},
+		{"update: improve performance +semver: minor", config.IncrementMinor},
+		{"feat: new feature\n\nBREAKING CHANGE: API has changed", config.IncrementMajor},
+		{"fix: parse dates\n\nBREAKING-CHANGE: local times are rejected", config.IncrementMajor},
+		{"refactor: settings\n\nMoves the settings file.\n\n+semver: minor", config.IncrementMinor},
+		{"Tidy up +semver: none", config.IncrementNone},
+		{"docs: typo", config.IncrementInherit},
+		// Markers in prose ask for nothing
+		{"fix: tidy\n\nThis is not a breaking change.", config.IncrementPatch},
+		{"docs: bumps\n\nWrite +semver: major in a message to bump the major version.", config.IncrementInherit},
+	}
+	for _, tt := range tests {
+		if increment, cause := rules.Increment(tt.message); increment != tt.increment {
+			t.Errorf("Increment(%q) = %s (%s), want %s", tt.message, increment, cause, tt.increment)
+		}
+	}
+
+	cfg.MajorVersionBumpMessage = `(`
+	if _, err := NewMessageRules(cfg); err == nil {
+		t.Error("NewMessageRules() with an invalid major-version-bump-message should fail")
+	}
+}
+
+func TestMessageIncrement(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: tidy", "-m", "Some detail.")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	calculator := NewCalculator(git.NewRepository(), cfg)
+	explain := func() *Diagnostics {
+		t.Helper()
+		diagnostics, err := calculator.Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		return diagnostics
+	}
+
+	if diagnostics := explain(); diagnostics.Version.MajorMinorPatch() != "1.0.1" {
+		t.Errorf("fix: version %s (%s), want 1.0.1", diagnostics.Version.MajorMinorPatch(), diagnostics.IncrementCause)
+	}
+
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "refactor: settings", "-m", "Moves the settings file.\n\n+semver: minor")
+	if diagnostics := explain(); diagnostics.Version.MajorMinorPatch() != "1.1.0" {
+		t.Errorf("bump message in the body: version %s (%s), want 1.1.0", diagnostics.Version.MajorMinorPatch(), diagnostics.IncrementCause)
+	}
+
+	// A footer alone makes the change breaking
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: parse dates in UTC", "-m", "BREAKING CHANGE: local times are no longer accepted")
+	diagnostics := explain()
+	if diagnostics.Version.MajorMinorPatch() != "2.0.0" || diagnostics.Increment != "major" {
+		t.Errorf("footer: increment %s to %s, want major to 2.0.0", diagnostics.Increment, diagnostics.Version.MajorMinorPatch())
+	}
+	if !strings.HasPrefix(diagnostics.IncrementCause, "BREAKING CHANGE footer of commit ") {
+		t.Errorf("IncrementCause = %q, want the footer and its commit", diagnostics.IncrementCause)
+	}
+}
+
+func TestMessageIncrementModes(t *testing.T) {
+	setupTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "-b", "topic")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: search")
+	runGit(t, "checkout", "-q", "main")
+	runGit(t, "merge", "-q", "--no-ff", "-m", "Merge topic", "topic")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	explain := func() string {
+		t.Helper()
+		diagnostics, err := NewCalculator(git.NewRepository(), cfg).Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		return diagnostics.Version.MajorMinorPatch()
+	}
+
+	tests := []struct {
+		enabled bool
+		mode    string
+		want    string
+	}{
+		{true, "Enabled", "1.1.0"},
+		{false, "Enabled", "1.0.1"},
+		{true, "Disabled", "1.0.1"},
+		// The feat commit is not a merge, and the merge asks for nothing
+		{true, "MergeMessageOnly", "1.0.1"},
+	}
+	for _, tt := range tests {
+		cfg.CommitMessageIncrement = config.CommitMessageConfig{Enabled: tt.enabled, IncrementMode: tt.mode}
+		if got := explain(); got != tt.want {
+			t.Errorf("enabled %t, increment-mode %s: version %s, want %s", tt.enabled, tt.mode, got, tt.want)
+		}
+	}
+
+	runGit(t, "commit", "-q", "--allow-empty", "--amend", "-m", "Merge topic", "-m", "+semver: minor")
+	cfg.CommitMessageIncrement = config.CommitMessageConfig{Enabled: true, IncrementMode: "MergeMessageOnly"}
+	if got := explain(); got != "1.1.0" {
+		t.Errorf("MergeMessageOnly with a bump message on the merge: version %s, want 1.1.0", got)
+	}
+
+	cfg.CommitMessageIncrement.IncrementMode = "Sometimes"
+	if _, err := NewCalculator(git.NewRepository(), cfg).Explain("", GitFlow, "", ""); err == nil {
+		t.Error("Explain() with an unknown increment-mode should fail")
+	}
+}
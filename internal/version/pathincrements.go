@@ -0,0 +1,90 @@
+package version
+
+import (
+	"path"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// incrementRanks orders the increments from none to major; "" is a patch
+// like applyIncrement takes it
+var incrementRanks = map[string]int{
+	"none":  0,
+	"":      1,
+	"patch": 1,
+	"minor": 2,
+	"major": 3,
+}
+
+// pathIncrement returns the largest increment the path-increments rules
+// give the files changed since source, "" for the whole history. Files
+// matching no rule, or a rule with the increment Inherit, take fallback.
+// ok is false when there are no rules or no changes, and the increment is
+// left to the branch.
+func (c *Calculator) pathIncrement(source string, fallback config.IncrementStrategy) (increment config.IncrementStrategy, ok bool) {
+	rules := c.config.PathIncrements
+	if len(rules) == 0 {
+		return "", false
+	}
+	if source != "" && !c.repo.RefExists(source) {
+		source = ""
+	}
+	changes, err := c.repo.GetChangedFilesSince(source)
+	if err != nil || len(changes) == 0 {
+		return "", false
+	}
+
+	increment = config.IncrementNone
+	for _, files := range changes {
+		for _, file := range files {
+			fileIncrement := fallback
+			for _, rule := range rules {
+				if matchPathGlob(rule.Path, file) {
+					if rule.Increment != config.IncrementInherit {
+						fileIncrement = rule.Increment
+					}
+					break
+				}
+			}
+			if rank(fileIncrement) > rank(increment) {
+				increment = fileIncrement
+			}
+		}
+	}
+	return increment, true
+}
+
+// rank returns the position of increment in incrementRanks; increments it
+// does not know, such as Inherit, apply no change
+func rank(increment config.IncrementStrategy) int {
+	return incrementRanks[strings.ToLower(string(increment))]
+}
+
+// matchPathGlob reports whether name, a slash separated path, matches
+// pattern. * and the other path.Match wildcards match within a directory,
+// a ** segment any number of directories, none included.
+func matchPathGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,102 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"docs/**", "docs/guide.md", true},
+		{"docs/**", "docs/api/index.md", true},
+		{"docs/**", "src/docs/guide.md", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "pkg/api/README.md", true},
+		{"*.md", "pkg/README.md", false},
+		{"db/migrations/*.sql", "db/migrations/001_init.sql", true},
+		{"db/migrations/*.sql", "db/migrations/old/001_init.sql", false},
+		{"src/**/test/*", "src/test/a.go", true},
+		{"src/**/test/*", "src/a/b/test/a.go", true},
+		{"/go.mod", "go.mod", true},
+	}
+	for _, tt := range tests {
+		if got := matchPathGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPathGlob(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPathIncrements(t *testing.T) {
+	setupTestRepo(t)
+	change := func(file, message string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(message), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, "add", file)
+		runGit(t, "commit", "-q", "-m", message)
+	}
+	runGit(t, "checkout", "-q", "-b", "main")
+	change("main.go", "initial commit")
+	runGit(t, "tag", "v2.0.0")
+	change("docs/guide.md", "docs: guide")
+	change("README.md", "docs: readme")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.PathIncrements = []config.PathIncrementRule{
+		{Path: "docs/api/**", Increment: config.IncrementInherit},
+		{Path: "docs/**", Increment: config.IncrementNone},
+		{Path: "*.md", Increment: config.IncrementNone},
+		{Path: "migrations/**", Increment: config.IncrementMinor},
+	}
+	calculator := NewCalculator(git.NewRepository(), cfg)
+	explain := func() *Diagnostics {
+		t.Helper()
+		diagnostics, err := calculator.Explain("", GitFlow, "", "")
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		return diagnostics
+	}
+
+	if diagnostics := explain(); diagnostics.Increment != "none" || diagnostics.Version.MajorMinorPatch() != "2.0.0" {
+		t.Errorf("docs only: increment %s to %s, want none to 2.0.0", diagnostics.Increment, diagnostics.Version.MajorMinorPatch())
+	}
+
+	change("main.go", "fix: crash")
+	if diagnostics := explain(); diagnostics.Increment != "patch" {
+		t.Errorf("code change: increment %s (%s), want the branch's patch", diagnostics.Increment, diagnostics.IncrementCause)
+	}
+
+	change("migrations/002_users.sql", "feat: users table")
+	if diagnostics := explain(); diagnostics.Increment != "minor" || diagnostics.Version.MajorMinorPatch() != "2.1.0" {
+		t.Errorf("migration: increment %s to %s, want minor to 2.1.0", diagnostics.Increment, diagnostics.Version.MajorMinorPatch())
+	}
+
+	// Generated API docs ship with the release like code
+	runGit(t, "tag", "v2.1.0")
+	change("docs/api/index.html", "docs: regenerate API reference")
+	if diagnostics := explain(); diagnostics.Increment != "patch" {
+		t.Errorf("API docs: increment %s, want the branch's patch", diagnostics.Increment)
+	}
+
+	// Without rules the branch decides
+	cfg.PathIncrements = nil
+	if diagnostics := explain(); diagnostics.Increment != "patch" {
+		t.Errorf("without rules: increment %s, want patch", diagnostics.Increment)
+	}
+}
@@ -0,0 +1,65 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]VersionStrategy{}
+)
+
+// RegisterStrategy makes a custom base version strategy available under the
+// given name so it can be referenced from the configured strategies list.
+// Names are case-insensitive and may not shadow a built-in strategy.
+func RegisterStrategy(name string, strategy VersionStrategy) error {
+	key := normalizeStrategyName(name)
+	if key == "" {
+		return fmt.Errorf("strategy name must not be empty")
+	}
+	if strategy == nil {
+		return fmt.Errorf("strategy %s is nil", name)
+	}
+	if parseBuiltinStrategy(key) != None {
+		return fmt.Errorf("strategy %s conflicts with a built-in strategy", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[key]; exists {
+		return fmt.Errorf("strategy %s is already registered", name)
+	}
+	registry[key] = strategy
+	return nil
+}
+
+// UnregisterStrategy removes a previously registered custom strategy
+func UnregisterStrategy(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, normalizeStrategyName(name))
+}
+
+// LookupStrategy returns the custom strategy registered under name
+func LookupStrategy(name string) (VersionStrategy, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	strategy, exists := registry[normalizeStrategyName(name)]
+	return strategy, exists
+}
+
+// RegisteredStrategies returns the names of all custom strategies, sorted
+func RegisteredStrategies() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
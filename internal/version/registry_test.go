@@ -0,0 +1,44 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestRegisterStrategy(t *testing.T) {
+	defer UnregisterStrategy("VersionService")
+
+	if err := RegisterStrategy("VersionService", &FallbackStrategy{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, exists := LookupStrategy("versionservice"); !exists {
+		t.Errorf("Expected lookup to be case-insensitive")
+	}
+
+	if err := RegisterStrategy("VersionService", &FallbackStrategy{}); err == nil {
+		t.Errorf("Expected error for duplicate registration")
+	}
+
+	names := RegisteredStrategies()
+	if len(names) != 1 || names[0] != "versionservice" {
+		t.Errorf("RegisteredStrategies() = %v, want [versionservice]", names)
+	}
+}
+
+func TestRegisterStrategyRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy VersionStrategy
+	}{
+		{name: "", strategy: &FallbackStrategy{}},
+		{name: "Nil", strategy: nil},
+		{name: "TaggedCommit", strategy: &FallbackStrategy{}},
+	}
+
+	for _, tt := range tests {
+		if err := RegisterStrategy(tt.name, tt.strategy); err == nil {
+			UnregisterStrategy(tt.name)
+			t.Errorf("Expected error registering %q", tt.name)
+		}
+	}
+}
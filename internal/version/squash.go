@@ -0,0 +1,131 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+var (
+	// GitHub: "Add feature X (#123)"
+	githubSquashPattern = lazyregexp.New(`^(.+?)\s+\(#(\d+)\)$`)
+	// Azure DevOps: "Merged PR 123: Add feature X"
+	azureSquashPattern = lazyregexp.New(`^Merged PR (\d+):\s*(.+)$`)
+	// Bitbucket: "Merged in release/1.2.0 (pull request #123)"
+	bitbucketSquashPattern = lazyregexp.New(`^Merged in (\S+) \(pull request #(\d+)\)`)
+	// GitLab appends "See merge request group/project!123" to the body
+	gitlabMergeRequestPattern = lazyregexp.New(`See merge request \S+!(\d+)`)
+	// Trailers naming the source branch of the pull request
+	sourceBranchTrailerPattern = lazyregexp.New(`(?mi)^(?:source-branch|branch|pr-branch):\s*(\S+)\s*$`)
+	// Titles such as "Release 1.2.0" or "release/v1.2.0"
+	releaseTitlePattern = lazyregexp.New(`(?i)^release[\s/-]+v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`)
+)
+
+// squashMerge describes a commit recognised as a squash-merged pull request
+type squashMerge struct {
+	PullRequest int
+	Title       string
+	Branch      string
+	Version     *semver.Version
+}
+
+// parseSquashMerge recognises squash-merge commits from the common hosting
+// providers and extracts the pull request, source branch and version.
+func parseSquashMerge(commit *git.Commit) *squashMerge {
+	var merge *squashMerge
+
+	if m := bitbucketSquashPattern.FindStringSubmatch(commit.Message); m != nil {
+		pr, _ := strconv.Atoi(m[2])
+		merge = &squashMerge{PullRequest: pr, Title: commit.Message, Branch: m[1]}
+	} else if m := azureSquashPattern.FindStringSubmatch(commit.Message); m != nil {
+		pr, _ := strconv.Atoi(m[1])
+		merge = &squashMerge{PullRequest: pr, Title: m[2]}
+	} else if m := githubSquashPattern.FindStringSubmatch(commit.Message); m != nil {
+		pr, _ := strconv.Atoi(m[2])
+		merge = &squashMerge{PullRequest: pr, Title: m[1]}
+	} else if m := gitlabMergeRequestPattern.FindStringSubmatch(commit.Body); m != nil {
+		pr, _ := strconv.Atoi(m[1])
+		merge = &squashMerge{PullRequest: pr, Title: commit.Message}
+	} else {
+		return nil
+	}
+
+	if merge.Branch == "" {
+		if m := sourceBranchTrailerPattern.FindStringSubmatch(commit.Body); m != nil {
+			merge.Branch = m[1]
+		}
+	}
+
+	if merge.Branch != "" {
+		merge.Version = parseVersionFragment(merge.Branch)
+	}
+	if merge.Version == nil {
+		if m := releaseTitlePattern.FindStringSubmatch(merge.Title); m != nil {
+			merge.Version = parseVersionFragment(m[1])
+		}
+	}
+
+	return merge
+}
+
+func parseVersionFragment(text string) *semver.Version {
+	matches := versionPattern.FindStringSubmatch(text)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	patch, _ := strconv.Atoi(matches[3])
+
+	return &semver.Version{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: matches[4],
+	}
+}
+
+// SquashMergeStrategy implements the squash merge strategy. It is the
+// counterpart of MergeMessageStrategy for repositories without merge commits.
+type SquashMergeStrategy struct{}
+
+func (s *SquashMergeStrategy) GetName() string {
+	return "SquashMerge"
+}
+
+// GetBaseVersions returns the version of the newest squash merge of a
+// versioned branch; older commits are not read
+func (s *SquashMergeStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
+	if ctx.BranchConfig == nil || !ctx.BranchConfig.TrackMergeMessage {
+		return nil, nil
+	}
+
+	shouldIncrement := true
+	if ctx.BranchConfig.PreventIncrement != nil && ctx.BranchConfig.PreventIncrement.OfMergedBranch {
+		shouldIncrement = false
+	}
+
+	var baseVersions []*BaseVersion
+	err := ctx.Repository.ForEachCommit("HEAD", func(commit *git.Commit) bool {
+		merge := parseSquashMerge(commit)
+		if merge == nil || merge.Version == nil {
+			return true
+		}
+		baseVersions = append(baseVersions, &BaseVersion{
+			SemanticVersion:   merge.Version,
+			Source:            fmt.Sprintf("Squash merge of #%d '%s'", merge.PullRequest, merge.Title),
+			ShouldIncrement:   shouldIncrement,
+			BaseVersionSource: commit.SHA,
+		})
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit history: %w", err)
+	}
+
+	return baseVersions, nil
+}
@@ -0,0 +1,101 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+)
+
+func TestParseSquashMerge(t *testing.T) {
+	tests := []struct {
+		name            string
+		commit          *git.Commit
+		expectMerge     bool
+		expectedPR      int
+		expectedBranch  string
+		expectedVersion string
+	}{
+		{
+			name:        "GitHub squash without version",
+			commit:      &git.Commit{Message: "Add feature X (#123)"},
+			expectMerge: true,
+			expectedPR:  123,
+		},
+		{
+			name:            "GitHub squash with release title",
+			commit:          &git.Commit{Message: "Release 1.4.0 (#130)"},
+			expectMerge:     true,
+			expectedPR:      130,
+			expectedVersion: "1.4.0",
+		},
+		{
+			name: "GitHub squash with branch trailer",
+			commit: &git.Commit{
+				Message: "Prepare release (#140)",
+				Body:    "* bump versions\n\nSource-Branch: release/2.0.0-rc.1",
+			},
+			expectMerge:     true,
+			expectedPR:      140,
+			expectedBranch:  "release/2.0.0-rc.1",
+			expectedVersion: "2.0.0-rc.1",
+		},
+		{
+			name:            "Bitbucket squash",
+			commit:          &git.Commit{Message: "Merged in release/1.2.0 (pull request #45)"},
+			expectMerge:     true,
+			expectedPR:      45,
+			expectedBranch:  "release/1.2.0",
+			expectedVersion: "1.2.0",
+		},
+		{
+			name:            "Azure DevOps squash",
+			commit:          &git.Commit{Message: "Merged PR 77: Release v3.1.0"},
+			expectMerge:     true,
+			expectedPR:      77,
+			expectedVersion: "3.1.0",
+		},
+		{
+			name: "GitLab squash",
+			commit: &git.Commit{
+				Message: "Release 0.9.0",
+				Body:    "See merge request group/project!12",
+			},
+			expectMerge:     true,
+			expectedPR:      12,
+			expectedVersion: "0.9.0",
+		},
+		{
+			name:        "Regular commit",
+			commit:      &git.Commit{Message: "fix: handle nil config"},
+			expectMerge: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merge := parseSquashMerge(tt.commit)
+			if !tt.expectMerge {
+				if merge != nil {
+					t.Errorf("Expected no squash merge, got %+v", merge)
+				}
+				return
+			}
+			if merge == nil {
+				t.Fatalf("Expected squash merge to be detected")
+			}
+			if merge.PullRequest != tt.expectedPR {
+				t.Errorf("PullRequest = %d, want %d", merge.PullRequest, tt.expectedPR)
+			}
+			if merge.Branch != tt.expectedBranch {
+				t.Errorf("Branch = %s, want %s", merge.Branch, tt.expectedBranch)
+			}
+			version := ""
+			if merge.Version != nil {
+				version = merge.Version.String()
+			}
+			if version != tt.expectedVersion {
+				t.Errorf("Version = %s, want %s", version, tt.expectedVersion)
+			}
+		})
+	}
+}
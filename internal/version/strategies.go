@@ -1,16 +1,28 @@
 package version
 
 import (
+	"context"
 	"fmt"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
 	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
 	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
 
+var (
+	// Merge commit subjects, capturing the merged branch
+	mergeMessagePattern = lazyregexp.New(`(?i)merge.*?(?:branch\s+)?(?:'([^']+)'|"([^"]+)"|(\S+))`)
+	// A semantic version anywhere in a branch name or message
+	versionPattern       = lazyregexp.New(`(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z\-]+(?:\.[0-9A-Za-z\-]+)*))?`)
+	releaseBranchPattern = lazyregexp.New(`^releases?[/-]`)
+)
+
 // VersionStrategies represents the available version calculation strategies
 type VersionStrategies int
 
@@ -31,17 +43,26 @@ const (
 	VersionInBranchName
 	// Mainline strategy - increments version on every commit for main branches
 	Mainline
+	// SquashMerge strategy - extracts version from squash-merged pull requests
+	SquashMerge
+	// VersionFile strategy - reads the version from a VERSION file
+	VersionFile
 )
 
 // BaseVersion represents a version source with metadata
 type BaseVersion struct {
-	SemanticVersion   *semver.Version
-	Source            string
-	ShouldIncrement   bool
-	BaseVersionSource string
+	SemanticVersion   *semver.Version `json:"SemanticVersion"`
+	Source            string          `json:"Source"`
+	ShouldIncrement   bool            `json:"ShouldIncrement"`
+	BaseVersionSource string          `json:"BaseVersionSource"`
+	Strategy          string          `json:"Strategy"`
+	// Tag is the version tag the base version came from, if any
+	Tag string `json:"Tag,omitempty"`
 }
 
-// VersionStrategy defines the interface for version calculation strategies
+// VersionStrategy defines the interface for version calculation strategies.
+// The enabled strategies run concurrently, so GetBaseVersions must be safe to
+// call alongside those of other strategies.
 type VersionStrategy interface {
 	GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error)
 	GetName() string
@@ -49,13 +70,18 @@ type VersionStrategy interface {
 
 // VersionContext provides context for version calculation
 type VersionContext struct {
+	// Context is done when the calculation is abandoned; Repository runs
+	// its git commands in it. It may be nil.
+	Context       context.Context
 	Repository    *git.Repository
 	Config        *config.Config
 	CurrentBranch string
 	CurrentCommit string
 	BranchConfig  *config.BranchConfiguration
 	Strategies    VersionStrategies
+	Custom        []string
 	NextVersion   string
+	Progress      progress.Reporter
 }
 
 // FallbackStrategy implements the fallback version strategy
@@ -116,34 +142,92 @@ func (t *TaggedCommitStrategy) GetName() string {
 }
 
 func (t *TaggedCommitStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
-	tags, err := ctx.Repository.GetTagsOnCurrentBranch()
+	// Two passes over the refs and the history answer for every tag, which
+	// matters in repositories with thousands of them
+	tags, err := ctx.Repository.GetTagCommits()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tags: %w", err)
 	}
+	graph, err := ctx.Repository.GetCommitGraph()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
 
 	var baseVersions []*BaseVersion
+	var offBranch []*git.Tag
 	for _, tag := range tags {
-		version, err := semver.Parse(tag)
-		if err != nil {
-			continue // Skip invalid semantic version tags
+		if !graph.ReachableFromHead(tag.SHA) {
+			offBranch = append(offBranch, tag)
+			continue
 		}
 
-		sha, err := ctx.Repository.GetCommitSHAForTag(tag)
+		version, err := ctx.Repository.ParseTag(tag.Name)
 		if err != nil {
+			continue // Skip invalid semantic version tags
+		}
+		if !signatureAccepted(ctx, tag.Name) {
 			continue
 		}
 
 		baseVersions = append(baseVersions, &BaseVersion{
 			SemanticVersion:   version,
-			Source:            fmt.Sprintf("Tag '%s'", tag),
+			Source:            fmt.Sprintf("Tag '%s'", tag.Name),
 			ShouldIncrement:   true,
-			BaseVersionSource: sha,
+			BaseVersionSource: tag.SHA,
+			Tag:               tag.Name,
 		})
 	}
 
+	if ctx.BranchConfig != nil && ctx.BranchConfig.TrackMergeTarget {
+		baseVersions = append(baseVersions, t.getMergeTargetVersions(ctx, graph, offBranch)...)
+	}
+
 	return baseVersions, nil
 }
 
+// getMergeTargetVersions finds version tags on merge commits outside the
+// current branch that merged a commit of the current branch, e.g. the tag on
+// main after develop was merged into it for a release.
+func (t *TaggedCommitStrategy) getMergeTargetVersions(ctx *VersionContext, graph *git.CommitGraph, offBranch []*git.Tag) []*BaseVersion {
+	var baseVersions []*BaseVersion
+	for _, tag := range offBranch {
+		version, err := ctx.Repository.ParseTag(tag.Name)
+		if err != nil || !signatureAccepted(ctx, tag.Name) {
+			continue
+		}
+
+		parents := graph.Parents(tag.SHA)
+		if len(parents) < 2 {
+			continue // Only merge commits can track a merge target
+		}
+
+		for _, parent := range parents[1:] {
+			if graph.ReachableFromHead(parent) {
+				baseVersions = append(baseVersions, &BaseVersion{
+					SemanticVersion:   version,
+					Source:            fmt.Sprintf("Merge target tag '%s'", tag.Name),
+					ShouldIncrement:   true,
+					BaseVersionSource: parent,
+					Tag:               tag.Name,
+				})
+				break
+			}
+		}
+	}
+
+	return baseVersions
+}
+
+// signatureAccepted reports whether tag may serve as a base version. With
+// require-signed-tags only tags with a verified signature qualify, so a
+// forged tag pushed to the repository cannot dictate the version.
+func signatureAccepted(ctx *VersionContext, tag string) bool {
+	if ctx.Config == nil || !ctx.Config.RequireSignedTags {
+		return true
+	}
+	return ctx.Repository.VerifyTag(tag)
+}
+
 // MergeMessageStrategy implements the merge message strategy
 type MergeMessageStrategy struct{}
 
@@ -151,75 +235,73 @@ func (m *MergeMessageStrategy) GetName() string {
 	return "MergeMessage"
 }
 
+// GetBaseVersions returns the version of the newest merge whose message
+// names a versioned branch; older merges are not read
 func (m *MergeMessageStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
 	if ctx.BranchConfig == nil || !ctx.BranchConfig.TrackMergeMessage {
 		return nil, nil
 	}
 
-	commits, err := ctx.Repository.GetCommitHistory(50) // Look at recent commits
-	if err != nil {
-		return nil, fmt.Errorf("failed to get commit history: %w", err)
+	shouldIncrement := true
+	if ctx.BranchConfig.PreventIncrement != nil && ctx.BranchConfig.PreventIncrement.OfMergedBranch {
+		shouldIncrement = false
 	}
 
 	var baseVersions []*BaseVersion
-	mergePattern := regexp.MustCompile(`(?i)merge.*?(?:branch\s+)?(?:'([^']+)'|"([^"]+)"|(\S+))`)
-	versionPattern := regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z\-]+(?:\.[0-9A-Za-z\-]+)*))?`)
-
-	for _, commit := range commits {
-		matches := mergePattern.FindStringSubmatch(commit.Message)
-		if len(matches) == 0 {
-			continue
+	err := ctx.Repository.ForEachCommit("HEAD", func(commit *git.Commit) bool {
+		version := mergeMessageVersion(commit.Message)
+		if version == nil {
+			return true
 		}
-
-		// Extract branch name from merge message
-		branchName := ""
-		for i := 1; i < len(matches); i++ {
-			if matches[i] != "" {
-				branchName = matches[i]
-				break
-			}
-		}
-
-		if branchName == "" {
-			continue
-		}
-
-		// Look for version in branch name
-		versionMatches := versionPattern.FindStringSubmatch(branchName)
-		if len(versionMatches) == 0 {
-			continue
-		}
-
-		major, _ := strconv.Atoi(versionMatches[1])
-		minor, _ := strconv.Atoi(versionMatches[2])
-		patch, _ := strconv.Atoi(versionMatches[3])
-		prerelease := versionMatches[4]
-
-		version := &semver.Version{
-			Major: major,
-			Minor: minor,
-			Patch: patch,
-		}
-		if prerelease != "" {
-			version.PreRelease = prerelease
-		}
-
-		shouldIncrement := true
-		if ctx.BranchConfig.PreventIncrement != nil && ctx.BranchConfig.PreventIncrement.OfMergedBranch {
-			shouldIncrement = false
-		}
-
 		baseVersions = append(baseVersions, &BaseVersion{
 			SemanticVersion:   version,
 			Source:            fmt.Sprintf("Merge message '%s'", commit.Message),
 			ShouldIncrement:   shouldIncrement,
 			BaseVersionSource: commit.SHA,
 		})
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit history: %w", err)
 	}
 
 	return baseVersions, nil
 }
 
+// mergeMessageVersion returns the version in the name of the branch a merge
+// commit subject names, nil for other subjects
+func mergeMessageVersion(message string) *semver.Version {
+	matches := mergeMessagePattern.FindStringSubmatch(message)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// Extract branch name from merge message
+	branchName := ""
+	for i := 1; i < len(matches); i++ {
+		if matches[i] != "" {
+			branchName = matches[i]
+			break
+		}
+	}
+
+	// Look for version in branch name
+	versionMatches := versionPattern.FindStringSubmatch(branchName)
+	if len(versionMatches) == 0 {
+		return nil
+	}
+
+	major, _ := strconv.Atoi(versionMatches[1])
+	minor, _ := strconv.Atoi(versionMatches[2])
+	patch, _ := strconv.Atoi(versionMatches[3])
+	return &semver.Version{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: versionMatches[4],
+	}
+}
+
 // VersionInBranchNameStrategy implements the version in branch name strategy
 type VersionInBranchNameStrategy struct{}
 
@@ -228,7 +310,6 @@ func (v *VersionInBranchNameStrategy) GetName() string {
 }
 
 func (v *VersionInBranchNameStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
-	versionPattern := regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z\-]+(?:\.[0-9A-Za-z\-]+)*))?`)
 	matches := versionPattern.FindStringSubmatch(ctx.CurrentBranch)
 
 	if len(matches) == 0 {
@@ -278,10 +359,8 @@ func (t *TrackReleaseBranchesStrategy) GetBaseVersions(ctx *VersionContext) ([]*
 	}
 
 	var baseVersions []*BaseVersion
-	releasePattern := regexp.MustCompile(`^releases?[/-]`)
-
 	for _, branch := range branches {
-		if !releasePattern.MatchString(branch) {
+		if !releaseBranchPattern.MatchString(branch) {
 			continue
 		}
 
@@ -301,8 +380,13 @@ func (t *TrackReleaseBranchesStrategy) GetBaseVersions(ctx *VersionContext) ([]*
 			continue
 		}
 
-		// Find merge base with current branch
-		mergeBase, err := ctx.Repository.GetMergeBase(branch, ctx.CurrentBranch)
+		// Find merge base with current branch, through the tracking branch
+		// of the primary remote when there is no local one
+		ref := branch
+		if !ctx.Repository.RefExists("refs/heads/" + branch) {
+			ref = ctx.Repository.Remote() + "/" + branch
+		}
+		mergeBase, err := ctx.Repository.GetMergeBase(ref, ctx.CurrentBranch)
 		if err != nil {
 			continue
 		}
@@ -332,9 +416,9 @@ func (m *MainlineStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion,
 		return nil, nil
 	}
 
-	// Get the latest tag on this branch
-	latestTag, err := ctx.Repository.GetLatestTag()
-	if err != nil {
+	// Get the latest version tag on this branch, ignoring non-version tags
+	latestTag, err := ctx.Repository.GetLatestVersionTag()
+	if err != nil || latestTag == "" {
 		// If no tags, start from 0.0.0
 		return []*BaseVersion{
 			{
@@ -346,7 +430,7 @@ func (m *MainlineStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion,
 		}, nil
 	}
 
-	version, err := semver.Parse(latestTag)
+	version, err := ctx.Repository.ParseTag(latestTag)
 	if err != nil {
 		// If tag is not a valid semantic version, start from 0.0.0
 		return []*BaseVersion{
@@ -370,6 +454,7 @@ func (m *MainlineStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion,
 			Source:            fmt.Sprintf("Mainline strategy from tag '%s'", latestTag),
 			ShouldIncrement:   true,
 			BaseVersionSource: tagSHA,
+			Tag:               latestTag,
 		},
 	}, nil
 }
@@ -392,27 +477,30 @@ func NewStrategyManager(repo *git.Repository, config *config.Config) *StrategyMa
 			VersionInBranchName:   &VersionInBranchNameStrategy{},
 			TrackReleaseBranches:  &TrackReleaseBranchesStrategy{},
 			Mainline:              &MainlineStrategy{},
+			SquashMerge:           &SquashMergeStrategy{},
+			VersionFile:           &VersionFileStrategy{},
 		},
 		repo:   repo,
 		config: config,
 	}
 }
 
-// GetBaseVersions calculates base versions using the specified strategies
-func (sm *StrategyManager) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
-	var allBaseVersions []*BaseVersion
-
+// EnabledStrategies returns the strategies enabled in ctx in evaluation order
+func (sm *StrategyManager) EnabledStrategies(ctx *VersionContext) ([]VersionStrategy, error) {
 	// Process strategies in order of priority
 	strategyOrder := []VersionStrategies{
 		ConfiguredNextVersion,
+		VersionFile,
 		VersionInBranchName,
 		TaggedCommit,
 		TrackReleaseBranches,
 		MergeMessage,
+		SquashMerge,
 		Mainline,
 		Fallback,
 	}
 
+	var enabled []VersionStrategy
 	for _, strategyType := range strategyOrder {
 		if ctx.Strategies&strategyType == 0 {
 			continue // Strategy not enabled
@@ -422,13 +510,82 @@ func (sm *StrategyManager) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion,
 		if !exists {
 			continue
 		}
+		enabled = append(enabled, strategy)
+	}
 
-		baseVersions, err := strategy.GetBaseVersions(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("strategy %s failed: %w", strategy.GetName(), err)
+	// Registered strategies run after the built-ins, in configured order
+	for _, name := range ctx.Custom {
+		strategy, exists := LookupStrategy(name)
+		if !exists {
+			return nil, fmt.Errorf("unknown strategy: %s", name)
 		}
+		enabled = append(enabled, strategy)
+	}
+
+	return enabled, nil
+}
+
+// GetBaseVersions calculates base versions using the specified strategies
+func (sm *StrategyManager) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
+	baseVersions, _, err := sm.GetBaseVersionsWithin(ctx, time.Time{})
+	return baseVersions, err
+}
 
-		allBaseVersions = append(allBaseVersions, baseVersions...)
+// GetBaseVersionsWithin is like GetBaseVersions but stops waiting for
+// strategies once deadline has passed, returning the base versions found so
+// far and the names of the strategies that did not complete. A zero
+// deadline never expires.
+func (sm *StrategyManager) GetBaseVersionsWithin(ctx *VersionContext, deadline time.Time) ([]*BaseVersion, []string, error) {
+	var allBaseVersions []*BaseVersion
+	var skipped []string
+
+	enabled, err := sm.EnabledStrategies(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cancelled <-chan struct{}
+	if ctx.Context != nil {
+		cancelled = ctx.Context.Done()
+	}
+	expired := make(chan struct{})
+	if !deadline.IsZero() {
+		timer := time.AfterFunc(time.Until(deadline), func() { close(expired) })
+		defer timer.Stop()
+	}
+
+	task := progress.Start(ctx.Progress, "strategies", len(enabled))
+	results := startStrategies(enabled, ctx, cancelled, expired)
+	// Results are collected in evaluation order, whatever order the
+	// strategies finish in
+	for i, strategy := range enabled {
+		var result strategyResult
+		select {
+		case result = <-results[i]:
+		case <-cancelled:
+			task.Finish(ctx.Context.Err())
+			return nil, nil, ctx.Context.Err()
+		case <-expired:
+			select {
+			case result = <-results[i]:
+			default:
+				skipped = append(skipped, strategy.GetName())
+				continue
+			}
+		}
+		if result.err != nil {
+			err := fmt.Errorf("strategy %s failed: %w", strategy.GetName(), result.err)
+			task.Finish(err)
+			return nil, nil, err
+		}
+		task.Step(strategy.GetName())
+
+		allBaseVersions = append(allBaseVersions, tagStrategy(result.baseVersions, strategy)...)
+	}
+	if len(skipped) > 0 {
+		task.Finish(fmt.Errorf("time budget exceeded"))
+	} else {
+		task.Finish(nil)
 	}
 
 	// If no base versions found, use fallback
@@ -436,12 +593,71 @@ func (sm *StrategyManager) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion,
 		fallback := sm.strategies[Fallback]
 		baseVersions, err := fallback.GetBaseVersions(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("fallback strategy failed: %w", err)
+			return nil, nil, fmt.Errorf("fallback strategy failed: %w", err)
 		}
-		allBaseVersions = append(allBaseVersions, baseVersions...)
+		allBaseVersions = append(allBaseVersions, tagStrategy(baseVersions, fallback)...)
 	}
 
-	return allBaseVersions, nil
+	return allBaseVersions, skipped, nil
+}
+
+// maxParallelStrategies bounds how many strategies run at once. They
+// mostly wait for git, whose processes need a CPU each.
+var maxParallelStrategies = runtime.GOMAXPROCS(0)
+
+type strategyResult struct {
+	baseVersions []*BaseVersion
+	err          error
+}
+
+// startStrategies runs the strategies concurrently, at most
+// maxParallelStrategies at a time and starting in order, and returns the
+// channel each strategy's result is sent on. Once cancelled or expired is
+// closed no more strategies start, and their channels stay empty. A
+// strategy no longer waited for keeps running in the background; its
+// result is discarded.
+func startStrategies(strategies []VersionStrategy, ctx *VersionContext, cancelled, expired <-chan struct{}) []chan strategyResult {
+	results := make([]chan strategyResult, len(strategies))
+	for i := range results {
+		results[i] = make(chan strategyResult, 1)
+	}
+
+	slots := make(chan struct{}, maxParallelStrategies)
+	go func() {
+		for i, strategy := range strategies {
+			select {
+			case slots <- struct{}{}:
+			case <-cancelled:
+				return
+			case <-expired:
+				return
+			}
+			// A slot coming free and the run ending can be ready together
+			select {
+			case <-cancelled:
+				return
+			case <-expired:
+				return
+			default:
+			}
+			go func(strategy VersionStrategy, result chan<- strategyResult) {
+				defer func() { <-slots }()
+				baseVersions, err := strategy.GetBaseVersions(ctx)
+				result <- strategyResult{baseVersions, err}
+			}(strategy, results[i])
+		}
+	}()
+	return results
+}
+
+// tagStrategy records which strategy produced each base version
+func tagStrategy(baseVersions []*BaseVersion, strategy VersionStrategy) []*BaseVersion {
+	for _, bv := range baseVersions {
+		if bv.Strategy == "" {
+			bv.Strategy = strategy.GetName()
+		}
+	}
+	return baseVersions
 }
 
 // FindBestBaseVersion selects the best base version from available options
@@ -471,27 +687,124 @@ func ParseVersionStrategies(strategies []string) VersionStrategies {
 	var result VersionStrategies
 
 	for _, strategy := range strategies {
-		switch strings.ToLower(strings.TrimSpace(strategy)) {
-		case "fallback":
-			result |= Fallback
-		case "configurednextversion":
-			result |= ConfiguredNextVersion
-		case "mergemessage":
-			result |= MergeMessage
-		case "taggedcommit":
-			result |= TaggedCommit
-		case "trackreleasebranches":
-			result |= TrackReleaseBranches
-		case "versioninbranchname":
-			result |= VersionInBranchName
-		case "mainline":
-			result |= Mainline
-		}
+		result |= parseBuiltinStrategy(strategy)
 	}
 
 	return result
 }
 
+func parseBuiltinStrategy(strategy string) VersionStrategies {
+	switch normalizeStrategyName(strategy) {
+	case "fallback":
+		return Fallback
+	case "configurednextversion":
+		return ConfiguredNextVersion
+	case "mergemessage":
+		return MergeMessage
+	case "taggedcommit":
+		return TaggedCommit
+	case "trackreleasebranches":
+		return TrackReleaseBranches
+	case "versioninbranchname":
+		return VersionInBranchName
+	case "mainline":
+		return Mainline
+	case "squashmerge":
+		return SquashMerge
+	case "versionfile":
+		return VersionFile
+	}
+	return None
+}
+
+func normalizeStrategyName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ResolveStrategies splits configured strategy names into the built-in
+// bitmask and the names of registered custom strategies. Names that match
+// neither are reported as an error.
+func ResolveStrategies(strategies []string) (VersionStrategies, []string, error) {
+	var mask VersionStrategies
+	var custom []string
+
+	for _, strategy := range strategies {
+		if builtin := parseBuiltinStrategy(strategy); builtin != None {
+			mask |= builtin
+			continue
+		}
+		if _, exists := LookupStrategy(strategy); exists {
+			custom = append(custom, strategy)
+			continue
+		}
+		return None, nil, fmt.Errorf("unknown strategy: %s", strategy)
+	}
+
+	return mask, custom, nil
+}
+
+// ApplyStrategyOverrides applies a branch's strategy overrides to the
+// resolved global strategies. Plain names replace the global set, names
+// prefixed with "+" are added to it and names prefixed with "-" removed.
+func ApplyStrategyOverrides(mask VersionStrategies, custom []string, overrides []string) (VersionStrategies, []string, error) {
+	var replace, add, remove []string
+	for _, override := range overrides {
+		override = strings.TrimSpace(override)
+		switch {
+		case strings.HasPrefix(override, "+"):
+			add = append(add, strings.TrimPrefix(override, "+"))
+		case strings.HasPrefix(override, "-"):
+			remove = append(remove, strings.TrimPrefix(override, "-"))
+		default:
+			replace = append(replace, override)
+		}
+	}
+
+	if len(replace) > 0 {
+		var err error
+		mask, custom, err = ResolveStrategies(replace)
+		if err != nil {
+			return None, nil, err
+		}
+	} else {
+		custom = append([]string(nil), custom...)
+	}
+
+	addMask, addCustom, err := ResolveStrategies(add)
+	if err != nil {
+		return None, nil, err
+	}
+	mask |= addMask
+	for _, name := range addCustom {
+		if !containsStrategy(custom, name) {
+			custom = append(custom, name)
+		}
+	}
+
+	removeMask, removeCustom, err := ResolveStrategies(remove)
+	if err != nil {
+		return None, nil, err
+	}
+	mask &^= removeMask
+	kept := custom[:0]
+	for _, name := range custom {
+		if !containsStrategy(removeCustom, name) {
+			kept = append(kept, name)
+		}
+	}
+
+	return mask, kept, nil
+}
+
+func containsStrategy(names []string, name string) bool {
+	for _, n := range names {
+		if normalizeStrategyName(n) == normalizeStrategyName(name) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDefaultStrategies returns the default set of strategies
 func GetDefaultStrategies() VersionStrategies {
 	return Fallback | ConfiguredNextVersion | MergeMessage | TaggedCommit | TrackReleaseBranches | VersionInBranchName
@@ -0,0 +1,364 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// setupTestRepo creates a temporary git repository and makes it the working
+// directory for the duration of the test.
+func setupTestRepo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+
+	runGit(t, "init", "-q")
+	runGit(t, "config", "user.name", "Test User")
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "config", "commit.gpgsign", "false")
+}
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+func TestMainlineStrategyIgnoresNonVersionTags(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "tag", "v2.1.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: bug")
+	runGit(t, "tag", "deploy-2024-01")
+
+	ctx := &VersionContext{
+		Repository:   git.NewRepository(),
+		Config:       &config.Config{},
+		BranchConfig: &config.BranchConfiguration{IsMainBranch: true},
+	}
+
+	baseVersions, err := (&MainlineStrategy{}).GetBaseVersions(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(baseVersions) != 1 {
+		t.Fatalf("Expected 1 base version, got %d", len(baseVersions))
+	}
+	if got := baseVersions[0].SemanticVersion.String(); got != "2.1.0" {
+		t.Errorf("Mainline base version = %s, want 2.1.0", got)
+	}
+	if baseVersions[0].BaseVersionSource == "" {
+		t.Errorf("Expected base version source to be the tagged commit")
+	}
+}
+
+func TestTaggedCommitStrategyRequiresSignedTags(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "tag", "-a", "v1.0.0", "-m", "Release v1.0.0")
+
+	ctx := &VersionContext{
+		Repository: git.NewRepository(),
+		Config:     &config.Config{},
+	}
+
+	baseVersions, err := (&TaggedCommitStrategy{}).GetBaseVersions(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(baseVersions) != 1 {
+		t.Fatalf("Expected the unsigned tag to count by default, got %d base versions", len(baseVersions))
+	}
+
+	ctx.Config.RequireSignedTags = true
+	baseVersions, err = (&TaggedCommitStrategy{}).GetBaseVersions(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(baseVersions) != 0 {
+		t.Errorf("Expected the unsigned tag to be ignored with require-signed-tags, got %d base versions", len(baseVersions))
+	}
+}
+
+func TestTaggedCommitStrategyTracksMergeTarget(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "checkout", "-q", "-b", "develop")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: new feature")
+	runGit(t, "checkout", "-q", "main")
+	runGit(t, "merge", "-q", "--no-ff", "-m", "Merge branch 'develop'", "develop")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "checkout", "-q", "develop")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "feat: next feature")
+
+	tests := []struct {
+		name             string
+		trackMergeTarget bool
+		expected         int
+	}{
+		{name: "Disabled", trackMergeTarget: false, expected: 0},
+		{name: "Enabled", trackMergeTarget: true, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &VersionContext{
+				Repository:   git.NewRepository(),
+				Config:       &config.Config{},
+				BranchConfig: &config.BranchConfiguration{TrackMergeTarget: tt.trackMergeTarget},
+			}
+
+			baseVersions, err := (&TaggedCommitStrategy{}).GetBaseVersions(ctx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(baseVersions) != tt.expected {
+				t.Fatalf("Expected %d base versions, got %d", tt.expected, len(baseVersions))
+			}
+			if tt.expected > 0 && baseVersions[0].SemanticVersion.String() != "1.0.0" {
+				t.Errorf("Base version = %s, want 1.0.0", baseVersions[0].SemanticVersion)
+			}
+		})
+	}
+}
+
+func TestMergeMessageStrategyReadsFullHistory(t *testing.T) {
+	setupTestRepo(t)
+
+	runGit(t, "checkout", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(t, "checkout", "-q", "-b", "release/1.3.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: one")
+	runGit(t, "checkout", "-q", "main")
+	runGit(t, "merge", "-q", "--no-ff", "-m", "Merge branch 'release/1.3.0'", "release/1.3.0")
+	runGit(t, "checkout", "-q", "-b", "release/1.4.0")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: two")
+	runGit(t, "checkout", "-q", "main")
+	runGit(t, "merge", "-q", "--no-ff", "-m", "Merge branch 'release/1.4.0'", "release/1.4.0")
+	// Far more commits than a fixed window of recent history holds
+	for i := 0; i < 60; i++ {
+		runGit(t, "commit", "-q", "--allow-empty", "-m", fmt.Sprintf("chore: %d", i))
+	}
+
+	ctx := &VersionContext{
+		Repository:   git.NewRepository(),
+		Config:       &config.Config{},
+		BranchConfig: &config.BranchConfiguration{TrackMergeMessage: true},
+	}
+	baseVersions, err := (&MergeMessageStrategy{}).GetBaseVersions(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The newest merge is the version source; older ones are not read
+	if len(baseVersions) != 1 || baseVersions[0].SemanticVersion.String() != "1.4.0" {
+		t.Errorf("GetBaseVersions() = %+v, want only 1.4.0", baseVersions)
+	}
+}
+
+type slowStrategy struct {
+	delay time.Duration
+}
+
+func (s *slowStrategy) GetName() string { return "Slow" }
+
+func (s *slowStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
+	time.Sleep(s.delay)
+	return []*BaseVersion{{SemanticVersion: &semver.Version{Major: 9}, Source: "slow"}}, nil
+}
+
+func TestGetBaseVersionsWithinDeadline(t *testing.T) {
+	if err := RegisterStrategy("Slow", &slowStrategy{delay: time.Second}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer UnregisterStrategy("Slow")
+
+	sm := NewStrategyManager(nil, &config.Config{})
+	ctx := &VersionContext{Config: &config.Config{}, Strategies: Fallback, Custom: []string{"Slow"}}
+
+	baseVersions, skipped, err := sm.GetBaseVersionsWithin(ctx, time.Now().Add(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != "Slow" {
+		t.Errorf("skipped = %v, want [Slow]", skipped)
+	}
+	if len(baseVersions) != 1 || baseVersions[0].Strategy != "Fallback" {
+		t.Errorf("Expected the Fallback result found before the deadline, got %+v", baseVersions)
+	}
+
+	// Without a deadline every strategy completes
+	UnregisterStrategy("Slow")
+	if err := RegisterStrategy("Slow", &slowStrategy{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	baseVersions, skipped, err = sm.GetBaseVersionsWithin(ctx, time.Time{})
+	if err != nil || len(skipped) != 0 || len(baseVersions) != 2 {
+		t.Errorf("Expected complete results, got %d versions, skipped %v, err %v", len(baseVersions), skipped, err)
+	}
+}
+
+// countingStrategy counts how often it runs
+type countingStrategy struct {
+	name string
+	runs *atomic.Int32
+}
+
+func (s *countingStrategy) GetName() string { return s.name }
+
+func (s *countingStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
+	s.runs.Add(1)
+	return nil, nil
+}
+
+func TestGetBaseVersionsWithinStopsStartingStrategies(t *testing.T) {
+	previous := maxParallelStrategies
+	maxParallelStrategies = 1
+	defer func() { maxParallelStrategies = previous }()
+
+	if err := RegisterStrategy("Slow", &slowStrategy{delay: 200 * time.Millisecond}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer UnregisterStrategy("Slow")
+	runs := &atomic.Int32{}
+	if err := RegisterStrategy("Counting", &countingStrategy{name: "Counting", runs: runs}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer UnregisterStrategy("Counting")
+
+	// Counting waits for the slot Slow holds past the deadline
+	sm := NewStrategyManager(nil, &config.Config{})
+	ctx := &VersionContext{Config: &config.Config{}, Custom: []string{"Slow", "Counting"}}
+	_, skipped, err := sm.GetBaseVersionsWithin(ctx, time.Now().Add(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("skipped = %v, want [Slow Counting]", skipped)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if n := runs.Load(); n != 0 {
+		t.Errorf("Counting ran %d times after the deadline, want none", n)
+	}
+}
+
+// rendezvousStrategy only completes once every strategy sharing started
+// has begun running
+type rendezvousStrategy struct {
+	name    string
+	major   int
+	started *sync.WaitGroup
+}
+
+func (s *rendezvousStrategy) GetName() string { return s.name }
+
+func (s *rendezvousStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
+	s.started.Done()
+	s.started.Wait()
+	// Finish in reverse order of evaluation
+	time.Sleep(time.Duration(10-s.major) * time.Millisecond)
+	return []*BaseVersion{{SemanticVersion: &semver.Version{Major: s.major}, Source: s.name}}, nil
+}
+
+func TestGetBaseVersionsRunsConcurrently(t *testing.T) {
+	previous := maxParallelStrategies
+	maxParallelStrategies = 3
+	defer func() { maxParallelStrategies = previous }()
+
+	started := &sync.WaitGroup{}
+	var names []string
+	for major := 1; major <= 3; major++ {
+		name := fmt.Sprintf("Rendezvous%d", major)
+		started.Add(1)
+		if err := RegisterStrategy(name, &rendezvousStrategy{name: name, major: major, started: started}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer UnregisterStrategy(name)
+		names = append(names, name)
+	}
+
+	// Run one at a time, the first strategy would wait for the others forever
+	sm := NewStrategyManager(nil, &config.Config{})
+	ctx := &VersionContext{Config: &config.Config{}, Custom: names}
+	baseVersions, skipped, err := sm.GetBaseVersionsWithin(ctx, time.Now().Add(5*time.Second))
+	if err != nil || len(skipped) != 0 {
+		t.Fatalf("GetBaseVersionsWithin() skipped %v, err %v", skipped, err)
+	}
+	var got []string
+	for _, bv := range baseVersions {
+		got = append(got, bv.Strategy)
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		t.Errorf("strategies = %v, want %v in evaluation order", got, names)
+	}
+}
+
+func TestVersionFileStrategy(t *testing.T) {
+	setupTestRepo(t)
+
+	ctx := &VersionContext{Repository: git.NewRepository(), Config: &config.Config{}}
+	strategy := &VersionFileStrategy{}
+
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	if baseVersions, err := strategy.GetBaseVersions(ctx); err != nil || len(baseVersions) != 0 {
+		t.Fatalf("Without a VERSION file got %v, %v", baseVersions, err)
+	}
+
+	if err := os.WriteFile("VERSION", []byte("v1.4.0-rc.1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write VERSION: %v", err)
+	}
+	runGit(t, "add", "VERSION")
+	runGit(t, "commit", "-q", "-m", "bump version")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "fix: bug")
+
+	baseVersions, err := strategy.GetBaseVersions(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(baseVersions) != 1 {
+		t.Fatalf("Expected 1 base version, got %d", len(baseVersions))
+	}
+	bv := baseVersions[0]
+	if got := bv.SemanticVersion.String(); got != "1.4.0-rc.1" {
+		t.Errorf("VersionFile base version = %s, want 1.4.0-rc.1", got)
+	}
+	if bv.ShouldIncrement {
+		t.Errorf("Expected the version file not to be incremented")
+	}
+	if sha, _ := ctx.Repository.ResolveCommit("HEAD~1"); bv.BaseVersionSource != sha {
+		t.Errorf("Base version source = %s, want the commit that changed VERSION %s", bv.BaseVersionSource, sha)
+	}
+
+	ctx.Config.VersionFile = "version.txt"
+	if err := os.WriteFile("version.txt", []byte("not a version\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write version.txt: %v", err)
+	}
+	if _, err := strategy.GetBaseVersions(ctx); err == nil {
+		t.Errorf("Expected an error for an invalid version file")
+	}
+}
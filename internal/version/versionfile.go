@@ -0,0 +1,62 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// DefaultVersionFile is the file read by the version file strategy when
+// version-file is not configured
+const DefaultVersionFile = "VERSION"
+
+// VersionFileStrategy implements the version file strategy. It reads the
+// version from a plain file such as VERSION, as many Go and C projects keep
+// one, and counts commits from the last commit that changed the file.
+type VersionFileStrategy struct{}
+
+func (v *VersionFileStrategy) GetName() string {
+	return "VersionFile"
+}
+
+func (v *VersionFileStrategy) GetBaseVersions(ctx *VersionContext) ([]*BaseVersion, error) {
+	path := DefaultVersionFile
+	if ctx.Config != nil && ctx.Config.VersionFile != "" {
+		path = ctx.Config.VersionFile
+	}
+
+	root, err := ctx.Repository.GetRootDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	text := strings.TrimSpace(string(content))
+	version, err := semver.Parse(strings.TrimPrefix(strings.TrimPrefix(text, "v"), "V"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version in %s: %w", path, err)
+	}
+
+	source, err := ctx.Repository.GetLastCommitForPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the last change of %s: %w", path, err)
+	}
+
+	return []*BaseVersion{
+		{
+			SemanticVersion:   version,
+			Source:            fmt.Sprintf("Version file '%s': %s", path, text),
+			ShouldIncrement:   false,
+			BaseVersionSource: source,
+		},
+	}, nil
+}
@@ -1,11 +1,11 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -30,6 +30,56 @@ const (
 	DeploymentContinuous         DeploymentMode = "ContinuousDeployment"
 )
 
+// CommitCountMode selects where commits are counted from for prerelease numbers
+type CommitCountMode string
+
+const (
+	// CountFromRepository counts every commit reachable from HEAD
+	CountFromRepository CommitCountMode = "Repository"
+	// CountFromBranchPoint counts commits since the merge-base with the nearest source branch
+	CountFromBranchPoint CommitCountMode = "BranchPoint"
+	// CountFromVersionSource counts commits since the commit of the selected base version
+	CountFromVersionSource CommitCountMode = "VersionSource"
+)
+
+// AssemblyVersioningScheme selects the components of AssemblySemVer
+type AssemblyVersioningScheme string
+
+const (
+	// AssemblyMajorMinorPatchTag uses the prerelease number as the fourth component
+	AssemblyMajorMinorPatchTag AssemblyVersioningScheme = "MajorMinorPatchTag"
+	AssemblyMajorMinorPatch    AssemblyVersioningScheme = "MajorMinorPatch"
+	AssemblyMajorMinor         AssemblyVersioningScheme = "MajorMinor"
+	AssemblyMajor              AssemblyVersioningScheme = "Major"
+	// AssemblyNone leaves AssemblySemVer empty
+	AssemblyNone AssemblyVersioningScheme = "None"
+)
+
+// TagSelection decides whether lightweight version tags count next to
+// annotated ones
+type TagSelection string
+
+const (
+	// TagSelectionAll counts annotated and lightweight tags alike
+	TagSelectionAll TagSelection = "All"
+	// TagSelectionPreferAnnotated ignores the lightweight version tags of a
+	// commit that also carries an annotated one
+	TagSelectionPreferAnnotated TagSelection = "PreferAnnotated"
+	// TagSelectionAnnotatedOnly ignores lightweight tags entirely
+	TagSelectionAnnotatedOnly TagSelection = "AnnotatedOnly"
+)
+
+// LatestTag decides which version tag reachable from HEAD is the latest
+type LatestTag string
+
+const (
+	// LatestTagNearest is the tag the fewest commits away, as git describe
+	// finds it
+	LatestTagNearest LatestTag = "Nearest"
+	// LatestTagHighest is the tag with the highest version
+	LatestTagHighest LatestTag = "Highest"
+)
+
 // PreventIncrementConfiguration defines when to prevent version increments
 type PreventIncrementConfiguration struct {
 	OfMergedBranch          bool `json:"of-merged-branch" yaml:"of-merged-branch"`
@@ -53,6 +103,14 @@ type BranchConfiguration struct {
 	IsReleaseBranch       bool                           `json:"is-release-branch" yaml:"is-release-branch"`
 	IsMainBranch          bool                           `json:"is-main-branch" yaml:"is-main-branch"`
 	PreReleaseWeight      int                            `json:"pre-release-weight" yaml:"pre-release-weight"`
+	AutoTag               bool                           `json:"auto-tag" yaml:"auto-tag"`
+	CommitsSince          CommitCountMode                `json:"commits-since" yaml:"commits-since"`
+	// IgnorePreReleaseTags ignores version tags with a prerelease, such as
+	// v1.5.0-rc.1, as base versions of the branch
+	IgnorePreReleaseTags bool `json:"ignore-pre-release-tags" yaml:"ignore-pre-release-tags"`
+	// Strategies overrides the global strategies for the branch: plain
+	// names replace the list, "+Name" adds and "-Name" removes a strategy.
+	Strategies []string `json:"strategies" yaml:"strategies"`
 }
 
 // Legacy BranchConfig for backward compatibility
@@ -67,6 +125,59 @@ type CommitMessageConfig struct {
 	IncrementMode string `json:"increment-mode" yaml:"increment-mode"`
 }
 
+// PublisherConfig enables an artifact publisher and carries its options
+type PublisherConfig struct {
+	Name    string            `json:"name" yaml:"name"`
+	Options map[string]string `json:"options" yaml:"options"`
+}
+
+// FileUpdateConfig names a file the update-files command writes the
+// version into and the writer that understands its format
+type FileUpdateConfig struct {
+	// Path is relative to the repository root
+	Path    string            `json:"path" yaml:"path"`
+	Writer  string            `json:"writer" yaml:"writer"`
+	Options map[string]string `json:"options" yaml:"options"`
+	// Create writes the file when it does not exist yet
+	Create bool `json:"create" yaml:"create"`
+}
+
+// PathIncrementRule gives the increment for commits changing files that
+// match a glob
+type PathIncrementRule struct {
+	// Path is a glob relative to the repository root, where * matches
+	// within a directory and ** any number of directories, e.g. docs/**
+	Path      string            `json:"path" yaml:"path"`
+	Increment IncrementStrategy `json:"increment" yaml:"increment"`
+}
+
+// ReleaseNotesConfig controls the release-notes command
+type ReleaseNotesConfig struct {
+	// RepositoryURL such as https://github.com/owner/repo enables links to
+	// commits, pull requests and the comparison with the previous release
+	RepositoryURL string `json:"repository-url" yaml:"repository-url"`
+	// Template is the path of a Go text/template file replacing the default
+	Template string `json:"template" yaml:"template"`
+}
+
+// RetryConfig limits retries of a remote operation. Delays are Go
+// durations such as "500ms" or "2s"; unset fields keep their defaults.
+type RetryConfig struct {
+	MaxAttempts  int      `json:"max-attempts" yaml:"max-attempts"`
+	InitialDelay string   `json:"initial-delay" yaml:"initial-delay"`
+	MaxDelay     string   `json:"max-delay" yaml:"max-delay"`
+	Multiplier   float64  `json:"multiplier" yaml:"multiplier"`
+	Jitter       *float64 `json:"jitter" yaml:"jitter"`
+}
+
+// CacheConfig bounds the calculation cache in the git directory. MaxAge
+// is a Go duration such as "168h"; MaxSize is a number of bytes with an
+// optional KB, MB or GB suffix. Unset fields keep their defaults.
+type CacheConfig struct {
+	MaxAge  string `json:"max-age" yaml:"max-age"`
+	MaxSize string `json:"max-size" yaml:"max-size"`
+}
+
 type Config struct {
 	NextVersion             string                          `json:"next-version" yaml:"next-version"`
 	Mode                    DeploymentMode                  `json:"mode" yaml:"mode"`
@@ -85,39 +196,106 @@ type Config struct {
 	Branches                map[string]*BranchConfiguration `json:"branches" yaml:"branches"`
 	Ignore                  map[string][]string             `json:"ignore" yaml:"ignore"`
 	CommitMessageIncrement  CommitMessageConfig             `json:"commit-message-incrementing" yaml:"commit-message-incrementing"`
+	Publishers              []PublisherConfig               `json:"publishers" yaml:"publishers"`
+	Retry                   map[string]*RetryConfig         `json:"retry" yaml:"retry"`
+
+	// AssemblyInformationalFormat is the template for InformationalVersion,
+	// with {Variable} and {env:NAME} placeholders
+	AssemblyInformationalFormat string `json:"assembly-informational-format" yaml:"assembly-informational-format"`
+	// DirtyBuildMetadata appends ".dirty" to the build metadata of versions
+	// calculated from a working tree with uncommitted changes
+	DirtyBuildMetadata bool `json:"dirty-build-metadata" yaml:"dirty-build-metadata"`
+	// AssemblyVersioningScheme selects the components of AssemblySemVer
+	AssemblyVersioningScheme AssemblyVersioningScheme `json:"assembly-versioning-scheme" yaml:"assembly-versioning-scheme"`
+	// AssemblyFileVersioningFormat is the template for AssemblySemFileVer,
+	// e.g. "{MajorMinorPatch}.{env:BUILD_NUMBER}"; empty keeps Major.Minor.Patch.0
+	AssemblyFileVersioningFormat string `json:"assembly-file-versioning-format" yaml:"assembly-file-versioning-format"`
+	// RequireSignedTags ignores version tags whose signature does not verify
+	RequireSignedTags bool `json:"require-signed-tags" yaml:"require-signed-tags"`
+	// TagSelection decides whether lightweight version tags count next to
+	// annotated ones; All when empty
+	TagSelection TagSelection `json:"tag-selection" yaml:"tag-selection"`
+	// LatestTag decides which reachable version tag is the latest, for the
+	// Mainline strategy, Describe and the commits counted since it;
+	// Nearest when empty
+	LatestTag LatestTag `json:"latest-tag" yaml:"latest-tag"`
+	// ReleaseNotes configures the release-notes command
+	ReleaseNotes ReleaseNotesConfig `json:"release-notes" yaml:"release-notes"`
+	// Workflow selects built-in branch configurations, e.g. GitFlow/v1.
+	// The branches section then overlays them field by field instead of
+	// replacing them, and the workflow applies when -w is not given.
+	Workflow WorkflowPreset `json:"workflow" yaml:"workflow"`
+	// Paths limits the commits that count towards the version to those
+	// touching these directories, relative to the repository root. A
+	// directory starting with "!" excludes commits that only touch it.
+	Paths []string `json:"paths" yaml:"paths"`
+	// Component versions one component of a monorepo independently: its
+	// version tags carry the component name as a prefix, e.g. api/v1.2.3,
+	// and tags of other components are ignored
+	Component string `json:"component" yaml:"component"`
+	// CommitScopes maps conventional commit scopes to components, e.g.
+	// api: api and ui: web, so that a commit like "feat(ui): ..." only
+	// counts towards the web component. Commits scoped to other
+	// components are ignored; the rest are left to paths.
+	CommitScopes map[string]string `json:"commit-scopes" yaml:"commit-scopes"`
+	// Projects lists the project directories, relative to the repository
+	// root, versioned by --all-projects. Without it every directory with a
+	// configuration file of its own is a project.
+	Projects []string `json:"projects" yaml:"projects"`
+	// FileUpdates lists the files the update-files command stamps with
+	// the calculated version
+	FileUpdates []FileUpdateConfig `json:"file-updates" yaml:"file-updates"`
+	// PathIncrements decide the increment from the files changed since
+	// the version source: each file takes the increment of the first rule
+	// matching it, or the branch's increment when none does or the rule's
+	// is Inherit, and the largest increment of all files applies. A change to docs/** only
+	// with the increment None is not released.
+	PathIncrements []PathIncrementRule `json:"path-increments" yaml:"path-increments"`
+	// VersionFile is the file, relative to the repository root, read by
+	// the VersionFile strategy; VERSION when empty
+	VersionFile string `json:"version-file" yaml:"version-file"`
+	// Cache bounds the calculation cache kept in the git directory
+	Cache *CacheConfig `json:"cache" yaml:"cache"`
+	// RemoteName is the primary remote: its tracking branches stand in for
+	// missing local branches, and it is fetched from and pushed to. origin
+	// when empty; forks name the repository they were forked from, e.g.
+	// upstream.
+	RemoteName string `json:"remote-name" yaml:"remote-name"`
 
 	// Legacy fields for backward compatibility
 	LegacyBranches map[string]BranchConfig `json:"-" yaml:"-"`
+	// Deprecations describes the GitVersion 5 keys found in the file and
+	// how they were mapped, one "file:line: message" each
+	Deprecations []string `json:"-" yaml:"-"`
 }
 
-func LoadConfig(configPath string) (*Config, error) {
-	if configPath == "" {
-		return getDefaultConfig(), nil
-	}
+// DefaultAssemblyInformationalFormat is GitVersion's InformationalVersion,
+// e.g. 1.2.3-beta.1+4.Branch.release-1-2.Sha.<sha>. It is built in code, so
+// the build metadata is joined after a single "+".
+const DefaultAssemblyInformationalFormat = "{InformationalVersion}"
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("configuration file not found: %s", configPath)
+// LoadConfig loads the configuration files in order, each deep-merged over
+// the ones before it, and applies the defaults. A file may name the files
+// it is layered over with an extends key. Without files, or with only
+// empty paths, the built-in configuration is returned.
+func LoadConfig(configPaths ...string) (*Config, error) {
+	config, _, err := loadConfig(&configLoader{}, configPaths)
+	return config, err
+}
+
+// loadConfig loads the files with l and also returns the merged document
+func loadConfig(l *configLoader, configPaths []string) (*Config, *yaml.Node, error) {
+	if !hasPath(configPaths) {
+		return getDefaultConfig(), nil, nil
 	}
 
-	data, err := os.ReadFile(configPath)
+	doc, err := l.loadAll(configPaths)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, nil, err
 	}
-
-	config := &Config{}
-	ext := strings.ToLower(filepath.Ext(configPath))
-
-	switch ext {
-	case ".json":
-		if err := json.Unmarshal(data, config); err != nil {
-			return nil, fmt.Errorf("failed to parse JSON config: %w", err)
-		}
-	case ".yml", ".yaml":
-		if err := yaml.Unmarshal(data, config); err != nil {
-			return nil, fmt.Errorf("failed to parse YAML config: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported configuration file format: %s", ext)
+	config := &Config{Deprecations: l.deprecations}
+	if err := doc.Decode(config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
 	// Set defaults for empty fields
@@ -139,6 +317,12 @@ func LoadConfig(configPath string) (*Config, error) {
 	if config.SemanticVersionFormat == "" {
 		config.SemanticVersionFormat = "Strict"
 	}
+	if config.AssemblyInformationalFormat == "" {
+		config.AssemblyInformationalFormat = DefaultAssemblyInformationalFormat
+	}
+	if config.AssemblyVersioningScheme == "" {
+		config.AssemblyVersioningScheme = AssemblyMajorMinorPatch
+	}
 	if len(config.Strategies) == 0 {
 		config.Strategies = []string{
 			"Fallback",
@@ -150,12 +334,27 @@ func LoadConfig(configPath string) (*Config, error) {
 		}
 	}
 
+	if config.Workflow != "" {
+		if err := applyWorkflow(config, doc); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Initialize branch configurations if not present
 	if config.Branches == nil {
 		config.Branches = getDefaultBranchConfigurations()
 	}
 
-	return config, nil
+	return config, doc, nil
+}
+
+func hasPath(paths []string) bool {
+	for _, path := range paths {
+		if path != "" {
+			return true
+		}
+	}
+	return false
 }
 
 func getDefaultConfig() *Config {
@@ -164,10 +363,10 @@ func getDefaultConfig() *Config {
 		Mode:                    DeploymentContinuousDelivery,
 		Increment:               IncrementInherit,
 		TagPrefix:               "[vV]",
-		MajorVersionBumpMessage: `\+semver:\s?(breaking|major)`,
-		MinorVersionBumpMessage: `\+semver:\s?(feature|minor)`,
-		PatchVersionBumpMessage: `\+semver:\s?(fix|patch)`,
-		NoBumpMessage:           `\+semver:\s?(none|skip)`,
+		MajorVersionBumpMessage: `(?m)\+semver:\s?(breaking|major)\s*$`,
+		MinorVersionBumpMessage: `(?m)\+semver:\s?(feature|minor)\s*$`,
+		PatchVersionBumpMessage: `(?m)\+semver:\s?(fix|patch)\s*$`,
+		NoBumpMessage:           `(?m)\+semver:\s?(none|skip)\s*$`,
 		TagPreReleaseWeight:     60000,
 		CommitDateFormat:        "yyyy-MM-dd",
 		UpdateBuildNumber:       true,
@@ -189,6 +388,8 @@ func getDefaultConfig() *Config {
 			Enabled:       true,
 			IncrementMode: "Enabled",
 		},
+		AssemblyInformationalFormat: DefaultAssemblyInformationalFormat,
+		AssemblyVersioningScheme:    AssemblyMajorMinorPatch,
 	}
 }
 
@@ -251,6 +452,7 @@ func getDefaultBranchConfigurations() map[string]*BranchConfiguration {
 			TrackMergeMessage:     true,
 			Regex:                 `^features?[\/-](?<BranchName>.+)`,
 			SourceBranches:        []string{"develop", "main", "release", "support", "hotfix"},
+			CommitsSince:          CountFromBranchPoint,
 			IsSourceBranchFor:     []string{},
 			TracksReleaseBranches: false,
 			IsReleaseBranch:       false,
@@ -320,27 +522,39 @@ func (c *Config) GetBranchConfig(branchName string) *BranchConfig {
 }
 
 func (c *Config) GetBranchConfiguration(branchName string) *BranchConfiguration {
+	_, config := c.ResolveBranchConfiguration(branchName)
+	return config
+}
+
+// ResolveBranchConfiguration returns the configuration for a branch and the
+// key it is configured under, "" for the built-in fallback. An exact key
+// match wins, then the first key whose regex matches, then the first key
+// that is a prefix of the branch ("feature" for "feature/x"). Keys are tried
+// in name order so the result does not depend on map iteration.
+func (c *Config) ResolveBranchConfiguration(branchName string) (string, *BranchConfiguration) {
 	// Try exact match first
 	if config, exists := c.Branches[branchName]; exists {
-		return config
+		return branchName, config
 	}
 
+	keys := c.branchKeys()
+
 	// Try regex matching
-	for _, config := range c.Branches {
-		if config.Regex != "" && matchesRegex(branchName, config.Regex) {
-			return config
+	for _, key := range keys {
+		if config := c.Branches[key]; config != nil && config.Regex != "" && matchesRegex(branchName, config.Regex) {
+			return key, config
 		}
 	}
 
 	// Try prefix matching as fallback
-	for branchType, config := range c.Branches {
-		if strings.HasPrefix(branchName, branchType+"/") {
-			return config
+	for _, key := range keys {
+		if strings.HasPrefix(branchName, key+"/") {
+			return key, c.Branches[key]
 		}
 	}
 
 	// Return default configuration
-	return &BranchConfiguration{
+	return "", &BranchConfiguration{
 		Mode:              DeploymentManual,
 		Label:             "{BranchName}",
 		Increment:         IncrementPatch,
@@ -353,29 +567,30 @@ func (c *Config) GetBranchConfiguration(branchName string) *BranchConfiguration
 	}
 }
 
-func matchesRegex(branchName, pattern string) bool {
-	// Simple regex matching - in a real implementation you'd use regexp package
-	// For now, handle basic cases
-	if pattern == "^(master|main)$" {
-		return branchName == "master" || branchName == "main"
-	}
-	if pattern == "^dev(elop)?(ment)?$" {
-		return branchName == "dev" || branchName == "develop" || branchName == "development"
-	}
-	if strings.Contains(pattern, "releases?") {
-		return strings.HasPrefix(branchName, "release/") || strings.HasPrefix(branchName, "releases/")
-	}
-	if strings.Contains(pattern, "features?") {
-		return strings.HasPrefix(branchName, "feature/") || strings.HasPrefix(branchName, "features/")
-	}
-	if strings.Contains(pattern, "hotfix") {
-		return strings.HasPrefix(branchName, "hotfix/") || strings.HasPrefix(branchName, "hotfixes/")
+// branchKeys returns the configured branch keys in name order
+func (c *Config) branchKeys() []string {
+	keys := make([]string, 0, len(c.Branches))
+	for key := range c.Branches {
+		keys = append(keys, key)
 	}
-	if strings.Contains(pattern, "support") {
-		return strings.HasPrefix(branchName, "support/")
-	}
-	if strings.Contains(pattern, "pull") {
-		return strings.HasPrefix(branchName, "pull/") || strings.HasPrefix(branchName, "pr/")
+	sort.Strings(keys)
+	return keys
+}
+
+// branchRegexes caches the compiled branch regexes by pattern
+var branchRegexes sync.Map
+
+// matchesRegex reports whether the branch regex pattern matches branchName.
+// A pattern that does not compile matches nothing; config validate reports
+// it.
+func matchesRegex(branchName, pattern string) bool {
+	re, ok := branchRegexes.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(goRegex(pattern))
+		if err != nil {
+			return false
+		}
+		re, _ = branchRegexes.LoadOrStore(pattern, compiled)
 	}
-	return false
+	return re.(*regexp.Regexp).MatchString(branchName)
 }
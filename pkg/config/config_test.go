@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -147,3 +148,38 @@ func TestGetBranchConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadConfigTOML(t *testing.T) {
+	path := writeConfig(t, "GitVersion.toml", `next-version = "2.0.0"
+tag-prefix = '[vV]?'
+
+[branches.main]
+increment = "Minor"
+regex = '^(master|main)$'
+is-main-branch = true
+
+[[publishers]]
+name = "github-release"
+options = { token-env = "GH_TOKEN" }
+`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.NextVersion != "2.0.0" || config.TagPrefix != "[vV]?" {
+		t.Errorf("next-version = %q, tag-prefix = %q", config.NextVersion, config.TagPrefix)
+	}
+	if main := config.Branches["main"]; main == nil || main.Increment != IncrementMinor || !main.IsMainBranch {
+		t.Errorf("main = %+v", main)
+	}
+	if len(config.Publishers) != 1 || config.Publishers[0].Options["token-env"] != "GH_TOKEN" {
+		t.Errorf("publishers = %+v", config.Publishers)
+	}
+
+	invalid := writeConfig(t, "GitVersion.toml", "[branches.main]\nincrement = \"Often\"\n")
+	err = Validate(invalid)
+	if err == nil || !strings.Contains(err.Error(), "GitVersion.toml:2:13: branches.main.increment") {
+		t.Errorf("Validate() error = %v, want the TOML line and column", err)
+	}
+}
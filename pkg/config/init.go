@@ -0,0 +1,328 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// Workflows the init wizard can write a configuration for. They match the
+// values of the -w flag.
+const (
+	WorkflowGitFlow    = "gitflow"
+	WorkflowGitHubFlow = "githubflow"
+	WorkflowTrunk      = "trunk"
+)
+
+// InitOptions are the answers of the init wizard
+type InitOptions struct {
+	Workflow string
+	// TagPrefix is the literal prefix of version tags, e.g. "v"
+	TagPrefix  string
+	MainBranch string
+}
+
+// DefaultInitOptions returns the answers "gitversion init --defaults" uses
+func DefaultInitOptions() InitOptions {
+	return InitOptions{Workflow: WorkflowGitFlow, TagPrefix: "v", MainBranch: "main"}
+}
+
+// Validate checks the answers before a configuration is generated
+func (o InitOptions) Validate() error {
+	switch o.Workflow {
+	case WorkflowGitFlow, WorkflowGitHubFlow, WorkflowTrunk:
+	default:
+		return fmt.Errorf("unknown workflow %q (use gitflow, githubflow or trunk)", o.Workflow)
+	}
+	if strings.TrimSpace(o.MainBranch) == "" || strings.ContainsAny(o.MainBranch, " \t:") {
+		return fmt.Errorf("invalid main branch name %q", o.MainBranch)
+	}
+	return nil
+}
+
+// initBranch is one branch section of a generated configuration
+type initBranch struct {
+	key     string
+	comment string
+	lines   []string
+}
+
+// GenerateYAML writes a commented GitVersion.yml for the answers. The
+// result loads with LoadConfig.
+func GenerateYAML(opts InitOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# GitVersion configuration generated by \"gitversion init\".\n\n")
+
+	b.WriteString("# Built-in branch configurations the branches below overlay; also\n")
+	b.WriteString("# selects the workflow when gitversion runs without -w\n")
+	fmt.Fprintf(&b, "workflow: %s\n\n", presetFor(opts.Workflow))
+
+	b.WriteString("# Version used until the first version tag exists\n")
+	b.WriteString("next-version: 0.1.0\n\n")
+	b.WriteString("# Regular expression for the prefix of version tags\n")
+	fmt.Fprintf(&b, "tag-prefix: %s\n\n", quoteYAML(tagPrefixPattern(opts.TagPrefix)))
+	b.WriteString("# Bump the version from commit messages such as \"feat:\" or \"+semver: minor\";\n")
+	b.WriteString("# increment-mode is Enabled, MergeMessageOnly or Disabled\n")
+	b.WriteString("commit-message-incrementing:\n  enabled: true\n  increment-mode: Enabled\n\n")
+
+	b.WriteString("# Branch configurations. The first whose regex matches a branch applies;\n")
+	b.WriteString("# branches matching none get patch increments and the branch name as label.\n")
+	b.WriteString("branches:\n")
+	for i, branch := range initBranches(opts) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  # %s\n  %s:\n", branch.comment, branch.key)
+		for _, line := range branch.lines {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	return b.String(), nil
+}
+
+// presetFor returns the workflow preset matching a -w value
+func presetFor(workflow string) WorkflowPreset {
+	switch workflow {
+	case WorkflowGitHubFlow:
+		return PresetGitHubFlow
+	case WorkflowTrunk:
+		return PresetTrunkBased
+	}
+	return PresetGitFlow
+}
+
+// tagPrefixPattern turns a literal prefix into the tag-prefix pattern. The
+// usual "v" also accepts "V", as the default does.
+func tagPrefixPattern(prefix string) string {
+	if strings.EqualFold(prefix, "v") {
+		return "[vV]"
+	}
+	return regexp.QuoteMeta(prefix)
+}
+
+// TagPrefixLiteral returns the prefix new version tags get for the
+// tag-prefix pattern, the reverse of tagPrefixPattern: "v" for "[vV]" and
+// "release-" for "release-". Optional parts are written, the first
+// alternative is taken, and a pattern with no such prefix gives "v".
+func TagPrefixLiteral(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "v"
+	}
+	if prefix, ok := literalPrefix(re.Simplify()); ok && prefix != "" {
+		return prefix
+	}
+	return "v"
+}
+
+// literalPrefix returns a string re matches, if re only matches fixed text
+func literalPrefix(re *syntax.Regexp) (string, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpBeginText:
+		return "", true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return strings.ToLower(string(re.Rune)), true
+		}
+		return string(re.Rune), true
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return "", false
+		}
+		r := re.Rune[0]
+		if lower := unicode.ToLower(r); classContains(re.Rune, lower) {
+			r = lower
+		}
+		return string(r), true
+	case syntax.OpCapture, syntax.OpQuest, syntax.OpPlus, syntax.OpAlternate:
+		return literalPrefix(re.Sub[0])
+	case syntax.OpConcat:
+		var b strings.Builder
+		for _, sub := range re.Sub {
+			part, ok := literalPrefix(sub)
+			if !ok {
+				return "", false
+			}
+			b.WriteString(part)
+		}
+		return b.String(), true
+	}
+	return "", false
+}
+
+// classContains reports whether the ranges of a character class contain r
+func classContains(ranges []rune, r rune) bool {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] <= r && r <= ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteYAML single-quotes s, so regex characters need no escaping
+func quoteYAML(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// mainBranch returns the key and regex of the main branch. main and master
+// keep the built-in key; other names become the key, which matches exactly.
+func mainBranch(name string) (string, string) {
+	if name == "main" || name == "master" {
+		return "main", "^(master|main)$"
+	}
+	return name, "^" + regexp.QuoteMeta(name) + "$"
+}
+
+func initBranches(opts InitOptions) []initBranch {
+	mainKey, mainRegex := mainBranch(opts.MainBranch)
+	sources := func(keys ...string) string {
+		for i, key := range keys {
+			if key == "main" {
+				keys[i] = mainKey
+			}
+		}
+		return "source-branches: [" + strings.Join(keys, ", ") + "]"
+	}
+
+	mainMode := DeploymentManual
+	if opts.Workflow == WorkflowTrunk {
+		mainMode = DeploymentContinuous
+	}
+	branches := []initBranch{{
+		key:     mainKey,
+		comment: "Releases are tagged on " + opts.MainBranch,
+		lines: []string{
+			"mode: " + string(mainMode),
+			"increment: Patch",
+			"label: ''",
+			"regex: " + quoteYAML(mainRegex),
+			"is-main-branch: true",
+			"track-merge-message: true",
+			sources(),
+			"pre-release-weight: 55000",
+		},
+	}}
+
+	if opts.Workflow == WorkflowGitFlow {
+		branches = append(branches, initBranch{
+			key:     "develop",
+			comment: "Integration branch; builds are alpha prereleases of the next minor",
+			lines: []string{
+				"mode: ContinuousDelivery",
+				"increment: Minor",
+				"label: alpha",
+				`regex: '^dev(elop)?(ment)?$'`,
+				"track-merge-target: true",
+				"tracks-release-branches: true",
+				sources("main"),
+				"pre-release-weight: 0",
+			},
+		})
+	}
+
+	if opts.Workflow != WorkflowTrunk {
+		releaseSources := sources("main")
+		if opts.Workflow == WorkflowGitFlow {
+			releaseSources = sources("develop", "main", "support")
+		}
+		branches = append(branches, initBranch{
+			key:     "release",
+			comment: "Release candidates, e.g. release/1.2.0 builds 1.2.0-beta.N",
+			lines: []string{
+				"mode: ManualDeployment",
+				"increment: None",
+				"label: beta",
+				`regex: '^releases?[\/-](?<BranchName>.+)'`,
+				"is-release-branch: true",
+				"prevent-increment:",
+				"  of-merged-branch: true",
+				releaseSources,
+				"pre-release-weight: 30000",
+			},
+		})
+	}
+
+	featureSources := sources("main")
+	if opts.Workflow == WorkflowGitFlow {
+		featureSources = sources("develop", "main", "release", "support", "hotfix")
+	}
+	branches = append(branches, initBranch{
+		key:     "feature",
+		comment: "Feature branches are labeled with their name, e.g. feature/login",
+		lines: []string{
+			"mode: ManualDeployment",
+			"increment: Inherit",
+			"label: '{BranchName}'",
+			`regex: '^features?[\/-](?<BranchName>.+)'`,
+			"commits-since: BranchPoint",
+			featureSources,
+			"pre-release-weight: 30000",
+		},
+	})
+
+	if opts.Workflow != WorkflowGitHubFlow {
+		hotfixSources := sources("main")
+		if opts.Workflow == WorkflowGitFlow {
+			hotfixSources = sources("main", "support")
+		}
+		branches = append(branches, initBranch{
+			key:     "hotfix",
+			comment: "Urgent fixes for a released version",
+			lines: []string{
+				"mode: ManualDeployment",
+				"increment: Inherit",
+				"label: beta",
+				`regex: '^hotfix(es)?[\/-](?<BranchName>.+)'`,
+				"is-release-branch: true",
+				hotfixSources,
+				"pre-release-weight: 30000",
+			},
+		})
+	}
+
+	if opts.Workflow == WorkflowGitFlow {
+		branches = append(branches, initBranch{
+			key:     "support",
+			comment: "Maintenance of older major versions, e.g. support/1.x",
+			lines: []string{
+				"increment: Patch",
+				"label: ''",
+				`regex: '^support[\/-](?<BranchName>.+)'`,
+				sources("main"),
+				"pre-release-weight: 55000",
+			},
+		})
+	}
+
+	var prSources []string
+	switch opts.Workflow {
+	case WorkflowGitFlow:
+		prSources = []string{"develop", "main", "release", "feature", "support", "hotfix"}
+	case WorkflowTrunk:
+		prSources = []string{"main", "feature", "hotfix"}
+	case WorkflowGitHubFlow:
+		prSources = []string{"main", "release", "feature"}
+	}
+	branches = append(branches, initBranch{
+		key:     "pull-request",
+		comment: "Pull request builds, labeled with the pull request number",
+		lines: []string{
+			"mode: ContinuousDelivery",
+			"increment: Inherit",
+			"label: 'PullRequest{Number}'",
+			`regex: '^(pull-requests|pull|pr)[\/-](?<Number>\d*)'`,
+			"prevent-increment:",
+			"  of-merged-branch: true",
+			sources(prSources...),
+			"pre-release-weight: 30000",
+		},
+	})
+	return branches
+}
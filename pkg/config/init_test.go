@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateYAML(t *testing.T) {
+	tests := []struct {
+		opts         InitOptions
+		expectedKeys []string
+		mainKey      string
+	}{
+		{
+			opts:         DefaultInitOptions(),
+			expectedKeys: []string{"develop", "feature", "hotfix", "main", "pull-request", "release", "support"},
+			mainKey:      "main",
+		},
+		{
+			opts:         InitOptions{Workflow: WorkflowGitHubFlow, TagPrefix: "release-", MainBranch: "master"},
+			expectedKeys: []string{"feature", "main", "pull-request", "release"},
+			mainKey:      "main",
+		},
+		{
+			// The preset's main configuration stays next to the custom one
+			opts:         InitOptions{Workflow: WorkflowTrunk, TagPrefix: "", MainBranch: "trunk"},
+			expectedKeys: []string{"feature", "hotfix", "main", "pull-request", "trunk"},
+			mainKey:      "trunk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.opts.Workflow, func(t *testing.T) {
+			content, err := GenerateYAML(tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateYAML() error = %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "GitVersion.yml")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("Generated configuration does not load: %v\n%s", err, content)
+			}
+			if keys := cfg.branchKeys(); !reflect.DeepEqual(keys, tt.expectedKeys) {
+				t.Errorf("Branch keys = %v, want %v", keys, tt.expectedKeys)
+			}
+			key, branch := cfg.ResolveBranchConfiguration(tt.opts.MainBranch)
+			if key != tt.mainKey || !branch.IsMainBranch {
+				t.Errorf("%s resolves to %s (main=%v), want %s", tt.opts.MainBranch, key, branch.IsMainBranch, tt.mainKey)
+			}
+			if overlaps := cfg.BranchOverlaps(); len(overlaps) != 0 {
+				t.Errorf("Expected no overlapping branch configurations, got %+v", overlaps)
+			}
+		})
+	}
+}
+
+func TestGenerateYAMLTagPrefix(t *testing.T) {
+	for prefix, expected := range map[string]string{"v": "[vV]", "release-": "release-", "v.": `v\.`} {
+		content, err := GenerateYAML(InitOptions{Workflow: WorkflowTrunk, TagPrefix: prefix, MainBranch: "main"})
+		if err != nil {
+			t.Fatalf("GenerateYAML() error = %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "GitVersion.yml")
+		os.WriteFile(path, []byte(content), 0o644)
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if cfg.TagPrefix != expected {
+			t.Errorf("tag-prefix for %q = %q, want %q", prefix, cfg.TagPrefix, expected)
+		}
+	}
+}
+
+func TestGenerateYAMLInvalidOptions(t *testing.T) {
+	for _, opts := range []InitOptions{
+		{Workflow: "scrum", MainBranch: "main"},
+		{Workflow: WorkflowGitFlow, MainBranch: ""},
+		{Workflow: WorkflowGitFlow, MainBranch: "my branch"},
+	} {
+		if _, err := GenerateYAML(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestTagPrefixLiteral(t *testing.T) {
+	tests := map[string]string{
+		"[vV]":           "v",
+		"v":              "v",
+		"release-":       "release-",
+		`release\.`:      "release.",
+		"(?i)v":          "v",
+		"[vV]?":          "v",
+		"(release|rel)-": "release-",
+		"":               "v",
+		".*":             "v",
+		"[":              "v",
+	}
+	for pattern, expected := range tests {
+		if got := TagPrefixLiteral(pattern); got != expected {
+			t.Errorf("TagPrefixLiteral(%q) = %q, want %q", pattern, got, expected)
+		}
+		if got := TagPrefixLiteral(tagPrefixPattern(expected)); got != expected {
+			t.Errorf("TagPrefixLiteral(tagPrefixPattern(%q)) = %q", expected, got)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the names of configuration files looked up in a
+// directory, in order of preference
+var FileNames = []string{"GitVersion.yml", "GitVersion.yaml", "GitVersion.json", "GitVersion.toml"}
+
+// Find returns the configuration file in dir, or "" when there is none
+func Find(dir string) string {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ProjectFiles layers the configuration file of a project directory, given
+// relative to the repository root, over configPaths. Without configPaths
+// the configuration file in the root is the base. A project without a
+// file of its own uses the base alone.
+func ProjectFiles(root, project string, configPaths []string) ([]string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(project))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("project directory not found: %s", project)
+	}
+
+	files := append([]string(nil), configPaths...)
+	if !hasPath(files) {
+		if path := Find(root); path != "" {
+			files = append(files, path)
+		}
+	}
+	if path := Find(dir); path != "" && filepath.Clean(dir) != filepath.Clean(root) {
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// Projects returns the project directories of the repository in root,
+// relative to it: the projects list of the root configuration, which is
+// configPaths or else the file in root, or otherwise every directory with
+// a configuration file of its own. Directories named vendor or
+// node_modules and those starting with "." are not searched.
+func Projects(root string, configPaths []string) ([]string, error) {
+	files := configPaths
+	if !hasPath(files) {
+		files = []string{Find(root)}
+	}
+	cfg, err := LoadConfig(files...)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Projects) > 0 {
+		return cfg.Projects, nil
+	}
+
+	var projects []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		if name := d.Name(); name == "vendor" || name == "node_modules" || strings.HasPrefix(name, ".") {
+			return filepath.SkipDir
+		}
+		if Find(path) != "" {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			projects = append(projects, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover projects: %w", err)
+	}
+	return projects, nil
+}
+
+// configLoader reads configuration files, and the files they extend, into
+// one merged document
+type configLoader struct {
+	// validate checks every file read and collects the problems in errors
+	validate     bool
+	errors       ValidationErrors
+	deprecations []string
+	// files are the files read, each after the files it extends
+	files []string
+	// loading is the chain of files being read, to report cycles
+	loading []string
+	// branchKeys are where each branch configuration is last set, when
+	// validating
+	branchKeys map[string]keyPosition
+}
+
+// keyPosition is a key node and the file it is in
+type keyPosition struct {
+	file string
+	node *yaml.Node
+}
+
+// loadAll merges the files in order, later files overriding earlier ones.
+// Empty paths are skipped; without files the document is an empty mapping.
+func (l *configLoader) loadAll(paths []string) (*yaml.Node, error) {
+	var merged *yaml.Node
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if !isURL(path) {
+			path = filepath.Clean(path)
+		}
+		doc, err := l.load(path)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, doc)
+	}
+	if merged == nil {
+		merged = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return merged, nil
+}
+
+// load reads one file or URL and merges it over the files its extends key
+// names, which are relative to its directory or URL
+func (l *configLoader) load(path string) (*yaml.Node, error) {
+	for i, loading := range l.loading {
+		if loading == path {
+			return nil, fmt.Errorf("configuration files extend each other: %s", strings.Join(append(l.loading[i:], path), " -> "))
+		}
+	}
+
+	root, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	l.deprecations = append(l.deprecations, migrateLegacy(path, root)...)
+	extends := takeExtends(root)
+	if l.validate {
+		v := &validator{file: path}
+		v.walk(root, reflect.TypeOf(Config{}), "")
+		if extends != nil {
+			v.walk(extends, reflect.TypeOf([]string{}), "extends")
+		}
+		l.errors = append(l.errors, v.errors...)
+	}
+
+	l.loading = append(l.loading, path)
+	var merged *yaml.Node
+	if extends != nil {
+		if extends.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("%s:%d: extends must name a file or a list of files", path, extends.Line)
+		}
+		for _, item := range extends.Content {
+			if item.Kind != yaml.ScalarNode || item.Value == "" {
+				return nil, fmt.Errorf("%s:%d: extends must name a file or a list of files", path, item.Line)
+			}
+			base, err := resolveExtends(path, item.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid extends %s: %w", path, item.Line, item.Value, err)
+			}
+			doc, err := l.load(base)
+			if err != nil {
+				return nil, err
+			}
+			merged = mergeNodes(merged, doc)
+		}
+	}
+	l.loading = l.loading[:len(l.loading)-1]
+
+	if l.validate {
+		l.recordBranchKeys(path, root)
+	}
+	l.files = append(l.files, path)
+	return mergeNodes(merged, root), nil
+}
+
+// recordBranchKeys records the branch keys of root, a file read after the
+// files it extends, so later files win
+func (l *configLoader) recordBranchKeys(path string, root *yaml.Node) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "branches" || root.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		branches := root.Content[i+1].Content
+		for j := 0; j+1 < len(branches); j += 2 {
+			if l.branchKeys == nil {
+				l.branchKeys = make(map[string]keyPosition)
+			}
+			l.branchKeys[branches[j].Value] = keyPosition{file: path, node: branches[j]}
+		}
+	}
+}
+
+// readDocument parses a YAML, JSON or TOML configuration file or URL into
+// its root mapping. JSON is read as YAML, which it is a subset of, and TOML
+// into the same node tree, so every format decodes the same way.
+func readDocument(path string) (*yaml.Node, error) {
+	data, err := readSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	switch ext := configExt(path); ext {
+	case ".json":
+		var probe interface{}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config %s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config %s: %w", path, err)
+		}
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config %s: %w", path, err)
+		}
+	case ".toml":
+		root, err := toml.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TOML config %s: %w", path, err)
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("unsupported configuration file format: %s", ext)
+	}
+
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config %s: expected a mapping at line %d", path, root.Line)
+	}
+	return root, nil
+}
+
+// takeExtends removes the extends key from root and returns its value as
+// a list; a single file may be given as a string
+func takeExtends(root *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "extends" {
+			continue
+		}
+		value := root.Content[i+1]
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		switch {
+		case isNull(value):
+			return nil
+		case value.Kind == yaml.ScalarNode:
+			return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: value.Line, Column: value.Column,
+				Content: []*yaml.Node{value}}
+		}
+		return value
+	}
+	return nil
+}
+
+// mergeNodes deep-merges override onto base. Mappings merge key by key, so
+// a branch in override only changes the fields it sets; lists and scalars
+// are replaced. Neither node is modified.
+func mergeNodes(base, override *yaml.Node) *yaml.Node {
+	if base != nil && base.Kind == yaml.AliasNode {
+		base = base.Alias
+	}
+	if override.Kind == yaml.AliasNode {
+		override = override.Alias
+	}
+	if base == nil || base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
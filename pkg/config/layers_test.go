@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigs writes the files into one directory and returns it
+func writeConfigs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	return dir
+}
+
+const orgConfig = `tag-prefix: 'release-'
+strategies: [Fallback, TaggedCommit]
+branches:
+  main:
+    regex: ^(master|main)$
+    increment: Minor
+    is-main-branch: true
+    source-branches: [develop]
+  develop:
+    regex: ^dev(elop)?(ment)?$
+    label: alpha
+`
+
+func TestLoadConfigLayers(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"org.yml": orgConfig,
+		"repo.json": `{
+  "next-version": "2.0.0",
+  "strategies": ["Fallback"],
+  "branches": {"main": {"increment": "Patch", "source-branches": []}}
+}`,
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "org.yml"), filepath.Join(dir, "repo.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if cfg.TagPrefix != "release-" || cfg.NextVersion != "2.0.0" {
+		t.Errorf("tag-prefix = %q, next-version = %q, want both files' values", cfg.TagPrefix, cfg.NextVersion)
+	}
+	if !reflect.DeepEqual(cfg.Strategies, []string{"Fallback"}) {
+		t.Errorf("strategies = %v, want the later list to replace the earlier", cfg.Strategies)
+	}
+	if keys := cfg.branchKeys(); !reflect.DeepEqual(keys, []string{"develop", "main"}) {
+		t.Errorf("Branch keys = %v", keys)
+	}
+	main := cfg.Branches["main"]
+	if main.Increment != IncrementPatch || main.Regex != "^(master|main)$" || !main.IsMainBranch {
+		t.Errorf("main = %+v, want the org branch with increment Patch", main)
+	}
+	if len(main.SourceBranches) != 0 {
+		t.Errorf("main source-branches = %v, want the empty override", main.SourceBranches)
+	}
+	if develop := cfg.Branches["develop"]; develop.Label != "alpha" {
+		t.Errorf("develop label = %q, want the org value", develop.Label)
+	}
+}
+
+func TestLoadConfigExtends(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"shared/org.yml":    orgConfig,
+		"shared/labels.yml": "branches:\n  develop:\n    label: beta\n",
+		"GitVersion.yml": `extends: [shared/org.yml, shared/labels.yml]
+branches:
+  main:
+    increment: Major
+`,
+		"single.yml": "extends: shared/org.yml\nnext-version: 3.0.0\n",
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "GitVersion.yml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.TagPrefix != "release-" {
+		t.Errorf("tag-prefix = %q, want the extended value", cfg.TagPrefix)
+	}
+	if main := cfg.Branches["main"]; main.Increment != IncrementMajor || !main.IsMainBranch {
+		t.Errorf("main = %+v, want the org branch with increment Major", main)
+	}
+	if develop := cfg.Branches["develop"]; develop.Label != "beta" || develop.Regex == "" {
+		t.Errorf("develop = %+v, want the org branch with the later label", develop)
+	}
+
+	cfg, err = LoadConfig(filepath.Join(dir, "single.yml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.TagPrefix != "release-" || cfg.NextVersion != "3.0.0" {
+		t.Errorf("tag-prefix = %q, next-version = %q", cfg.TagPrefix, cfg.NextVersion)
+	}
+}
+
+func TestLoadConfigExtendsErrors(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"a.yml":       "extends: b.yml\n",
+		"b.yml":       "extends: a.yml\n",
+		"missing.yml": "extends: nowhere.yml\n",
+		"mapping.yml": "extends:\n  file: a.yml\n",
+	})
+
+	tests := map[string]string{
+		"a.yml":       "extend each other",
+		"missing.yml": "configuration file not found",
+		"mapping.yml": "extends must name a file",
+	}
+	for name, want := range tests {
+		_, err := LoadConfig(filepath.Join(dir, name))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%s) error = %v, want %q", name, err, want)
+		}
+	}
+}
+
+func TestValidateLayers(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"org.yml":        "branches:\n  main:\n    increment: Sometimes\n",
+		"GitVersion.yml": "extends: org.yml\ntag-prefx: v\n",
+	})
+
+	err := Validate(filepath.Join(dir, "GitVersion.yml"))
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || len(problems) != 2 {
+		t.Fatalf("Validate() error = %v, want a problem in each file", err)
+	}
+	if filepath.Base(problems[0].File) != "GitVersion.yml" || filepath.Base(problems[1].File) != "org.yml" {
+		t.Errorf("Problems in %s and %s", problems[0].File, problems[1].File)
+	}
+}
+
+func TestLoadEffectiveConfigLayers(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"org.yml":        orgConfig,
+		"GitVersion.yml": "extends: org.yml\nnext-version: 2.0.0\n",
+	})
+
+	effective, err := LoadEffectiveConfig(filepath.Join(dir, "GitVersion.yml"))
+	if err != nil {
+		t.Fatalf("LoadEffectiveConfig() error = %v", err)
+	}
+	if len(effective.Files) != 2 || filepath.Base(effective.Files[0]) != "org.yml" {
+		t.Errorf("Files = %v, want the extended file first", effective.Files)
+	}
+	for _, key := range []string{"next-version", "tag-prefix", "branches.develop.label"} {
+		if effective.Sources[key] != SourceFile {
+			t.Errorf("Sources[%s] = %q, want file", key, effective.Sources[key])
+		}
+	}
+}
+
+func TestProjectFiles(t *testing.T) {
+	root := writeConfigs(t, map[string]string{
+		"GitVersion.yml":               orgConfig,
+		"packages/api/GitVersion.toml": "next-version = \"3.0.0\"\n",
+		"packages/web/README.md":       "no configuration\n",
+	})
+	api := filepath.Join(root, "packages", "api", "GitVersion.toml")
+
+	tests := []struct {
+		name        string
+		project     string
+		configPaths []string
+		want        []string
+	}{
+		{"root config", "packages/api", nil, []string{filepath.Join(root, "GitVersion.yml"), api}},
+		{"given config", "packages/api", []string{"base.yml"}, []string{"base.yml", api}},
+		{"no project config", "packages/web", nil, []string{filepath.Join(root, "GitVersion.yml")}},
+		{"root project", ".", nil, []string{filepath.Join(root, "GitVersion.yml")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ProjectFiles(root, tt.project, tt.configPaths)
+			if err != nil {
+				t.Fatalf("ProjectFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(files, tt.want) {
+				t.Errorf("ProjectFiles() = %v, want %v", files, tt.want)
+			}
+		})
+	}
+
+	cfg, err := LoadConfig(filepath.Join(root, "GitVersion.yml"), api)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.NextVersion != "3.0.0" || cfg.TagPrefix != "release-" {
+		t.Errorf("next-version = %q, tag-prefix = %q, want the project over the root", cfg.NextVersion, cfg.TagPrefix)
+	}
+
+	if _, err := ProjectFiles(root, "packages/missing", nil); err == nil {
+		t.Errorf("ProjectFiles() accepted a missing directory")
+	}
+}
+
+func TestProjects(t *testing.T) {
+	root := writeConfigs(t, map[string]string{
+		"packages/api/GitVersion.yml":                "component: api\n",
+		"packages/web/GitVersion.json":               "{}",
+		"packages/web/node_modules/x/GitVersion.yml": "",
+		".github/GitVersion.yml":                     "",
+		"docs/README.md":                             "",
+	})
+
+	projects, err := Projects(root, nil)
+	if err != nil {
+		t.Fatalf("Projects() error = %v", err)
+	}
+	if want := []string{"packages/api", "packages/web"}; !reflect.DeepEqual(projects, want) {
+		t.Errorf("Projects() = %v, want %v", projects, want)
+	}
+
+	listed := filepath.Join(root, "projects.yml")
+	if err := os.WriteFile(listed, []byte("projects: [docs]\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	projects, err = Projects(root, []string{listed})
+	if err != nil {
+		t.Fatalf("Projects() error = %v", err)
+	}
+	if !reflect.DeepEqual(projects, []string{"docs"}) {
+		t.Errorf("Projects() = %v, want the listed projects", projects)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of GitVersion 5 configurations that have no equivalent. They are
+// dropped with a warning instead of failing strict validation.
+var (
+	ignoredLegacyKeys = map[string]bool{
+		"legacy-semver-padding":                true,
+		"build-metadata-padding":               true,
+		"commits-since-version-source-padding": true,
+	}
+	ignoredLegacyBranchKeys = map[string]bool{
+		"tag-number-pattern": true,
+	}
+)
+
+// legacyMigration rewrites GitVersion 5 keys in a parsed configuration file
+// to their current form and records a deprecation for each
+type legacyMigration struct {
+	file         string
+	deprecations []string
+}
+
+// migrateLegacy rewrites the legacy keys of the document in place and
+// returns the deprecation messages, "file:line: message"
+func migrateLegacy(file string, doc *yaml.Node) []string {
+	m := &legacyMigration{file: file}
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	fallbackTag := ""
+	for i := 0; i < len(root.Content); {
+		key, value := root.Content[i], root.Content[i+1]
+		switch {
+		case ignoredLegacyKeys[key.Value]:
+			m.warn(key, "%s is no longer supported and is ignored", key.Value)
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			continue
+		case key.Value == "continuous-delivery-fallback-tag":
+			fallbackTag = value.Value
+			m.warn(key, "continuous-delivery-fallback-tag is deprecated; set label on ContinuousDeployment branches instead")
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			continue
+		case key.Value == "commit-message-incrementing" && value.Kind == yaml.ScalarNode:
+			// GitVersion 5 took the mode alone: Enabled, Disabled or MergeMessageOnly
+			m.warn(key, "commit-message-incrementing: %s is deprecated; use enabled and increment-mode", value.Value)
+			enabled := scalar(value, boolString(value.Value != "Disabled"))
+			enabled.Tag = "!!bool"
+			root.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column,
+				Content: []*yaml.Node{scalar(value, "enabled"), enabled, scalar(value, "increment-mode"), scalar(value, value.Value)}}
+		case key.Value == "mode":
+			m.migrateMode(key.Value, value)
+		}
+		i += 2
+	}
+
+	if branches := lookup(root, "branches"); branches != nil && branches.Kind == yaml.MappingNode {
+		keys := map[string]bool{}
+		for i := 0; i+1 < len(branches.Content); i += 2 {
+			keys[branches.Content[i].Value] = true
+		}
+		for i := 0; i+1 < len(branches.Content); i += 2 {
+			if branch := branches.Content[i+1]; branch.Kind == yaml.MappingNode {
+				m.migrateBranch("branches."+branches.Content[i].Value, branch, keys, fallbackTag)
+			}
+		}
+	}
+	return m.deprecations
+}
+
+func (m *legacyMigration) migrateBranch(path string, branch *yaml.Node, keys map[string]bool, fallbackTag string) {
+	// The fallback tag applied to ContinuousDeployment branches without
+	// a tag of their own
+	if mode := lookup(branch, "mode"); fallbackTag != "" && mode != nil && mode.Value == string(DeploymentContinuous) &&
+		lookup(branch, "tag") == nil && lookup(branch, "label") == nil {
+		branch.Content = append(branch.Content, scalar(mode, "label"), scalar(mode, fallbackTag))
+	}
+
+	for i := 0; i < len(branch.Content); {
+		key, value := branch.Content[i], branch.Content[i+1]
+		field := path + "." + key.Value
+		switch key.Value {
+		case "tag":
+			if lookup(branch, "label") == nil {
+				m.warn(key, "%s is deprecated; use label", field)
+				label := *value
+				branch.Content = append(branch.Content, scalar(key, "label"), &label)
+			}
+		case "is-mainline":
+			m.warn(key, "%s is deprecated; use is-main-branch", field)
+			key.Value = "is-main-branch"
+		case "prevent-increment-of-merged-branch-version":
+			m.warn(key, "%s is deprecated; use prevent-increment.of-merged-branch", field)
+			branch.Content = append(branch.Content[:i], branch.Content[i+2:]...)
+			prevent := lookup(branch, "prevent-increment")
+			if prevent == nil || prevent.Kind != yaml.MappingNode {
+				prevent = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: key.Line, Column: key.Column}
+				branch.Content = append(branch.Content, scalar(key, "prevent-increment"), prevent)
+			}
+			if lookup(prevent, "of-merged-branch") == nil {
+				prevent.Content = append(prevent.Content, scalar(key, "of-merged-branch"), value)
+			}
+			continue
+		case "mode":
+			m.migrateMode(field, value)
+		case "source-branches", "is-source-branch-for":
+			m.migrateBranchList(field, key, value, keys)
+		default:
+			if ignoredLegacyBranchKeys[key.Value] {
+				m.warn(key, "%s is no longer supported and is ignored", field)
+				branch.Content = append(branch.Content[:i], branch.Content[i+2:]...)
+				continue
+			}
+		}
+		i += 2
+	}
+}
+
+// migrateMode maps the Mainline mode of GitVersion 5, which versions every
+// commit, to ContinuousDeployment
+func (m *legacyMigration) migrateMode(path string, value *yaml.Node) {
+	if value.Value == "Mainline" {
+		m.warn(value, "%s: Mainline is deprecated; use ContinuousDeployment", path)
+		value.Value = string(DeploymentContinuous)
+	}
+}
+
+// migrateBranchList accepts a single branch instead of a list and the
+// master key of GitVersion 5 where the configuration names it main
+func (m *legacyMigration) migrateBranchList(path string, key, value *yaml.Node, keys map[string]bool) {
+	if value.Kind == yaml.ScalarNode && value.Tag != "!!null" {
+		m.warn(key, "%s should be a list", path)
+		item := *value
+		value.Kind, value.Tag, value.Value, value.Content = yaml.SequenceNode, "!!seq", "", []*yaml.Node{&item}
+	}
+	for _, item := range value.Content {
+		if item.Value == "master" && !keys["master"] && keys["main"] {
+			m.warn(item, "%s: master is deprecated; use main", path)
+			item.Value = "main"
+		}
+	}
+}
+
+func (m *legacyMigration) warn(node *yaml.Node, format string, args ...interface{}) {
+	m.deprecations = append(m.deprecations, fmt.Sprintf("%s:%d: %s", m.file, node.Line, fmt.Sprintf(format, args...)))
+}
+
+// lookup returns the value of key in a mapping node, or nil
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns a string node positioned at at, so later errors point to
+// the legacy key it came from
+func scalar(at *yaml.Node, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: at.Line, Column: at.Column}
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
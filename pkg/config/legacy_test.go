@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const legacyConfig = `mode: Mainline
+continuous-delivery-fallback-tag: ci
+legacy-semver-padding: 4
+commit-message-incrementing: MergeMessageOnly
+branches:
+  main:
+    regex: ^(master|main)$
+    tag: ''
+    is-mainline: true
+    prevent-increment-of-merged-branch-version: true
+  develop:
+    mode: ContinuousDeployment
+    regex: ^dev(elop)?(ment)?$
+    source-branches: master
+    tag-number-pattern: '[/-](?<number>\d+)'
+  feature:
+    mode: ContinuousDeployment
+    tag: useBranchName
+`
+
+func TestLoadConfigLegacyKeys(t *testing.T) {
+	path := writeConfig(t, "GitVersion.yml", legacyConfig)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if cfg.Mode != DeploymentContinuous {
+		t.Errorf("Mode = %s, want ContinuousDeployment for Mainline", cfg.Mode)
+	}
+	if !cfg.CommitMessageIncrement.Enabled || cfg.CommitMessageIncrement.IncrementMode != "MergeMessageOnly" {
+		t.Errorf("CommitMessageIncrement = %+v", cfg.CommitMessageIncrement)
+	}
+
+	main := cfg.Branches["main"]
+	if !main.IsMainBranch || main.PreventIncrement == nil || !main.PreventIncrement.OfMergedBranch {
+		t.Errorf("main = %+v, want is-main-branch and prevent-increment.of-merged-branch", main)
+	}
+	develop := cfg.Branches["develop"]
+	if develop.Label != "ci" {
+		t.Errorf("develop label = %q, want the fallback tag", develop.Label)
+	}
+	if !reflect.DeepEqual(develop.SourceBranches, []string{"main"}) {
+		t.Errorf("develop source-branches = %v, want [main]", develop.SourceBranches)
+	}
+	if feature := cfg.Branches["feature"]; feature.Label != "useBranchName" {
+		t.Errorf("feature label = %q, want the tag", feature.Label)
+	}
+
+	expected := []string{
+		path + ":1: mode: Mainline is deprecated; use ContinuousDeployment",
+		path + ":2: continuous-delivery-fallback-tag is deprecated",
+		path + ":3: legacy-semver-padding is no longer supported and is ignored",
+		path + ":4: commit-message-incrementing: MergeMessageOnly is deprecated",
+		path + ":8: branches.main.tag is deprecated; use label",
+		path + ":9: branches.main.is-mainline is deprecated; use is-main-branch",
+		path + ":10: branches.main.prevent-increment-of-merged-branch-version is deprecated",
+		path + ":14: branches.develop.source-branches should be a list",
+		path + ":14: branches.develop.source-branches: master is deprecated; use main",
+		path + ":15: branches.develop.tag-number-pattern is no longer supported and is ignored",
+		path + ":18: branches.feature.tag is deprecated; use label",
+	}
+	if len(cfg.Deprecations) != len(expected) {
+		t.Fatalf("Deprecations =\n%s", strings.Join(cfg.Deprecations, "\n"))
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(cfg.Deprecations[i], prefix) {
+			t.Errorf("Deprecations[%d] = %q, want prefix %q", i, cfg.Deprecations[i], prefix)
+		}
+	}
+
+	if err := Validate(path); err != nil {
+		t.Errorf("Validate() rejected legacy keys: %v", err)
+	}
+}
+
+func TestLoadConfigLegacyKeysJSON(t *testing.T) {
+	path := writeConfig(t, "GitVersion.json", `{"branches": {"main": {"tag": "", "is-mainline": true}}}`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !cfg.Branches["main"].IsMainBranch || len(cfg.Deprecations) != 2 {
+		t.Errorf("main = %+v, deprecations = %v", cfg.Branches["main"], cfg.Deprecations)
+	}
+}
+
+func TestLoadConfigWithoutLegacyKeys(t *testing.T) {
+	content, err := GenerateYAML(DefaultInitOptions())
+	if err != nil {
+		t.Fatalf("GenerateYAML() error = %v", err)
+	}
+	cfg, err := LoadConfig(writeConfig(t, "GitVersion.yml", content))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Deprecations) != 0 {
+		t.Errorf("Deprecations = %v, want none", cfg.Deprecations)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// BranchOverlap reports branch names matched by more than one branch
+// configuration. Only Winner applies to them; the other matches are dead
+// configuration for those names.
+type BranchOverlap struct {
+	// Keys are the branch configurations matching the examples, in name order
+	Keys []string
+	// Winner is the configuration ResolveBranchConfiguration picks
+	Winner string
+	// Examples are branch names affected by the overlap
+	Examples []string
+}
+
+// maxOverlapExamples bounds the example branch names per overlap
+const maxOverlapExamples = 3
+
+// exampleBranchNames are probed in addition to names derived from the
+// configured keys. They cover the usual naming conventions.
+var exampleBranchNames = []string{
+	"main", "master", "develop", "dev", "development",
+	"release/1.2.0", "releases/1.2.0", "release-1.2.0",
+	"feature/login", "features/login", "feature-login",
+	"hotfix/1.2.1", "hotfixes/1.2.1", "hotfix-1.2.1",
+	"support/1.x", "support-1.x",
+	"pull/12", "pr/12", "pull-requests/12",
+	"bugfix/crash", "fix/crash", "chore/deps",
+}
+
+// BranchOverlaps finds branch names that more than one branch configuration
+// matches, either by key, regex or key prefix. Overlaps are grouped by the
+// set of matching keys and the winning key, in a stable order.
+func (c *Config) BranchOverlaps() []BranchOverlap {
+	keys := c.branchKeys()
+
+	candidates := append([]string(nil), exampleBranchNames...)
+	for _, key := range keys {
+		candidates = append(candidates, key, key+"/example", key+"-example")
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	index := make(map[string]int)
+	var overlaps []BranchOverlap
+
+	for _, branch := range candidates {
+		if seen[branch] {
+			continue
+		}
+		seen[branch] = true
+
+		matches := c.matchingBranchKeys(branch, keys)
+		if len(matches) < 2 {
+			continue
+		}
+
+		winner, _ := c.ResolveBranchConfiguration(branch)
+		group := strings.Join(matches, "\x00") + "\x00" + winner
+		i, ok := index[group]
+		if !ok {
+			i = len(overlaps)
+			index[group] = i
+			overlaps = append(overlaps, BranchOverlap{Keys: matches, Winner: winner})
+		}
+		if len(overlaps[i].Examples) < maxOverlapExamples {
+			overlaps[i].Examples = append(overlaps[i].Examples, branch)
+		}
+	}
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		a, b := strings.Join(overlaps[i].Keys, ","), strings.Join(overlaps[j].Keys, ",")
+		if a != b {
+			return a < b
+		}
+		return overlaps[i].Winner < overlaps[j].Winner
+	})
+	return overlaps
+}
+
+// matchingBranchKeys returns every key whose configuration would match
+// branch under any of the ResolveBranchConfiguration rules.
+func (c *Config) matchingBranchKeys(branch string, keys []string) []string {
+	var matches []string
+	for _, key := range keys {
+		config := c.Branches[key]
+		switch {
+		case key == branch:
+		case config != nil && config.Regex != "" && matchesRegex(branch, config.Regex):
+		case strings.HasPrefix(branch, key+"/"):
+		default:
+			continue
+		}
+		matches = append(matches, key)
+	}
+	return matches
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBranchOverlapsDefaultConfig(t *testing.T) {
+	if overlaps := getDefaultConfig().BranchOverlaps(); len(overlaps) != 0 {
+		t.Errorf("Expected no overlaps in the default configuration, got %+v", overlaps)
+	}
+}
+
+func TestBranchOverlaps(t *testing.T) {
+	cfg := getDefaultConfig()
+	// "feature" matches feature/... by regex, "feature/ui" by exact key and
+	// "features" by prefix, competing for the same branches
+	cfg.Branches["feature/ui"] = &BranchConfiguration{Increment: IncrementMinor}
+	cfg.Branches["features"] = &BranchConfiguration{Increment: IncrementMinor}
+
+	overlaps := cfg.BranchOverlaps()
+
+	expected := []BranchOverlap{
+		// Regex matches win over key prefixes, shadowing feature/ui below itself
+		{Keys: []string{"feature", "feature/ui"}, Winner: "feature", Examples: []string{"feature/ui/example"}},
+		{Keys: []string{"feature", "feature/ui"}, Winner: "feature/ui", Examples: []string{"feature/ui"}},
+		{Keys: []string{"feature", "features"}, Winner: "feature", Examples: []string{"features/login", "features/example"}},
+	}
+	if !reflect.DeepEqual(overlaps, expected) {
+		t.Errorf("BranchOverlaps() = %+v, want %+v", overlaps, expected)
+	}
+}
+
+func TestResolveBranchConfigurationIsDeterministic(t *testing.T) {
+	cfg := getDefaultConfig()
+	cfg.Branches["feat"] = &BranchConfiguration{Regex: `^features?[\/-](?<BranchName>.+)`}
+
+	for i := 0; i < 20; i++ {
+		if key, _ := cfg.ResolveBranchConfiguration("feature/login"); key != "feat" {
+			t.Fatalf("ResolveBranchConfiguration() key = %s, want feat", key)
+		}
+	}
+
+	if key, _ := cfg.ResolveBranchConfiguration("main"); key != "main" {
+		t.Errorf("Expected exact key match, got %s", key)
+	}
+	if key, _ := cfg.ResolveBranchConfiguration("experiment"); key != "" {
+		t.Errorf("Expected fallback configuration, got %s", key)
+	}
+}
+
+func TestBranchOverlapsCustomRegexes(t *testing.T) {
+	cfg := &Config{Branches: map[string]*BranchConfiguration{
+		"feat":     {Regex: `^feat.*`},
+		"features": {Regex: `^feature/.*`},
+	}}
+
+	overlaps := cfg.BranchOverlaps()
+	if len(overlaps) == 0 {
+		t.Fatal("BranchOverlaps() found no overlap between ^feat.* and ^feature/.*")
+	}
+	if got := overlaps[0]; !reflect.DeepEqual(got.Keys, []string{"feat", "features"}) || got.Winner != "feat" || got.Examples[0] != "feature/login" {
+		t.Errorf("BranchOverlaps()[0] = %+v, want feat winning feature/login", got)
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override sets one value of the loaded configuration from an assignment
+// such as "branches.main.label=stable", as GitVersion's /overrideconfig
+// does. The path uses the keys of the configuration file; branch and retry
+// entries that do not exist yet are created. String values are taken
+// literally, so "tag-prefix=[vV]" needs no quoting; other values are read
+// as YAML, e.g. "branches.main.source-branches=[develop]".
+func (c *Config) Override(assignment string) error {
+	path, value, ok := strings.Cut(assignment, "=")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" {
+		return fmt.Errorf("invalid override %q (use key=value)", assignment)
+	}
+	if err := override(reflect.ValueOf(c).Elem(), "", strings.Split(path, "."), value); err != nil {
+		return fmt.Errorf("invalid override %s: %w", path, err)
+	}
+	return nil
+}
+
+// override sets the value at path below target, which is at key
+func override(target reflect.Value, key string, path []string, value string) error {
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	if len(path) == 0 {
+		return set(target, value)
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		fields := yamlFields(target.Type())
+		field, ok := fields[path[0]]
+		if !ok {
+			return fmt.Errorf("unknown key %s%s", path[0], suggestion(path[0], fields))
+		}
+		return override(target.FieldByIndex(field.Index), path[0], path[1:], value)
+	case reflect.Map:
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		mapKey := reflect.ValueOf(path[0])
+		// Map elements are not addressable, so a copy is changed and stored
+		elem := reflect.New(target.Type().Elem()).Elem()
+		if existing := target.MapIndex(mapKey); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := override(elem, path[0], path[1:], value); err != nil {
+			return err
+		}
+		target.SetMapIndex(mapKey, elem)
+		return nil
+	}
+	return fmt.Errorf("%s is not a mapping", key)
+}
+
+// set assigns value to a leaf, checking enumerated values like Validate
+func set(target reflect.Value, value string) error {
+	if target.Kind() == reflect.String {
+		if allowed, ok := enumValues[target.Type()]; ok && value != "" && !contains(allowed, value) {
+			return fmt.Errorf("invalid value %q (expected one of %s)", value, strings.Join(allowed, ", "))
+		}
+		target.SetString(value)
+		return nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &node); err != nil {
+		return err
+	}
+	fresh := reflect.New(target.Type())
+	if len(node.Content) > 0 {
+		if err := node.Content[0].Decode(fresh.Interface()); err != nil {
+			return err
+		}
+	}
+	target.Set(fresh.Elem())
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOverride(t *testing.T) {
+	cfg := getDefaultConfig()
+	assignments := []string{
+		"tag-prefix=[vV]?",
+		"next-version=2.0.0",
+		"branches.main.label=stable",
+		"branches.main.prevent-increment.when-current-commit-tagged=true",
+		"branches.feature.source-branches=[main]",
+		"branches.docs.regex=^docs/",
+		"commit-message-incrementing.enabled=false",
+		"retry.git.max-attempts=5",
+		"update-build-number=false",
+	}
+	for _, assignment := range assignments {
+		if err := cfg.Override(assignment); err != nil {
+			t.Fatalf("Override(%q) error = %v", assignment, err)
+		}
+	}
+
+	if cfg.TagPrefix != "[vV]?" || cfg.NextVersion != "2.0.0" {
+		t.Errorf("tag-prefix = %q, next-version = %q, want the literal strings", cfg.TagPrefix, cfg.NextVersion)
+	}
+	main := cfg.Branches["main"]
+	if main.Label != "stable" || main.Regex != "^(master|main)$" || !main.PreventIncrement.WhenCurrentCommitTagged {
+		t.Errorf("main = %+v, want only the overridden fields changed", main)
+	}
+	if source := cfg.Branches["feature"].SourceBranches; !reflect.DeepEqual(source, []string{"main"}) {
+		t.Errorf("feature source-branches = %v", source)
+	}
+	if docs := cfg.Branches["docs"]; docs == nil || docs.Regex != "^docs/" {
+		t.Errorf("docs = %+v, want a new branch", docs)
+	}
+	if cfg.CommitMessageIncrement.Enabled || cfg.UpdateBuildNumber {
+		t.Errorf("Booleans not overridden")
+	}
+	if retry := cfg.Retry["git"]; retry == nil || retry.MaxAttempts != 5 {
+		t.Errorf("retry.git = %+v", retry)
+	}
+}
+
+func TestOverrideErrors(t *testing.T) {
+	tests := map[string]string{
+		"tag-prefix":                             "use key=value",
+		"=v":                                     "use key=value",
+		"tag-prefx=v":                            `did you mean "tag-prefix"`,
+		"branches.main.increment=Often":          "invalid value",
+		"branches.main.pre-release-weight=heavy": "cannot unmarshal",
+		"tag-prefix.sub=v":                       "is not a mapping",
+	}
+	for assignment, want := range tests {
+		err := getDefaultConfig().Override(assignment)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Override(%q) error = %v, want %q", assignment, err, want)
+		}
+	}
+}
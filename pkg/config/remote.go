@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+const (
+	// RemoteHeaderEnv names the environment variable holding a header sent
+	// when downloading configuration files, e.g. "Authorization: Bearer X"
+	RemoteHeaderEnv = "GITVERSION_CONFIG_HEADER"
+	// RemoteCacheTTLEnv names the environment variable holding how long a
+	// downloaded configuration file is reused, as a Go duration such as
+	// "15m". Files are downloaded on every run without it.
+	RemoteCacheTTLEnv = "GITVERSION_CONFIG_CACHE_TTL"
+	// RemoteOperation is the retry operation of configuration downloads
+	RemoteOperation = "remote-config"
+)
+
+// remoteClient downloads configuration files
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxRemoteSize limits the size of a downloaded configuration file
+const maxRemoteSize = 1 << 20
+
+// isURL tells configuration URLs from file paths
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// configExt returns the lower-case extension of a file path or URL,
+// ignoring any query string
+func configExt(location string) string {
+	if isURL(location) {
+		if u, err := url.Parse(location); err == nil {
+			return strings.ToLower(path.Ext(u.Path))
+		}
+	}
+	return strings.ToLower(filepath.Ext(location))
+}
+
+// resolveExtends returns the location of a file named by the extends key
+// of the file at location. Relative names are relative to its directory,
+// or to its URL for downloaded files.
+func resolveExtends(location, name string) (string, error) {
+	switch {
+	case isURL(name):
+		return name, nil
+	case isURL(location):
+		base, err := url.Parse(location)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(name)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	case filepath.IsAbs(name):
+		return filepath.Clean(name), nil
+	}
+	return filepath.Join(filepath.Dir(location), name), nil
+}
+
+// readSource returns the content of a configuration file or URL
+func readSource(location string) ([]byte, error) {
+	if isURL(location) {
+		return fetchRemote(location)
+	}
+	if _, err := os.Stat(location); os.IsNotExist(err) {
+		return nil, fmt.Errorf("configuration file not found: %s", location)
+	}
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return data, nil
+}
+
+// fetchRemote downloads a configuration file, reusing a cached copy that
+// is younger than the TTL in RemoteCacheTTLEnv
+func fetchRemote(location string) ([]byte, error) {
+	var ttl time.Duration
+	if value := os.Getenv(RemoteCacheTTLEnv); value != "" {
+		var err error
+		if ttl, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", RemoteCacheTTLEnv, err)
+		}
+	}
+
+	cachePath := ""
+	if ttl > 0 {
+		if dir, err := os.UserCacheDir(); err == nil {
+			sum := sha256.Sum256([]byte(location))
+			cachePath = filepath.Join(dir, "gitversion-go", "config", hex.EncodeToString(sum[:])+configExt(location))
+			if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < ttl {
+				if data, err := os.ReadFile(cachePath); err == nil {
+					return data, nil
+				}
+			}
+		}
+	}
+
+	data, err := download(location)
+	if err != nil {
+		return nil, err
+	}
+	// The cache only saves downloads, so failing to write it is no error
+	if cachePath != "" && os.MkdirAll(filepath.Dir(cachePath), 0o755) == nil {
+		_ = os.WriteFile(cachePath, data, 0o600)
+	}
+	return data, nil
+}
+
+// download fetches location under the RemoteOperation retry policy.
+// Network errors, 429 and 5xx responses are retried.
+func download(location string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration URL: %w", err)
+	}
+	if header := os.Getenv(RemoteHeaderEnv); header != "" {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid %s: expected \"Name: value\"", RemoteHeaderEnv)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	var data []byte
+	err = retry.Do(context.Background(), RemoteOperation, func(ctx context.Context) error {
+		resp, err := remoteClient.Do(req.Clone(ctx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			err := fmt.Errorf("GET %s returned %s: %s", location, resp.Status, bytes.TrimSpace(body))
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+				return err
+			}
+			return retry.Permanent(err)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+		if err != nil {
+			return err
+		}
+		if len(data) > maxRemoteSize {
+			return retry.Permanent(fmt.Errorf("%s is larger than %d bytes", location, maxRemoteSize))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download config: %w", err)
+	}
+	return data, nil
+}
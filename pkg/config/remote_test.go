@@ -0,0 +1,100 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+// configServer serves configuration files and counts the requests
+func configServer(t *testing.T, files map[string]string) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestLoadConfigRemote(t *testing.T) {
+	server, requests := configServer(t, map[string]string{
+		"/gitversion/base.yml": orgConfig,
+		"/gitversion/repo.yml": "extends: base.yml\nnext-version: 2.0.0\n",
+	})
+	t.Setenv(RemoteHeaderEnv, "Authorization: Bearer secret")
+	t.Setenv(RemoteCacheTTLEnv, "")
+
+	local := writeConfig(t, "GitVersion.yml", "extends: "+server.URL+"/gitversion/repo.yml?ref=main\nbranches:\n  main:\n    increment: Major\n")
+	cfg, err := LoadConfig(local)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.TagPrefix != "release-" || cfg.NextVersion != "2.0.0" {
+		t.Errorf("tag-prefix = %q, next-version = %q, want the remote values", cfg.TagPrefix, cfg.NextVersion)
+	}
+	if main := cfg.Branches["main"]; main.Increment != IncrementMajor || !main.IsMainBranch {
+		t.Errorf("main = %+v, want the remote branch with the local increment", main)
+	}
+	if *requests != 2 {
+		t.Errorf("%d requests, want 2", *requests)
+	}
+}
+
+func TestLoadConfigRemoteCache(t *testing.T) {
+	server, requests := configServer(t, map[string]string{"/base.yml": orgConfig})
+	t.Setenv(RemoteHeaderEnv, "Authorization: Bearer secret")
+	t.Setenv(RemoteCacheTTLEnv, "1h")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		if _, err := LoadConfig(server.URL + "/base.yml"); err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("%d requests, want the second load from the cache", *requests)
+	}
+
+	t.Setenv(RemoteCacheTTLEnv, "soon")
+	if _, err := LoadConfig(server.URL + "/other.yml"); err == nil || !strings.Contains(err.Error(), RemoteCacheTTLEnv) {
+		t.Errorf("LoadConfig() error = %v, want the invalid TTL reported", err)
+	}
+}
+
+func TestLoadConfigRemoteErrors(t *testing.T) {
+	retry.SetPolicy(RemoteOperation, retry.Policy{MaxAttempts: 1})
+	t.Cleanup(retry.ResetPolicies)
+	server, _ := configServer(t, map[string]string{"/base.yml": orgConfig})
+	t.Setenv(RemoteCacheTTLEnv, "")
+
+	t.Setenv(RemoteHeaderEnv, "")
+	if _, err := LoadConfig(server.URL + "/base.yml"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("LoadConfig() error = %v, want 401 without the header", err)
+	}
+
+	t.Setenv(RemoteHeaderEnv, "Authorization: Bearer secret")
+	if _, err := LoadConfig(server.URL + "/missing.yml"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("LoadConfig() error = %v, want 404", err)
+	}
+
+	t.Setenv(RemoteHeaderEnv, "no header")
+	if _, err := LoadConfig(server.URL + "/base.yml"); err == nil || !strings.Contains(err.Error(), RemoteHeaderEnv) {
+		t.Errorf("LoadConfig() error = %v, want the invalid header reported", err)
+	}
+}
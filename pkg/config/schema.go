@@ -0,0 +1,110 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+//go:generate go run ./schemagen gitversion.schema.json
+
+// SchemaID identifies the JSON Schema of the configuration file
+const SchemaID = "https://raw.githubusercontent.com/VirtuallyScott/gitversion-go/main/pkg/config/gitversion.schema.json"
+
+// schema is GenerateSchema's output, kept in the repository so editors and
+// CI can fetch it
+//
+//go:embed gitversion.schema.json
+var schema []byte
+
+// Schema returns the JSON Schema of the configuration file
+func Schema() []byte {
+	return schema
+}
+
+// GenerateSchema derives the JSON Schema from the Config type. Structs
+// allow only their own keys, and enumerated types list their values, like
+// Validate. Run "go generate ./pkg/config" after changing Config.
+func GenerateSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: map[string]interface{}{}}
+	root := g.object(reflect.TypeOf(Config{}))
+	// extends is resolved while loading and has no field in Config
+	root["properties"].(map[string]interface{})["extends"] = map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["$id"] = SchemaID
+	root["title"] = "GitVersion configuration"
+	root["definitions"] = g.definitions
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// object describes a struct and its keys
+func (g *schemaGenerator) object(typ reflect.Type) map[string]interface{} {
+	fields := yamlFields(typ)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	properties := map[string]interface{}{}
+	for _, name := range names {
+		property := g.schemaFor(fields[name].Type)
+		if regexKeys[name] {
+			property["format"] = "regex"
+		}
+		properties[name] = property
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGenerator) schemaFor(typ reflect.Type) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if values, ok := enumValues[typ]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		// Nested structs are shared definitions, so branches reuse one
+		if _, ok := g.definitions[typ.Name()]; !ok {
+			g.definitions[typ.Name()] = nil
+			g.definitions[typ.Name()] = g.object(typ)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + typ.Name()}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(typ.Elem())}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(typ.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	// interface{} values such as merge-message-formats take anything
+	return map[string]interface{}{}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	generated, err := GenerateSchema()
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+	if !bytes.Equal(Schema(), generated) {
+		t.Errorf("gitversion.schema.json is out of date; run go generate ./pkg/config")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Ref                  string                 `json:"$ref"`
+			AdditionalProperties map[string]interface{} `json:"additionalProperties"`
+		} `json:"properties"`
+		AdditionalProperties bool `json:"additionalProperties"`
+		Definitions          map[string]struct {
+			Properties map[string]struct {
+				Enum   []string `json:"enum"`
+				Format string   `json:"format"`
+			} `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	if schema.AdditionalProperties {
+		t.Errorf("Expected unknown top-level keys to be rejected")
+	}
+	if ref := schema.Properties["branches"].AdditionalProperties["$ref"]; ref != "#/definitions/BranchConfiguration" {
+		t.Errorf("branches items = %v, want the BranchConfiguration definition", ref)
+	}
+	if ref := schema.Properties["release-notes"].Ref; ref != "#/definitions/ReleaseNotesConfig" {
+		t.Errorf("release-notes = %q, want the ReleaseNotesConfig definition", ref)
+	}
+
+	branch := schema.Definitions["BranchConfiguration"].Properties
+	if len(branch["increment"].Enum) != 5 || branch["regex"].Format != "regex" {
+		t.Errorf("BranchConfiguration increment = %+v, regex = %+v", branch["increment"], branch["regex"])
+	}
+}
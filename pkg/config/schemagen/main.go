@@ -0,0 +1,26 @@
+// Command schemagen writes the JSON Schema of the configuration file. It is
+// run by "go generate ./pkg/config".
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: schemagen FILE")
+		os.Exit(2)
+	}
+	data, err := config.GenerateSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(os.Args[1], data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source tells where a value of the effective configuration came from
+type Source string
+
+const (
+	// SourceDefault values are built in, either because no file was given
+	// or because the file left them out
+	SourceDefault Source = "default"
+	// SourceFile values are set in the configuration file
+	SourceFile Source = "file"
+	// SourceUnset values are neither in the file nor defaulted
+	SourceUnset Source = "unset"
+)
+
+// BranchResolution explains which branch configuration applies to a branch
+type BranchResolution struct {
+	Branch string `json:"branch" yaml:"branch"`
+	// Key is the configuration used, "" for the built-in fallback
+	Key string `json:"key" yaml:"key"`
+	// MatchedBy is "key", "regex", "prefix" or "fallback", in the order
+	// ResolveBranchConfiguration tries them
+	MatchedBy     string               `json:"matched-by" yaml:"matched-by"`
+	Configuration *BranchConfiguration `json:"configuration" yaml:"configuration"`
+}
+
+// EffectiveConfig is the configuration versioning runs with, after
+// defaults have been applied, and where each value came from
+type EffectiveConfig struct {
+	// Files are the configuration files merged, each after the files it
+	// is layered over
+	Files  []string `json:"files,omitempty" yaml:"files,omitempty"`
+	Config *Config  `json:"config" yaml:"config"`
+	// Sources maps each top-level key and each branch field, such as
+	// "branches.main.regex", to its source
+	Sources map[string]Source `json:"sources" yaml:"sources"`
+	// Deprecations are the legacy keys mapped while loading the files
+	Deprecations []string `json:"deprecations,omitempty" yaml:"deprecations,omitempty"`
+	// Resolution is set by ResolveBranch
+	Resolution *BranchResolution `json:"resolution,omitempty" yaml:"resolution,omitempty"`
+}
+
+// LoadEffectiveConfig loads the configuration like LoadConfig and records
+// the source of every value
+func LoadEffectiveConfig(configPaths ...string) (*EffectiveConfig, error) {
+	l := &configLoader{}
+	cfg, doc, err := loadConfig(l, configPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	inFile := map[string]bool{}
+	if doc != nil {
+		if inFile, err = fileKeys(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	effective, err := toMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]Source)
+	for key, value := range effective {
+		sources[key] = sourceOf(inFile[key], value)
+	}
+	if branches, ok := effective["branches"].(map[string]interface{}); ok {
+		for name, branch := range branches {
+			fields, _ := branch.(map[string]interface{})
+			for field, value := range fields {
+				path := "branches." + name + "." + field
+				sources[path] = sourceOf(inFile[path], value)
+			}
+		}
+	}
+
+	return &EffectiveConfig{Files: l.files, Config: cfg, Sources: sources, Deprecations: cfg.Deprecations}, nil
+}
+
+// ResolveBranch records which branch configuration applies to branch
+func (e *EffectiveConfig) ResolveBranch(branch string) {
+	key, configuration := e.Config.ResolveBranchConfiguration(branch)
+	matchedBy := "fallback"
+	switch {
+	case key == "":
+	case key == branch:
+		matchedBy = "key"
+	case configuration.Regex != "" && matchesRegex(branch, configuration.Regex):
+		matchedBy = "regex"
+	default:
+		matchedBy = "prefix"
+	}
+	e.Resolution = &BranchResolution{Branch: branch, Key: key, MatchedBy: matchedBy, Configuration: configuration}
+}
+
+// YAML renders the configuration as YAML with each value's source as a
+// line comment
+func (e *EffectiveConfig) YAML() (string, error) {
+	var doc yaml.Node
+	if err := doc.Encode(e.Config); err != nil {
+		return "", fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	annotate(&doc, "", e.Sources)
+
+	var b strings.Builder
+	if len(e.Files) > 0 {
+		fmt.Fprintf(&b, "# Effective configuration from %s and the built-in defaults\n", strings.Join(e.Files, ", "))
+	} else {
+		b.WriteString("# Effective configuration: built-in defaults, no file given\n")
+	}
+	for _, deprecation := range e.Deprecations {
+		fmt.Fprintf(&b, "# Deprecated: %s\n", deprecation)
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	b.Write(out)
+
+	if r := e.Resolution; r != nil {
+		key := r.Key
+		if key == "" {
+			key = "(fallback)"
+		}
+		fmt.Fprintf(&b, "\n# Branch %s uses branches.%s, matched by %s\n", r.Branch, key, r.MatchedBy)
+	}
+	return b.String(), nil
+}
+
+// annotate sets the source comments on the mapping under prefix. Only the
+// top level and the fields of each branch carry sources.
+func annotate(node *yaml.Node, prefix string, sources map[string]Source) {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			annotate(child, prefix, sources)
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := prefix + key.Value
+		if source, ok := sources[path]; ok {
+			// Scalars and empty collections such as "[]" are written inline
+			// and carry the comment themselves
+			if value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+				value.LineComment = string(source)
+			} else {
+				key.LineComment = string(source)
+			}
+		}
+		switch {
+		case prefix == "" && key.Value == "branches":
+			annotate(value, "branches.", sources)
+		case prefix == "branches.":
+			annotate(value, path+".", sources)
+		}
+	}
+}
+
+// fileKeys returns the top-level keys and branch fields set in the merged
+// document of the configuration files
+func fileKeys(doc *yaml.Node) (map[string]bool, error) {
+	raw := make(map[string]interface{})
+	if err := doc.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	keys := make(map[string]bool)
+	for key, value := range raw {
+		keys[key] = true
+		if key != "branches" {
+			continue
+		}
+		branches, _ := value.(map[string]interface{})
+		for name, branch := range branches {
+			fields, _ := branch.(map[string]interface{})
+			for field := range fields {
+				keys["branches."+name+"."+field] = true
+			}
+		}
+	}
+	return keys, nil
+}
+
+// toMap converts cfg to the generic form its JSON decodes to, keyed like
+// the configuration file
+func toMap(cfg *Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func sourceOf(inFile bool, value interface{}) Source {
+	switch {
+	case inFile:
+		return SourceFile
+	case isZero(value):
+		return SourceUnset
+	default:
+		return SourceDefault
+	}
+}
+
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, field := range v {
+			if !isZero(field) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
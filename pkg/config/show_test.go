@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadEffectiveConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GitVersion.yml")
+	content := `next-version: 2.0.0
+branches:
+  main:
+    regex: '^(master|main)$'
+    is-main-branch: true
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	effective, err := LoadEffectiveConfig(path)
+	if err != nil {
+		t.Fatalf("LoadEffectiveConfig() error = %v", err)
+	}
+
+	expected := map[string]Source{
+		"next-version":               SourceFile,
+		"tag-prefix":                 SourceDefault,
+		"no-bump-message":            SourceUnset,
+		"branches":                   SourceFile,
+		"branches.main.regex":        SourceFile,
+		"branches.main.label":        SourceUnset,
+		"branches.main.increment":    SourceUnset,
+		"assembly-versioning-scheme": SourceDefault,
+	}
+	for path, source := range expected {
+		if effective.Sources[path] != source {
+			t.Errorf("Sources[%s] = %q, want %q", path, effective.Sources[path], source)
+		}
+	}
+
+	out, err := effective.YAML()
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	for _, line := range []string{"next-version: 2.0.0 # file", "tag-prefix: '[vV]' # default", "branches: # file"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("YAML() is missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestLoadEffectiveConfigDefaults(t *testing.T) {
+	effective, err := LoadEffectiveConfig("")
+	if err != nil {
+		t.Fatalf("LoadEffectiveConfig() error = %v", err)
+	}
+	for path, source := range effective.Sources {
+		if source == SourceFile {
+			t.Errorf("Sources[%s] = file without a configuration file", path)
+		}
+	}
+	if effective.Sources["branches.develop.label"] != SourceDefault {
+		t.Errorf("Sources[branches.develop.label] = %q, want default", effective.Sources["branches.develop.label"])
+	}
+}
+
+func TestResolveBranch(t *testing.T) {
+	effective, err := LoadEffectiveConfig("")
+	if err != nil {
+		t.Fatalf("LoadEffectiveConfig() error = %v", err)
+	}
+	effective.Config.Branches["docs"] = &BranchConfiguration{Label: "docs"}
+
+	tests := []struct {
+		branch    string
+		key       string
+		matchedBy string
+	}{
+		{"develop", "develop", "key"},
+		{"feature/login", "feature", "regex"},
+		{"docs/readme", "docs", "prefix"},
+		{"experiment", "", "fallback"},
+	}
+	for _, tt := range tests {
+		effective.ResolveBranch(tt.branch)
+		if r := effective.Resolution; r.Key != tt.key || r.MatchedBy != tt.matchedBy || r.Configuration == nil {
+			t.Errorf("ResolveBranch(%s) = %+v, want key %q matched by %s", tt.branch, r, tt.key, tt.matchedBy)
+		}
+	}
+}
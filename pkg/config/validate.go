@@ -0,0 +1,285 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a configuration file, with the
+// position of the offending key or value
+type ValidationError struct {
+	File   string
+	Line   int
+	Column int
+	// Path is the dotted key path, e.g. "branches.main.increment"
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Path, e.Message)
+}
+
+// ValidationErrors holds every problem found in a file
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// enumValues are the accepted values of the enumerated configuration types
+var enumValues = map[reflect.Type][]string{
+	reflect.TypeOf(IncrementStrategy("")): {
+		string(IncrementNone), string(IncrementPatch), string(IncrementMinor), string(IncrementMajor), string(IncrementInherit),
+	},
+	reflect.TypeOf(DeploymentMode("")): {
+		string(DeploymentManual), string(DeploymentContinuousDelivery), string(DeploymentContinuous),
+	},
+	reflect.TypeOf(CommitCountMode("")): {
+		string(CountFromRepository), string(CountFromBranchPoint), string(CountFromVersionSource),
+	},
+	reflect.TypeOf(WorkflowPreset("")): {
+		string(PresetGitFlow), string(PresetGitHubFlow), string(PresetTrunkBased),
+	},
+	reflect.TypeOf(AssemblyVersioningScheme("")): {
+		string(AssemblyMajorMinorPatchTag), string(AssemblyMajorMinorPatch), string(AssemblyMajorMinor),
+		string(AssemblyMajor), string(AssemblyNone),
+	},
+	reflect.TypeOf(TagSelection("")): {
+		string(TagSelectionAll), string(TagSelectionPreferAnnotated), string(TagSelectionAnnotatedOnly),
+	},
+	reflect.TypeOf(LatestTag("")): {
+		string(LatestTagNearest), string(LatestTagHighest),
+	},
+}
+
+// regexKeys are the keys whose values are regular expressions
+var regexKeys = map[string]bool{
+	"regex":                      true,
+	"tag-prefix":                 true,
+	"major-version-bump-message": true,
+	"minor-version-bump-message": true,
+	"patch-version-bump-message": true,
+	"no-bump-message":            true,
+}
+
+// Validate checks configuration files, and the files they extend, for
+// unknown keys, invalid enum values, regular expressions that do not
+// compile and branch configurations shadowed by others matching the same
+// branches. It returns ValidationErrors listing every problem, or nil.
+// JSON files are checked too, since JSON is read as YAML here.
+func Validate(configPaths ...string) error {
+	l := &configLoader{validate: true}
+	cfg, _, err := loadConfig(l, configPaths)
+	if err != nil {
+		return err
+	}
+	if len(l.errors) > 0 {
+		return l.errors
+	}
+	if problems := overlapErrors(cfg, l.branchKeys); len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// overlapErrors reports branch configurations that match the same
+// branches, at the key of one the files set, preferring one that loses the
+// branches. Overlaps only between built-in configurations are left out.
+func overlapErrors(cfg *Config, keys map[string]keyPosition) ValidationErrors {
+	var problems ValidationErrors
+	for _, overlap := range cfg.BranchOverlaps() {
+		key, position, found := "", keyPosition{}, false
+		for _, k := range overlap.Keys {
+			if p, ok := keys[k]; ok && (!found || key == overlap.Winner) {
+				key, position, found = k, p, true
+			}
+		}
+		if !found {
+			continue
+		}
+		problems = append(problems, &ValidationError{
+			File:   position.file,
+			Line:   position.node.Line,
+			Column: position.node.Column,
+			Path:   join("branches", key),
+			Message: fmt.Sprintf("branch configurations %s all match %s; only %s applies",
+				strings.Join(overlap.Keys, ", "), strings.Join(overlap.Examples, ", "), overlap.Winner),
+		})
+	}
+	return problems
+}
+
+// LoadConfigStrict validates the files before loading them like
+// LoadConfig, so typos fail instead of being ignored
+func LoadConfigStrict(configPaths ...string) (*Config, error) {
+	if err := Validate(configPaths...); err != nil {
+		return nil, err
+	}
+	return LoadConfig(configPaths...)
+}
+
+type validator struct {
+	file   string
+	errors ValidationErrors
+}
+
+func (v *validator) add(node *yaml.Node, path, format string, args ...interface{}) {
+	v.errors = append(v.errors, &ValidationError{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// walk checks node against the Go type it decodes into
+func (v *validator) walk(node *yaml.Node, typ reflect.Type, path string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			if !isNull(node) {
+				v.add(node, path, "expected a mapping")
+			}
+			return
+		}
+		fields := yamlFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				v.add(key, join(path, key.Value), "unknown key%s", suggestion(key.Value, fields))
+				continue
+			}
+			v.walk(value, field.Type, join(path, key.Value))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			if !isNull(node) {
+				v.add(node, path, "expected a mapping")
+			}
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.walk(node.Content[i+1], typ.Elem(), join(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			if !isNull(node) {
+				v.add(node, path, "expected a list")
+			}
+			return
+		}
+		for i, item := range node.Content {
+			v.walk(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode || node.Value == "" {
+			return
+		}
+		if allowed, ok := enumValues[typ]; ok && !contains(allowed, node.Value) {
+			v.add(node, path, "invalid value %q (expected one of %s)", node.Value, strings.Join(allowed, ", "))
+		}
+		if regexKeys[lastKey(path)] {
+			if _, err := regexp.Compile(goRegex(node.Value)); err != nil {
+				v.add(node, path, "invalid regular expression: %v", err)
+			}
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct to its fields
+func yamlFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// goRegex rewrites .NET named groups, (?<Name>...), which GitVersion
+// configurations use, to the syntax every Go version accepts
+func goRegex(pattern string) string {
+	return strings.ReplaceAll(pattern, "(?<", "(?P<")
+}
+
+// suggestion names the known key closest to an unknown one, if any is
+// within two edits
+func suggestion(key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func lastKey(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
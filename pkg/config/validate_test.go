@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	path := writeConfig(t, "GitVersion.yml", `next-version: 1.0.0
+mode: Continuous
+branches:
+  main:
+    incremnt: Patch
+    regex: '^(main'
+  feature:
+    regex: '^features?[\/-](?<BranchName>.+)'
+    increment: Minr
+    prevent-increment:
+      of-merged-branch: true
+retry:
+  push:
+    max-attempts: 3
+`)
+
+	err := Validate(path)
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+	}
+
+	expected := []string{
+		path + `:2:7: mode: invalid value "Continuous"`,
+		path + `:5:5: branches.main.incremnt: unknown key (did you mean "increment"?)`,
+		path + `:6:12: branches.main.regex: invalid regular expression`,
+		path + `:9:16: branches.feature.increment: invalid value "Minr"`,
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Validate() found %d problems, want %d:\n%v", len(problems), len(expected), err)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(problems[i].Error(), prefix) {
+			t.Errorf("problem %d = %q, want prefix %q", i, problems[i].Error(), prefix)
+		}
+	}
+}
+
+func TestValidateBranchOverlaps(t *testing.T) {
+	path := writeConfig(t, "GitVersion.yml", `branches:
+  feat:
+    regex: ^feat.*
+  features:
+    regex: ^feature/.*
+`)
+
+	err := Validate(path)
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || len(problems) == 0 {
+		t.Fatalf("Validate() error = %v, want the overlap", err)
+	}
+	if prefix := path + ":4:3: branches.features: branch configurations feat, features all match feature/login"; !strings.HasPrefix(problems[0].Error(), prefix) {
+		t.Errorf("problem = %q, want prefix %q", problems[0].Error(), prefix)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	path := writeConfig(t, "GitVersion.json", "{\n\t\"next-version\": \"1.0.0\",\n\t\"tagprefix\": \"v\"\n}\n")
+
+	err := Validate(path)
+	if err == nil || !strings.Contains(err.Error(), `:3:2: tagprefix: unknown key (did you mean "tag-prefix"?)`) {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidateGeneratedConfigs(t *testing.T) {
+	for _, workflow := range []string{WorkflowGitFlow, WorkflowGitHubFlow, WorkflowTrunk} {
+		content, err := GenerateYAML(InitOptions{Workflow: workflow, TagPrefix: "v", MainBranch: "main"})
+		if err != nil {
+			t.Fatalf("GenerateYAML() error = %v", err)
+		}
+		if err := Validate(writeConfig(t, "GitVersion.yml", content)); err != nil {
+			t.Errorf("Generated %s configuration is invalid: %v", workflow, err)
+		}
+	}
+}
+
+func TestLoadConfigStrict(t *testing.T) {
+	path := writeConfig(t, "GitVersion.yml", "next-version: 1.0.0\nincremnt: Minor\n")
+
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("LoadConfig() error = %v, want unknown keys ignored", err)
+	}
+	if _, err := LoadConfigStrict(path); err == nil {
+		t.Errorf("LoadConfigStrict() accepted an unknown key")
+	}
+	if _, err := LoadConfigStrict(""); err != nil {
+		t.Errorf("LoadConfigStrict(\"\") error = %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowPreset names a built-in set of branch configurations, as the
+// workflow key of GitVersion 6 does
+type WorkflowPreset string
+
+const (
+	PresetGitFlow    WorkflowPreset = "GitFlow/v1"
+	PresetGitHubFlow WorkflowPreset = "GitHubFlow/v1"
+	PresetTrunkBased WorkflowPreset = "TrunkBased/preview1"
+)
+
+// Workflow returns the -w value matching the preset, "" for no preset
+func (p WorkflowPreset) Workflow() string {
+	switch p {
+	case PresetGitFlow:
+		return WorkflowGitFlow
+	case PresetGitHubFlow:
+		return WorkflowGitHubFlow
+	case PresetTrunkBased:
+		return WorkflowTrunk
+	}
+	return ""
+}
+
+// Branches returns a fresh copy of the preset's branch configurations
+func (p WorkflowPreset) Branches() (map[string]*BranchConfiguration, error) {
+	switch p {
+	case PresetGitFlow:
+		return getDefaultBranchConfigurations(), nil
+	case PresetGitHubFlow:
+		return presetBranches(nil, "main", "release", "feature", "pull-request"), nil
+	case PresetTrunkBased:
+		return presetBranches(func(key string, bc *BranchConfiguration) {
+			if key == "main" {
+				bc.Mode = DeploymentContinuous
+			}
+		}, "main", "feature", "hotfix", "pull-request"), nil
+	}
+	return nil, fmt.Errorf("unknown workflow %q (use %s, %s or %s)", p, PresetGitFlow, PresetGitHubFlow, PresetTrunkBased)
+}
+
+// presetBranches keeps the named default branch configurations, dropping
+// source branch references to the others, and lets adjust change each
+func presetBranches(adjust func(key string, bc *BranchConfiguration), keys ...string) map[string]*BranchConfiguration {
+	defaults := getDefaultBranchConfigurations()
+	kept := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		kept[key] = true
+	}
+
+	branches := make(map[string]*BranchConfiguration, len(keys))
+	for _, key := range keys {
+		bc := defaults[key]
+		sources := []string{}
+		for _, source := range bc.SourceBranches {
+			if kept[source] {
+				sources = append(sources, source)
+			}
+		}
+		bc.SourceBranches = sources
+		if adjust != nil {
+			adjust(key, bc)
+		}
+		branches[key] = bc
+	}
+	return branches
+}
+
+// applyWorkflow replaces config's branches with the preset's, overlaid
+// with the branches section of the file in doc. Branches in the file only
+// set the fields they name; new branch keys are added.
+func applyWorkflow(config *Config, doc *yaml.Node) error {
+	branches, err := config.Workflow.Branches()
+	if err != nil {
+		return err
+	}
+
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if section := lookup(root, "branches"); section != nil && section.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			key := section.Content[i].Value
+			bc, ok := branches[key]
+			if !ok {
+				bc = &BranchConfiguration{}
+				branches[key] = bc
+			}
+			if err := section.Content[i+1].Decode(bc); err != nil {
+				return fmt.Errorf("failed to parse branch %s: %w", key, err)
+			}
+		}
+	}
+
+	config.Branches = branches
+	return nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWorkflowPresetOverlay(t *testing.T) {
+	path := writeConfig(t, "GitVersion.yml", `workflow: GitHubFlow/v1
+branches:
+  main:
+    increment: Minor
+    prevent-increment:
+      when-current-commit-tagged: true
+  docs:
+    regex: ^docs[/-]
+    label: docs
+`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if keys := cfg.branchKeys(); !reflect.DeepEqual(keys, []string{"docs", "feature", "main", "pull-request", "release"}) {
+		t.Errorf("Branch keys = %v", keys)
+	}
+	main := cfg.Branches["main"]
+	if main.Increment != IncrementMinor || main.Regex != "^(master|main)$" || !main.IsMainBranch {
+		t.Errorf("main = %+v, want the preset overlaid with increment Minor", main)
+	}
+	if main.PreventIncrement == nil || !main.PreventIncrement.WhenCurrentCommitTagged {
+		t.Errorf("main prevent-increment = %+v", main.PreventIncrement)
+	}
+	if feature := cfg.Branches["feature"]; !reflect.DeepEqual(feature.SourceBranches, []string{"main", "release"}) {
+		t.Errorf("feature source-branches = %v, want references to GitHubFlow branches only", feature.SourceBranches)
+	}
+	if cfg.Workflow.Workflow() != WorkflowGitHubFlow {
+		t.Errorf("Workflow() = %q", cfg.Workflow.Workflow())
+	}
+}
+
+func TestWorkflowPresets(t *testing.T) {
+	tests := map[WorkflowPreset][]string{
+		PresetGitFlow:    {"develop", "feature", "hotfix", "main", "pull-request", "release", "support"},
+		PresetGitHubFlow: {"feature", "main", "pull-request", "release"},
+		PresetTrunkBased: {"feature", "hotfix", "main", "pull-request"},
+	}
+	for preset, expected := range tests {
+		branches, err := preset.Branches()
+		if err != nil {
+			t.Fatalf("%s: Branches() error = %v", preset, err)
+		}
+		cfg := &Config{Branches: branches}
+		if keys := cfg.branchKeys(); !reflect.DeepEqual(keys, expected) {
+			t.Errorf("%s: branch keys = %v, want %v", preset, keys, expected)
+		}
+		for key, bc := range branches {
+			for _, source := range bc.SourceBranches {
+				if branches[source] == nil {
+					t.Errorf("%s: %s names unknown source branch %s", preset, key, source)
+				}
+			}
+		}
+	}
+
+	if branches, _ := PresetTrunkBased.Branches(); branches["main"].Mode != DeploymentContinuous {
+		t.Errorf("TrunkBased main mode = %s, want ContinuousDeployment", branches["main"].Mode)
+	}
+}
+
+func TestUnknownWorkflowPreset(t *testing.T) {
+	path := writeConfig(t, "GitVersion.yml", "workflow: Scrum/v1\n")
+	if _, err := LoadConfig(path); err == nil {
+		t.Errorf("Expected an error for an unknown workflow")
+	}
+	if err := Validate(path); err == nil {
+		t.Errorf("Expected Validate() to reject an unknown workflow")
+	}
+}
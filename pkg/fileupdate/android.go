@@ -0,0 +1,214 @@
+package fileupdate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// DefaultVersionCodeFormat derives an Android versionCode from the version.
+// The weighted prerelease number orders alpha, beta and release builds of
+// the same version; builds of the same stage share a code.
+const DefaultVersionCodeFormat = "{Major} * 1000000 + {Minor} * 10000 + {Patch} * 100 + {WeightedPreReleaseNumber} / 1000"
+
+// maxVersionCode is the largest versionCode Google Play accepts
+const maxVersionCode = 2100000000
+
+var (
+	// versionCode 10203 in Groovy, versionCode = 10203 in Kotlin
+	gradleVersionCode = lazyregexp.New(`(?m)^[ \t]*versionCode[ \t]*(?:=[ \t]*)?(\d+)`)
+	// versionName "1.2.3" in Groovy, versionName = "1.2.3" in Kotlin
+	gradleVersionName = lazyregexp.New(`(?m)^[ \t]*versionName[ \t]*(?:=[ \t]*)?["']([^"'\n]*)["']`)
+)
+
+// androidWriter sets the versionCode and versionName of an Android app,
+// in the defaultConfig of build.gradle or build.gradle.kts, or with style
+// "properties" as versionCode= and versionName= lines of a properties file
+// the build script reads
+type androidWriter struct {
+	style             string
+	versionCodeFormat string
+	versionNameFormat string
+}
+
+func newAndroidWriter(options map[string]string) (Writer, error) {
+	w := &androidWriter{style: options["style"], versionCodeFormat: DefaultVersionCodeFormat, versionNameFormat: "{SemVer}"}
+	if w.style == "" {
+		w.style = "gradle"
+	}
+	if format := options["version-code-format"]; format != "" {
+		w.versionCodeFormat = format
+	}
+	if format := options["version-name-format"]; format != "" {
+		w.versionNameFormat = format
+	}
+	return w, nil
+}
+
+func (w *androidWriter) Name() string {
+	return "android"
+}
+
+func (w *androidWriter) Validate() error {
+	if w.style != "gradle" && w.style != "properties" {
+		return fmt.Errorf("invalid style %q, want gradle or properties", w.style)
+	}
+	if _, err := (&v1.Variables{}).Expand(w.versionNameFormat); err != nil {
+		return err
+	}
+	// Every variable is a number here, so only the formula itself can fail
+	zero := 0
+	expression, err := (&v1.Variables{PreReleaseNumber: &zero}).Expand(w.versionCodeFormat)
+	if err != nil {
+		return err
+	}
+	_, err = evaluate(expression)
+	return err
+}
+
+func (w *androidWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	code, err := VersionCode(w.versionCodeFormat, variables)
+	if err != nil {
+		return nil, err
+	}
+	name, err := variables.Expand(w.versionNameFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.style == "properties" {
+		if content, err = setProperty(content, "versionCode", strconv.Itoa(code)); err != nil {
+			return nil, err
+		}
+		return setProperty(content, "versionName", name)
+	}
+
+	if !gradleVersionCode.Match(content) {
+		return nil, fmt.Errorf("no versionCode found")
+	}
+	if content, err = replaceMatches(gradleVersionCode.Regexp(), content, []byte(strconv.Itoa(code))); err != nil {
+		return nil, err
+	}
+	if gradleVersionName.Match(content) {
+		return replaceMatches(gradleVersionName.Regexp(), content, []byte(name))
+	}
+	return content, nil
+}
+
+// VersionCode evaluates an Android versionCode formula such as
+// DefaultVersionCodeFormat: the variables are filled in and the result is
+// computed with integer +, -, *, / and % and parentheses. Codes outside
+// 1 to 2100000000 are an error, as Google Play rejects them.
+func VersionCode(format string, variables *v1.Variables) (int, error) {
+	expression, err := variables.Expand(format)
+	if err != nil {
+		return 0, err
+	}
+	code, err := evaluate(expression)
+	if err != nil {
+		return 0, err
+	}
+	if code < 1 || code > maxVersionCode {
+		return 0, fmt.Errorf("version code %d is outside 1 to %d", code, maxVersionCode)
+	}
+	return code, nil
+}
+
+// evaluate computes an integer expression
+func evaluate(expression string) (int, error) {
+	p := &arithmeticParser{input: expression}
+	value, err := p.expression()
+	if err == nil && p.skipSpace() < len(p.input) {
+		err = fmt.Errorf("unexpected %q", p.input[p.pos:])
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid version code formula %q: %w", expression, err)
+	}
+	return value, nil
+}
+
+// arithmeticParser evaluates integer expressions by recursive descent
+type arithmeticParser struct {
+	input string
+	pos   int
+}
+
+func (p *arithmeticParser) skipSpace() int {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	return p.pos
+}
+
+// expression parses terms joined by + and -
+func (p *arithmeticParser) expression() (int, error) {
+	value, err := p.term()
+	for err == nil && p.skipSpace() < len(p.input) && strings.IndexByte("+-", p.input[p.pos]) >= 0 {
+		op := p.input[p.pos]
+		p.pos++
+		var right int
+		if right, err = p.term(); err == nil {
+			if op == '+' {
+				value += right
+			} else {
+				value -= right
+			}
+		}
+	}
+	return value, err
+}
+
+// term parses factors joined by *, / and %
+func (p *arithmeticParser) term() (int, error) {
+	value, err := p.factor()
+	for err == nil && p.skipSpace() < len(p.input) && strings.IndexByte("*/%", p.input[p.pos]) >= 0 {
+		op := p.input[p.pos]
+		p.pos++
+		var right int
+		if right, err = p.factor(); err != nil {
+			break
+		}
+		switch {
+		case op == '*':
+			value *= right
+		case right == 0:
+			err = fmt.Errorf("division by zero")
+		case op == '/':
+			value /= right
+		default:
+			value %= right
+		}
+	}
+	return value, err
+}
+
+// factor parses a number or a parenthesized expression
+func (p *arithmeticParser) factor() (int, error) {
+	if p.skipSpace() == len(p.input) {
+		return 0, fmt.Errorf("unexpected end")
+	}
+	if p.input[p.pos] == '(' {
+		p.pos++
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if p.skipSpace() == len(p.input) || p.input[p.pos] != ')' {
+			return 0, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("unexpected %q", p.input[start:])
+	}
+	return strconv.Atoi(p.input[start:p.pos])
+}
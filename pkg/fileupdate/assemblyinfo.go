@@ -0,0 +1,136 @@
+package fileupdate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// assemblyAttributes are the version attributes of an AssemblyInfo file and
+// the variables written into them, as GitVersion's /updateassemblyinfo does
+var assemblyAttributes = []struct {
+	name   string
+	format string
+}{
+	{"AssemblyVersion", "{AssemblySemVer}"},
+	{"AssemblyFileVersion", "{AssemblySemFileVer}"},
+	{"AssemblyInformationalVersion", "{InformationalVersion}"},
+}
+
+// assemblyInfoHeaders start the AssemblyInfo files created by
+// AssemblyInfoUpdates, by extension
+var assemblyInfoHeaders = map[string]string{
+	".cs": "using System.Reflection;\n",
+	".vb": "Imports System.Reflection\n",
+}
+
+// assemblyInfoWriter rewrites the version attributes of a C# or Visual
+// Basic AssemblyInfo file, appending those it lacks
+type assemblyInfoWriter struct{}
+
+func newAssemblyInfoWriter(options map[string]string) (Writer, error) {
+	return &assemblyInfoWriter{}, nil
+}
+
+func (w *assemblyInfoWriter) Name() string {
+	return "assembly-info"
+}
+
+func (w *assemblyInfoWriter) Validate() error {
+	return nil
+}
+
+func (w *assemblyInfoWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	// Visual Basic writes attributes as <Assembly: Name("...")>
+	visualBasic := regexp.MustCompile(`(?mi)^\s*(Imports\s|<Assembly:)`).Match(content)
+
+	for _, attribute := range assemblyAttributes {
+		value, err := variables.Expand(attribute.format)
+		if err != nil {
+			return nil, err
+		}
+		re := regexp.MustCompile(`(?i)\b` + attribute.name + `(?:Attribute)?\s*\(\s*("[^"]*")\s*\)`)
+		if re.Match(content) {
+			if content, err = replaceMatches(re, content, []byte(fmt.Sprintf("%q", value))); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		line := fmt.Sprintf("[assembly: %s(%q)]\n", attribute.name, value)
+		if visualBasic {
+			line = fmt.Sprintf("<Assembly: %s(%q)>\n", attribute.name, value)
+		}
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			content = append(content, '\n')
+		}
+		content = append(content, line...)
+	}
+	return content, nil
+}
+
+// AssemblyInfoUpdates returns file updates for AssemblyInfo files below
+// dir: the given files, relative to dir, or without them every
+// AssemblyInfo.cs and AssemblyInfo.vb outside bin, obj and hidden
+// directories. With ensure, given files that do not exist are created
+// with just the using or Imports line the attributes need.
+func AssemblyInfoUpdates(dir string, files []string, ensure bool) ([]config.FileUpdateConfig, error) {
+	if len(files) == 0 {
+		if ensure {
+			return nil, fmt.Errorf("name the AssemblyInfo files to create")
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); path != dir && (name == "bin" || name == "obj" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if name := d.Name(); strings.EqualFold(name, "AssemblyInfo.cs") || strings.EqualFold(name, "AssemblyInfo.vb") {
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				files = append(files, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find AssemblyInfo files: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no AssemblyInfo.cs or AssemblyInfo.vb files found in %s", dir)
+		}
+	}
+
+	updates := make([]config.FileUpdateConfig, 0, len(files))
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, filepath.FromSlash(path))
+		}
+		header, ok := assemblyInfoHeaders[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a C# or Visual Basic file", file)
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) && ensure {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create %s: %w", file, err)
+			}
+			if err := os.WriteFile(path, []byte(header), 0o644); err != nil {
+				return nil, fmt.Errorf("failed to create %s: %w", file, err)
+			}
+		}
+		updates = append(updates, config.FileUpdateConfig{Path: file, Writer: "assembly-info"})
+	}
+	return updates, nil
+}
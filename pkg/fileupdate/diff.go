@@ -0,0 +1,131 @@
+package fileupdate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// Diff renders the change as a unified diff, empty when nothing changed
+func (c *Change) Diff() string {
+	if !c.Changed() {
+		return ""
+	}
+	before := splitLines(string(c.Before))
+	after := splitLines(string(c.After))
+	ops := diffLines(before, after)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", c.Path, c.Path)
+	for start := 0; start < len(ops); {
+		// Find the next change and the end of its hunk, merging changes
+		// whose context overlaps
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(end+diffContext, len(ops))
+
+		oldStart, newStart := ops[from].oldLine, ops[from].newLine
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, op := range ops[from:to] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.text)
+			if !strings.HasSuffix(op.text, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = to
+	}
+	return b.String()
+}
+
+// diffOp is one line of a diff: ' ' kept, '-' removed or '+' added. The
+// line numbers are where the line is, or would be, in each file.
+type diffOp struct {
+	kind    byte
+	text    string
+	oldLine int
+	newLine int
+}
+
+// diffLines computes a shortest line diff from the longest common
+// subsequence. Version files are small, so the quadratic table is fine.
+func diffLines(before, after []string) []diffOp {
+	n, m := len(before), len(after)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && before[i] == after[j]:
+			ops = append(ops, diffOp{' ', before[i], i + 1, j + 1})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', before[i], i + 1, j + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', after[j], i + 1, j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits text after each newline, keeping the newlines
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange formats the start and length of a hunk as diff does; an empty
+// range starts at the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
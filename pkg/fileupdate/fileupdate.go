@@ -0,0 +1,181 @@
+package fileupdate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// Writer stamps a calculated version into one format of file, such as a
+// package.json or a .csproj project.
+type Writer interface {
+	Name() string
+	// Validate checks the writer's configuration before any file is read
+	Validate() error
+	// Update returns content with the version written into it
+	Update(content []byte, variables *v1.Variables) ([]byte, error)
+}
+
+// Factory creates a writer from its configured options
+type Factory func(options map[string]string) (Writer, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a writer available under name for use in the
+// file-updates section of the configuration.
+func Register(name string, factory Factory) error {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		return fmt.Errorf("writer name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("writer %s has no factory", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[key]; exists {
+		return fmt.Errorf("writer %s is already registered", name)
+	}
+	registry[key] = factory
+	return nil
+}
+
+// Registered returns the names of all registered writers, sorted
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the writer registered under name
+func New(name string, options map[string]string) (Writer, error) {
+	registryMu.RLock()
+	factory, exists := registry[strings.ToLower(strings.TrimSpace(name))]
+	registryMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown writer: %s (available: %s)", name, strings.Join(Registered(), ", "))
+	}
+	if options == nil {
+		options = map[string]string{}
+	}
+	return factory(options)
+}
+
+// Change is the new content of one file
+type Change struct {
+	// Path is the path of the file as configured
+	Path   string
+	Before []byte
+	After  []byte
+
+	file string
+	mode os.FileMode
+}
+
+// Changed reports whether the file's content differs from before
+func (c *Change) Changed() bool {
+	return !bytes.Equal(c.Before, c.After)
+}
+
+// Plan computes the new content of every configured file, with paths
+// relative to dir, without writing anything. All writers are validated
+// before the first file is read. Updates of the same file apply in order.
+// Missing files are an error unless their update sets Create; writers
+// then start from empty content.
+func Plan(dir string, configs []config.FileUpdateConfig, variables *v1.Variables) ([]*Change, error) {
+	writers := make([]Writer, len(configs))
+	for i, cfg := range configs {
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file-updates[%d]: path is required", i)
+		}
+		writer, err := New(cfg.Writer, cfg.Options)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Path, err)
+		}
+		if err := writer.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: writer %s: %w", cfg.Path, writer.Name(), err)
+		}
+		writers[i] = writer
+	}
+
+	var changes []*Change
+	byPath := map[string]*Change{}
+	for i, cfg := range configs {
+		file := cfg.Path
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, filepath.FromSlash(file))
+		}
+		change, ok := byPath[file]
+		if !ok {
+			change = &Change{Path: cfg.Path, file: file, mode: 0o644}
+			if info, err := os.Stat(file); err == nil {
+				change.mode = info.Mode().Perm()
+				if change.Before, err = os.ReadFile(file); err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", cfg.Path, err)
+				}
+			} else if !os.IsNotExist(err) || !cfg.Create {
+				return nil, fmt.Errorf("failed to read %s: %w", cfg.Path, err)
+			}
+			change.After = change.Before
+			byPath[file] = change
+			changes = append(changes, change)
+		}
+
+		updated, err := writers[i].Update(change.After, variables)
+		if err != nil {
+			return nil, fmt.Errorf("%s: writer %s: %w", cfg.Path, writers[i].Name(), err)
+		}
+		change.After = updated
+	}
+	return changes, nil
+}
+
+// Write saves the changed files, keeping their permissions
+func Write(changes []*Change) error {
+	for _, change := range changes {
+		if !change.Changed() {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(change.file), 0o755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", change.Path, err)
+		}
+		if err := os.WriteFile(change.file, change.After, change.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", change.Path, err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register("version-file", newVersionFileWriter)
+	Register("regex", newRegexWriter)
+	Register("package-json", newPackageJSONWriter)
+	Register("msbuild", newMSBuildWriter)
+	Register("assembly-info", newAssemblyInfoWriter)
+	Register("helm-chart", newHelmChartWriter)
+	Register("maven", newMavenWriter)
+	Register("gradle-properties", newGradlePropertiesWriter)
+	Register("android", newAndroidWriter)
+	Register("info-plist", newInfoPlistWriter)
+	Register("kubernetes", newKubernetesWriter)
+	Register("wix", newWiXWriter)
+}
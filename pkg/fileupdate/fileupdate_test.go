@@ -0,0 +1,556 @@
+package fileupdate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+func testVariables() *v1.Variables {
+	return &v1.Variables{
+		Major:                     1,
+		Minor:                     2,
+		Patch:                     3,
+		SemVer:                    "1.2.3-beta.1",
+		PreReleaseTag:             "beta.1",
+		WeightedPreReleaseNumber:  30001,
+		FullSemVer:                "1.2.3-beta.1+4",
+		MajorMinorPatch:           "1.2.3",
+		AssemblySemVer:            "1.2.0.0",
+		AssemblySemFileVer:        "1.2.3.0",
+		InformationalVersion:      "1.2.3-beta.1+Branch.main.Sha.abc1234",
+		CommitsSinceVersionSource: 4,
+		MsiVersion:                "1.2.3.4",
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	tests := []struct {
+		name    string
+		writer  string
+		options map[string]string
+		content string
+		want    string
+	}{
+		{
+			name:    "version file",
+			writer:  "version-file",
+			options: map[string]string{"format": "{MajorMinorPatch}"},
+			content: "0.9.0\n",
+			want:    "1.2.3\n",
+		},
+		{
+			name:    "regex group",
+			writer:  "regex",
+			options: map[string]string{"pattern": `const Version = "([^"]*)"`},
+			content: "package version\n\nconst Version = \"0.9.0\"\n",
+			want:    "package version\n\nconst Version = \"1.2.3-beta.1\"\n",
+		},
+		{
+			name:    "regex match",
+			writer:  "regex",
+			options: map[string]string{"pattern": `\d+\.\d+\.\d+`, "format": "{MajorMinorPatch}"},
+			content: "from 0.9.0 to 0.9.1",
+			want:    "from 1.2.3 to 1.2.3",
+		},
+		{
+			name:    "package.json",
+			writer:  "package-json",
+			content: "{\n  \"name\": \"app\",\n  \"version\":  \"0.9.0\",\n  \"dependencies\": {\"dep\": {\"version\": \"2.0.0\"}}\n}\n",
+			want:    "{\n  \"name\": \"app\",\n  \"version\":  \"1.2.3-beta.1\",\n  \"dependencies\": {\"dep\": {\"version\": \"2.0.0\"}}\n}\n",
+		},
+		{
+			name:    "helm chart",
+			writer:  "helm-chart",
+			content: "apiVersion: v2\nname: app\nversion: 0.1.0 # chart version\nappVersion: \"0.9.0\"\ndependencies:\n  - name: db\n    version: 1.0.0\n",
+			want:    "apiVersion: v2\nname: app\nversion: 1.2.3 # chart version\nappVersion: \"1.2.3-beta.1+4\"\ndependencies:\n  - name: db\n    version: 1.0.0\n",
+		},
+		{
+			name:    "helm chart without appVersion",
+			writer:  "helm-chart",
+			options: map[string]string{"version-format": "{Major}.{Minor}", "app-version-format": "{MajorMinorPatch}"},
+			content: "name: app\nversion: '0.1'",
+			want:    "name: app\nversion: '1.2'\nappVersion: \"1.2.3\"\n",
+		},
+		{
+			name:   "maven module",
+			writer: "maven",
+			content: `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <version>0.9.0</version>
+  </parent>
+  <artifactId>api</artifactId>
+  <dependencies>
+    <dependency><version>2.0.0</version></dependency>
+  </dependencies>
+</project>
+`,
+			want: `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <version>1.2.3-SNAPSHOT</version>
+  </parent>
+  <artifactId>api</artifactId>
+  <dependencies>
+    <dependency><version>2.0.0</version></dependency>
+  </dependencies>
+</project>
+`,
+		},
+		{
+			name:    "maven with external parent",
+			writer:  "maven",
+			options: map[string]string{"prerelease": "qualifier"},
+			content: `<project><groupId>com.example</groupId><parent><groupId>org.springframework.boot</groupId><version>3.2.0</version></parent><version>0.9.0</version></project>`,
+			want:    `<project><groupId>com.example</groupId><parent><groupId>org.springframework.boot</groupId><version>3.2.0</version></parent><version>1.2.3-beta.1</version></project>`,
+		},
+		{
+			name:    "gradle properties",
+			writer:  "gradle-properties",
+			content: "org.gradle.jvmargs=-Xmx2g\nversion = 0.9.0\r\nkotlin.code.style=official",
+			want:    "org.gradle.jvmargs=-Xmx2g\nversion = 1.2.3-beta.1\r\nkotlin.code.style=official",
+		},
+		{
+			name:    "gradle properties without the key",
+			writer:  "gradle-properties",
+			options: map[string]string{"key": "appVersion", "format": "{MajorMinorPatch}"},
+			content: "# versions\nversion=0.9.0",
+			want:    "# versions\nversion=0.9.0\nappVersion=1.2.3\n",
+		},
+		{
+			name:    "android build.gradle",
+			writer:  "android",
+			content: "android {\n    defaultConfig {\n        versionCode 1\n        versionName \"0.9.0\"\n    }\n}\n",
+			want:    "android {\n    defaultConfig {\n        versionCode 1020330\n        versionName \"1.2.3-beta.1\"\n    }\n}\n",
+		},
+		{
+			name:    "android build.gradle.kts",
+			writer:  "android",
+			options: map[string]string{"version-code-format": "({Major} * 100 + {Minor}) * 100 + {Patch}", "version-name-format": "{MajorMinorPatch}"},
+			content: "android {\n    defaultConfig {\n        versionCode = 1\n        versionName = \"0.9.0\"\n    }\n}\n",
+			want:    "android {\n    defaultConfig {\n        versionCode = 10203\n        versionName = \"1.2.3\"\n    }\n}\n",
+		},
+		{
+			name:    "android properties",
+			writer:  "android",
+			options: map[string]string{"style": "properties"},
+			content: "versionCode=1\n",
+			want:    "versionCode=1020330\nversionName=1.2.3-beta.1\n",
+		},
+		{
+			name:   "info plist",
+			writer: "info-plist",
+			content: `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>CFBundleName</key>
+	<string>App</string>
+	<key>CFBundleShortVersionString</key>
+	<string>0.9.0</string>
+</dict>
+</plist>
+`,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>CFBundleName</key>
+	<string>App</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.2.3</string>
+	<key>CFBundleVersion</key>
+	<string>4</string>
+</dict>
+</plist>
+`,
+		},
+		{
+			name:    "xcconfig",
+			writer:  "info-plist",
+			options: map[string]string{"style": "xcconfig", "bundle-version-format": "{Major}.{Minor}.{CommitsSinceVersionSource}"},
+			content: "// Version.xcconfig\nMARKETING_VERSION = 0.9.0\n",
+			want:    "// Version.xcconfig\nMARKETING_VERSION = 1.2.3\nCURRENT_PROJECT_VERSION = 1.2.4\n",
+		},
+		{
+			name:    "kubernetes manifest",
+			writer:  "kubernetes",
+			options: map[string]string{"image": "ghcr.io/acme/app", "format": "{FullSemVer}"},
+			content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app.kubernetes.io/version: "0.9.0"
+spec:
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/version: 0.9.0
+    spec:
+      containers:
+        - name: app
+          image: ghcr.io/acme/app:0.9.0
+        - name: proxy
+          image: ghcr.io/acme/app-proxy:0.9.0
+`,
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app.kubernetes.io/version: "1.2.3-beta.1-4"
+spec:
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/version: 1.2.3-beta.1-4
+    spec:
+      containers:
+        - name: app
+          image: ghcr.io/acme/app:1.2.3-beta.1-4
+        - name: proxy
+          image: ghcr.io/acme/app-proxy:0.9.0
+`,
+		},
+		{
+			name:    "kustomization",
+			writer:  "kubernetes",
+			options: map[string]string{"image": "app", "labels": "false", "format": "{MajorMinorPatch}"},
+			content: `resources:
+  - deployment.yaml
+images:
+  - name: app
+    newName: ghcr.io/acme/app
+    newTag: "0.9.0"
+  - name: worker
+    newTag: 0.9.0
+  - name: app
+    newName: ghcr.io/acme/app
+`,
+			want: `resources:
+  - deployment.yaml
+images:
+  - name: app
+    newName: ghcr.io/acme/app
+    newTag: "1.2.3"
+  - name: worker
+    newTag: 0.9.0
+  - name: app
+    newTag: 1.2.3
+    newName: ghcr.io/acme/app
+`,
+		},
+		{
+			name:   "wix product",
+			writer: "wix",
+			content: `<Wix xmlns="http://schemas.microsoft.com/wix/2006/wi">
+  <Product Id="*" Name="App" Version="0.9.0.0" Manufacturer="Acme" UpgradeCode="PUT-GUID-HERE">
+    <Package InstallerVersion="200" Compressed="yes" />
+  </Product>
+</Wix>
+`,
+			want: `<Wix xmlns="http://schemas.microsoft.com/wix/2006/wi">
+  <Product Id="*" Name="App" Version="1.2.3.4" Manufacturer="Acme" UpgradeCode="PUT-GUID-HERE">
+    <Package InstallerVersion="200" Compressed="yes" />
+  </Product>
+</Wix>
+`,
+		},
+		{
+			name:    "wix define",
+			writer:  "wix",
+			options: map[string]string{"define": "ProductVersion", "format": "{MajorMinorPatch}"},
+			content: "<?define ProductVersion = \"0.9.0\" ?>\n<Wix>\n  <Package Name=\"App\" Version=\"$(var.ProductVersion)\" />\n</Wix>\n",
+			want:    "<?define ProductVersion = \"1.2.3\" ?>\n<Wix>\n  <Package Name=\"App\" Version=\"$(var.ProductVersion)\" />\n</Wix>\n",
+		},
+		{
+			name:    "msbuild",
+			writer:  "msbuild",
+			content: "<Project>\n  <PropertyGroup>\n    <Version>0.9.0</Version>\n    <FileVersion>0.9.0.0</FileVersion>\n  </PropertyGroup>\n</Project>\n",
+			want:    "<Project>\n  <PropertyGroup>\n    <Version>1.2.3-beta.1</Version>\n    <FileVersion>1.2.3.0</FileVersion>\n  </PropertyGroup>\n</Project>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := New(tt.writer, tt.options)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := writer.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			got, err := writer.Update([]byte(tt.content), testVariables())
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Update() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	if _, err := New("pom", nil); err == nil || !strings.Contains(err.Error(), "unknown writer") {
+		t.Errorf("New(pom) error = %v", err)
+	}
+	if _, err := New("regex", map[string]string{"pattern": "("}); err == nil {
+		t.Errorf("New(regex) accepted an invalid pattern")
+	}
+
+	writer, _ := New("regex", nil)
+	if err := writer.Validate(); err == nil {
+		t.Errorf("Validate() accepted a regex writer without pattern")
+	}
+	writer, _ = New("version-file", map[string]string{"format": "{Nope}"})
+	if err := writer.Validate(); err == nil {
+		t.Errorf("Validate() accepted an unknown variable")
+	}
+	writer, _ = New("gradle-properties", map[string]string{"key": "version name"})
+	if err := writer.Validate(); err == nil {
+		t.Errorf("Validate() accepted an invalid property key")
+	}
+	writer, _ = New("android", map[string]string{"version-code-format": "{Major} *"})
+	if err := writer.Validate(); err == nil {
+		t.Errorf("Validate() accepted an incomplete version code formula")
+	}
+	writer, _ = New("android", map[string]string{"style": "manifest"})
+	if err := writer.Validate(); err == nil {
+		t.Errorf("Validate() accepted an unknown android style")
+	}
+	writer, _ = New("kubernetes", map[string]string{"labels": "false"})
+	if err := writer.Validate(); err == nil {
+		t.Errorf("Validate() accepted a kubernetes writer with nothing to update")
+	}
+	writer, _ = New("wix", nil)
+	tooLarge := testVariables()
+	tooLarge.MsiVersion = ""
+	if _, err := writer.Update([]byte(`<Product Version="1.0.0" />`), tooLarge); err == nil {
+		t.Errorf("Update() accepted a version beyond the Windows Installer limits")
+	}
+	writer, _ = New("info-plist", map[string]string{"short-version-format": "{SemVer}"})
+	if _, err := writer.Update([]byte("<plist><dict></dict></plist>"), testVariables()); err == nil {
+		t.Errorf("Update() wrote a prerelease CFBundleShortVersionString")
+	}
+
+	updates := map[string]string{
+		"regex":        "no version here",
+		"package-json": `{"name": "app"}`,
+		"msbuild":      "<Project />",
+		"helm-chart":   "name: app\nappVersion: 1.0.0\n",
+		"maven":        "<project><artifactId>app</artifactId></project>",
+		"android":      "android {\n    defaultConfig {\n    }\n}\n",
+		"info-plist":   "bplist00",
+		"kubernetes":   "kind: Service\n",
+		"wix":          "<Wix><Product Name=\"App\" /></Wix>",
+	}
+	for name, content := range updates {
+		writer, _ := New(name, map[string]string{"pattern": `\d+\.\d+`})
+		if _, err := writer.Update([]byte(content), testVariables()); err == nil {
+			t.Errorf("%s Update(%q) found something to update", name, content)
+		}
+	}
+}
+
+func TestVersionCode(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    int
+		wantErr bool
+	}{
+		{format: DefaultVersionCodeFormat, want: 1020330},
+		{format: "{Major} + {Minor} * {Patch}", want: 7},
+		{format: "({Major} + {Minor}) * {Patch}", want: 9},
+		{format: "{WeightedPreReleaseNumber} % 1000 - 1", wantErr: true},
+		{format: "{Major} / 0", wantErr: true},
+		{format: "{Major} * 3000000000", wantErr: true},
+		{format: "({Major}", wantErr: true},
+		{format: "{Major} 2", wantErr: true},
+		{format: "{SemVer}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := VersionCode(tt.format, testVariables())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("VersionCode(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("VersionCode(%q) = %d, want %d", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestPlanAndWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "VERSION", "1.2.3-beta.1\n")
+	writeFile(t, dir, "version.go", "const Version = \"0.9.0\"\nconst Major = 0\n")
+
+	configs := []config.FileUpdateConfig{
+		{Path: "VERSION", Writer: "version-file"},
+		{Path: "version.go", Writer: "regex", Options: map[string]string{"pattern": `Version = "(.*)"`}},
+		{Path: "version.go", Writer: "regex", Options: map[string]string{"pattern": `Major = (\d+)`, "format": "{Major}"}},
+	}
+	changes, err := Plan(dir, configs, testVariables())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].Changed() || !changes[1].Changed() {
+		t.Fatalf("Plan() = %d changes, want an unchanged VERSION and a changed version.go", len(changes))
+	}
+
+	wantDiff := `--- a/version.go
++++ b/version.go
+@@ -1,2 +1,2 @@
+-const Version = "0.9.0"
+-const Major = 0
++const Version = "1.2.3-beta.1"
++const Major = 1
+`
+	if diff := changes[1].Diff(); diff != wantDiff {
+		t.Errorf("Diff() =\n%s\nwant\n%s", diff, wantDiff)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "version.go")); strings.Contains(string(data), "1.2.3") {
+		t.Errorf("Plan() wrote the file")
+	}
+
+	if err := Write(changes); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "version.go")); string(data) != string(changes[1].After) {
+		t.Errorf("Write() left %q", data)
+	}
+
+	// Missing files are created only when asked to
+	generated := []config.FileUpdateConfig{{Path: "build/version.properties", Writer: "gradle-properties"}}
+	if _, err := Plan(dir, generated, testVariables()); err == nil {
+		t.Errorf("Plan() accepted a missing file")
+	}
+	generated[0].Create = true
+	changes, err = Plan(dir, generated, testVariables())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if err := Write(changes); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "build/version.properties")); string(data) != "version=1.2.3-beta.1\n" {
+		t.Errorf("Write() created %q", data)
+	}
+
+	// A bad entry stops the plan before anything is read
+	configs = append(configs, config.FileUpdateConfig{Path: "missing.txt", Writer: "nope"})
+	if _, err := Plan(dir, configs, testVariables()); err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("Plan() error = %v, want the unknown writer", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm"
+	change := &Change{Path: "x", Before: []byte(before), After: []byte(after)}
+
+	want := `--- a/x
++++ b/x
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,5 +9,5 @@
+ i
+ j
+ k
+-l
+-m
++L
++m
+\ No newline at end of file
+`
+	if diff := change.Diff(); diff != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", diff, want)
+	}
+}
+
+func TestAssemblyInfo(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"src/App/Properties", "src/Lib/My Project", "src/App/obj"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	writeFile(t, dir, "src/App/Properties/AssemblyInfo.cs", `using System.Reflection;
+
+[assembly: AssemblyTitle("App")]
+[assembly: AssemblyVersion("1.0.0.0")]
+[assembly: System.Reflection.AssemblyFileVersionAttribute( "1.0.0.0" )]`)
+	writeFile(t, dir, "src/Lib/My Project/AssemblyInfo.vb", "Imports System.Reflection\n\n<Assembly: AssemblyVersion(\"1.0.0.0\")>\n")
+	writeFile(t, dir, "src/App/obj/AssemblyInfo.cs", "generated\n")
+
+	updates, err := AssemblyInfoUpdates(dir, nil, false)
+	if err != nil {
+		t.Fatalf("AssemblyInfoUpdates() error = %v", err)
+	}
+	if len(updates) != 2 || updates[0].Path != "src/App/Properties/AssemblyInfo.cs" || updates[1].Path != "src/Lib/My Project/AssemblyInfo.vb" {
+		t.Fatalf("AssemblyInfoUpdates() = %+v", updates)
+	}
+
+	changes, err := Plan(dir, updates, testVariables())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	wantCS := `using System.Reflection;
+
+[assembly: AssemblyTitle("App")]
+[assembly: AssemblyVersion("1.2.0.0")]
+[assembly: System.Reflection.AssemblyFileVersionAttribute( "1.2.3.0" )]
+[assembly: AssemblyInformationalVersion("1.2.3-beta.1+Branch.main.Sha.abc1234")]
+`
+	if got := string(changes[0].After); got != wantCS {
+		t.Errorf("C# AssemblyInfo =\n%s\nwant\n%s", got, wantCS)
+	}
+	wantVB := `Imports System.Reflection
+
+<Assembly: AssemblyVersion("1.2.0.0")>
+<Assembly: AssemblyFileVersion("1.2.3.0")>
+<Assembly: AssemblyInformationalVersion("1.2.3-beta.1+Branch.main.Sha.abc1234")>
+`
+	if got := string(changes[1].After); got != wantVB {
+		t.Errorf("VB AssemblyInfo =\n%s\nwant\n%s", got, wantVB)
+	}
+
+	// Named files are created on demand
+	if _, err := AssemblyInfoUpdates(dir, []string{"src/New/AssemblyInfo.cs"}, false); err != nil {
+		t.Fatalf("AssemblyInfoUpdates() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src/New/AssemblyInfo.cs")); !os.IsNotExist(err) {
+		t.Errorf("AssemblyInfoUpdates() created a file without ensure")
+	}
+	updates, err = AssemblyInfoUpdates(dir, []string{"src/New/AssemblyInfo.cs"}, true)
+	if err != nil {
+		t.Fatalf("AssemblyInfoUpdates() error = %v", err)
+	}
+	changes, err = Plan(dir, updates, testVariables())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if got := string(changes[0].After); !strings.HasPrefix(got, "using System.Reflection;\n[assembly: AssemblyVersion(\"1.2.0.0\")]\n") {
+		t.Errorf("Created AssemblyInfo =\n%s", got)
+	}
+
+	if _, err := AssemblyInfoUpdates(dir, []string{"AssemblyInfo.fs"}, true); err == nil {
+		t.Errorf("AssemblyInfoUpdates() accepted an F# file")
+	}
+}
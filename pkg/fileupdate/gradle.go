@@ -0,0 +1,59 @@
+package fileupdate
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// propertyKeyPattern matches the property keys the writers accept
+var propertyKeyPattern = lazyregexp.New(`^[\w.-]+$`)
+
+// gradlePropertiesWriter sets a property, "version" by default, in a
+// gradle.properties or version.properties file, adding it when missing
+type gradlePropertiesWriter struct {
+	key    string
+	format string
+}
+
+func newGradlePropertiesWriter(options map[string]string) (Writer, error) {
+	key := options["key"]
+	if key == "" {
+		key = "version"
+	}
+	return &gradlePropertiesWriter{key: key, format: formatOption(options)}, nil
+}
+
+func (w *gradlePropertiesWriter) Name() string {
+	return "gradle-properties"
+}
+
+func (w *gradlePropertiesWriter) Validate() error {
+	if !propertyKeyPattern.MatchString(w.key) {
+		return fmt.Errorf("invalid key %q", w.key)
+	}
+	_, err := (&v1.Variables{}).Expand(w.format)
+	return err
+}
+
+func (w *gradlePropertiesWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := variables.Expand(w.format)
+	if err != nil {
+		return nil, err
+	}
+	return setProperty(content, w.key, version)
+}
+
+// setProperty sets key in a Java properties file, appending it when missing
+func setProperty(content []byte, key, value string) ([]byte, error) {
+	re := regexp.MustCompile(`(?m)^[ \t]*` + regexp.QuoteMeta(key) + `[ \t]*[=:][ \t]*(.*?)[ \t]*\r?$`)
+	if re.Match(content) {
+		return replaceMatches(re, content, []byte(value))
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	return append(content, key+"="+value+"\n"...), nil
+}
@@ -0,0 +1,99 @@
+package fileupdate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// helmChartWriter sets the chart version and appVersion of a Helm
+// Chart.yaml, leaving the rest of the file as it was formatted
+type helmChartWriter struct {
+	versionFormat    string
+	appVersionFormat string
+}
+
+func newHelmChartWriter(options map[string]string) (Writer, error) {
+	w := &helmChartWriter{versionFormat: "{MajorMinorPatch}", appVersionFormat: "{FullSemVer}"}
+	if format := options["version-format"]; format != "" {
+		w.versionFormat = format
+	}
+	if format := options["app-version-format"]; format != "" {
+		w.appVersionFormat = format
+	}
+	return w, nil
+}
+
+func (w *helmChartWriter) Name() string {
+	return "helm-chart"
+}
+
+func (w *helmChartWriter) Validate() error {
+	for _, format := range []string{w.versionFormat, w.appVersionFormat} {
+		if _, err := (&v1.Variables{}).Expand(format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *helmChartWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := variables.Expand(w.versionFormat)
+	if err != nil {
+		return nil, err
+	}
+	appVersion, err := variables.Expand(w.appVersionFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	content, found := setTopLevelKey(content, "version", version)
+	if !found {
+		return nil, fmt.Errorf("Chart.yaml has no version")
+	}
+	if content, found = setTopLevelKey(content, "appVersion", appVersion); !found {
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			content = append(content, '\n')
+		}
+		content = append(content, "appVersion: "+strconv.Quote(appVersion)+"\n"...)
+	}
+	return content, nil
+}
+
+// setTopLevelKey replaces the value of an unindented "key: value" line,
+// keeping its quotes and any trailing comment. Unquoted values that YAML
+// would not read as a string, such as 1.10, are quoted.
+func setTopLevelKey(content []byte, key, value string) ([]byte, bool) {
+	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `:[ \t]*("[^"\n]*"|'[^'\n]*'|[^#\r\n]*?)[ \t]*(?:#.*)?\r?$`)
+	m := re.FindSubmatchIndex(content)
+	if m == nil {
+		return content, false
+	}
+
+	out := append([]byte(nil), content[:m[2]]...)
+	out = append(out, yamlString(string(content[m[2]:m[3]]), value)...)
+	return append(out, content[m[3]:]...), true
+}
+
+// yamlString renders value as a YAML string replacing old, in the quotes
+// old used. Unquoted values that YAML would not read as a string are
+// double-quoted.
+func yamlString(old, value string) string {
+	switch {
+	case len(old) > 0 && old[0] == '"':
+		return strconv.Quote(value)
+	case len(old) > 0 && old[0] == '\'':
+		return "'" + value + "'"
+	}
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return strconv.Quote(value)
+	}
+	if _, ok := parsed.(string); !ok {
+		return strconv.Quote(value)
+	}
+	return value
+}
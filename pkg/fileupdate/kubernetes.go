@@ -0,0 +1,162 @@
+package fileupdate
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+var (
+	// app.kubernetes.io/version: "1.2.3", quoted or not
+	versionLabelPattern = lazyregexp.New(`(?m)^[ \t]*(?:-[ \t]*)?["']?app\.kubernetes\.io/version["']?:[ \t]*("[^"\n]*"|'[^'\n]*'|[^#\s]*)`)
+	// The first line of an entry of a kustomization images list
+	kustomizeImagePattern = lazyregexp.New(`^([ \t]*)-[ \t]+name:[ \t]*["']?([^"'\s#]+)["']?[ \t]*(?:#.*)?$`)
+	// The newTag of a kustomization images entry
+	kustomizeTagPattern = lazyregexp.New(`^[ \t]*newTag:[ \t]*("[^"\n]*"|'[^'\n]*'|[^#\s]*)`)
+)
+
+// maxLabelLength is the longest Kubernetes label value
+const maxLabelLength = 63
+
+// kubernetesWriter stamps Kubernetes manifests: the tag of every reference
+// to the image option, the newTag of its entry in the images of a
+// kustomization.yaml and every app.kubernetes.io/version label. Labels are
+// left alone with labels "false".
+type kubernetesWriter struct {
+	image  string
+	format string
+	labels bool
+}
+
+func newKubernetesWriter(options map[string]string) (Writer, error) {
+	w := &kubernetesWriter{image: options["image"], format: formatOption(options), labels: true}
+	switch options["labels"] {
+	case "", "true":
+	case "false":
+		w.labels = false
+	default:
+		return nil, fmt.Errorf("invalid labels %q, want true or false", options["labels"])
+	}
+	return w, nil
+}
+
+func (w *kubernetesWriter) Name() string {
+	return "kubernetes"
+}
+
+func (w *kubernetesWriter) Validate() error {
+	if w.image == "" && !w.labels {
+		return fmt.Errorf("image option is required when labels is false")
+	}
+	_, err := (&v1.Variables{}).Expand(w.format)
+	return err
+}
+
+func (w *kubernetesWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := variables.Expand(w.format)
+	if err != nil {
+		return nil, err
+	}
+	tag := semver.DockerTag(version)
+
+	found := false
+	if w.image != "" {
+		re := regexp.MustCompile(`(?m)^[ \t]*(?:-[ \t]*)?image:[ \t]*["']?` + regexp.QuoteMeta(w.image) + `:([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+		if re.Match(content) {
+			found = true
+			if content, err = replaceMatches(re, content, []byte(tag)); err != nil {
+				return nil, err
+			}
+		}
+		var kustomized bool
+		if content, kustomized = setKustomizeTag(content, w.image, tag); kustomized {
+			found = true
+		}
+	}
+	if w.labels && versionLabelPattern.Match(content) {
+		found = true
+		content = replaceYAMLValues(versionLabelPattern.Regexp(), content, labelValue(version))
+	}
+
+	if !found {
+		if w.image == "" {
+			return nil, fmt.Errorf("no app.kubernetes.io/version label found")
+		}
+		return nil, fmt.Errorf("no reference to image %s found", w.image)
+	}
+	return content, nil
+}
+
+// labelValue sanitizes version for a Kubernetes label value: at most 63
+// characters out of [A-Za-z0-9_.-], starting and ending alphanumeric
+func labelValue(version string) string {
+	value := semver.DockerTag(version)
+	if len(value) > maxLabelLength {
+		value = value[:maxLabelLength]
+	}
+	return strings.Trim(value, "_.-")
+}
+
+// replaceYAMLValues replaces the first group of every match of re, a YAML
+// scalar, with value in the quotes the scalar used
+func replaceYAMLValues(re *regexp.Regexp, content []byte, value string) []byte {
+	var out []byte
+	last := 0
+	for _, m := range re.FindAllSubmatchIndex(content, -1) {
+		out = append(out, content[last:m[2]]...)
+		out = append(out, yamlString(string(content[m[2]:m[3]]), value)...)
+		last = m[3]
+	}
+	return append(out, content[last:]...)
+}
+
+// setKustomizeTag sets the newTag of the entries named image in the images
+// list of a kustomization.yaml, adding newTag after the name of entries
+// without one
+func setKustomizeTag(content []byte, image, tag string) ([]byte, bool) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	found := false
+	var out []byte
+	for i := 0; i < len(lines); i++ {
+		out = append(out, lines[i]...)
+		m := kustomizeImagePattern.FindSubmatch(bytes.TrimRight(lines[i], "\r\n"))
+		if m == nil || string(m[2]) != image {
+			continue
+		}
+		found = true
+
+		// The keys of the entry are indented past its dash
+		keyIndent := len(m[1]) + 2
+		nameEnd := len(out)
+		tagged := false
+		for i+1 < len(lines) {
+			line := lines[i+1]
+			trimmed := bytes.TrimLeft(line, " \t")
+			if len(bytes.TrimSpace(line)) > 0 && len(line)-len(trimmed) < keyIndent {
+				break
+			}
+			i++
+			if tm := kustomizeTagPattern.FindSubmatchIndex(line); tm != nil {
+				tagged = true
+				out = append(out, line[:tm[2]]...)
+				out = append(out, yamlString(string(line[tm[2]:tm[3]]), tag)...)
+				out = append(out, line[tm[3]:]...)
+				continue
+			}
+			out = append(out, line...)
+		}
+		if !tagged {
+			entry := strings.Repeat(" ", keyIndent) + "newTag: " + yamlString("", tag) + "\n"
+			if out[nameEnd-1] != '\n' {
+				entry = "\n" + entry
+			}
+			out = append(out[:nameEnd], append([]byte(entry), out[nameEnd:]...)...)
+		}
+	}
+	return out, found
+}
@@ -0,0 +1,131 @@
+package fileupdate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// mavenWriter sets the version of a Maven pom.xml and, for the modules of
+// a multi-module build, the version of their parent reference
+type mavenWriter struct {
+	format       string
+	prerelease   string
+	updateParent string
+}
+
+func newMavenWriter(options map[string]string) (Writer, error) {
+	w := &mavenWriter{format: options["format"], prerelease: options["prerelease"], updateParent: options["update-parent"]}
+	if w.prerelease == "" {
+		w.prerelease = "snapshot"
+	}
+	return w, nil
+}
+
+func (w *mavenWriter) Name() string {
+	return "maven"
+}
+
+func (w *mavenWriter) Validate() error {
+	if w.prerelease != "snapshot" && w.prerelease != "qualifier" {
+		return fmt.Errorf("unsupported prerelease: %s (snapshot|qualifier)", w.prerelease)
+	}
+	if w.updateParent != "" && w.updateParent != "true" && w.updateParent != "false" {
+		return fmt.Errorf("update-parent must be true or false")
+	}
+	if w.format != "" {
+		_, err := (&v1.Variables{}).Expand(w.format)
+		return err
+	}
+	return nil
+}
+
+// version returns the Maven version: the format when given, otherwise
+// Major.Minor.Patch with prereleases as 1.2.3-SNAPSHOT or, with the
+// qualifier convention, 1.2.3-beta.1
+func (w *mavenWriter) version(variables *v1.Variables) (string, error) {
+	if w.format != "" {
+		return variables.Expand(w.format)
+	}
+	switch {
+	case variables.PreReleaseTag == "":
+		return variables.MajorMinorPatch, nil
+	case w.prerelease == "qualifier":
+		return variables.MajorMinorPatch + "-" + variables.PreReleaseTag, nil
+	}
+	return variables.MajorMinorPatch + "-SNAPSHOT", nil
+}
+
+// pomText is the text of an element of a pom.xml and where it is
+type pomText struct {
+	value      string
+	start, end int64
+}
+
+func (w *mavenWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := w.version(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := map[string]pomText{}
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	var path []string
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid pom.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			key := strings.Join(path, "/")
+			switch key {
+			case "project/version", "project/groupId", "project/parent/version", "project/parent/groupId":
+				texts[key] = pomText{strings.TrimSpace(string(t)), start, dec.InputOffset()}
+			}
+		}
+	}
+
+	var targets []pomText
+	if text, ok := texts["project/version"]; ok {
+		targets = append(targets, text)
+	}
+	if parent, ok := texts["project/parent/version"]; ok {
+		// A parent in the project's own group is taken to be part of the
+		// same build, as a module's parent reference to the root pom is
+		update := w.updateParent == "true"
+		if w.updateParent == "" {
+			group, ok := texts["project/groupId"]
+			update = !ok || group.value == texts["project/parent/groupId"].value
+		}
+		if update {
+			targets = append(targets, parent)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("pom.xml has no project or parent version")
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	if len(targets) == 2 && targets[1].start > targets[0].start {
+		targets[0], targets[1] = targets[1], targets[0]
+	}
+	for _, target := range targets {
+		out := append([]byte(nil), content[:target.start]...)
+		out = append(out, version...)
+		content = append(out, content[target.end:]...)
+	}
+	return content, nil
+}
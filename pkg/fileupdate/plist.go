@@ -0,0 +1,134 @@
+package fileupdate
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// bundleVersionPattern matches what App Store Connect accepts for both
+// bundle versions: one to three period-separated integers
+var bundleVersionPattern = lazyregexp.New(`^\d+(?:\.\d+){0,2}$`)
+
+// plistIndentPattern finds the indentation of the first key of a plist
+var plistIndentPattern = lazyregexp.New(`(?m)^([ \t]*)<key>`)
+
+// bundleVersions are the keys written by the info-plist writer: the plist
+// key, the matching Xcode build setting, the option overriding the format
+// and the variable written by default
+var bundleVersions = []struct {
+	key     string
+	setting string
+	option  string
+	format  string
+}{
+	{"CFBundleShortVersionString", "MARKETING_VERSION", "short-version-format", "{MajorMinorPatch}"},
+	{"CFBundleVersion", "CURRENT_PROJECT_VERSION", "bundle-version-format", "{CommitsSinceVersionSource}"},
+}
+
+// infoPlistWriter sets CFBundleShortVersionString and CFBundleVersion in
+// an XML Info.plist, adding them to the top-level dictionary when missing.
+// With style "xcconfig" it sets the MARKETING_VERSION and
+// CURRENT_PROJECT_VERSION build settings of an .xcconfig file instead.
+type infoPlistWriter struct {
+	style   string
+	formats []string
+}
+
+func newInfoPlistWriter(options map[string]string) (Writer, error) {
+	w := &infoPlistWriter{style: options["style"]}
+	if w.style == "" {
+		w.style = "plist"
+	}
+	for _, version := range bundleVersions {
+		format := options[version.option]
+		if format == "" {
+			format = version.format
+		}
+		w.formats = append(w.formats, format)
+	}
+	return w, nil
+}
+
+func (w *infoPlistWriter) Name() string {
+	return "info-plist"
+}
+
+func (w *infoPlistWriter) Validate() error {
+	if w.style != "plist" && w.style != "xcconfig" {
+		return fmt.Errorf("invalid style %q, want plist or xcconfig", w.style)
+	}
+	for _, format := range w.formats {
+		if _, err := (&v1.Variables{}).Expand(format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *infoPlistWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	if w.style == "plist" && !bytes.Contains(content, []byte("<plist")) {
+		return nil, fmt.Errorf("not an XML property list; convert binary plists with plutil -convert xml1")
+	}
+
+	for i, version := range bundleVersions {
+		value, err := variables.Expand(w.formats[i])
+		if err != nil {
+			return nil, err
+		}
+		if !bundleVersionPattern.MatchString(value) {
+			return nil, fmt.Errorf("%s %q is not one to three period-separated integers", version.key, value)
+		}
+		if w.style == "xcconfig" {
+			content = setBuildSetting(content, version.setting, value)
+		} else if content, err = setPlistString(content, version.key, value); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// setPlistString sets the string value of key in a plist, adding the key
+// at the end of the top-level dictionary when missing
+func setPlistString(content []byte, key, value string) ([]byte, error) {
+	re := regexp.MustCompile(`<key>` + regexp.QuoteMeta(key) + `</key>\s*<string>([^<]*)</string>`)
+	if re.Match(content) {
+		return replaceMatches(re, content, []byte(value))
+	}
+
+	end := bytes.LastIndex(content, []byte("</dict>"))
+	if end < 0 {
+		return nil, fmt.Errorf("plist has no dictionary to add %s to", key)
+	}
+	indent := "\t"
+	if m := plistIndentPattern.FindSubmatch(content); m != nil {
+		indent = string(m[1])
+	}
+	// Insert before the indentation of the closing tag
+	lineStart := bytes.LastIndexByte(content[:end], '\n') + 1
+	if strings.TrimSpace(string(content[lineStart:end])) != "" {
+		lineStart = end
+	}
+	entry := fmt.Sprintf("%s<key>%s</key>\n%s<string>%s</string>\n", indent, key, indent, value)
+	out := append([]byte(nil), content[:lineStart]...)
+	out = append(out, entry...)
+	return append(out, content[lineStart:]...), nil
+}
+
+// setBuildSetting sets an Xcode build setting in an .xcconfig file,
+// appending it when missing
+func setBuildSetting(content []byte, setting, value string) []byte {
+	re := regexp.MustCompile(`(?m)^[ \t]*` + setting + `[ \t]*=[ \t]*(.*?)[ \t]*\r?$`)
+	if re.Match(content) {
+		content, _ = replaceMatches(re, content, []byte(value))
+		return content
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	return append(content, setting+" = "+value+"\n"...)
+}
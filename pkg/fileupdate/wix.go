@@ -0,0 +1,70 @@
+package fileupdate
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+var (
+	// The Version attribute of a WiX 3 Product or WiX 4 Package element
+	wixVersionPattern = lazyregexp.New(`<(?:Product|Package)\b[^>]*?\sVersion\s*=\s*["']([^"']*)["']`)
+	// MSI product versions: three or four numeric components
+	msiVersionPattern = lazyregexp.New(`^\d+\.\d+\.\d+(?:\.\d+)?$`)
+)
+
+// wixWriter sets the product version of a WiX source file: the Version
+// attribute of its Product or Package element, or with the define option
+// the value of a preprocessor variable such as
+// <?define ProductVersion = "1.2.3.4" ?> that the element refers to
+type wixWriter struct {
+	define string
+	format string
+}
+
+func newWiXWriter(options map[string]string) (Writer, error) {
+	w := &wixWriter{define: options["define"], format: options["format"]}
+	if w.format == "" {
+		w.format = "{MsiVersion}"
+	}
+	return w, nil
+}
+
+func (w *wixWriter) Name() string {
+	return "wix"
+}
+
+func (w *wixWriter) Validate() error {
+	if w.define != "" && !propertyKeyPattern.MatchString(w.define) {
+		return fmt.Errorf("invalid define %q", w.define)
+	}
+	_, err := (&v1.Variables{}).Expand(w.format)
+	return err
+}
+
+func (w *wixWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := variables.Expand(w.format)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		return nil, fmt.Errorf("version %s exceeds the 255.255.65535 Windows Installer allows", variables.MajorMinorPatch)
+	}
+	if !msiVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("%q is not a Windows Installer version", version)
+	}
+
+	if w.define != "" {
+		re := regexp.MustCompile(`<\?define\s+` + regexp.QuoteMeta(w.define) + `\s*=\s*["']?([^"'?\s]*)["']?\s*\?>`)
+		if !re.Match(content) {
+			return nil, fmt.Errorf("no <?define %s ?> found", w.define)
+		}
+		return replaceMatches(re, content, []byte(version))
+	}
+	if !wixVersionPattern.Match(content) {
+		return nil, fmt.Errorf("no Product or Package element with a Version found")
+	}
+	return replaceMatches(wixVersionPattern.Regexp(), content, []byte(version))
+}
@@ -0,0 +1,213 @@
+package fileupdate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// defaultFormat is the version written when a writer has no format option
+const defaultFormat = "{SemVer}"
+
+// formatOption returns the format option, "{SemVer}" when unset
+func formatOption(options map[string]string) string {
+	if format := options["format"]; format != "" {
+		return format
+	}
+	return defaultFormat
+}
+
+// versionFileWriter replaces the whole file with the version, as in a
+// VERSION file
+type versionFileWriter struct {
+	format string
+}
+
+func newVersionFileWriter(options map[string]string) (Writer, error) {
+	return &versionFileWriter{format: formatOption(options)}, nil
+}
+
+func (w *versionFileWriter) Name() string {
+	return "version-file"
+}
+
+func (w *versionFileWriter) Validate() error {
+	_, err := (&v1.Variables{}).Expand(w.format)
+	return err
+}
+
+func (w *versionFileWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := variables.Expand(w.format)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(version + "\n"), nil
+}
+
+// regexWriter replaces every match of a pattern with the version. When the
+// pattern has groups, only the first group of each match is replaced, so
+// the surrounding text can be matched without repeating it.
+type regexWriter struct {
+	pattern string
+	format  string
+	re      *regexp.Regexp
+}
+
+func newRegexWriter(options map[string]string) (Writer, error) {
+	w := &regexWriter{pattern: options["pattern"], format: formatOption(options)}
+	re, err := regexp.Compile(w.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	w.re = re
+	return w, nil
+}
+
+func (w *regexWriter) Name() string {
+	return "regex"
+}
+
+func (w *regexWriter) Validate() error {
+	if w.pattern == "" {
+		return fmt.Errorf("pattern option is required")
+	}
+	_, err := (&v1.Variables{}).Expand(w.format)
+	return err
+}
+
+func (w *regexWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	version, err := variables.Expand(w.format)
+	if err != nil {
+		return nil, err
+	}
+	return replaceMatches(w.re, content, []byte(version))
+}
+
+// replaceMatches replaces the first group of every match of re, or the
+// whole match when re has no groups. Finding no match is an error.
+func replaceMatches(re *regexp.Regexp, content, replacement []byte) ([]byte, error) {
+	matches := re.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("pattern %q does not match", re)
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if re.NumSubexp() > 0 {
+			start, end = m[2], m[3]
+			if start < 0 {
+				continue
+			}
+		}
+		out = append(out, content[last:start]...)
+		out = append(out, replacement...)
+		last = end
+	}
+	return append(out, content[last:]...), nil
+}
+
+// packageJSONVersion matches the version of a package.json. The first
+// match is the package's own, as npm writes it before any dependencies.
+var packageJSONVersion = lazyregexp.New(`"version"\s*:\s*("(?:[^"\\]|\\.)*")`)
+
+// packageJSONWriter sets the version of an npm package.json, leaving the
+// rest of the file as it was formatted
+type packageJSONWriter struct {
+	format string
+}
+
+func newPackageJSONWriter(options map[string]string) (Writer, error) {
+	return &packageJSONWriter{format: formatOption(options)}, nil
+}
+
+func (w *packageJSONWriter) Name() string {
+	return "package-json"
+}
+
+func (w *packageJSONWriter) Validate() error {
+	_, err := (&v1.Variables{}).Expand(w.format)
+	return err
+}
+
+func (w *packageJSONWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	var pkg struct {
+		Version *string `json:"version"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+	if pkg.Version == nil {
+		return nil, fmt.Errorf("package.json has no version")
+	}
+	version, err := variables.Expand(w.format)
+	if err != nil {
+		return nil, err
+	}
+
+	quoted, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+	m := packageJSONVersion.FindSubmatchIndex(content)
+	if m == nil {
+		return nil, fmt.Errorf("package.json has no version")
+	}
+	out := append([]byte(nil), content[:m[2]]...)
+	out = append(out, quoted...)
+	return append(out, content[m[3]:]...), nil
+}
+
+// msbuildProperties are the version properties of an MSBuild project and
+// the variables written into them, as GitVersion's /updateprojectfiles does
+var msbuildProperties = []struct {
+	name   string
+	format string
+}{
+	{"Version", "{SemVer}"},
+	{"AssemblyVersion", "{AssemblySemVer}"},
+	{"FileVersion", "{AssemblySemFileVer}"},
+	{"InformationalVersion", "{InformationalVersion}"},
+}
+
+// msbuildWriter updates the version properties of a .csproj, .vbproj or
+// Directory.Build.props file. Only properties present in the file change.
+type msbuildWriter struct{}
+
+func newMSBuildWriter(options map[string]string) (Writer, error) {
+	return &msbuildWriter{}, nil
+}
+
+func (w *msbuildWriter) Name() string {
+	return "msbuild"
+}
+
+func (w *msbuildWriter) Validate() error {
+	return nil
+}
+
+func (w *msbuildWriter) Update(content []byte, variables *v1.Variables) ([]byte, error) {
+	found := false
+	for _, property := range msbuildProperties {
+		re := regexp.MustCompile(`<` + property.name + `>([^<]*)</` + property.name + `>`)
+		if !re.Match(content) {
+			continue
+		}
+		found = true
+		value, err := variables.Expand(property.format)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = replaceMatches(re, content, []byte(value)); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no Version, AssemblyVersion, FileVersion or InformationalVersion property found")
+	}
+	return content, nil
+}
@@ -0,0 +1,48 @@
+package gitversion
+
+import (
+	"fmt"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// assemblyVersion renders AssemblySemVer for version according to scheme.
+// Components the scheme leaves out are zero, so MajorMinor keeps the
+// assembly version stable across patch releases for binding redirects.
+func assemblyVersion(version *semver.Version, scheme config.AssemblyVersioningScheme) (string, error) {
+	switch scheme {
+	case config.AssemblyMajorMinorPatchTag:
+		number, _ := version.PreReleaseNumber()
+		return fmt.Sprintf("%d.%d.%d.%d", version.Major, version.Minor, version.Patch, number), nil
+	case config.AssemblyMajorMinorPatch, "":
+		return version.AssemblySemVer(), nil
+	case config.AssemblyMajorMinor:
+		return fmt.Sprintf("%d.%d.0.0", version.Major, version.Minor), nil
+	case config.AssemblyMajor:
+		return fmt.Sprintf("%d.0.0.0", version.Major), nil
+	case config.AssemblyNone:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown assembly-versioning-scheme %q", scheme)
+	}
+}
+
+// Windows Installer limits of the product version components
+const (
+	msiMaxMajor    = 255
+	msiMaxMinor    = 255
+	msiMaxBuild    = 65535
+	msiMaxRevision = 65535
+)
+
+// msiVersion renders MsiVersion, Major.Minor.Patch.Commits within Windows
+// Installer's 255.255.65535.65535 limits, or "" when the version exceeds
+// them. The commit count is capped, as Windows Installer ignores the
+// fourth component when comparing versions anyway.
+func msiVersion(version *semver.Version, commits int) string {
+	if version.Major > msiMaxMajor || version.Minor > msiMaxMinor || version.Patch > msiMaxBuild {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", version.Major, version.Minor, version.Patch, min(commits, msiMaxRevision))
+}
@@ -0,0 +1,63 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+func TestAssemblyVersion(t *testing.T) {
+	version := &semver.Version{Major: 2, Minor: 3, Patch: 4, PreRelease: "beta.7"}
+
+	tests := []struct {
+		scheme   config.AssemblyVersioningScheme
+		expected string
+	}{
+		{config.AssemblyMajorMinorPatchTag, "2.3.4.7"},
+		{config.AssemblyMajorMinorPatch, "2.3.4.0"},
+		{"", "2.3.4.0"},
+		{config.AssemblyMajorMinor, "2.3.0.0"},
+		{config.AssemblyMajor, "2.0.0.0"},
+		{config.AssemblyNone, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := assemblyVersion(version, tt.scheme)
+		if err != nil {
+			t.Errorf("assemblyVersion(%q) failed: %v", tt.scheme, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("assemblyVersion(%q) = %q, want %q", tt.scheme, got, tt.expected)
+		}
+	}
+
+	stable := &semver.Version{Major: 2, Minor: 3, Patch: 4}
+	if got, _ := assemblyVersion(stable, config.AssemblyMajorMinorPatchTag); got != "2.3.4.0" {
+		t.Errorf("assemblyVersion(MajorMinorPatchTag) = %q for a stable version, want 2.3.4.0", got)
+	}
+
+	if _, err := assemblyVersion(version, "MajorMinorPatchBuild"); err == nil {
+		t.Errorf("Expected an error for an unknown scheme")
+	}
+}
+
+func TestMsiVersion(t *testing.T) {
+	tests := []struct {
+		version  semver.Version
+		commits  int
+		expected string
+	}{
+		{semver.Version{Major: 1, Minor: 2, Patch: 3, PreRelease: "beta.4"}, 5, "1.2.3.5"},
+		{semver.Version{Major: 255, Minor: 255, Patch: 65535}, 70000, "255.255.65535.65535"},
+		{semver.Version{Major: 256}, 0, ""},
+		{semver.Version{Major: 1, Patch: 65536}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := msiVersion(&tt.version, tt.commits); got != tt.expected {
+			t.Errorf("msiVersion(%s, %d) = %q, want %q", tt.version.String(), tt.commits, got, tt.expected)
+		}
+	}
+}
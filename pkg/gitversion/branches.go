@@ -0,0 +1,30 @@
+package gitversion
+
+import (
+	"fmt"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+)
+
+// BranchStatus describes a branch's merge and release state
+type BranchStatus = version.BranchStatus
+
+// BranchState classifies a branch in a branch report
+type BranchState = version.BranchState
+
+// Branch states reported by BranchReport
+const (
+	BranchActive   = version.BranchActive
+	BranchMerged   = version.BranchMerged
+	BranchReleased = version.BranchReleased
+)
+
+// BranchReport lists the repository's branches with their merge and release
+// state, flagging release and hotfix branches that are safe to delete.
+func (gv *GitVersion) BranchReport() ([]*BranchStatus, error) {
+	report, err := gv.calculator.BranchReport()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return report, nil
+}
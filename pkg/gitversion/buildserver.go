@@ -0,0 +1,92 @@
+package gitversion
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/buildservers"
+)
+
+// writeBuildServer publishes the version variables to the detected build
+// server and returns what it wrote to stdout, e.g. Azure logging commands.
+func (gv *GitVersion) writeBuildServer(diagnostics *Diagnostics, getenv func(string) string) (string, error) {
+	server := buildservers.Detect(getenv)
+	if server == nil {
+		return "", fmt.Errorf("no supported build server detected")
+	}
+	gv.logDebug("detected build server", "name", server.Name())
+
+	var b strings.Builder
+	if err := server.WriteOutput(getenv, &b, gv.buildServerOutput(diagnostics)); err != nil {
+		return "", fmt.Errorf("failed to write %s variables: %w", server.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// writeGitHubActions writes the version variables to the GitHub Actions
+// step output and environment files, without requiring detection.
+func (gv *GitVersion) writeGitHubActions(diagnostics *Diagnostics, getenv func(string) string) (string, error) {
+	if err := buildservers.WriteGitHubActions(getenv, gv.buildServerOutput(diagnostics)); err != nil {
+		return "", fmt.Errorf("failed to write GitHub Actions variables: %w", err)
+	}
+	return "", nil
+}
+
+func (gv *GitVersion) buildServerOutput(diagnostics *Diagnostics) *buildservers.Output {
+	variables := gv.Variables(diagnostics)
+	output := &buildservers.Output{Variables: variableList(variables)}
+	if gv.config.UpdateBuildNumber {
+		output.BuildNumber = variables.FullSemVer
+	}
+	return output
+}
+
+// variableList flattens the scalar version variables in declaration order,
+// named after their JSON keys.
+func variableList(output *JSONOutput) []buildservers.Variable {
+	value := reflect.ValueOf(output).Elem()
+	typ := value.Type()
+
+	variables := make([]buildservers.Variable, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		var s string
+		switch field := value.Field(i); field.Kind() {
+		case reflect.Int:
+			s = strconv.FormatInt(field.Int(), 10)
+		case reflect.String:
+			s = field.String()
+		case reflect.Pointer:
+			if field.Type().Elem().Kind() != reflect.Int {
+				continue // nested sections such as BranchConfig are not variables
+			}
+			if !field.IsNil() {
+				s = strconv.FormatInt(field.Elem().Int(), 10)
+			}
+		default:
+			continue // nested sections such as BranchConfig are not variables
+		}
+		variables = append(variables, buildservers.Variable{Name: name, Value: s})
+	}
+	return variables
+}
+
+// Variable returns the scalar variable name as text. Names match case
+// insensitively, like GitVersion's /showvariable.
+func (output *JSONOutput) Variable(name string) (string, error) {
+	variables := variableList(output)
+	names := make([]string, 0, len(variables))
+	for _, v := range variables {
+		if strings.EqualFold(v.Name, name) {
+			return v.Value, nil
+		}
+		names = append(names, v.Name)
+	}
+	return "", fmt.Errorf("unknown variable %q (available: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,42 @@
+package gitversion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVariableList(t *testing.T) {
+	variables := variableList(&JSONOutput{Major: 1, SemVer: "1.2.3", CommitsSinceVersionSource: 4})
+
+	values := make(map[string]string, len(variables))
+	for _, v := range variables {
+		values[v.Name] = v.Value
+	}
+
+	if variables[0].Name != "Major" || variables[0].Value != "1" {
+		t.Errorf("Expected Major first, got %+v", variables[0])
+	}
+	if values["SemVer"] != "1.2.3" {
+		t.Errorf("SemVer = %q, want 1.2.3", values["SemVer"])
+	}
+	if values["CommitsSinceVersionSource"] != "4" {
+		t.Errorf("CommitsSinceVersionSource = %q, want 4", values["CommitsSinceVersionSource"])
+	}
+}
+
+func TestVariable(t *testing.T) {
+	output := &JSONOutput{Major: 2, SemVer: "2.0.0-beta.1"}
+
+	if got, err := output.Variable("SemVer"); err != nil || got != "2.0.0-beta.1" {
+		t.Errorf("Variable(SemVer) = %q, %v", got, err)
+	}
+	if got, err := output.Variable("major"); err != nil || got != "2" {
+		t.Errorf("Variable(major) = %q, %v", got, err)
+	}
+	if _, err := output.Variable("BranchConfig"); err == nil {
+		t.Errorf("Variable(BranchConfig) should fail for a nested section")
+	}
+	if _, err := output.Variable("Nope"); err == nil || !strings.Contains(err.Error(), "FullSemVer") {
+		t.Errorf("Variable(Nope) error should list the available names, got %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package gitversion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// CacheDirName is the directory of the git directory calculations are
+// cached in, as GitVersion does
+const CacheDirName = "gitversion_cache"
+
+// Defaults of the cache eviction policy
+const (
+	DefaultCacheMaxAge  = 30 * 24 * time.Hour
+	DefaultCacheMaxSize = 50 << 20
+)
+
+// cacheFormat changes whenever entries written before would no longer
+// match what this version calculates, so they are not read
+const cacheFormat = 2
+
+// cacheEntry is a cached calculation: the diagnostics and the variables
+// derived from them, which take a dozen git commands of their own
+type cacheEntry struct {
+	Diagnostics *Diagnostics `json:"Diagnostics"`
+	Variables   *JSONOutput  `json:"Variables"`
+}
+
+// cacheRequest is everything besides the repository a calculation depends on
+type cacheRequest struct {
+	Branch         string
+	Workflow       version.WorkflowType
+	ForceIncrement string
+	NextVersion    string
+	Module         string
+}
+
+// cachePath returns the cache file for a calculation of HEAD in repo, named
+// <sha>-<hash> after the commit and a hash of the refs, configuration and
+// request, or "" when the calculation cannot be cached
+func (gv *GitVersion) cachePath(repo *git.Repository, request cacheRequest) string {
+	if !gv.cache {
+		return ""
+	}
+	sha, err := repo.GetSHA()
+	if err != nil || sha == "unknown" {
+		return ""
+	}
+	refs, err := repo.ListRefs()
+	if err != nil {
+		return ""
+	}
+	_, commonDir, err := repo.GetGitDirs()
+	if err != nil {
+		return ""
+	}
+	config, err := json.Marshal(gv.config)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "format %d\nrequest %+v\ntag prefix %q\nconfig %s\nrefs\n%s", cacheFormat, request, repo.TagPrefix(), config, refs)
+	// Deepening a shallow clone changes the history but not the refs
+	if shallow, err := os.ReadFile(filepath.Join(commonDir, "shallow")); err == nil {
+		fmt.Fprintf(h, "shallow\n%s", shallow)
+	}
+	// The version file strategy reads the working tree, not HEAD
+	if root, err := repo.GetRootDir(); err == nil {
+		path := version.DefaultVersionFile
+		if gv.config.VersionFile != "" {
+			path = gv.config.VersionFile
+		}
+		if data, err := os.ReadFile(filepath.Join(root, path)); err == nil {
+			fmt.Fprintf(h, "version file %s\n", data)
+		}
+	}
+	return filepath.Join(commonDir, CacheDirName, sha+"-"+hex.EncodeToString(h.Sum(nil))[:16]+".json")
+}
+
+// loadCache reads the entry at path, nil when there is none or it cannot
+// be read
+func (gv *GitVersion) loadCache(path string) *cacheEntry {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil || entry.Diagnostics == nil || entry.Diagnostics.Version == nil || entry.Variables == nil {
+		gv.logDebug("ignoring invalid cache entry", "path", path)
+		return nil
+	}
+	gv.logDebug("using cached calculation", "path", path)
+	return entry
+}
+
+// storeCache writes the calculation to path and keeps it for Variables,
+// then evicts entries beyond the cache policy. A cache that cannot be
+// written only costs the next run its speed.
+func (gv *GitVersion) storeCache(path string, diagnostics *Diagnostics) {
+	if path == "" {
+		return
+	}
+	entry := &cacheEntry{Diagnostics: diagnostics, Variables: gv.Variables(diagnostics)}
+	gv.cached = entry
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(path, data)
+	}
+	if err == nil {
+		_, err = PruneCache(filepath.Dir(path), gv.cachePolicy)
+	}
+	if err != nil {
+		gv.logDebug("failed to cache calculation", "error", err)
+	}
+}
+
+// CachePolicy bounds the calculation cache: entries older than MaxAge are
+// evicted, then the oldest until the rest fit in MaxSize bytes. Zero
+// fields do not limit the cache.
+type CachePolicy struct {
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// NewCachePolicy returns the policy of the cache configuration, the
+// defaults for fields it leaves unset
+func NewCachePolicy(cc *config.CacheConfig) (CachePolicy, error) {
+	policy := CachePolicy{MaxAge: DefaultCacheMaxAge, MaxSize: DefaultCacheMaxSize}
+	if cc == nil {
+		return policy, nil
+	}
+	if cc.MaxAge != "" {
+		age, err := time.ParseDuration(cc.MaxAge)
+		if err != nil || age < 0 {
+			return policy, fmt.Errorf("invalid cache max-age %q", cc.MaxAge)
+		}
+		policy.MaxAge = age
+	}
+	if cc.MaxSize != "" {
+		size, err := parseSize(cc.MaxSize)
+		if err != nil {
+			return policy, fmt.Errorf("invalid cache max-size %q", cc.MaxSize)
+		}
+		policy.MaxSize = size
+	}
+	return policy, nil
+}
+
+// sizeUnits are the suffixes parseSize accepts, longest first
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"B", 1},
+}
+
+// parseSize parses a byte count such as "512KB" or "50MB"
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// CacheEntry describes a cached calculation
+type CacheEntry struct {
+	// Path is the cache file
+	Path string `json:"Path"`
+	// Commit is the commit the version was calculated for
+	Commit  string    `json:"Commit"`
+	Branch  string    `json:"Branch"`
+	Version string    `json:"Version"`
+	Size    int64     `json:"Size"`
+	Written time.Time `json:"Written"`
+}
+
+// CacheDir returns the calculation cache directory of the repository in
+// dir, the current directory when empty. It need not exist.
+func CacheDir(dir string) (string, error) {
+	_, commonDir, err := git.NewRepositoryAt(dir).GetGitDirs()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, CacheDirName), nil
+}
+
+// ListCache returns the entries of the cache in cacheDir, newest first.
+// Entries that cannot be read are listed without a version.
+func ListCache(cacheDir string) ([]*CacheEntry, error) {
+	return scanCache(cacheDir, true)
+}
+
+// scanCache lists the entries of the cache in cacheDir, newest first,
+// reading their versions when withVersions is set
+func scanCache(cacheDir string, withVersions bool) ([]*CacheEntry, error) {
+	files, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var entries []*CacheEntry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		commit, _, _ := strings.Cut(strings.TrimSuffix(name, ".json"), "-")
+		entry := &CacheEntry{
+			Path:    filepath.Join(cacheDir, name),
+			Commit:  commit,
+			Size:    info.Size(),
+			Written: info.ModTime(),
+		}
+		if withVersions {
+			entry.Branch, entry.Version = readCacheVersion(entry.Path)
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Written.After(entries[j].Written)
+	})
+	return entries, nil
+}
+
+// readCacheVersion returns the branch and version of a cache entry, empty
+// when it cannot be read
+func readCacheVersion(path string) (branch, version string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", ""
+	}
+	var entry cacheEntry
+	if json.Unmarshal(data, &entry) != nil || entry.Diagnostics == nil || entry.Variables == nil {
+		return "", ""
+	}
+	return entry.Diagnostics.Branch, entry.Variables.FullSemVer
+}
+
+// PruneCache evicts the entries of the cache in cacheDir beyond policy and
+// returns how many it removed
+func PruneCache(cacheDir string, policy CachePolicy) (int, error) {
+	entries, err := scanCache(cacheDir, false)
+	if err != nil {
+		return 0, err
+	}
+	var evict []*CacheEntry
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+		expired := policy.MaxAge > 0 && time.Since(entry.Written) > policy.MaxAge
+		if expired || (policy.MaxSize > 0 && size > policy.MaxSize) {
+			evict = append(evict, entry)
+		}
+	}
+	return removeCacheEntries(evict)
+}
+
+// ClearCache removes the entries of the cache in cacheDir written longer
+// than olderThan ago, every entry when olderThan is zero, and returns how
+// many it removed
+func ClearCache(cacheDir string, olderThan time.Duration) (int, error) {
+	entries, err := scanCache(cacheDir, false)
+	if err != nil {
+		return 0, err
+	}
+	var evict []*CacheEntry
+	for _, entry := range entries {
+		if olderThan == 0 || time.Since(entry.Written) > olderThan {
+			evict = append(evict, entry)
+		}
+	}
+	return removeCacheEntries(evict)
+}
+
+func removeCacheEntries(entries []*CacheEntry) (int, error) {
+	removed := 0
+	for _, entry := range entries {
+		// Another run may have evicted it already
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// writeFileAtomic writes data to path through a temporary file, so
+// concurrent runs never read a partial entry
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,98 @@
+package gitversion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+func TestNewCachePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.CacheConfig
+		want    CachePolicy
+		wantErr bool
+	}{
+		{"defaults", nil, CachePolicy{MaxAge: DefaultCacheMaxAge, MaxSize: DefaultCacheMaxSize}, false},
+		{"age", &config.CacheConfig{MaxAge: "24h"}, CachePolicy{MaxAge: 24 * time.Hour, MaxSize: DefaultCacheMaxSize}, false},
+		{"size", &config.CacheConfig{MaxSize: "10MB"}, CachePolicy{MaxAge: DefaultCacheMaxAge, MaxSize: 10 << 20}, false},
+		{"bytes", &config.CacheConfig{MaxSize: "4096"}, CachePolicy{MaxAge: DefaultCacheMaxAge, MaxSize: 4096}, false},
+		{"lower case unit", &config.CacheConfig{MaxSize: "512 kb"}, CachePolicy{MaxAge: DefaultCacheMaxAge, MaxSize: 512 << 10}, false},
+		{"unlimited", &config.CacheConfig{MaxAge: "0s", MaxSize: "0"}, CachePolicy{}, false},
+		{"bad age", &config.CacheConfig{MaxAge: "a week"}, CachePolicy{}, true},
+		{"negative age", &config.CacheConfig{MaxAge: "-1h"}, CachePolicy{}, true},
+		{"bad size", &config.CacheConfig{MaxSize: "10TB"}, CachePolicy{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCachePolicy(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCachePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("NewCachePolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	// write creates a 100 byte entry written age ago
+	write := func(name string, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := func() []string {
+		t.Helper()
+		entries, err := ListCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Commit)
+		}
+		return names
+	}
+
+	write("new-aaaa", time.Minute)
+	write("mid-bbbb", time.Hour)
+	write("old-cccc", 48*time.Hour)
+	write("ancient-dddd", 30*24*time.Hour)
+	if got := names(); len(got) != 4 || got[0] != "new" || got[3] != "ancient" {
+		t.Fatalf("ListCache() = %v, want newest first", got)
+	}
+
+	// Age goes first, then the oldest until the rest fit
+	removed, err := PruneCache(dir, CachePolicy{MaxAge: 7 * 24 * time.Hour, MaxSize: 250})
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if got := names(); removed != 2 || len(got) != 2 || got[0] != "new" || got[1] != "mid" {
+		t.Errorf("PruneCache() removed %d, left %v; want new and mid", removed, got)
+	}
+
+	if removed, err := ClearCache(dir, 30*time.Minute); err != nil || removed != 1 {
+		t.Errorf("ClearCache(30m) = %d, %v; want 1", removed, err)
+	}
+	if removed, err := ClearCache(dir, 0); err != nil || removed != 1 {
+		t.Errorf("ClearCache(0) = %d, %v; want 1", removed, err)
+	}
+
+	entries, err := ListCache(filepath.Join(dir, "missing"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("ListCache() of a missing directory = %v, %v; want nothing", entries, err)
+	}
+}
@@ -0,0 +1,71 @@
+package gitversion
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+// Progressive deepening fetches deepenStep commits first and doubles the
+// step until a version tag is reachable; past deepenLimit commits it
+// fetches the rest of the history at once
+const (
+	deepenStep  = 50
+	deepenLimit = 3200
+)
+
+// deepenClone deepens a shallow clone from gv.remote until a version tag is
+// reachable from HEAD or the clone is complete
+func (gv *GitVersion) deepenClone(ctx context.Context, repo *git.Repository) (err error) {
+	task := progress.Start(gv.progress, "deepen", 0)
+	defer func() { task.Finish(err) }()
+
+	auth, err := git.RemoteAuthFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	for by, fetched := deepenStep, 0; ; by *= 2 {
+		shallow, err := repo.IsShallow()
+		if err != nil {
+			return err
+		}
+		if !shallow {
+			if fetched > 0 {
+				gv.logInfo("fetched the full history of the shallow clone")
+			}
+			return nil
+		}
+		if tag, _ := repo.GetLatestVersionTag(); tag != "" {
+			gv.logInfo("deepened the shallow clone", "commits", fetched, "tag", tag)
+			return nil
+		}
+		if fetched >= deepenLimit {
+			by = 0
+		}
+		gv.logDebug("deepening the shallow clone", "remote", gv.remote, "by", by)
+		if by == 0 {
+			task.Step("the full history")
+		} else {
+			task.Step(fmt.Sprintf("%d more commits", by))
+		}
+		err = retry.DoWithPolicy(ctx, gv.retries.For(FetchOperation), func(ctx context.Context) error {
+			err := repo.Deepen(gv.remote, by, auth)
+			if err != nil && !isTransientRemoteError(err) {
+				return retry.Permanent(err)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if by == 0 {
+			gv.logInfo("fetched the full history of the shallow clone")
+			return nil
+		}
+		fetched += by
+	}
+}
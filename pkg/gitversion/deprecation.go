@@ -0,0 +1,41 @@
+package gitversion
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	deprecationMu     sync.Mutex
+	deprecationWarned = map[string]bool{}
+)
+
+// warnDeprecated logs a warning the first time a deprecated entry point is
+// used in this process.
+func (gv *GitVersion) warnDeprecated(name, replacement string) {
+	deprecationMu.Lock()
+	defer deprecationMu.Unlock()
+
+	if deprecationWarned[name] {
+		return
+	}
+	deprecationWarned[name] = true
+
+	gv.logWarn(fmt.Sprintf("%s is deprecated and will be removed in the next major version; use %s instead", name, replacement))
+}
+
+// warnConfigDeprecations logs the legacy configuration keys LoadConfig
+// mapped, each once per process
+func (gv *GitVersion) warnConfigDeprecations(deprecations []string) {
+	deprecationMu.Lock()
+	defer deprecationMu.Unlock()
+
+	for _, deprecation := range deprecations {
+		if deprecationWarned[deprecation] {
+			continue
+		}
+		deprecationWarned[deprecation] = true
+
+		gv.logWarn(deprecation)
+	}
+}
@@ -0,0 +1,36 @@
+package gitversion
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWarnDeprecatedOnce(t *testing.T) {
+	var buf bytes.Buffer
+	gv := &GitVersion{logger: slog.New(slog.NewTextHandler(&buf, nil))}
+
+	gv.warnDeprecated("test.OldEntryPoint", "test.NewEntryPoint")
+	gv.warnDeprecated("test.OldEntryPoint", "test.NewEntryPoint")
+
+	output := buf.String()
+	if strings.Count(output, "level=WARN") != 1 {
+		t.Errorf("Expected exactly one warning, got %q", output)
+	}
+	if !strings.Contains(output, "use test.NewEntryPoint instead") {
+		t.Errorf("Warning should name the replacement, got %q", output)
+	}
+}
+
+func TestWarnConfigDeprecationsOnce(t *testing.T) {
+	var buf bytes.Buffer
+	gv := &GitVersion{logger: slog.New(slog.NewTextHandler(&buf, nil))}
+
+	gv.warnConfigDeprecations([]string{"test-key is deprecated; use test-replacement"})
+	gv.warnConfigDeprecations([]string{"test-key is deprecated; use test-replacement"})
+
+	if output := buf.String(); strings.Count(output, "level=WARN") != 1 {
+		t.Errorf("Expected exactly one warning, got %q", output)
+	}
+}
@@ -0,0 +1,16 @@
+package gitversion
+
+import "strings"
+
+// formatDockerLabels renders the OCI image annotations of the version as
+// docker build --label arguments. None of the values contain spaces, so
+// the output can be used unquoted in $(...). The creation date is the
+// commit date, so images rebuilt from the same commit carry the same labels.
+func formatDockerLabels(output *JSONOutput) string {
+	labels := []string{
+		"org.opencontainers.image.version=" + output.FullSemVer,
+		"org.opencontainers.image.revision=" + output.Sha,
+		"org.opencontainers.image.created=" + commitDateRFC3339(output.CommitDate),
+	}
+	return "--label " + strings.Join(labels, " --label ")
+}
@@ -0,0 +1,18 @@
+package gitversion
+
+import "testing"
+
+func TestFormatDockerLabels(t *testing.T) {
+	output := formatDockerLabels(&JSONOutput{
+		FullSemVer: "1.2.3-beta.4+5",
+		Sha:        "abc1234def",
+		CommitDate: "2024-01-02 03:04:05 +0000",
+	})
+
+	expected := "--label org.opencontainers.image.version=1.2.3-beta.4+5 " +
+		"--label org.opencontainers.image.revision=abc1234def " +
+		"--label org.opencontainers.image.created=2024-01-02T03:04:05Z"
+	if output != expected {
+		t.Errorf("formatDockerLabels() = %q, want %q", output, expected)
+	}
+}
@@ -0,0 +1,44 @@
+package gitversion
+
+import (
+	"strings"
+)
+
+// envPrefix is prepended to the upper-cased variable names of the env format
+const envPrefix = "GITVERSION_"
+
+// formatEnv renders the version variables as raw GITVERSION_<NAME>=value
+// lines, the format of docker --env-file and .env files, which keep quotes
+// as part of the value. Shells source formatShell instead.
+func formatEnv(output *JSONOutput) string {
+	var b strings.Builder
+	for _, v := range variableList(output) {
+		b.WriteString(envPrefix + strings.ToUpper(v.Name) + "=" + v.Value + "\n")
+	}
+	return b.String()
+}
+
+// formatShell renders the version variables as export statements a shell
+// can eval. Values are written bare; only values a shell would split or
+// expand are single-quoted.
+func formatShell(output *JSONOutput) string {
+	var b strings.Builder
+	for _, v := range variableList(output) {
+		b.WriteString("export " + envPrefix + strings.ToUpper(v.Name) + "=" + shellValue(v.Value) + "\n")
+	}
+	return b.String()
+}
+
+func shellValue(value string) string {
+	safe := strings.IndexFunc(value, func(r rune) bool {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return false
+		}
+		return !strings.ContainsRune("_-.+/:@%,", r)
+	}) == -1
+	if safe {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,66 @@
+package gitversion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatEnv(t *testing.T) {
+	output := formatEnv(&JSONOutput{
+		Major:      1,
+		SemVer:     "1.2.3-beta.4",
+		BranchName: "release/1.2.3",
+		CommitDate: "2024-01-02 03:04:05 +0000",
+	})
+
+	expected := []string{
+		"GITVERSION_MAJOR=1\n",
+		"GITVERSION_SEMVER=1.2.3-beta.4\n",
+		"GITVERSION_BRANCHNAME=release/1.2.3\n",
+		"GITVERSION_COMMITDATE=2024-01-02 03:04:05 +0000\n",
+		"GITVERSION_PRERELEASETAG=\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Output missing %q:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "BRANCHCONFIG") || strings.Contains(output, "'") {
+		t.Errorf("Nested sections and quotes should not be rendered:\n%s", output)
+	}
+}
+
+func TestFormatShell(t *testing.T) {
+	output := formatShell(&JSONOutput{
+		SemVer:     "1.2.3-beta.4",
+		CommitDate: "2024-01-02 03:04:05 +0000",
+	})
+
+	for _, line := range []string{
+		"export GITVERSION_SEMVER=1.2.3-beta.4\n",
+		"export GITVERSION_COMMITDATE='2024-01-02 03:04:05 +0000'\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Output missing %q:\n%s", line, output)
+		}
+	}
+}
+
+func TestShellValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"1.2.3+4", "1.2.3+4"},
+		{"", ""},
+		{"a b", "'a b'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+	}
+
+	for _, tt := range tests {
+		if got := shellValue(tt.value); got != tt.expected {
+			t.Errorf("shellValue(%q) = %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+}
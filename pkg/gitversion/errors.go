@@ -0,0 +1,62 @@
+package gitversion
+
+import (
+	"errors"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// Kinds of failure embedders can tell apart with errors.Is
+var (
+	// ErrNotARepository is returned for a directory outside any git working
+	// tree
+	ErrNotARepository = errors.New("not a git repository")
+	// ErrNoCommits is returned for a branch without commits, as right after
+	// git init
+	ErrNoCommits = errors.New("no commits on the current branch")
+	// ErrShallowClone is added to a calculation that failed in a shallow
+	// clone, whose missing history is the likely cause
+	ErrShallowClone = errors.New("the clone is shallow; fetch the full history with git fetch --unshallow")
+	// ErrInvalidConfig matches every ConfigError
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
+
+// ConfigError is a configuration that cannot be used
+type ConfigError struct {
+	// Field is the dotted key at fault, e.g. "branches.main.increment", or
+	// "" when it is not known, e.g. for a file that does not parse
+	Field string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports ErrInvalidConfig as matching
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// configError wraps err as a ConfigError of field, taking the field of the
+// first problem of a validation error when field is ""
+func configError(field string, err error) error {
+	var validation *config.ValidationError
+	if field == "" && errors.As(err, &validation) {
+		field = validation.Path
+	}
+	var validations config.ValidationErrors
+	if field == "" && errors.As(err, &validations) && len(validations) > 0 {
+		field = validations[0].Path
+	}
+	return &ConfigError{Field: field, Err: err}
+}
+
+// GitError is a git command that failed, with its arguments and what it
+// printed on stderr; find it with errors.As
+type GitError = git.CommandError
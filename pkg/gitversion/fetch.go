@@ -0,0 +1,55 @@
+package gitversion
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+// FetchOperation is the retry operation for fetches before a calculation
+const FetchOperation = "fetch"
+
+// fetchTargets fetches the tags of gv.remote and the branches a
+// calculation of branch looks at: the branch itself and the source
+// branches of its configuration. Branches the remote does not have are
+// skipped.
+func (gv *GitVersion) fetchTargets(ctx context.Context, repo *git.Repository, branch string, workflow version.WorkflowType) error {
+	auth, err := git.RemoteAuthFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	wanted := map[string]bool{branch: true}
+	_, branchConfig := gv.calculator.BranchConfiguration(branch, workflow)
+	for _, source := range branchConfig.SourceBranches {
+		for _, name := range version.SourceBranchNames(source) {
+			wanted[name] = true
+		}
+	}
+
+	task := progress.Start(gv.progress, "fetch", 0)
+	err = retry.DoWithPolicy(ctx, gv.retries.For(FetchOperation), func(ctx context.Context) error {
+		heads, err := repo.RemoteHeads(gv.remote, auth)
+		if err == nil {
+			var branches []string
+			for _, head := range heads {
+				if wanted[head] {
+					branches = append(branches, head)
+				}
+			}
+			gv.logDebug("fetching", "remote", gv.remote, "branches", branches)
+			task.Step(gv.remote + " " + strings.Join(branches, ", "))
+			err = repo.FetchBranches(gv.remote, branches, auth)
+		}
+		if err != nil && !isTransientRemoteError(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+	task.Finish(err)
+	return err
+}
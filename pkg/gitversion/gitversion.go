@@ -1,12 +1,20 @@
 package gitversion
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/gomod"
 	"github.com/VirtuallyScott/gitversion-go/internal/version"
 	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
 
 type Options struct {
@@ -16,7 +24,70 @@ type Options struct {
 	Workflow       version.WorkflowType
 	ForceIncrement string
 	NextVersion    string
-	Debug          bool
+	Strategies     []string
+	// Module selects a Go module of a multi-module repository by its
+	// directory relative to the repository root. Tags are then prefixed
+	// with the directory and commits outside the module are ignored.
+	Module string
+	// MaxDuration bounds the time spent running strategies; the result is
+	// marked partial when it runs out. Zero means no limit.
+	MaxDuration time.Duration
+	Progress    progress.Reporter
+	// Debug logs debug records as text to stderr when Logger is nil
+	Debug bool
+	// Logger receives the log records of the calculation, so embedders can
+	// route them into their own logging; nil logs nothing unless Debug is
+	// set
+	Logger *slog.Logger
+
+	// StrictConfig rejects configuration files with unknown keys, invalid
+	// enum values or regular expressions that do not compile
+	StrictConfig bool
+	// ConfigFiles are layered over ConfigFile in order, each deep-merged
+	// onto the ones before it
+	ConfigFiles []string
+	// OverrideConfig sets configuration values after loading, each
+	// "key=value" with a dotted key such as "branches.main.label"
+	OverrideConfig []string
+	// Project is a directory, relative to the repository root, whose
+	// configuration file is layered over ConfigFile and ConfigFiles, or
+	// over the root's configuration file when they are empty
+	Project string
+	// IncludePaths replaces the paths of the configuration: only commits
+	// touching these directories, relative to the repository root, count
+	// towards the version. With Module they add to the module directory.
+	IncludePaths []string
+	// Component replaces the component of the configuration, whose name
+	// prefixes the version tags, e.g. api/v1.2.3. It takes precedence over
+	// the tag prefix of Module.
+	Component string
+	// GoPackage is the import path, or main, of the package whose version,
+	// commit and date variables the goldflags output sets; main when empty
+	GoPackage string
+	// DockerLabels makes the docker output print the OCI image labels as
+	// docker build --label arguments instead of the image tag
+	DockerLabels bool
+	// Dir is the working tree to calculate the version of, the current
+	// directory when empty. Relative configuration files are still
+	// resolved against the current directory. GIT_DIR, GIT_WORK_TREE and
+	// the like locate the repository as they do for git when Dir is
+	// empty, and are ignored when it is not.
+	Dir string
+	// NoCache recalculates even when the cache of the git directory holds
+	// a calculation for the same commit, refs, configuration and options
+	NoCache bool
+	// Fetch fetches the tags of Remote and the branches the calculation
+	// looks at, the target branch and its source branches, before each
+	// calculation, so stale local tags cannot produce an old version
+	Fetch bool
+	// AllowDeepen deepens a shallow clone from Remote before each
+	// calculation, progressively until a version tag is reachable from HEAD,
+	// and then completely when none is found in the commits fetched
+	AllowDeepen bool
+	// Remote is the primary remote, whose tracking branches stand in for
+	// missing local branches and which Fetch and AllowDeepen fetch from.
+	// When empty it is remote-name from the configuration, else origin.
+	Remote string
 }
 
 type GitVersion struct {
@@ -24,79 +95,392 @@ type GitVersion struct {
 	config     *config.Config
 	calculator *version.Calculator
 	formatter  *Formatter
-	debug      bool
+	logger     *slog.Logger
+	goPackage  string
+	labels     bool
+	// cache enables the calculation cache; cached is the entry of the last
+	// calculation, whose variables need not be derived again
+	cache       bool
+	cached      *cacheEntry
+	cachePolicy CachePolicy
+	// refresh clears the git output memoized by the previous calculation
+	// before each one; projects share theirs across calculations instead
+	refresh bool
+	// remote is fetched from before each calculation when fetch is set,
+	// and deepened from when deepen is set and the clone is shallow
+	remote string
+	fetch  bool
+	deepen bool
+	// retries are the retry policies of remote operations by name
+	retries retry.Policies
+	// progress reports fetches and deepening; the calculator reports the
+	// strategies
+	progress progress.Reporter
 }
 
 func New(opts *Options) (*GitVersion, error) {
-	repo := git.NewRepository()
+	// The calculator and the formatter ask git many of the same questions,
+	// so a calculation runs each query once
+	gv, err := newGitVersion(opts, git.NewCachedRepository(opts.Dir))
+	if err != nil {
+		return nil, err
+	}
+	gv.refresh = true
+	return gv, nil
+}
 
+// newGitVersion creates a GitVersion for opts that runs git through repo
+func newGitVersion(opts *Options, repo *git.Repository) (*GitVersion, error) {
 	if !repo.IsRepository() {
-		return nil, fmt.Errorf("not a git repository")
+		return nil, ErrNotARepository
+	}
+
+	configFiles := append([]string{opts.ConfigFile}, opts.ConfigFiles...)
+	if opts.Project != "" {
+		root, err := repo.GetRootDir()
+		if err != nil {
+			return nil, err
+		}
+		if configFiles, err = config.ProjectFiles(root, opts.Project, configFiles); err != nil {
+			return nil, configError("", err)
+		}
+	}
+
+	if opts.Module != "" {
+		var err error
+		repo, err = moduleRepository(repo, opts.Module)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	load := config.LoadConfig
+	if opts.StrictConfig {
+		load = config.LoadConfigStrict
+	}
+	cfg, err := load(configFiles...)
+	if err != nil {
+		return nil, configError("", fmt.Errorf("failed to load config: %w", err))
+	}
+	for _, assignment := range opts.OverrideConfig {
+		if err := cfg.Override(assignment); err != nil {
+			key, _, _ := strings.Cut(assignment, "=")
+			return nil, configError(strings.TrimSpace(key), err)
+		}
+	}
+
+	if repo, err = repo.WithVersionPrefix(cfg.TagPrefix); err != nil {
+		return nil, configError("tag-prefix", err)
+	}
+	if len(opts.IncludePaths) > 0 {
+		cfg.Paths = opts.IncludePaths
+	}
+	if len(cfg.Paths) > 0 {
+		repo = repo.WithPaths(cfg.Paths)
+	}
+	if opts.Component != "" {
+		cfg.Component = opts.Component
+	}
+	if component := strings.Trim(cfg.Component, "/"); component != "" {
+		repo = repo.WithTagPrefix(component+"/").WithCommitScopes(component, cfg.CommitScopes)
 	}
 
-	cfg, err := config.LoadConfig(opts.ConfigFile)
+	retries, err := retryPolicies(cfg.Retry)
+	if err != nil {
+		return nil, configError("retry", err)
+	}
+	cachePolicy, err := NewCachePolicy(cfg.Cache)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, configError("cache", err)
+	}
+
+	if err := validateFormat(cfg.AssemblyInformationalFormat); err != nil {
+		return nil, configError("assembly-informational-format", fmt.Errorf("invalid assembly-informational-format: %w", err))
+	}
+	if err := validateFormat(cfg.AssemblyFileVersioningFormat); err != nil {
+		return nil, configError("assembly-file-versioning-format", fmt.Errorf("invalid assembly-file-versioning-format: %w", err))
+	}
+	if _, err := assemblyVersion(&semver.Version{}, cfg.AssemblyVersioningScheme); err != nil {
+		return nil, configError("assembly-versioning-scheme", err)
 	}
 
+	// Command line strategies replace the configured list entirely
+	if len(opts.Strategies) > 0 {
+		cfg.Strategies = opts.Strategies
+	}
+
+	if opts.Remote != "" {
+		cfg.RemoteName = opts.Remote
+	}
+	if cfg.RemoteName != "" {
+		repo = repo.WithRemote(cfg.RemoteName)
+	}
+	if cfg.TagSelection != "" {
+		repo = repo.WithTagSelection(git.TagSelection(cfg.TagSelection))
+	}
+	if cfg.LatestTag != "" {
+		repo = repo.WithLatestTag(git.LatestTag(cfg.LatestTag))
+	}
+
+	// Git commands are the most detailed records there are
+	logger := newLogger(opts, os.Stderr)
+	repo = repo.WithLogger(logger)
+
 	calculator := version.NewCalculator(repo, cfg)
+	calculator.SetProgress(opts.Progress)
+	calculator.SetMaxDuration(opts.MaxDuration)
 	formatter := NewFormatter(repo)
 
-	return &GitVersion{
+	gv := &GitVersion{
 		repo:       repo,
 		config:     cfg,
 		calculator: calculator,
 		formatter:  formatter,
-		debug:      opts.Debug,
-	}, nil
+		logger:     logger,
+		goPackage:  opts.GoPackage,
+		labels:     opts.DockerLabels,
+		// Custom strategies run code the cache key cannot capture
+		cache:       !opts.NoCache && len(version.RegisteredStrategies()) == 0,
+		cachePolicy: cachePolicy,
+		remote:      repo.Remote(),
+		fetch:       opts.Fetch,
+		deepen:      opts.AllowDeepen,
+		retries:     retries,
+		progress:    opts.Progress,
+	}
+
+	gv.warnConfigDeprecations(cfg.Deprecations)
+	if gv.logger.Enabled(context.Background(), slog.LevelDebug) {
+		for _, overlap := range cfg.BranchOverlaps() {
+			gv.logDebug("branch configurations overlap",
+				"keys", overlap.Keys, "examples", overlap.Examples, "winner", overlap.Winner)
+		}
+	}
+
+	return gv, nil
 }
 
+// moduleRepository scopes repo to the Go module in dir
+func moduleRepository(repo *git.Repository, dir string) (*git.Repository, error) {
+	root, err := repo.GetRootDir()
+	if err != nil {
+		return nil, err
+	}
+	modules, err := gomod.Discover(root)
+	if err != nil {
+		return nil, err
+	}
+	module, err := gomod.Find(modules, dir)
+	if err != nil {
+		return nil, err
+	}
+	return repo.WithScope(module.TagPrefix(), module.Pathspecs(modules)), nil
+}
+
+// Calculate calculates the version and renders it in opts.OutputFormat.
+//
+// Deprecated: use the stable API in pkg/gitversion/v1, which returns
+// structured results. Calculate will be removed in the next major version.
 func (gv *GitVersion) Calculate(opts *Options) (string, error) {
+	gv.warnDeprecated("gitversion.GitVersion.Calculate", "v1.Client.Calculate")
+
+	diagnostics, err := gv.Explain(opts)
+	if err != nil {
+		return "", err
+	}
+
+	return gv.Format(diagnostics, opts.OutputFormat)
+}
+
+// Format renders the version from a calculation in the given output format
+func (gv *GitVersion) Format(diagnostics *Diagnostics, format OutputFormat) (string, error) {
+	version := diagnostics.Version
+	gv.logDebug("calculated version", "version", version.String())
+
+	switch format {
+	case AssemblySemVer:
+		return assemblyVersion(version, gv.config.AssemblyVersioningScheme)
+	case AssemblySemFileVer:
+		return gv.Variables(diagnostics).AssemblySemFileVer, nil
+	case BuildServer:
+		return gv.writeBuildServer(diagnostics, os.Getenv)
+	case GitHubActions:
+		return gv.writeGitHubActions(diagnostics, os.Getenv)
+	case Env:
+		return formatEnv(gv.Variables(diagnostics)), nil
+	case Shell:
+		return formatShell(gv.Variables(diagnostics)), nil
+	case GoLdflags:
+		return formatGoLdflags(gv.goPackage, gv.Variables(diagnostics)), nil
+	case Docker:
+		if gv.labels {
+			return formatDockerLabels(gv.Variables(diagnostics)), nil
+		}
+		return semver.DockerTag(gv.Variables(diagnostics).FullSemVer), nil
+	case JSON:
+		// Includes the branch configuration, which the formatter cannot see
+		return marshalVariables(gv.Variables(diagnostics))
+	}
+
+	output, err := gv.formatter.Format(version, format, diagnostics.Branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to format output: %w", err)
+	}
+
+	return output, nil
+}
+
+// Variables returns all version variables for a calculation
+func (gv *GitVersion) Variables(diagnostics *Diagnostics) *JSONOutput {
+	if cached := gv.cached; cached != nil && cached.Diagnostics == diagnostics {
+		variables := *cached.Variables
+		// The working tree is not part of the cache key
+		variables.UncommittedChanges, _ = gv.repo.GetUncommittedChanges()
+		return &variables
+	}
+	variables := gv.formatter.Variables(diagnostics.Version, diagnostics.Branch)
+	if bc := diagnostics.BranchConfig; bc != nil {
+		variables.BranchConfig = &BranchConfigOutput{
+			Key:              diagnostics.BranchConfigKey,
+			Label:            bc.Label,
+			Increment:        string(bc.Increment),
+			Mode:             string(bc.Mode),
+			PreReleaseWeight: bc.PreReleaseWeight,
+		}
+	}
+	variables.WeightedPreReleaseNumber = gv.weightedPreReleaseNumber(diagnostics)
+	// The SHA of the commit the base version came from; sources such as
+	// "fallback" name no commit and leave it empty
+	variables.VersionSourceSha = ""
+	if bv := diagnostics.Selected; bv != nil && bv.BaseVersionSource != "" {
+		variables.VersionSourceSha, _ = gv.repo.ResolveCommit(bv.BaseVersionSource)
+	}
+	variables.VersionSourceTagDate = ""
+	if bv := diagnostics.Selected; bv != nil && bv.Tag != "" {
+		variables.VersionSourceTagDate, _ = gv.repo.GetTaggerDate(bv.Tag)
+	}
+	variables.UncommittedChanges, _ = gv.repo.GetUncommittedChanges()
+	variables.Partial = diagnostics.Partial
+	// The scheme was validated in New
+	variables.AssemblySemVer, _ = assemblyVersion(diagnostics.Version, gv.config.AssemblyVersioningScheme)
+	// The formats were validated in New, so expansion cannot fail
+	if format := gv.config.AssemblyInformationalFormat; format != "" {
+		variables.InformationalVersion, _ = expandFormat(format, variables)
+	}
+	if format := gv.config.AssemblyFileVersioningFormat; format != "" {
+		variables.AssemblySemFileVer, _ = expandFormat(format, variables)
+	}
+	return variables
+}
+
+// weightedPreReleaseNumber adds the branch's pre-release weight to the
+// prerelease number so builds sort across branches. Stable versions get
+// tag-pre-release-weight, placing them after every prerelease of the same
+// version.
+func (gv *GitVersion) weightedPreReleaseNumber(diagnostics *Diagnostics) int {
+	if diagnostics.Version.PreRelease == "" {
+		return gv.config.TagPreReleaseWeight
+	}
+	number, _ := diagnostics.Version.PreReleaseNumber()
+	if bc := diagnostics.BranchConfig; bc != nil {
+		return bc.PreReleaseWeight + number
+	}
+	return number
+}
+
+// Explain calculates the version and returns the full decision trail:
+// candidate base versions, the selected one and the applied increment.
+func (gv *GitVersion) Explain(opts *Options) (*Diagnostics, error) {
+	return gv.ExplainContext(context.Background(), opts)
+}
+
+// ExplainContext is like Explain but fails once ctx is done, killing the
+// git commands of the calculation
+func (gv *GitVersion) ExplainContext(ctx context.Context, opts *Options) (*Diagnostics, error) {
+	// A GitVersion may outlive many commits, e.g. in a server
+	if gv.refresh {
+		gv.repo.Refresh()
+	}
+	repo := gv.repo.WithContext(ctx)
+
 	branch := opts.TargetBranch
 	if branch == "" {
 		var err error
-		branch, err = gv.repo.GetCurrentBranch()
+		branch, err = repo.GetCurrentBranch()
 		if err != nil {
-			return "", fmt.Errorf("failed to get current branch: %w", err)
+			return nil, fmt.Errorf("failed to get current branch: %w", err)
 		}
 	}
 
-	if gv.debug {
-		gv.logDebug("Target branch: %s", branch)
-		gv.logDebug("Workflow: %s", opts.Workflow)
-		gv.logDebug("Force increment: %s", opts.ForceIncrement)
-		gv.logDebug("Next version: %s", opts.NextVersion)
-		gv.logDebug("Config next version: %s", gv.config.NextVersion)
+	// -w wins over the workflow of the configuration, GitFlow applies
+	// when neither names one
+	workflow := opts.Workflow
+	if workflow == "" {
+		workflow = version.WorkflowType(gv.config.Workflow.Workflow())
 	}
+	if workflow == "" {
+		workflow = version.GitFlow
+	}
+
+	gv.logDebug("calculating version",
+		"branch", branch,
+		"workflow", workflow,
+		"force_increment", opts.ForceIncrement,
+		"next_version", opts.NextVersion,
+		"config_next_version", gv.config.NextVersion,
+		"strategies", gv.config.Strategies)
 
 	// Use config NextVersion if no command line override provided
 	nextVersion := opts.NextVersion
 	if nextVersion == "" && gv.config.NextVersion != "" {
 		nextVersion = gv.config.NextVersion
-		if gv.debug {
-			gv.logDebug("Using config next version: %s", nextVersion)
-		}
+		gv.logDebug("using configured next version", "next_version", nextVersion)
 	}
 
-	version, err := gv.calculator.CalculateVersion(branch, opts.Workflow, opts.ForceIncrement, nextVersion)
-	if err != nil {
-		return "", fmt.Errorf("failed to calculate version: %w", err)
+	// The fetched refs and the depth of the clone are part of the cache key
+	if gv.deepen {
+		if err := gv.deepenClone(ctx, repo); err != nil {
+			return nil, err
+		}
+	}
+	if gv.fetch {
+		if err := gv.fetchTargets(ctx, repo, branch, workflow); err != nil {
+			return nil, err
+		}
 	}
 
-	if gv.debug {
-		gv.logDebug("Calculated version: %s", version.String())
+	cachePath := gv.cachePath(repo, cacheRequest{
+		Branch:         branch,
+		Workflow:       workflow,
+		ForceIncrement: opts.ForceIncrement,
+		NextVersion:    nextVersion,
+		Module:         opts.Module,
+	})
+	if entry := gv.loadCache(cachePath); entry != nil {
+		gv.cached = entry
+		gv.logDiagnostics(entry.Diagnostics)
+		return entry.Diagnostics, nil
 	}
 
-	output, err := gv.formatter.Format(version, opts.OutputFormat, branch)
+	diagnostics, err := gv.calculator.ExplainContext(ctx, branch, workflow, opts.ForceIncrement, nextVersion)
+	shallow, _ := repo.IsShallow()
 	if err != nil {
-		return "", fmt.Errorf("failed to format output: %w", err)
+		if shallow && ctx.Err() == nil {
+			return nil, fmt.Errorf("failed to calculate version: %w (%w)", err, ErrShallowClone)
+		}
+		return nil, fmt.Errorf("failed to calculate version: %w", err)
+	}
+	diagnostics.Shallow = shallow
+	// A partial result is only as good as the time budget it had
+	if !diagnostics.Partial {
+		gv.storeCache(cachePath, diagnostics)
 	}
 
-	return output, nil
+	gv.logDiagnostics(diagnostics)
+	return diagnostics, nil
 }
 
-func (gv *GitVersion) logDebug(format string, args ...interface{}) {
-	if gv.debug {
-		fmt.Fprintf(os.Stderr, "[DEBUG] "+format+"\n", args...)
-	}
+// Config returns the effective configuration
+func (gv *GitVersion) Config() *config.Config {
+	return gv.config
 }
@@ -0,0 +1,50 @@
+package gitversion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultGoPackage is the package whose variables -o goldflags sets when
+// Options.GoPackage is empty
+const DefaultGoPackage = "main"
+
+// commitDateLayout is the layout of git's %ci commit dates
+const commitDateLayout = "2006-01-02 15:04:05 -0700"
+
+// formatGoLdflags renders a -ldflags argument for go build that sets the
+// version, commit and date string variables of pkg, e.g.
+// -ldflags "-X main.version=1.2.3 -X main.commit=abc1234 -X main.date=...".
+// The date is the commit date in UTC RFC 3339, so builds of the same
+// commit are reproducible.
+func formatGoLdflags(pkg string, output *JSONOutput) string {
+	if pkg == "" {
+		pkg = DefaultGoPackage
+	}
+	date := commitDateRFC3339(output.CommitDate)
+
+	flags := make([]string, 0, 3)
+	for _, v := range []struct{ name, value string }{
+		{"version", output.SemVer},
+		{"commit", output.ShortSha},
+		{"date", date},
+	} {
+		flag := fmt.Sprintf("%s.%s=%s", pkg, v.name, v.value)
+		if strings.ContainsAny(flag, " \t'\"") {
+			// go build splits -ldflags on spaces outside single quotes
+			flag = "'" + strings.ReplaceAll(flag, "'", "") + "'"
+		}
+		flags = append(flags, "-X "+flag)
+	}
+	return fmt.Sprintf("-ldflags %q", strings.Join(flags, " "))
+}
+
+// commitDateRFC3339 converts a %ci commit date to UTC RFC 3339, keeping
+// dates it cannot parse as they are
+func commitDateRFC3339(date string) string {
+	if t, err := time.Parse(commitDateLayout, date); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return date
+}
@@ -0,0 +1,30 @@
+package gitversion
+
+import "testing"
+
+func TestFormatGoLdflags(t *testing.T) {
+	output := &JSONOutput{
+		SemVer:     "1.2.3-alpha.4",
+		ShortSha:   "abc1234",
+		CommitDate: "2024-01-02 05:04:05 +0200",
+	}
+
+	tests := []struct {
+		pkg      string
+		expected string
+	}{
+		{"", `-ldflags "-X main.version=1.2.3-alpha.4 -X main.commit=abc1234 -X main.date=2024-01-02T03:04:05Z"`},
+		{"example.com/app/internal/buildinfo", `-ldflags "-X example.com/app/internal/buildinfo.version=1.2.3-alpha.4 -X example.com/app/internal/buildinfo.commit=abc1234 -X example.com/app/internal/buildinfo.date=2024-01-02T03:04:05Z"`},
+	}
+	for _, tt := range tests {
+		if got := formatGoLdflags(tt.pkg, output); got != tt.expected {
+			t.Errorf("formatGoLdflags(%q) = %s, want %s", tt.pkg, got, tt.expected)
+		}
+	}
+
+	output.CommitDate = "Jan 2 2024"
+	expected := `-ldflags "-X main.version=1.2.3-alpha.4 -X main.commit=abc1234 -X 'main.date=Jan 2 2024'"`
+	if got := formatGoLdflags("main", output); got != expected {
+		t.Errorf("formatGoLdflags() = %s, want %s", got, expected)
+	}
+}
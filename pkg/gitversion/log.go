@@ -0,0 +1,75 @@
+package gitversion
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// newLogger returns the logger of a GitVersion for opts: opts.Logger when
+// set, otherwise debug records as text on w when opts.Debug is set and
+// nothing at all when it is not
+func newLogger(opts *Options, w io.Writer) *slog.Logger {
+	switch {
+	case opts.Logger != nil:
+		return opts.Logger
+	case opts.Debug:
+		return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	default:
+		return slog.New(discardHandler{})
+	}
+}
+
+// logDebug logs a debug record with alternating keys and values. A zero
+// GitVersion logs nothing.
+func (gv *GitVersion) logDebug(msg string, args ...any) {
+	if gv.logger != nil {
+		gv.logger.Debug(msg, args...)
+	}
+}
+
+// logInfo logs an info record with alternating keys and values. A zero
+// GitVersion logs nothing.
+func (gv *GitVersion) logInfo(msg string, args ...any) {
+	if gv.logger != nil {
+		gv.logger.Info(msg, args...)
+	}
+}
+
+// logWarn logs a warning with alternating keys and values. A zero
+// GitVersion logs nothing.
+func (gv *GitVersion) logWarn(msg string, args ...any) {
+	if gv.logger != nil {
+		gv.logger.Warn(msg, args...)
+	}
+}
+
+// logDiagnostics logs how the version of diagnostics was reached, a record
+// for every candidate and for the selection, at info level
+func (gv *GitVersion) logDiagnostics(d *Diagnostics) {
+	if gv.logger == nil || !gv.logger.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+	for _, bv := range d.Candidates {
+		gv.logger.Info("base version candidate",
+			"strategy", bv.Strategy, "version", bv.SemanticVersion.String(), "source", bv.Source,
+			"increment", bv.ShouldIncrement)
+	}
+	if d.Partial {
+		gv.logger.Info("time budget exceeded", "skipped", d.SkippedStrategies)
+	}
+	if d.Selected != nil {
+		gv.logger.Info("selected base version",
+			"version", d.Selected.SemanticVersion.String(), "source", d.Selected.Source, "reason", d.SelectionReason)
+	}
+	gv.logger.Info("incremented version",
+		"increment", d.Increment, "cause", d.IncrementCause, "commits", d.CommitCount, "version", d.Version.String())
+}
+
+// discardHandler drops every record without formatting it
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
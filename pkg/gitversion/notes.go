@@ -0,0 +1,102 @@
+package gitversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// NotesRef is the notes ref calculation records are stored under. Git does
+// not push or fetch notes by default; push it explicitly to share the trail.
+const NotesRef = "refs/notes/gitversion"
+
+// Note records a calculated version on the commit it was calculated for,
+// with the diagnostics explaining how it was derived.
+type Note struct {
+	Commit      string       `json:"Commit"`
+	Version     string       `json:"Version"`
+	Tag         string       `json:"Tag"`
+	Branch      string       `json:"Branch"`
+	RecordedAt  time.Time    `json:"RecordedAt"`
+	Diagnostics *Diagnostics `json:"Diagnostics"`
+}
+
+// RecordNote stores the calculation as a note on HEAD under NotesRef,
+// replacing an earlier record for the same commit.
+func (gv *GitVersion) RecordNote(diagnostics *Diagnostics) (*Note, error) {
+	commit, err := gv.headCommit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current commit: %w", err)
+	}
+
+	note := &Note{
+		Commit:      commit,
+		Version:     gv.Variables(diagnostics).FullSemVer,
+		Tag:         gv.TagName(diagnostics.Version),
+		Branch:      diagnostics.Branch,
+		RecordedAt:  time.Now().UTC().Truncate(time.Second),
+		Diagnostics: diagnostics,
+	}
+
+	data, err := json.MarshalIndent(note, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal note: %w", err)
+	}
+	if err := gv.repo.AddNote(NotesRef, commit, string(data)); err != nil {
+		return nil, err
+	}
+	gv.logDebug("recorded note", "version", note.Version, "commit", commit, "ref", NotesRef)
+
+	return note, nil
+}
+
+// ReadNote returns the calculation recorded on the commit rev names, or nil
+// when none was recorded.
+func (gv *GitVersion) ReadNote(rev string) (*Note, error) {
+	commit, err := gv.repo.ResolveCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+	return gv.readNote(commit)
+}
+
+// Notes returns every recorded calculation, oldest first
+func (gv *GitVersion) Notes() ([]*Note, error) {
+	commits, err := gv.repo.GetNotedCommits(NotesRef)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]*Note, 0, len(commits))
+	for _, commit := range commits {
+		note, err := gv.readNote(commit)
+		if err != nil {
+			return nil, err
+		}
+		if note != nil {
+			notes = append(notes, note)
+		}
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		if !notes[i].RecordedAt.Equal(notes[j].RecordedAt) {
+			return notes[i].RecordedAt.Before(notes[j].RecordedAt)
+		}
+		return notes[i].Commit < notes[j].Commit
+	})
+	return notes, nil
+}
+
+func (gv *GitVersion) readNote(commit string) (*Note, error) {
+	data, ok, err := gv.repo.GetNote(NotesRef, commit)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	note := &Note{}
+	if err := json.Unmarshal([]byte(data), note); err != nil {
+		return nil, fmt.Errorf("note on %s is not a gitversion record: %w", commit, err)
+	}
+	return note, nil
+}
@@ -3,6 +3,7 @@ package gitversion
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
@@ -14,32 +15,79 @@ const (
 	JSON               OutputFormat = "json"
 	AssemblySemVer     OutputFormat = "AssemblySemVer"
 	AssemblySemFileVer OutputFormat = "AssemblySemFileVer"
+	// BuildServer publishes the variables to the detected CI system
+	BuildServer OutputFormat = "buildserver"
+	// GitHubActions writes the variables to $GITHUB_OUTPUT and $GITHUB_ENV
+	GitHubActions OutputFormat = "githubactions"
+	// Describe renders the version like git describe: v1.2.3-14-gabc1234
+	Describe OutputFormat = "describe"
+	// Env renders raw GITVERSION_<NAME>=value lines for docker --env-file
+	// and .env files
+	Env OutputFormat = "env"
+	// Shell renders export GITVERSION_<NAME>=value lines, quoted for eval
+	Shell OutputFormat = "shell"
+	// GoLdflags renders -ldflags "-X main.version=..." for go build
+	GoLdflags OutputFormat = "goldflags"
+	// Docker renders the version as an OCI-safe image tag, or with
+	// Options.DockerLabels as docker build --label arguments
+	Docker OutputFormat = "docker"
 )
 
 type JSONOutput struct {
-	Major                     int    `json:"Major"`
-	Minor                     int    `json:"Minor"`
-	Patch                     int    `json:"Patch"`
-	PreReleaseTag             string `json:"PreReleaseTag"`
-	PreReleaseTagWithDash     string `json:"PreReleaseTagWithDash"`
-	BuildMetaData             string `json:"BuildMetaData"`
-	BuildMetaDataPadded       string `json:"BuildMetaDataPadded"`
-	FullBuildMetaData         string `json:"FullBuildMetaData"`
-	MajorMinorPatch           string `json:"MajorMinorPatch"`
-	SemVer                    string `json:"SemVer"`
-	AssemblySemVer            string `json:"AssemblySemVer"`
-	AssemblySemFileVer        string `json:"AssemblySemFileVer"`
-	FullSemVer                string `json:"FullSemVer"`
-	InformationalVersion      string `json:"InformationalVersion"`
-	BranchName                string `json:"BranchName"`
-	EscapedBranchName         string `json:"EscapedBranchName"`
-	Sha                       string `json:"Sha"`
-	ShortSha                  string `json:"ShortSha"`
-	NuGetVersionV2            string `json:"NuGetVersionV2"`
-	NuGetVersion              string `json:"NuGetVersion"`
-	VersionSourceSha          string `json:"VersionSourceSha"`
-	CommitsSinceVersionSource int    `json:"CommitsSinceVersionSource"`
-	CommitDate                string `json:"CommitDate"`
+	Major                   int    `json:"Major"`
+	Minor                   int    `json:"Minor"`
+	Patch                   int    `json:"Patch"`
+	PreReleaseTag           string `json:"PreReleaseTag"`
+	PreReleaseTagWithDash   string `json:"PreReleaseTagWithDash"`
+	PreReleaseLabel         string `json:"PreReleaseLabel"`
+	PreReleaseLabelWithDash string `json:"PreReleaseLabelWithDash"`
+	// PreReleaseNumber is null without a numbered prerelease, like GitVersion
+	PreReleaseNumber         *int   `json:"PreReleaseNumber"`
+	WeightedPreReleaseNumber int    `json:"WeightedPreReleaseNumber"`
+	BuildMetaData            string `json:"BuildMetaData"`
+	BuildMetaDataPadded      string `json:"BuildMetaDataPadded"`
+	FullBuildMetaData        string `json:"FullBuildMetaData"`
+	MajorMinorPatch          string `json:"MajorMinorPatch"`
+	SemVer                   string `json:"SemVer"`
+	AssemblySemVer           string `json:"AssemblySemVer"`
+	AssemblySemFileVer       string `json:"AssemblySemFileVer"`
+	FullSemVer               string `json:"FullSemVer"`
+	InformationalVersion     string `json:"InformationalVersion"`
+	BranchName               string `json:"BranchName"`
+	EscapedBranchName        string `json:"EscapedBranchName"`
+	DockerTagBranchName      string `json:"DockerTagBranchName"`
+	Sha                      string `json:"Sha"`
+	ShortSha                 string `json:"ShortSha"`
+	NuGetVersionV2           string `json:"NuGetVersionV2"`
+	NuGetVersion             string `json:"NuGetVersion"`
+	VersionSourceSha         string `json:"VersionSourceSha"`
+	// VersionSourceTagDate is when the annotated version tag the base
+	// version came from was created, formatted like CommitDate; empty for
+	// lightweight tags and other sources
+	VersionSourceTagDate            string `json:"VersionSourceTagDate"`
+	CommitsSinceVersionSource       int    `json:"CommitsSinceVersionSource"`
+	CommitsSinceVersionSourcePadded string `json:"CommitsSinceVersionSourcePadded"`
+	UncommittedChanges              int    `json:"UncommittedChanges"`
+	CommitDate                      string `json:"CommitDate"`
+	Describe                        string `json:"Describe"`
+	// MsiVersion is the Windows Installer product version, empty when the
+	// version exceeds 255.255.65535
+	MsiVersion string `json:"MsiVersion"`
+
+	BranchConfig *BranchConfigOutput `json:"BranchConfig,omitempty"`
+	// Partial is set when --max-duration cut the analysis short
+	Partial bool `json:"Partial,omitempty"`
+}
+
+// BranchConfigOutput summarizes the branch configuration a version was
+// calculated with
+type BranchConfigOutput struct {
+	// Key is the matched configuration key, "" for the built-in fallback
+	Key              string `json:"Key"`
+	Label            string `json:"Label"`
+	Increment        string `json:"Increment"`
+	Mode             string `json:"Mode"`
+	PreReleaseWeight int    `json:"PreReleaseWeight"`
 }
 
 type Formatter struct {
@@ -60,21 +108,29 @@ func (f *Formatter) Format(version *semver.Version, format OutputFormat, branch
 		return version.AssemblySemFileVer(), nil
 	case JSON:
 		return f.formatJSON(version, branch)
+	case Describe:
+		return f.Variables(version, branch).Describe, nil
 	default:
 		return "", fmt.Errorf("unknown output format: %s", format)
 	}
 }
 
-func (f *Formatter) formatJSON(version *semver.Version, branch string) (string, error) {
+// Variables computes the full set of version variables for version on branch
+func (f *Formatter) Variables(version *semver.Version, branch string) *JSONOutput {
 	sha, _ := f.repo.GetSHA()
 	shortSha, _ := f.repo.GetShortSHA()
 	commitDate, _ := f.repo.GetCommitDate()
-	latestTag, _ := f.repo.GetLatestTag()
+	latestTag, _ := latestVersionTag(f.repo)
 	commitCount, _ := f.repo.GetCommitCountSinceTag(latestTag)
 
-	preReleaseWithDash := ""
+	preReleaseWithDash, labelWithDash := "", ""
 	if version.PreRelease != "" {
 		preReleaseWithDash = "-" + version.PreRelease
+		labelWithDash = "-" + version.PreReleaseLabel()
+	}
+	var preReleaseNumber *int
+	if number, ok := version.PreReleaseNumber(); ok {
+		preReleaseNumber = &number
 	}
 
 	buildMetaDataPadded := ""
@@ -82,32 +138,79 @@ func (f *Formatter) formatJSON(version *semver.Version, branch string) (string,
 		buildMetaDataPadded = "+" + version.Build
 	}
 
-	output := JSONOutput{
-		Major:                     version.Major,
-		Minor:                     version.Minor,
-		Patch:                     version.Patch,
-		PreReleaseTag:             version.PreRelease,
-		PreReleaseTagWithDash:     preReleaseWithDash,
-		BuildMetaData:             version.Build,
-		BuildMetaDataPadded:       buildMetaDataPadded,
-		FullBuildMetaData:         version.Build,
-		MajorMinorPatch:           version.MajorMinorPatch(),
-		SemVer:                    version.String(),
-		AssemblySemVer:            version.AssemblySemVer(),
-		AssemblySemFileVer:        version.AssemblySemFileVer(),
-		FullSemVer:                version.String(),
-		InformationalVersion:      version.String(),
-		BranchName:                branch,
-		EscapedBranchName:         semver.SanitizeBranchName(branch),
-		Sha:                       sha,
-		ShortSha:                  shortSha,
-		NuGetVersionV2:            version.String(),
-		NuGetVersion:              version.String(),
-		VersionSourceSha:          sha,
-		CommitsSinceVersionSource: commitCount,
-		CommitDate:                commitDate,
+	output := &JSONOutput{
+		Major:                           version.Major,
+		Minor:                           version.Minor,
+		Patch:                           version.Patch,
+		PreReleaseTag:                   version.PreRelease,
+		PreReleaseTagWithDash:           preReleaseWithDash,
+		PreReleaseLabel:                 version.PreReleaseLabel(),
+		PreReleaseLabelWithDash:         labelWithDash,
+		PreReleaseNumber:                preReleaseNumber,
+		BuildMetaData:                   version.Build,
+		BuildMetaDataPadded:             buildMetaDataPadded,
+		FullBuildMetaData:               version.Build,
+		MajorMinorPatch:                 version.MajorMinorPatch(),
+		SemVer:                          version.String(),
+		AssemblySemVer:                  version.AssemblySemVer(),
+		AssemblySemFileVer:              version.AssemblySemFileVer(),
+		FullSemVer:                      version.String(),
+		BranchName:                      branch,
+		EscapedBranchName:               semver.EscapeBranchName(branch),
+		DockerTagBranchName:             semver.DockerTagBranchName(branch),
+		Sha:                             sha,
+		ShortSha:                        shortSha,
+		NuGetVersionV2:                  version.NuGetVersion(semver.NuGetPadding),
+		NuGetVersion:                    version.NuGetVersion(semver.NuGetPadding),
+		VersionSourceSha:                sha,
+		CommitsSinceVersionSource:       commitCount,
+		CommitsSinceVersionSourcePadded: fmt.Sprintf("%04d", commitCount),
+		CommitDate:                      commitDate,
+		Describe:                        describe(latestTag, commitCount, shortSha),
+		MsiVersion:                      msiVersion(version, commitCount),
+	}
+	output.InformationalVersion = informationalVersion(output)
+	return output
+}
+
+// informationalVersion is the default InformationalVersion: the semantic
+// version without build metadata, then one "+" and the build metadata,
+// branch and commit as dot-separated identifiers, so it stays a valid
+// semantic version
+func informationalVersion(output *JSONOutput) string {
+	parts := strings.FieldsFunc(output.BuildMetaData, func(r rune) bool { return r == '+' || r == '.' })
+	if output.EscapedBranchName != "" {
+		parts = append(parts, "Branch", output.EscapedBranchName)
+	}
+	if output.Sha != "" {
+		parts = append(parts, "Sha", output.Sha)
+	}
+	version := output.MajorMinorPatch + output.PreReleaseTagWithDash
+	if len(parts) == 0 {
+		return version
+	}
+	return version + "+" + strings.Join(parts, ".")
+}
+
+// describe mirrors git describe --tags --always: the tag alone on a tagged
+// commit, the tag followed by the distance and abbreviated SHA otherwise,
+// and only the SHA when there is no version tag.
+func describe(tag string, distance int, shortSha string) string {
+	switch {
+	case tag == "":
+		return shortSha
+	case distance == 0:
+		return tag
+	default:
+		return fmt.Sprintf("%s-%d-g%s", tag, distance, shortSha)
 	}
+}
+
+func (f *Formatter) formatJSON(version *semver.Version, branch string) (string, error) {
+	return marshalVariables(f.Variables(version, branch))
+}
 
+func marshalVariables(output *JSONOutput) (string, error) {
 	data, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal JSON: %w", err)
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
 	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
 )
 
@@ -22,10 +24,15 @@ func (m *mockRepo) GetCommitDate() (string, error) {
 	return "2025-01-15 10:30:45 +0000", nil
 }
 
-func (m *mockRepo) GetLatestTag() (string, error) {
+func (m *mockRepo) GetLatestVersionTag() (string, error) {
 	return "v1.0.0", nil
 }
 
+// GetLatestTag is not used, since mockRepo has GetLatestVersionTag
+func (m *mockRepo) GetLatestTag() (string, error) {
+	return "nightly", nil
+}
+
 func (m *mockRepo) GetCommitCountSinceTag(tag string) (int, error) {
 	return 5, nil
 }
@@ -60,6 +67,11 @@ func TestFormat(t *testing.T) {
 			format:   AssemblySemFileVer,
 			expected: "1.2.3.0",
 		},
+		{
+			name:     "Describe format",
+			format:   Describe,
+			expected: "v1.0.0-5-gabc1234",
+		},
 	}
 
 	for _, tt := range tests {
@@ -156,6 +168,29 @@ func TestFormatJSON(t *testing.T) {
 	if output.CommitDate != "2025-01-15 10:30:45 +0000" {
 		t.Errorf("CommitDate = %s, want 2025-01-15 10:30:45 +0000", output.CommitDate)
 	}
+	if output.PreReleaseLabel != "alpha" || output.PreReleaseLabelWithDash != "-alpha" {
+		t.Errorf("PreReleaseLabel = %s, PreReleaseLabelWithDash = %s, want alpha, -alpha", output.PreReleaseLabel, output.PreReleaseLabelWithDash)
+	}
+	if output.PreReleaseNumber == nil || *output.PreReleaseNumber != 5 {
+		t.Errorf("PreReleaseNumber = %v, want 5", output.PreReleaseNumber)
+	}
+	if output.NuGetVersionV2 != "1.2.3-alpha0005" || output.NuGetVersion != "1.2.3-alpha0005" {
+		t.Errorf("NuGetVersionV2 = %s, NuGetVersion = %s, want 1.2.3-alpha0005", output.NuGetVersionV2, output.NuGetVersion)
+	}
+	if output.InformationalVersion != "1.2.3-alpha.5+10.abc1234.Branch.develop.Sha.abc1234567890def" {
+		t.Errorf("InformationalVersion = %s, want 1.2.3-alpha.5+10.abc1234.Branch.develop.Sha.abc1234567890def", output.InformationalVersion)
+	}
+	// One "+" starts the build metadata, so it parses back as the version
+	informational, err := semver.Parse(output.InformationalVersion)
+	if err != nil {
+		t.Fatalf("semver.Parse(InformationalVersion) error = %v", err)
+	}
+	if informational.MajorMinorPatch() != "1.2.3" || informational.PreRelease != "alpha.5" || strings.Contains(informational.Build, "+") {
+		t.Errorf("InformationalVersion parses as %+v, want 1.2.3-alpha.5 with build metadata free of +", informational)
+	}
+	if output.CommitsSinceVersionSourcePadded != "0005" {
+		t.Errorf("CommitsSinceVersionSourcePadded = %s, want 0005", output.CommitsSinceVersionSourcePadded)
+	}
 }
 
 func TestFormatJSONWithoutPrerelease(t *testing.T) {
@@ -183,6 +218,43 @@ func TestFormatJSONWithoutPrerelease(t *testing.T) {
 	if output.PreReleaseTagWithDash != "" {
 		t.Errorf("PreReleaseTagWithDash = %s, want empty string", output.PreReleaseTagWithDash)
 	}
+	if output.PreReleaseLabel != "" || output.PreReleaseLabelWithDash != "" {
+		t.Errorf("PreReleaseLabel = %s, PreReleaseLabelWithDash = %s, want empty strings", output.PreReleaseLabel, output.PreReleaseLabelWithDash)
+	}
+	if !strings.Contains(result, `"PreReleaseNumber": null`) {
+		t.Errorf("PreReleaseNumber should be null:\n%s", result)
+	}
+}
+
+func TestVariablesWeightedPreReleaseNumber(t *testing.T) {
+	gv := &GitVersion{repo: git.NewRepository(), config: &config.Config{TagPreReleaseWeight: 60000}, formatter: NewFormatter(&mockRepo{})}
+
+	tests := []struct {
+		name     string
+		version  *semver.Version
+		expected int
+	}{
+		{"prerelease", &semver.Version{Major: 1, PreRelease: "beta.3"}, 30003},
+		{"label only", &semver.Version{Major: 1, PreRelease: "beta"}, 30000},
+		{"stable", &semver.Version{Major: 1}, 60000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variables := gv.Variables(&Diagnostics{
+				Branch:       "release/1.0",
+				BranchConfig: &config.BranchConfiguration{PreReleaseWeight: 30000},
+				Version:      tt.version,
+				Selected:     &BaseVersion{BaseVersionSource: "fallback"},
+			})
+			if variables.WeightedPreReleaseNumber != tt.expected {
+				t.Errorf("WeightedPreReleaseNumber = %d, want %d", variables.WeightedPreReleaseNumber, tt.expected)
+			}
+			if variables.VersionSourceSha != "" {
+				t.Errorf("VersionSourceSha = %s, want empty for a source without a commit", variables.VersionSourceSha)
+			}
+		})
+	}
 }
 
 func TestFormatInvalidFormat(t *testing.T) {
@@ -197,3 +269,81 @@ func TestFormatInvalidFormat(t *testing.T) {
 		t.Errorf("Error should mention unknown output format, got: %v", err)
 	}
 }
+
+func TestFormatJSONIncludesBranchConfig(t *testing.T) {
+	gv := &GitVersion{repo: git.NewRepository(), config: &config.Config{}, formatter: NewFormatter(&mockRepo{})}
+	diagnostics := &Diagnostics{
+		Branch:          "feature/login",
+		BranchConfigKey: "feature",
+		BranchConfig: &config.BranchConfiguration{
+			Label:            "{BranchName}",
+			Increment:        config.IncrementInherit,
+			Mode:             config.DeploymentManual,
+			PreReleaseWeight: 30000,
+		},
+		Version: &semver.Version{Major: 1, Minor: 2, Patch: 3},
+	}
+
+	result, err := gv.Format(diagnostics, JSON)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var output JSONOutput
+	if err := json.Unmarshal([]byte(result), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	expected := BranchConfigOutput{
+		Key:              "feature",
+		Label:            "{BranchName}",
+		Increment:        "Inherit",
+		Mode:             "ManualDeployment",
+		PreReleaseWeight: 30000,
+	}
+	if output.BranchConfig == nil || *output.BranchConfig != expected {
+		t.Errorf("BranchConfig = %+v, want %+v", output.BranchConfig, expected)
+	}
+}
+
+// legacyRepo implements Repository as it was before GetLatestVersionTag
+type legacyRepo struct{}
+
+func (legacyRepo) GetSHA() (string, error)                    { return "abc1234567890def", nil }
+func (legacyRepo) GetShortSHA() (string, error)               { return "abc1234", nil }
+func (legacyRepo) GetCommitDate() (string, error)             { return "2025-01-15 10:30:45 +0000", nil }
+func (legacyRepo) GetLatestTag() (string, error)              { return "v0.9.0", nil }
+func (legacyRepo) GetCommitCountSinceTag(string) (int, error) { return 5, nil }
+
+func TestFormatterWithoutGetLatestVersionTag(t *testing.T) {
+	var repo Repository = legacyRepo{}
+	if _, ok := repo.(VersionTagRepository); ok {
+		t.Fatal("legacyRepo should not implement VersionTagRepository")
+	}
+	describe, err := NewFormatter(repo).Format(&semver.Version{Major: 1}, Describe, "main")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if describe != "v0.9.0-5-gabc1234" {
+		t.Errorf("Format(Describe) = %s, want the tag from GetLatestTag", describe)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		tag      string
+		distance int
+		expected string
+	}{
+		{"v1.2.3", 14, "v1.2.3-14-gabc1234"},
+		{"v1.2.3", 0, "v1.2.3"},
+		{"tools/v0.3.0", 2, "tools/v0.3.0-2-gabc1234"},
+		{"", 7, "abc1234"},
+	}
+
+	for _, tt := range tests {
+		if got := describe(tt.tag, tt.distance, "abc1234"); got != tt.expected {
+			t.Errorf("describe(%q, %d) = %s, want %s", tt.tag, tt.distance, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package gitversion
+
+import (
+	"fmt"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+)
+
+// NewProjects creates a GitVersion for every project of the repository,
+// keyed by its directory relative to the repository root; see
+// config.Projects for how projects are found. Each is created as New does
+// with opts.Project set to its directory. The projects share one cache of
+// git output, so the history they have in common is read only once.
+func NewProjects(opts *Options) (map[string]*GitVersion, error) {
+	repo := git.NewCachedRepository(opts.Dir)
+	if !repo.IsRepository() {
+		return nil, ErrNotARepository
+	}
+	root, err := repo.GetRootDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := config.Projects(root, append([]string{opts.ConfigFile}, opts.ConfigFiles...))
+	if err != nil {
+		return nil, configError("", fmt.Errorf("failed to load config: %w", err))
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no projects found in %s (list them under projects in the configuration or give each one a configuration file)", root)
+	}
+
+	projects := make(map[string]*GitVersion, len(dirs))
+	for _, dir := range dirs {
+		projectOpts := *opts
+		projectOpts.Project = dir
+		gv, err := newGitVersion(&projectOpts, repo)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", dir, err)
+		}
+		projects[dir] = gv
+	}
+	return projects, nil
+}
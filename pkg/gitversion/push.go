@@ -0,0 +1,98 @@
+package gitversion
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+// Environment variables holding the credentials of remote operations: tag
+// pushes, fetches before a calculation and normalization. Without them
+// git's credential helpers are used.
+const (
+	// RemoteTokenEnv holds a token, which takes precedence over the
+	// password
+	RemoteTokenEnv = git.RemoteTokenEnv
+	// RemoteUsernameEnv holds the user name sent with the token or
+	// password, x-access-token when unset
+	RemoteUsernameEnv = git.RemoteUsernameEnv
+	// RemotePasswordEnv holds a password
+	RemotePasswordEnv = git.RemotePasswordEnv
+)
+
+// Environment variables configuring the ssh command of remote operations
+// over SSH. Without them ssh's own configuration and ssh-agent apply.
+const (
+	// SSHIdentityFileEnv holds the private key to authenticate with
+	SSHIdentityFileEnv = git.SSHIdentityFileEnv
+	// SSHStrictHostKeyCheckingEnv holds the host key checking mode: yes,
+	// accept-new or no
+	SSHStrictHostKeyCheckingEnv = git.SSHStrictHostKeyCheckingEnv
+	// SSHKnownHostsFileEnv holds the known_hosts file to check host keys
+	// against
+	SSHKnownHostsFileEnv = git.SSHKnownHostsFileEnv
+)
+
+// PushOperation is the retry operation for pushes
+const PushOperation = "push"
+
+// transientRemoteErrors are fragments of git errors caused by the network
+// or an overloaded server. Anything else, such as rejected refs or failed
+// authentication, would fail the same way again.
+var transientRemoteErrors = []string{
+	"could not resolve host",
+	"connection timed out",
+	"connection reset",
+	"connection refused",
+	"operation timed out",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"the requested url returned error: 429",
+	"the requested url returned error: 5",
+}
+
+// Remote returns the primary remote: Options.Remote, else remote-name from
+// the configuration, else origin
+func (gv *GitVersion) Remote() string {
+	return gv.remote
+}
+
+// PushTag pushes tag to remote, the primary remote when empty, retrying
+// network failures under the "push" retry policy. Credentials come from
+// the Remote*Env variables when set.
+func (gv *GitVersion) PushTag(remote, tag string) error {
+	return gv.PushTagContext(context.Background(), remote, tag)
+}
+
+// PushTagContext is like PushTag but gives up once ctx is done, killing
+// the push in flight
+func (gv *GitVersion) PushTagContext(ctx context.Context, remote, tag string) error {
+	if remote == "" {
+		remote = gv.remote
+	}
+	auth, err := git.RemoteAuthFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	repo := gv.repo.WithContext(ctx)
+	return retry.DoWithPolicy(ctx, gv.retries.For(PushOperation), func(ctx context.Context) error {
+		err := repo.PushTag(remote, tag, auth)
+		if err != nil && !isTransientRemoteError(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
+
+func isTransientRemoteError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, fragment := range transientRemoteErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package gitversion
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsTransientRemoteError(t *testing.T) {
+	tests := []struct {
+		message   string
+		transient bool
+	}{
+		{"fatal: unable to access 'https://example.com/repo.git/': Could not resolve host: example.com", true},
+		{"fatal: unable to access 'https://example.com/repo.git/': The requested URL returned error: 503", true},
+		{"fatal: the remote end hung up unexpectedly", true},
+		{"remote: Invalid username or password.\nfatal: Authentication failed for 'https://example.com/repo.git/'", false},
+		{"! [rejected] v1.0.0 -> v1.0.0 (already exists)", false},
+		{"fatal: The requested URL returned error: 403", false},
+	}
+
+	for _, tt := range tests {
+		if got := isTransientRemoteError(errors.New(tt.message)); got != tt.transient {
+			t.Errorf("isTransientRemoteError(%q) = %t, want %t", tt.message, got, tt.transient)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gitversion
+
+import (
+	"github.com/VirtuallyScott/gitversion-go/pkg/releasenotes"
+)
+
+// ReleaseNotes collects the commits since the previous version tag for the
+// calculated version. Stable versions are compared with the previous stable
+// release, so the notes for 1.2.0 cover all of its release candidates.
+func (gv *GitVersion) ReleaseNotes(diagnostics *Diagnostics) (*releasenotes.Notes, error) {
+	previous := gv.previousTag(diagnostics)
+	commits, err := gv.repo.GetCommitsWithBodySinceTag(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]releasenotes.Commit, 0, len(commits))
+	for _, commit := range commits {
+		entries = append(entries, releasenotes.Commit{SHA: commit.SHA, Subject: commit.Message, Body: commit.Body})
+	}
+
+	date, _ := gv.repo.GetCommitDate()
+	if len(date) > len("2006-01-02") {
+		date = date[:len("2006-01-02")]
+	}
+
+	version := diagnostics.Version.Copy()
+	version.Build = ""
+	return releasenotes.New(version.String(), gv.TagName(version), previous, date,
+		gv.config.ReleaseNotes.RepositoryURL, entries), nil
+}
+
+// previousTag returns the highest version tag reachable from HEAD that is
+// not on HEAD itself, skipping prereleases when the version is stable
+func (gv *GitVersion) previousTag(diagnostics *Diagnostics) string {
+	tags, _ := gv.repo.GetTagsOnCurrentBranch()
+	head, _ := gv.repo.GetSHA()
+
+	// Tags are sorted by version, highest last
+	for i := len(tags) - 1; i >= 0; i-- {
+		version, err := gv.repo.ParseTag(tags[i])
+		if err != nil {
+			continue
+		}
+		if version.PreRelease != "" && diagnostics.Version.PreRelease == "" {
+			continue
+		}
+		if sha, err := gv.repo.GetCommitSHAForTag(tags[i]); err == nil && sha == head {
+			continue
+		}
+		return tags[i]
+	}
+	return ""
+}
@@ -4,6 +4,28 @@ type Repository interface {
 	GetSHA() (string, error)
 	GetShortSHA() (string, error)
 	GetCommitDate() (string, error)
+	// GetLatestTag returns the latest tag reachable from HEAD.
+	//
+	// Deprecated: it is only called on repositories that do not implement
+	// VersionTagRepository, whose GetLatestVersionTag skips tags that are
+	// not versions.
 	GetLatestTag() (string, error)
 	GetCommitCountSinceTag(tag string) (int, error)
 }
+
+// VersionTagRepository is a Repository that finds the latest version tag,
+// skipping tags that do not parse as versions. The Formatter prefers it
+// over GetLatestTag.
+type VersionTagRepository interface {
+	Repository
+	GetLatestVersionTag() (string, error)
+}
+
+// latestVersionTag returns the latest version tag of repo, falling back to
+// GetLatestTag for repositories written before GetLatestVersionTag
+func latestVersionTag(repo Repository) (string, error) {
+	if versionTags, ok := repo.(VersionTagRepository); ok {
+		return versionTags.GetLatestVersionTag()
+	}
+	return repo.GetLatestTag()
+}
@@ -0,0 +1,90 @@
+package gitversion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+// retryPolicies returns the retry policies of the configuration.
+// Operations inherit unset fields from the "default" entry.
+func retryPolicies(retries map[string]*config.RetryConfig) (retry.Policies, error) {
+	policies := retry.Policies{}
+	base := retry.DefaultPolicy
+	if rc, ok := retries[retry.Default]; ok && rc != nil {
+		policy, err := retryPolicy(base, rc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry configuration for %s: %w", retry.Default, err)
+		}
+		policies[retry.Default] = policy
+		base = policy
+	}
+
+	operations := make([]string, 0, len(retries))
+	for operation := range retries {
+		if operation != retry.Default {
+			operations = append(operations, operation)
+		}
+	}
+	sort.Strings(operations)
+
+	for _, operation := range operations {
+		rc := retries[operation]
+		if rc == nil {
+			continue
+		}
+		policy, err := retryPolicy(base, rc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry configuration for %s: %w", operation, err)
+		}
+		policies[operation] = policy
+	}
+	return policies, nil
+}
+
+// RetryContext returns a copy of ctx carrying the retry policies of the
+// configuration, for remote operations run outside gv such as publishers
+func (gv *GitVersion) RetryContext(ctx context.Context) context.Context {
+	return retry.WithPolicies(ctx, gv.retries)
+}
+
+func retryPolicy(base retry.Policy, rc *config.RetryConfig) (retry.Policy, error) {
+	policy := base
+	if rc.MaxAttempts < 0 {
+		return policy, fmt.Errorf("max-attempts must not be negative")
+	}
+	if rc.MaxAttempts > 0 {
+		policy.MaxAttempts = rc.MaxAttempts
+	}
+	if rc.InitialDelay != "" {
+		d, err := time.ParseDuration(rc.InitialDelay)
+		if err != nil {
+			return policy, fmt.Errorf("initial-delay: %w", err)
+		}
+		policy.InitialDelay = d
+	}
+	if rc.MaxDelay != "" {
+		d, err := time.ParseDuration(rc.MaxDelay)
+		if err != nil {
+			return policy, fmt.Errorf("max-delay: %w", err)
+		}
+		policy.MaxDelay = d
+	}
+	if rc.Multiplier != 0 {
+		if rc.Multiplier < 1 {
+			return policy, fmt.Errorf("multiplier must be at least 1")
+		}
+		policy.Multiplier = rc.Multiplier
+	}
+	if rc.Jitter != nil {
+		if *rc.Jitter < 0 || *rc.Jitter > 1 {
+			return policy, fmt.Errorf("jitter must be between 0 and 1")
+		}
+		policy.Jitter = *rc.Jitter
+	}
+	return policy, nil
+}
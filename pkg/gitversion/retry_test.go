@@ -0,0 +1,53 @@
+package gitversion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	noJitter := 0.0
+	policies, err := retryPolicies(map[string]*config.RetryConfig{
+		"default": {MaxAttempts: 5, InitialDelay: "1s"},
+		"fetch":   {MaxDelay: "1m", Jitter: &noJitter},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fetch := policies.For("fetch")
+	if fetch.MaxAttempts != 5 || fetch.InitialDelay != time.Second {
+		t.Errorf("Expected fetch to inherit the default entry, got %+v", fetch)
+	}
+	if fetch.MaxDelay != time.Minute || fetch.Jitter != 0 {
+		t.Errorf("Expected fetch overrides, got %+v", fetch)
+	}
+	if webhook := policies.For("webhook"); webhook.MaxAttempts != 5 {
+		t.Errorf("Expected webhook to use the default entry, got %+v", webhook)
+	}
+
+	// Policies belong to one configuration and leave the others alone
+	other, err := retryPolicies(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if other.For("fetch") != retry.DefaultPolicy || retry.PolicyFor("fetch") != retry.DefaultPolicy {
+		t.Errorf("Expected DefaultPolicy for another configuration, got %+v", other.For("fetch"))
+	}
+}
+
+func TestRetryPoliciesInvalid(t *testing.T) {
+	tests := map[string]*config.RetryConfig{
+		"bad delay":      {InitialDelay: "soon"},
+		"bad multiplier": {Multiplier: 0.5},
+		"bad attempts":   {MaxAttempts: -1},
+	}
+	for name, rc := range tests {
+		if _, err := retryPolicies(map[string]*config.RetryConfig{"default": {MaxAttempts: 5}, "fetch": rc}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
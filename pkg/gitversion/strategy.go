@@ -0,0 +1,35 @@
+package gitversion
+
+import (
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+)
+
+// Strategy is implemented by base version sources. Custom strategies
+// receive the same context as the built-in ones and run concurrently with
+// them.
+type Strategy = version.VersionStrategy
+
+// BaseVersion is a candidate version produced by a Strategy
+type BaseVersion = version.BaseVersion
+
+// VersionContext provides the repository and configuration to a Strategy
+type VersionContext = version.VersionContext
+
+// Diagnostics describes how a version was calculated
+type Diagnostics = version.Diagnostics
+
+// RegisterStrategy adds a custom base version strategy that can be enabled
+// by name in the configuration's strategies list or with --strategies.
+func RegisterStrategy(name string, strategy Strategy) error {
+	return version.RegisterStrategy(name, strategy)
+}
+
+// UnregisterStrategy removes a custom strategy added with RegisterStrategy
+func UnregisterStrategy(name string) {
+	version.UnregisterStrategy(name)
+}
+
+// RegisteredStrategies returns the names of all custom strategies
+func RegisteredStrategies() []string {
+	return version.RegisteredStrategies()
+}
@@ -0,0 +1,160 @@
+package gitversion
+
+import (
+	"fmt"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// TagResult describes the outcome of ApplyTag
+type TagResult struct {
+	Tag     string
+	Created bool
+	// Skipped is set by ApplyTag when the branch does not allow auto-tag;
+	// Tag is empty then
+	Skipped bool
+}
+
+// TagName returns the tag name for a version: "v" followed by the semantic
+// version without build metadata.
+func TagName(version *semver.Version) string {
+	return PrefixedTagName("v", version)
+}
+
+// PrefixedTagName returns the tag name for a version with the given prefix
+// in place of "v", e.g. "release-1.2.3" for the prefix "release-".
+func PrefixedTagName(prefix string, version *semver.Version) string {
+	name := prefix + version.MajorMinorPatch()
+	if version.PreRelease != "" {
+		name += "-" + version.PreRelease
+	}
+	return name
+}
+
+// TagName returns the tag name for a version in this repository: the
+// prefix of tag-prefix, plus the module prefix when a Go module is selected.
+func (gv *GitVersion) TagName(version *semver.Version) string {
+	return gv.repo.TagPrefix() + PrefixedTagName(gv.tagPrefix(), version)
+}
+
+// tagPrefix returns the prefix new tags get, so they are read back as
+// version tags: the literal prefix of tag-prefix, "v" by default
+func (gv *GitVersion) tagPrefix() string {
+	return config.TagPrefixLiteral(gv.config.TagPrefix)
+}
+
+// ApplyTag tags HEAD with the calculated version when the branch's
+// auto-tag policy allows it, and otherwise returns a skipped result, so
+// --apply can run on every branch. An existing tag with the same name on
+// HEAD is left untouched; one on another commit is an error.
+func (gv *GitVersion) ApplyTag(diagnostics *Diagnostics) (*TagResult, error) {
+	branchConfig := diagnostics.BranchConfig
+	if branchConfig == nil {
+		branchConfig = gv.config.GetBranchConfiguration(diagnostics.Branch)
+	}
+	if branchConfig == nil || !branchConfig.AutoTag {
+		gv.logDebug("auto-tag is off, not tagging", "branch", diagnostics.Branch)
+		return &TagResult{Skipped: true}, nil
+	}
+
+	head, err := gv.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	tag := gv.TagName(diagnostics.Version)
+	if exists, err := gv.existingTag(tag, head); err != nil || exists {
+		if err != nil {
+			return nil, err
+		}
+		return &TagResult{Tag: tag}, nil
+	}
+
+	if err := gv.repo.CreateTag(tag, fmt.Sprintf("Release %s", tag)); err != nil {
+		return nil, err
+	}
+	gv.logDebug("created tag", "tag", tag)
+
+	return &TagResult{Tag: tag, Created: true}, nil
+}
+
+// TagOptions controls CreateTag
+type TagOptions struct {
+	// Prefix goes before the version; when empty it is taken from
+	// tag-prefix, "v" by default
+	Prefix string
+	// AllowPrerelease permits tagging prerelease versions
+	AllowPrerelease bool
+	// Sign signs the tag with the signing key git is configured with
+	Sign bool
+}
+
+// CreateTag creates an annotated tag for the calculated version on HEAD.
+// Unlike ApplyTag it ignores the branch's auto-tag policy, since it is run
+// on purpose, but refuses prerelease versions unless opts allows them.
+// Retagging a commit is a no-op; a tag of the same name on another commit
+// is an error.
+func (gv *GitVersion) CreateTag(diagnostics *Diagnostics, opts TagOptions) (*TagResult, error) {
+	version := diagnostics.Version
+	if version.PreRelease != "" && !opts.AllowPrerelease {
+		return nil, fmt.Errorf("refusing to tag prerelease version %s (use --allow-prerelease)", version)
+	}
+
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = gv.tagPrefix()
+	}
+	tag := gv.repo.TagPrefix() + PrefixedTagName(prefix, version)
+
+	head, err := gv.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	if exists, err := gv.existingTag(tag, head); err != nil || exists {
+		if err != nil {
+			return nil, err
+		}
+		return &TagResult{Tag: tag}, nil
+	}
+
+	create := gv.repo.CreateTag
+	if opts.Sign {
+		create = gv.repo.CreateSignedTag
+	}
+	if err := create(tag, fmt.Sprintf("Release %s", tag)); err != nil {
+		return nil, err
+	}
+	gv.logDebug("created tag", "tag", tag)
+
+	return &TagResult{Tag: tag, Created: true}, nil
+}
+
+// existingTag reports whether tag already exists on head. A tag of the same
+// name on another commit is an error.
+func (gv *GitVersion) existingTag(tag, head string) (bool, error) {
+	if !gv.repo.TagExists(tag) {
+		return false, nil
+	}
+	tagged, err := gv.repo.GetCommitSHAForTag(tag)
+	if err != nil {
+		return false, err
+	}
+	if tagged != head {
+		return false, fmt.Errorf("tag %s already exists on commit %.7s", tag, tagged)
+	}
+	gv.logDebug("tag already exists", "tag", tag)
+	return true, nil
+}
+
+// headCommit returns the commit HEAD points at, ErrNoCommits before the
+// first commit
+func (gv *GitVersion) headCommit() (string, error) {
+	sha, err := gv.repo.GetSHA()
+	if err != nil {
+		return "", err
+	}
+	if sha == "unknown" {
+		return "", ErrNoCommits
+	}
+	return sha, nil
+}
@@ -0,0 +1,75 @@
+package gitversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+func TestTagName(t *testing.T) {
+	tests := []struct {
+		version  *semver.Version
+		expected string
+	}{
+		{&semver.Version{Major: 1, Minor: 2, Patch: 3}, "v1.2.3"},
+		{&semver.Version{Major: 1, Minor: 2, Patch: 3, Build: "5+abc1234"}, "v1.2.3"},
+		{&semver.Version{Major: 1, Minor: 2, Patch: 3, PreRelease: "beta.1", Build: "1+abc"}, "v1.2.3-beta.1"},
+	}
+
+	for _, tt := range tests {
+		if got := TagName(tt.version); got != tt.expected {
+			t.Errorf("TagName(%s) = %s, want %s", tt.version, got, tt.expected)
+		}
+	}
+}
+
+func TestApplyTagRespectsBranchPolicy(t *testing.T) {
+	gv := &GitVersion{
+		config: &config.Config{
+			Branches: map[string]*config.BranchConfiguration{
+				"main":    {Regex: "^(master|main)$", AutoTag: true},
+				"feature": {Regex: `^features?[\/-](?<BranchName>.+)`},
+			},
+		},
+	}
+
+	diagnostics := &Diagnostics{
+		Branch:  "feature/login",
+		Version: &semver.Version{Major: 1, Minor: 0, Patch: 0},
+	}
+
+	// Skipped without touching the repository, which is nil here
+	result, err := gv.ApplyTag(diagnostics)
+	if err != nil {
+		t.Fatalf("ApplyTag() on a feature branch error = %v, want it skipped", err)
+	}
+	if !result.Skipped || result.Created || result.Tag != "" {
+		t.Errorf("ApplyTag() on a feature branch = %+v, want it skipped", result)
+	}
+}
+
+func TestCreateTagRefusesPrerelease(t *testing.T) {
+	gv := &GitVersion{config: &config.Config{}}
+
+	diagnostics := &Diagnostics{
+		Branch:  "release/1.2.0",
+		Version: &semver.Version{Major: 1, Minor: 2, Patch: 0, PreRelease: "beta.1"},
+	}
+
+	_, err := gv.CreateTag(diagnostics, TagOptions{})
+	if err == nil {
+		t.Fatalf("Expected tagging a prerelease version to be refused")
+	}
+	if !strings.Contains(err.Error(), "--allow-prerelease") {
+		t.Errorf("Error should mention --allow-prerelease, got: %v", err)
+	}
+}
+
+func TestPrefixedTagName(t *testing.T) {
+	version := &semver.Version{Major: 1, Minor: 4, Patch: 0, Build: "3+abc"}
+	if got := PrefixedTagName("release-", version); got != "release-1.4.0" {
+		t.Errorf("PrefixedTagName() = %s, want release-1.4.0", got)
+	}
+}
@@ -0,0 +1,42 @@
+package gitversion
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+)
+
+var placeholderPattern = lazyregexp.New(`\{([^{}]+)\}`)
+
+// expandFormat fills a GitVersion format string such as
+// assembly-informational-format: {Name} becomes the variable Name and
+// {env:NAME} the environment variable NAME, empty when unset.
+func expandFormat(format string, output *JSONOutput) (string, error) {
+	var expandErr error
+	expanded := placeholderPattern.ReplaceAllStringFunc(format, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if env, ok := strings.CutPrefix(name, "env:"); ok {
+			return os.Getenv(env)
+		}
+		value, err := output.Variable(name)
+		if err != nil && expandErr == nil {
+			expandErr = fmt.Errorf("unknown variable {%s}", name)
+		}
+		return value
+	})
+	return expanded, expandErr
+}
+
+// validateFormat reports placeholders in format that name no variable
+func validateFormat(format string) error {
+	_, err := expandFormat(format, &JSONOutput{})
+	return err
+}
+
+// Expand fills a format string such as "{Major}.{Minor}" with the
+// variables, as assembly-informational-format is filled
+func (output *JSONOutput) Expand(format string) (string, error) {
+	return expandFormat(format, output)
+}
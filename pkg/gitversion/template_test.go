@@ -0,0 +1,63 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+func TestExpandFormat(t *testing.T) {
+	t.Setenv("BUILD_ID", "42")
+	output := &JSONOutput{FullSemVer: "1.2.3-beta.1+4", EscapedBranchName: "release-1.2", Sha: "abc123", CommitsSinceVersionSource: 4}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"{FullSemVer}+Branch.{EscapedBranchName}.Sha.{Sha}", "1.2.3-beta.1+4+Branch.release-1.2.Sha.abc123"},
+		{"{FullSemVer}.build{env:BUILD_ID}", "1.2.3-beta.1+4.build42"},
+		{"{fullsemver} ({CommitsSinceVersionSource} commits)", "1.2.3-beta.1+4 (4 commits)"},
+		{"{env:UNSET_GITVERSION_TEST}", ""},
+		{"no placeholders", "no placeholders"},
+	}
+
+	for _, tt := range tests {
+		got, err := expandFormat(tt.format, output)
+		if err != nil {
+			t.Errorf("expandFormat(%q) failed: %v", tt.format, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("expandFormat(%q) = %q, want %q", tt.format, got, tt.expected)
+		}
+	}
+
+	if err := validateFormat("{SemVer}.{Nope}"); err == nil {
+		t.Errorf("Expected validateFormat to reject an unknown variable")
+	}
+}
+
+func TestVariablesApplyConfiguredFormats(t *testing.T) {
+	t.Setenv("BUILD_NUMBER", "812")
+	gv := &GitVersion{
+		repo: git.NewRepository(),
+		config: &config.Config{
+			AssemblyInformationalFormat:  "{SemVer}+{ShortSha}",
+			AssemblyFileVersioningFormat: "{MajorMinorPatch}.{env:BUILD_NUMBER}",
+		},
+		formatter: NewFormatter(&mockRepo{}),
+	}
+
+	variables := gv.Variables(&Diagnostics{
+		Branch:  "main",
+		Version: &semver.Version{Major: 1, Minor: 4, Patch: 2},
+	})
+	if variables.InformationalVersion != "1.4.2+abc1234" {
+		t.Errorf("InformationalVersion = %s, want 1.4.2+abc1234", variables.InformationalVersion)
+	}
+	if variables.AssemblySemFileVer != "1.4.2.812" {
+		t.Errorf("AssemblySemFileVer = %s, want 1.4.2.812", variables.AssemblySemFileVer)
+	}
+}
@@ -0,0 +1,454 @@
+package v1
+
+import (
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	"github.com/VirtuallyScott/gitversion-go/pkg/releasenotes"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+// The types of this package mirror those of pkg/gitversion, which are free
+// to change. The functions below copy between them at the API boundary.
+
+// internal returns the options as pkg/gitversion takes them
+func (opts *Options) internal() *gitversion.Options {
+	return &gitversion.Options{
+		OutputFormat:   gitversion.OutputFormat(opts.OutputFormat),
+		ConfigFile:     opts.ConfigFile,
+		TargetBranch:   opts.TargetBranch,
+		Workflow:       version.WorkflowType(opts.Workflow),
+		ForceIncrement: opts.ForceIncrement,
+		NextVersion:    opts.NextVersion,
+		Strategies:     opts.Strategies,
+		Module:         opts.Module,
+		MaxDuration:    opts.MaxDuration,
+		Progress:       opts.Progress,
+		Debug:          opts.Debug,
+		Logger:         opts.Logger,
+		StrictConfig:   opts.StrictConfig,
+		ConfigFiles:    opts.ConfigFiles,
+		OverrideConfig: opts.OverrideConfig,
+		Project:        opts.Project,
+		IncludePaths:   opts.IncludePaths,
+		Component:      opts.Component,
+		GoPackage:      opts.GoPackage,
+		DockerLabels:   opts.DockerLabels,
+		Dir:            opts.Dir,
+		NoCache:        opts.NoCache,
+		Fetch:          opts.Fetch,
+		AllowDeepen:    opts.AllowDeepen,
+		Remote:         opts.Remote,
+	}
+}
+
+func newVariables(output *gitversion.JSONOutput) *Variables {
+	variables := &Variables{
+		Major:                           output.Major,
+		Minor:                           output.Minor,
+		Patch:                           output.Patch,
+		PreReleaseTag:                   output.PreReleaseTag,
+		PreReleaseTagWithDash:           output.PreReleaseTagWithDash,
+		PreReleaseLabel:                 output.PreReleaseLabel,
+		PreReleaseLabelWithDash:         output.PreReleaseLabelWithDash,
+		PreReleaseNumber:                output.PreReleaseNumber,
+		WeightedPreReleaseNumber:        output.WeightedPreReleaseNumber,
+		BuildMetaData:                   output.BuildMetaData,
+		BuildMetaDataPadded:             output.BuildMetaDataPadded,
+		FullBuildMetaData:               output.FullBuildMetaData,
+		MajorMinorPatch:                 output.MajorMinorPatch,
+		SemVer:                          output.SemVer,
+		AssemblySemVer:                  output.AssemblySemVer,
+		AssemblySemFileVer:              output.AssemblySemFileVer,
+		FullSemVer:                      output.FullSemVer,
+		InformationalVersion:            output.InformationalVersion,
+		BranchName:                      output.BranchName,
+		EscapedBranchName:               output.EscapedBranchName,
+		DockerTagBranchName:             output.DockerTagBranchName,
+		Sha:                             output.Sha,
+		ShortSha:                        output.ShortSha,
+		NuGetVersionV2:                  output.NuGetVersionV2,
+		NuGetVersion:                    output.NuGetVersion,
+		VersionSourceSha:                output.VersionSourceSha,
+		VersionSourceTagDate:            output.VersionSourceTagDate,
+		CommitsSinceVersionSource:       output.CommitsSinceVersionSource,
+		CommitsSinceVersionSourcePadded: output.CommitsSinceVersionSourcePadded,
+		UncommittedChanges:              output.UncommittedChanges,
+		CommitDate:                      output.CommitDate,
+		Describe:                        output.Describe,
+		MsiVersion:                      output.MsiVersion,
+		Partial:                         output.Partial,
+	}
+	if bc := output.BranchConfig; bc != nil {
+		variables.BranchConfig = &BranchConfig{
+			Key:              bc.Key,
+			Label:            bc.Label,
+			Increment:        bc.Increment,
+			Mode:             bc.Mode,
+			PreReleaseWeight: bc.PreReleaseWeight,
+		}
+	}
+	return variables
+}
+
+func (v *Variables) internal() *gitversion.JSONOutput {
+	output := &gitversion.JSONOutput{
+		Major:                           v.Major,
+		Minor:                           v.Minor,
+		Patch:                           v.Patch,
+		PreReleaseTag:                   v.PreReleaseTag,
+		PreReleaseTagWithDash:           v.PreReleaseTagWithDash,
+		PreReleaseLabel:                 v.PreReleaseLabel,
+		PreReleaseLabelWithDash:         v.PreReleaseLabelWithDash,
+		PreReleaseNumber:                v.PreReleaseNumber,
+		WeightedPreReleaseNumber:        v.WeightedPreReleaseNumber,
+		BuildMetaData:                   v.BuildMetaData,
+		BuildMetaDataPadded:             v.BuildMetaDataPadded,
+		FullBuildMetaData:               v.FullBuildMetaData,
+		MajorMinorPatch:                 v.MajorMinorPatch,
+		SemVer:                          v.SemVer,
+		AssemblySemVer:                  v.AssemblySemVer,
+		AssemblySemFileVer:              v.AssemblySemFileVer,
+		FullSemVer:                      v.FullSemVer,
+		InformationalVersion:            v.InformationalVersion,
+		BranchName:                      v.BranchName,
+		EscapedBranchName:               v.EscapedBranchName,
+		DockerTagBranchName:             v.DockerTagBranchName,
+		Sha:                             v.Sha,
+		ShortSha:                        v.ShortSha,
+		NuGetVersionV2:                  v.NuGetVersionV2,
+		NuGetVersion:                    v.NuGetVersion,
+		VersionSourceSha:                v.VersionSourceSha,
+		VersionSourceTagDate:            v.VersionSourceTagDate,
+		CommitsSinceVersionSource:       v.CommitsSinceVersionSource,
+		CommitsSinceVersionSourcePadded: v.CommitsSinceVersionSourcePadded,
+		UncommittedChanges:              v.UncommittedChanges,
+		CommitDate:                      v.CommitDate,
+		Describe:                        v.Describe,
+		MsiVersion:                      v.MsiVersion,
+		Partial:                         v.Partial,
+	}
+	if bc := v.BranchConfig; bc != nil {
+		output.BranchConfig = &gitversion.BranchConfigOutput{
+			Key:              bc.Key,
+			Label:            bc.Label,
+			Increment:        bc.Increment,
+			Mode:             bc.Mode,
+			PreReleaseWeight: bc.PreReleaseWeight,
+		}
+	}
+	return output
+}
+
+// newDiagnostics copies diagnostics, nil for nil
+func newDiagnostics(diagnostics *gitversion.Diagnostics) *Diagnostics {
+	if diagnostics == nil {
+		return nil
+	}
+	candidates := make([]*BaseVersion, len(diagnostics.Candidates))
+	for i, candidate := range diagnostics.Candidates {
+		candidates[i] = newBaseVersion(candidate)
+	}
+	return &Diagnostics{
+		Branch:            diagnostics.Branch,
+		BranchType:        string(diagnostics.BranchType),
+		Workflow:          string(diagnostics.Workflow),
+		BranchConfig:      newBranchConfiguration(diagnostics.BranchConfig),
+		BranchConfigKey:   diagnostics.BranchConfigKey,
+		Strategies:        diagnostics.Strategies,
+		Candidates:        candidates,
+		Partial:           diagnostics.Partial,
+		SkippedStrategies: diagnostics.SkippedStrategies,
+		Selected:          newBaseVersion(diagnostics.Selected),
+		SelectionReason:   diagnostics.SelectionReason,
+		Increment:         diagnostics.Increment,
+		IncrementCause:    diagnostics.IncrementCause,
+		CommitCount:       diagnostics.CommitCount,
+		CommitCountSource: diagnostics.CommitCountSource,
+		Version:           newVersion(diagnostics.Version),
+		Iteration:         diagnostics.Iteration,
+		IterationSource:   diagnostics.IterationSource,
+		Shallow:           diagnostics.Shallow,
+	}
+}
+
+func (d *Diagnostics) internal() *gitversion.Diagnostics {
+	candidates := make([]*version.BaseVersion, len(d.Candidates))
+	for i, candidate := range d.Candidates {
+		candidates[i] = candidate.internal()
+	}
+	return &gitversion.Diagnostics{
+		Branch:            d.Branch,
+		BranchType:        version.BranchType(d.BranchType),
+		Workflow:          version.WorkflowType(d.Workflow),
+		BranchConfig:      d.BranchConfig.internal(),
+		BranchConfigKey:   d.BranchConfigKey,
+		Strategies:        d.Strategies,
+		Candidates:        candidates,
+		Partial:           d.Partial,
+		SkippedStrategies: d.SkippedStrategies,
+		Selected:          d.Selected.internal(),
+		SelectionReason:   d.SelectionReason,
+		Increment:         d.Increment,
+		IncrementCause:    d.IncrementCause,
+		CommitCount:       d.CommitCount,
+		CommitCountSource: d.CommitCountSource,
+		Version:           d.Version.internal(),
+		Iteration:         d.Iteration,
+		IterationSource:   d.IterationSource,
+		Shallow:           d.Shallow,
+	}
+}
+
+// newBaseVersion copies bv, nil for nil
+func newBaseVersion(bv *version.BaseVersion) *BaseVersion {
+	if bv == nil {
+		return nil
+	}
+	return &BaseVersion{
+		SemanticVersion:   newVersion(bv.SemanticVersion),
+		Source:            bv.Source,
+		ShouldIncrement:   bv.ShouldIncrement,
+		BaseVersionSource: bv.BaseVersionSource,
+		Strategy:          bv.Strategy,
+		Tag:               bv.Tag,
+	}
+}
+
+func (bv *BaseVersion) internal() *version.BaseVersion {
+	if bv == nil {
+		return nil
+	}
+	return &version.BaseVersion{
+		SemanticVersion:   bv.SemanticVersion.internal(),
+		Source:            bv.Source,
+		ShouldIncrement:   bv.ShouldIncrement,
+		BaseVersionSource: bv.BaseVersionSource,
+		Strategy:          bv.Strategy,
+		Tag:               bv.Tag,
+	}
+}
+
+// newVersion copies v, nil for nil
+func newVersion(v *semver.Version) *Version {
+	if v == nil {
+		return nil
+	}
+	return &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, PreRelease: v.PreRelease, Build: v.Build}
+}
+
+func (v *Version) internal() *semver.Version {
+	if v == nil {
+		return nil
+	}
+	return &semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, PreRelease: v.PreRelease, Build: v.Build}
+}
+
+// newBranchConfiguration copies bc, nil for nil
+func newBranchConfiguration(bc *config.BranchConfiguration) *BranchConfiguration {
+	if bc == nil {
+		return nil
+	}
+	branchConfig := &BranchConfiguration{
+		Mode:                  string(bc.Mode),
+		Label:                 bc.Label,
+		Increment:             string(bc.Increment),
+		Tag:                   bc.Tag,
+		TrackMergeTarget:      bc.TrackMergeTarget,
+		TrackMergeMessage:     bc.TrackMergeMessage,
+		Regex:                 bc.Regex,
+		SourceBranches:        bc.SourceBranches,
+		IsSourceBranchFor:     bc.IsSourceBranchFor,
+		TracksReleaseBranches: bc.TracksReleaseBranches,
+		IsReleaseBranch:       bc.IsReleaseBranch,
+		IsMainBranch:          bc.IsMainBranch,
+		PreReleaseWeight:      bc.PreReleaseWeight,
+		AutoTag:               bc.AutoTag,
+		CommitsSince:          string(bc.CommitsSince),
+		IgnorePreReleaseTags:  bc.IgnorePreReleaseTags,
+		Strategies:            bc.Strategies,
+	}
+	if pi := bc.PreventIncrement; pi != nil {
+		branchConfig.PreventIncrement = &PreventIncrement{
+			OfMergedBranch:          pi.OfMergedBranch,
+			WhenCurrentCommitTagged: pi.WhenCurrentCommitTagged,
+			WhenBranchMerged:        pi.WhenBranchMerged,
+		}
+	}
+	return branchConfig
+}
+
+func (bc *BranchConfiguration) internal() *config.BranchConfiguration {
+	if bc == nil {
+		return nil
+	}
+	branchConfig := &config.BranchConfiguration{
+		Mode:                  config.DeploymentMode(bc.Mode),
+		Label:                 bc.Label,
+		Increment:             config.IncrementStrategy(bc.Increment),
+		Tag:                   bc.Tag,
+		TrackMergeTarget:      bc.TrackMergeTarget,
+		TrackMergeMessage:     bc.TrackMergeMessage,
+		Regex:                 bc.Regex,
+		SourceBranches:        bc.SourceBranches,
+		IsSourceBranchFor:     bc.IsSourceBranchFor,
+		TracksReleaseBranches: bc.TracksReleaseBranches,
+		IsReleaseBranch:       bc.IsReleaseBranch,
+		IsMainBranch:          bc.IsMainBranch,
+		PreReleaseWeight:      bc.PreReleaseWeight,
+		AutoTag:               bc.AutoTag,
+		CommitsSince:          config.CommitCountMode(bc.CommitsSince),
+		IgnorePreReleaseTags:  bc.IgnorePreReleaseTags,
+		Strategies:            bc.Strategies,
+	}
+	if pi := bc.PreventIncrement; pi != nil {
+		branchConfig.PreventIncrement = &config.PreventIncrementConfiguration{
+			OfMergedBranch:          pi.OfMergedBranch,
+			WhenCurrentCommitTagged: pi.WhenCurrentCommitTagged,
+			WhenBranchMerged:        pi.WhenBranchMerged,
+		}
+	}
+	return branchConfig
+}
+
+func newTagResult(result *gitversion.TagResult) *TagResult {
+	return &TagResult{Tag: result.Tag, Created: result.Created, Skipped: result.Skipped}
+}
+
+// newNote copies note, nil for nil
+func newNote(note *gitversion.Note) *Note {
+	if note == nil {
+		return nil
+	}
+	return &Note{
+		Commit:      note.Commit,
+		Version:     note.Version,
+		Tag:         note.Tag,
+		Branch:      note.Branch,
+		RecordedAt:  note.RecordedAt,
+		Diagnostics: newDiagnostics(note.Diagnostics),
+	}
+}
+
+func newBranchStatus(status *gitversion.BranchStatus) *BranchStatus {
+	return &BranchStatus{
+		Branch:       status.Branch,
+		ConfigKey:    status.ConfigKey,
+		SHA:          status.SHA,
+		State:        BranchState(status.State),
+		MergedInto:   status.MergedInto,
+		ReleasedIn:   status.ReleasedIn,
+		SafeToDelete: status.SafeToDelete,
+	}
+}
+
+func newConfig(cfg *config.Config) *Config {
+	c := &Config{
+		ReleaseNotes: ReleaseNotesConfig{
+			RepositoryURL: cfg.ReleaseNotes.RepositoryURL,
+			Template:      cfg.ReleaseNotes.Template,
+		},
+	}
+	for _, publisher := range cfg.Publishers {
+		c.Publishers = append(c.Publishers, PublisherConfig{Name: publisher.Name, Options: publisher.Options})
+	}
+	for _, update := range cfg.FileUpdates {
+		c.FileUpdates = append(c.FileUpdates, FileUpdateConfig{
+			Path:    update.Path,
+			Writer:  update.Writer,
+			Options: update.Options,
+			Create:  update.Create,
+		})
+	}
+	if cfg.Cache != nil {
+		c.Cache = &CacheConfig{MaxAge: cfg.Cache.MaxAge, MaxSize: cfg.Cache.MaxSize}
+	}
+	return c
+}
+
+func newReleaseNotes(notes *releasenotes.Notes) *ReleaseNotes {
+	// Sections share their entries with Entries
+	entries := make(map[*releasenotes.Entry]*ReleaseNotesEntry, len(notes.Entries))
+	entry := func(e *releasenotes.Entry) *ReleaseNotesEntry {
+		if copied, ok := entries[e]; ok {
+			return copied
+		}
+		copied := &ReleaseNotesEntry{
+			SHA:      e.SHA,
+			ShortSHA: e.ShortSHA,
+			Type:     e.Type,
+			Scope:    e.Scope,
+			Subject:  e.Subject,
+			Breaking: e.Breaking,
+			URL:      e.URL,
+		}
+		for _, pr := range e.PullRequests {
+			copied.PullRequests = append(copied.PullRequests, PullRequest{Number: pr.Number, URL: pr.URL})
+		}
+		entries[e] = copied
+		return copied
+	}
+
+	n := &ReleaseNotes{
+		Version:       notes.Version,
+		Tag:           notes.Tag,
+		PreviousTag:   notes.PreviousTag,
+		Date:          notes.Date,
+		RepositoryURL: notes.RepositoryURL,
+		CompareURL:    notes.CompareURL,
+	}
+	for _, e := range notes.Entries {
+		n.Entries = append(n.Entries, entry(e))
+	}
+	for _, section := range notes.Sections {
+		s := &ReleaseNotesSection{Title: section.Title}
+		for _, e := range section.Entries {
+			s.Entries = append(s.Entries, entry(e))
+		}
+		n.Sections = append(n.Sections, s)
+	}
+	return n
+}
+
+func (n *ReleaseNotes) internal() *releasenotes.Notes {
+	entries := make(map[*ReleaseNotesEntry]*releasenotes.Entry, len(n.Entries))
+	entry := func(e *ReleaseNotesEntry) *releasenotes.Entry {
+		if copied, ok := entries[e]; ok {
+			return copied
+		}
+		copied := &releasenotes.Entry{
+			SHA:      e.SHA,
+			ShortSHA: e.ShortSHA,
+			Type:     e.Type,
+			Scope:    e.Scope,
+			Subject:  e.Subject,
+			Breaking: e.Breaking,
+			URL:      e.URL,
+		}
+		for _, pr := range e.PullRequests {
+			copied.PullRequests = append(copied.PullRequests, releasenotes.PullRequest{Number: pr.Number, URL: pr.URL})
+		}
+		entries[e] = copied
+		return copied
+	}
+
+	notes := &releasenotes.Notes{
+		Version:       n.Version,
+		Tag:           n.Tag,
+		PreviousTag:   n.PreviousTag,
+		Date:          n.Date,
+		RepositoryURL: n.RepositoryURL,
+		CompareURL:    n.CompareURL,
+	}
+	for _, e := range n.Entries {
+		notes.Entries = append(notes.Entries, entry(e))
+	}
+	for _, section := range n.Sections {
+		s := &releasenotes.Section{Title: section.Title}
+		for _, e := range section.Entries {
+			s.Entries = append(s.Entries, entry(e))
+		}
+		notes.Sections = append(notes.Sections, s)
+	}
+	return notes
+}
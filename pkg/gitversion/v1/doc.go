@@ -0,0 +1,24 @@
+// Package v1 is the stable library API of gitversion-go.
+//
+// Stability guarantees:
+//
+//   - Exported identifiers in this package are not removed or renamed
+//     within major version 1 of the module.
+//   - Fields are only ever added to Options and Result; existing fields keep
+//     their meaning and JSON names.
+//   - Calculation behavior changes only when it brings results closer to
+//     GitTools/GitVersion, and such changes are listed in the release notes.
+//
+// Deprecation policy:
+//
+//   - Entry points that are superseded are marked with a "Deprecated:"
+//     doc comment and log a warning once per process when used.
+//   - Deprecated entry points keep working for at least one minor release
+//     and are only removed in the next major version.
+//
+// The pkg/gitversion package predates this API and is not covered by the
+// guarantees above. This package defines its own types and copies results
+// out of pkg/gitversion, so changes there do not leak into it. Errors keep
+// their messages and match the sentinels and error types of this package
+// with errors.Is and errors.As.
+package v1
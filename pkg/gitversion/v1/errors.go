@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+)
+
+// Kinds of failure, told apart with errors.Is instead of matching messages
+var (
+	// ErrNotARepository is returned for a directory outside any git working
+	// tree
+	ErrNotARepository = errors.New("not a git repository")
+	// ErrNoCommits is returned for a branch without commits, as right after
+	// git init
+	ErrNoCommits = errors.New("no commits on the current branch")
+	// ErrShallowClone is added to a calculation that failed in a shallow
+	// clone, whose missing history is the likely cause
+	ErrShallowClone = errors.New("the clone is shallow; fetch the full history with git fetch --unshallow")
+	// ErrInvalidConfig matches every ConfigError
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
+
+// sentinels pairs each sentinel of this package with the one of
+// pkg/gitversion it stands for
+var sentinels = map[error]error{
+	ErrNotARepository: gitversion.ErrNotARepository,
+	ErrNoCommits:      gitversion.ErrNoCommits,
+	ErrShallowClone:   gitversion.ErrShallowClone,
+	ErrInvalidConfig:  gitversion.ErrInvalidConfig,
+}
+
+// ConfigError is a configuration that cannot be used, with the key at fault
+type ConfigError struct {
+	// Field is the dotted key at fault, e.g. "branches.main.increment", or
+	// "" when it is not known, e.g. for a file that does not parse
+	Field string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports ErrInvalidConfig as matching
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// GitError is a git command that failed, with what it printed on stderr
+type GitError struct {
+	// Args are the arguments git ran with
+	Args []string
+	// Stderr is the trimmed error output of git, e.g. "fatal: bad revision"
+	Stderr string
+	Err    error
+}
+
+func (e *GitError) Error() string {
+	msg := fmt.Sprintf("git %s: %v", e.Args[0], e.Err)
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// apiError carries an error of pkg/gitversion across the API boundary, so
+// errors.Is and errors.As find the sentinels and error types of this
+// package in it. The message is unchanged.
+type apiError struct {
+	err error
+}
+
+// convertError returns err as the API reports it, nil for nil
+func convertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &apiError{err: err}
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+func (e *apiError) Is(target error) bool {
+	internal, ok := sentinels[target]
+	return ok && errors.Is(e.err, internal)
+}
+
+func (e *apiError) As(target interface{}) bool {
+	switch target := target.(type) {
+	case **ConfigError:
+		var configErr *gitversion.ConfigError
+		if errors.As(e.err, &configErr) {
+			*target = &ConfigError{Field: configErr.Field, Err: configErr.Err}
+			return true
+		}
+	case **GitError:
+		var gitErr *gitversion.GitError
+		if errors.As(e.err, &gitErr) {
+			*target = &GitError{Args: gitErr.Args, Stderr: gitErr.Stderr, Err: gitErr.Err}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,676 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
+	"github.com/VirtuallyScott/gitversion-go/pkg/releasenotes"
+)
+
+// APIVersion identifies this version of the library API
+const APIVersion = "1"
+
+// Options controls a calculation
+type Options struct {
+	OutputFormat OutputFormat
+	ConfigFile   string
+	TargetBranch string
+	// Workflow is gitflow, githubflow or trunk; the configuration decides
+	// when empty
+	Workflow       string
+	ForceIncrement string
+	NextVersion    string
+	Strategies     []string
+	// Module selects a Go module of a multi-module repository by its
+	// directory relative to the repository root. Tags are then prefixed
+	// with the directory and commits outside the module are ignored.
+	Module string
+	// MaxDuration bounds the time spent running strategies; the result is
+	// marked partial when it runs out. Zero means no limit.
+	MaxDuration time.Duration
+	Progress    progress.Reporter
+	// Debug logs debug records as text to stderr when Logger is nil
+	Debug bool
+	// Logger receives the log records of the calculation; nil logs nothing
+	// unless Debug is set
+	Logger *slog.Logger
+
+	// StrictConfig rejects configuration files with unknown keys, invalid
+	// enum values or regular expressions that do not compile
+	StrictConfig bool
+	// ConfigFiles are layered over ConfigFile in order, each deep-merged
+	// onto the ones before it
+	ConfigFiles []string
+	// OverrideConfig sets configuration values after loading, each
+	// "key=value" with a dotted key such as "branches.main.label"
+	OverrideConfig []string
+	// Project is a directory, relative to the repository root, whose
+	// configuration file is layered over ConfigFile and ConfigFiles
+	Project string
+	// IncludePaths replaces the paths of the configuration: only commits
+	// touching these directories count towards the version
+	IncludePaths []string
+	// Component replaces the component of the configuration, whose name
+	// prefixes the version tags, e.g. api/v1.2.3
+	Component string
+	// GoPackage is the import path, or main, of the package whose version,
+	// commit and date variables the goldflags output sets; main when empty
+	GoPackage string
+	// DockerLabels makes the docker output print the OCI image labels as
+	// docker build --label arguments instead of the image tag
+	DockerLabels bool
+	// Dir is the working tree to calculate the version of, the current
+	// directory when empty
+	Dir string
+	// NoCache recalculates even when the cache holds a calculation for the
+	// same commit, refs, configuration and options
+	NoCache bool
+	// Fetch fetches the tags of Remote and the branches the calculation
+	// looks at before each calculation
+	Fetch bool
+	// AllowDeepen deepens a shallow clone from Remote until a version tag
+	// is reachable from HEAD
+	AllowDeepen bool
+	// Remote is the primary remote; when empty it is remote-name from the
+	// configuration, else origin
+	Remote string
+}
+
+// OutputFormat selects how a Result is rendered
+type OutputFormat string
+
+// Output formats supported by Result.Format
+const (
+	Text               OutputFormat = "text"
+	JSON               OutputFormat = "json"
+	AssemblySemVer     OutputFormat = "AssemblySemVer"
+	AssemblySemFileVer OutputFormat = "AssemblySemFileVer"
+	BuildServer        OutputFormat = "buildserver"
+	GitHubActions      OutputFormat = "githubactions"
+	Describe           OutputFormat = "describe"
+	Env                OutputFormat = "env"
+	Shell              OutputFormat = "shell"
+	GoLdflags          OutputFormat = "goldflags"
+	Docker             OutputFormat = "docker"
+)
+
+// Variables holds the GitVersion-compatible version variables
+type Variables struct {
+	Major                   int    `json:"Major"`
+	Minor                   int    `json:"Minor"`
+	Patch                   int    `json:"Patch"`
+	PreReleaseTag           string `json:"PreReleaseTag"`
+	PreReleaseTagWithDash   string `json:"PreReleaseTagWithDash"`
+	PreReleaseLabel         string `json:"PreReleaseLabel"`
+	PreReleaseLabelWithDash string `json:"PreReleaseLabelWithDash"`
+	// PreReleaseNumber is null without a numbered prerelease, like GitVersion
+	PreReleaseNumber         *int   `json:"PreReleaseNumber"`
+	WeightedPreReleaseNumber int    `json:"WeightedPreReleaseNumber"`
+	BuildMetaData            string `json:"BuildMetaData"`
+	BuildMetaDataPadded      string `json:"BuildMetaDataPadded"`
+	FullBuildMetaData        string `json:"FullBuildMetaData"`
+	MajorMinorPatch          string `json:"MajorMinorPatch"`
+	SemVer                   string `json:"SemVer"`
+	AssemblySemVer           string `json:"AssemblySemVer"`
+	AssemblySemFileVer       string `json:"AssemblySemFileVer"`
+	FullSemVer               string `json:"FullSemVer"`
+	InformationalVersion     string `json:"InformationalVersion"`
+	BranchName               string `json:"BranchName"`
+	EscapedBranchName        string `json:"EscapedBranchName"`
+	DockerTagBranchName      string `json:"DockerTagBranchName"`
+	Sha                      string `json:"Sha"`
+	ShortSha                 string `json:"ShortSha"`
+	NuGetVersionV2           string `json:"NuGetVersionV2"`
+	NuGetVersion             string `json:"NuGetVersion"`
+	VersionSourceSha         string `json:"VersionSourceSha"`
+	// VersionSourceTagDate is when the annotated version tag the base
+	// version came from was created, formatted like CommitDate
+	VersionSourceTagDate            string `json:"VersionSourceTagDate"`
+	CommitsSinceVersionSource       int    `json:"CommitsSinceVersionSource"`
+	CommitsSinceVersionSourcePadded string `json:"CommitsSinceVersionSourcePadded"`
+	UncommittedChanges              int    `json:"UncommittedChanges"`
+	CommitDate                      string `json:"CommitDate"`
+	Describe                        string `json:"Describe"`
+	// MsiVersion is the Windows Installer product version, empty when the
+	// version exceeds 255.255.65535
+	MsiVersion string `json:"MsiVersion"`
+
+	BranchConfig *BranchConfig `json:"BranchConfig,omitempty"`
+	// Partial is set when MaxDuration cut the analysis short
+	Partial bool `json:"Partial,omitempty"`
+}
+
+// BranchConfig summarizes the branch configuration a version was
+// calculated with
+type BranchConfig struct {
+	// Key is the matched configuration key, "" for the built-in fallback
+	Key              string `json:"Key"`
+	Label            string `json:"Label"`
+	Increment        string `json:"Increment"`
+	Mode             string `json:"Mode"`
+	PreReleaseWeight int    `json:"PreReleaseWeight"`
+}
+
+// Expand fills a format string such as "{Major}.{Minor}" with the
+// variables, as assembly-informational-format is filled
+func (v *Variables) Expand(format string) (string, error) {
+	return v.internal().Expand(format)
+}
+
+// Variable returns the scalar variable name as text. Names match case
+// insensitively, like GitVersion's /showvariable.
+func (v *Variables) Variable(name string) (string, error) {
+	return v.internal().Variable(name)
+}
+
+// Diagnostics describes how a version was calculated
+type Diagnostics struct {
+	Branch     string `json:"Branch"`
+	BranchType string `json:"BranchType"`
+	Workflow   string `json:"Workflow"`
+	// BranchConfig is the configuration of the branch, BranchConfigKey its
+	// key, "" for the built-in fallback
+	BranchConfig    *BranchConfiguration `json:"BranchConfig"`
+	BranchConfigKey string               `json:"BranchConfigKey"`
+	Strategies      []string             `json:"Strategies"`
+	Candidates      []*BaseVersion       `json:"Candidates"`
+	// Partial is set when the time budget ran out before all strategies
+	// completed; SkippedStrategies lists the ones that did not.
+	Partial           bool         `json:"Partial"`
+	SkippedStrategies []string     `json:"SkippedStrategies,omitempty"`
+	Selected          *BaseVersion `json:"Selected"`
+	SelectionReason   string       `json:"SelectionReason"`
+	Increment         string       `json:"Increment"`
+	IncrementCause    string       `json:"IncrementCause"`
+	CommitCount       int          `json:"CommitCount"`
+	CommitCountSource string       `json:"CommitCountSource"`
+	Version           *Version     `json:"Version"`
+	// Iteration is the release candidate number used as the prerelease
+	// number on ContinuousDelivery release branches, 0 elsewhere
+	Iteration       int    `json:"Iteration,omitempty"`
+	IterationSource string `json:"IterationSource,omitempty"`
+	// Shallow is set when the clone is shallow, so the commits and tags
+	// beyond its depth were not seen and counts may be too low
+	Shallow bool `json:"Shallow,omitempty"`
+}
+
+// String renders the diagnostics as a human readable report
+func (d *Diagnostics) String() string {
+	return d.internal().String()
+}
+
+// BranchConfiguration is the configuration a branch was calculated with,
+// as the branches section of the configuration file sets it
+type BranchConfiguration struct {
+	Mode                  string            `json:"mode" yaml:"mode"`
+	Label                 string            `json:"label" yaml:"label"`
+	Increment             string            `json:"increment" yaml:"increment"`
+	Tag                   string            `json:"tag" yaml:"tag"`
+	PreventIncrement      *PreventIncrement `json:"prevent-increment" yaml:"prevent-increment"`
+	TrackMergeTarget      bool              `json:"track-merge-target" yaml:"track-merge-target"`
+	TrackMergeMessage     bool              `json:"track-merge-message" yaml:"track-merge-message"`
+	Regex                 string            `json:"regex" yaml:"regex"`
+	SourceBranches        []string          `json:"source-branches" yaml:"source-branches"`
+	IsSourceBranchFor     []string          `json:"is-source-branch-for" yaml:"is-source-branch-for"`
+	TracksReleaseBranches bool              `json:"tracks-release-branches" yaml:"tracks-release-branches"`
+	IsReleaseBranch       bool              `json:"is-release-branch" yaml:"is-release-branch"`
+	IsMainBranch          bool              `json:"is-main-branch" yaml:"is-main-branch"`
+	PreReleaseWeight      int               `json:"pre-release-weight" yaml:"pre-release-weight"`
+	AutoTag               bool              `json:"auto-tag" yaml:"auto-tag"`
+	CommitsSince          string            `json:"commits-since" yaml:"commits-since"`
+	// IgnorePreReleaseTags ignores version tags with a prerelease, such as
+	// v1.5.0-rc.1, as base versions of the branch
+	IgnorePreReleaseTags bool `json:"ignore-pre-release-tags" yaml:"ignore-pre-release-tags"`
+	// Strategies overrides the global strategies for the branch: plain
+	// names replace the list, "+Name" adds and "-Name" removes a strategy.
+	Strategies []string `json:"strategies" yaml:"strategies"`
+}
+
+// PreventIncrement lists when a branch keeps the version it builds on
+type PreventIncrement struct {
+	OfMergedBranch          bool `json:"of-merged-branch" yaml:"of-merged-branch"`
+	WhenCurrentCommitTagged bool `json:"when-current-commit-tagged" yaml:"when-current-commit-tagged"`
+	WhenBranchMerged        bool `json:"when-branch-merged" yaml:"when-branch-merged"`
+}
+
+// Version is a semantic version
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	Build      string
+}
+
+// String renders the version, e.g. 1.2.3-beta.4+5
+func (v *Version) String() string {
+	return v.internal().String()
+}
+
+// MajorMinorPatch renders the version without prerelease and build
+// metadata, e.g. 1.2.3
+func (v *Version) MajorMinorPatch() string {
+	return v.internal().MajorMinorPatch()
+}
+
+// BaseVersion is a version a strategy found to build on
+type BaseVersion struct {
+	SemanticVersion   *Version `json:"SemanticVersion"`
+	Source            string   `json:"Source"`
+	ShouldIncrement   bool     `json:"ShouldIncrement"`
+	BaseVersionSource string   `json:"BaseVersionSource"`
+	Strategy          string   `json:"Strategy"`
+	// Tag is the version tag the base version came from, if any
+	Tag string `json:"Tag,omitempty"`
+}
+
+// Result is the outcome of a calculation
+type Result struct {
+	Variables
+	APIVersion string `json:"-"`
+
+	client      *Client
+	diagnostics *gitversion.Diagnostics
+}
+
+// String returns the full semantic version
+func (r *Result) String() string {
+	return r.FullSemVer
+}
+
+// Diagnostics returns the decision trail behind the result
+func (r *Result) Diagnostics() *Diagnostics {
+	return newDiagnostics(r.diagnostics)
+}
+
+// Format renders the result in the given output format
+func (r *Result) Format(format OutputFormat) (string, error) {
+	rendered, err := r.client.gv.Format(r.diagnostics, gitversion.OutputFormat(format))
+	return rendered, convertError(err)
+}
+
+// TagName returns the name of the tag for the result's version, e.g.
+// "v1.2.3" or "tools/v1.2.3" for a nested Go module.
+func (r *Result) TagName() string {
+	return r.client.gv.TagName(r.diagnostics.Version)
+}
+
+// TagResult describes the outcome of Result.ApplyTag and Result.CreateTag
+type TagResult struct {
+	Tag     string
+	Created bool
+	// Skipped is set by ApplyTag when the branch does not allow auto-tag;
+	// Tag is empty then
+	Skipped bool
+}
+
+// ApplyTag tags HEAD with the result's version if the branch configuration
+// has auto-tag enabled.
+func (r *Result) ApplyTag() (*TagResult, error) {
+	result, err := r.client.gv.ApplyTag(r.diagnostics)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return newTagResult(result), nil
+}
+
+// TagOptions controls Result.CreateTag
+type TagOptions struct {
+	// Prefix goes before the version; when empty it is taken from
+	// tag-prefix, "v" by default
+	Prefix string
+	// AllowPrerelease permits tagging prerelease versions
+	AllowPrerelease bool
+	// Sign signs the tag with the signing key git is configured with
+	Sign bool
+}
+
+// CreateTag creates an annotated tag for the result's version on HEAD,
+// regardless of auto-tag. Prerelease versions are refused unless
+// opts.AllowPrerelease is set.
+func (r *Result) CreateTag(opts TagOptions) (*TagResult, error) {
+	result, err := r.client.gv.CreateTag(r.diagnostics, gitversion.TagOptions{
+		Prefix:          opts.Prefix,
+		AllowPrerelease: opts.AllowPrerelease,
+		Sign:            opts.Sign,
+	})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return newTagResult(result), nil
+}
+
+// PushTag pushes tag to remote, the primary remote when empty. Credentials
+// are taken from the GITVERSION_REMOTE_* variables when set, otherwise from
+// git's credential helpers.
+func (c *Client) PushTag(remote, tag string) error {
+	return convertError(c.gv.PushTag(remote, tag))
+}
+
+// PushTagContext is like PushTag but gives up once ctx is done, killing
+// the push in flight
+func (c *Client) PushTagContext(ctx context.Context, remote, tag string) error {
+	return convertError(c.gv.PushTagContext(ctx, remote, tag))
+}
+
+// RetryContext returns a copy of ctx carrying the retry policies of the
+// configuration, for remote operations run outside the client such as
+// publishers
+func (c *Client) RetryContext(ctx context.Context) context.Context {
+	return c.gv.RetryContext(ctx)
+}
+
+// Remote returns the primary remote: Options.Remote, else remote-name from
+// the configuration, else origin
+func (c *Client) Remote() string {
+	return c.gv.Remote()
+}
+
+// ReleaseNotes holds the commits of a release, grouped for rendering
+type ReleaseNotes struct {
+	// Version is the semantic version without build metadata
+	Version     string
+	Tag         string
+	PreviousTag string
+	// Date is the commit date of the release, YYYY-MM-DD
+	Date          string
+	RepositoryURL string
+	// CompareURL links the changes since PreviousTag, when both are known
+	CompareURL string
+	// Sections holds only non-empty sections, in a fixed order
+	Sections []*ReleaseNotesSection
+	// Entries holds every commit, newest first
+	Entries []*ReleaseNotesEntry
+}
+
+// ReleaseNotesSection groups entries under a heading
+type ReleaseNotesSection struct {
+	Title   string
+	Entries []*ReleaseNotesEntry
+}
+
+// ReleaseNotesEntry is a commit as rendered in the notes. Conventional
+// commit subjects are split into type, scope and description.
+type ReleaseNotesEntry struct {
+	SHA      string
+	ShortSHA string
+	// Type and Scope are empty for subjects that are no conventional commit
+	Type  string
+	Scope string
+	// Subject is the description without type, scope and squash merge
+	// suffix, e.g. "add widgets" for "feat(api): add widgets (#12)"
+	Subject  string
+	Breaking bool
+	// URL links the commit when a repository URL is configured
+	URL          string
+	PullRequests []PullRequest
+}
+
+// PullRequest is a "#123" reference in a commit subject
+type PullRequest struct {
+	Number int
+	URL    string
+}
+
+// ReleaseNotes collects the commits since the previous version tag
+func (r *Result) ReleaseNotes() (*ReleaseNotes, error) {
+	notes, err := r.client.gv.ReleaseNotes(r.diagnostics)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return newReleaseNotes(notes), nil
+}
+
+// Render renders the notes with the Go template text, such as
+// releasenotes.DefaultTemplate
+func (n *ReleaseNotes) Render(text string) (string, error) {
+	return releasenotes.Render(n.internal(), text)
+}
+
+// Note is a calculation recorded as a git note on its commit
+type Note struct {
+	Commit      string       `json:"Commit"`
+	Version     string       `json:"Version"`
+	Tag         string       `json:"Tag"`
+	Branch      string       `json:"Branch"`
+	RecordedAt  time.Time    `json:"RecordedAt"`
+	Diagnostics *Diagnostics `json:"Diagnostics"`
+}
+
+// RecordNote stores the result as a git note on HEAD under
+// refs/notes/gitversion, replacing an earlier record for the commit.
+func (r *Result) RecordNote() (*Note, error) {
+	note, err := r.client.gv.RecordNote(r.diagnostics)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return newNote(note), nil
+}
+
+// Client calculates versions for the repository in the working directory
+type Client struct {
+	gv   *gitversion.GitVersion
+	opts Options
+}
+
+// New creates a client. The options are copied, later changes to opts
+// have no effect on the client.
+func New(opts Options) (*Client, error) {
+	gv, err := gitversion.New(opts.internal())
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &Client{gv: gv, opts: opts}, nil
+}
+
+// Config is the part of the effective configuration that commands built
+// on a calculation act on
+type Config struct {
+	Publishers   []PublisherConfig  `json:"publishers" yaml:"publishers"`
+	ReleaseNotes ReleaseNotesConfig `json:"release-notes" yaml:"release-notes"`
+	FileUpdates  []FileUpdateConfig `json:"file-updates" yaml:"file-updates"`
+	Cache        *CacheConfig       `json:"cache" yaml:"cache"`
+}
+
+// PublisherConfig enables an artifact publisher and carries its options
+type PublisherConfig struct {
+	Name    string            `json:"name" yaml:"name"`
+	Options map[string]string `json:"options" yaml:"options"`
+}
+
+// ReleaseNotesConfig configures the release-notes command
+type ReleaseNotesConfig struct {
+	// RepositoryURL such as https://github.com/owner/repo enables links to
+	// commits, pull requests and the comparison with the previous release
+	RepositoryURL string `json:"repository-url" yaml:"repository-url"`
+	// Template is the path of a Go text/template file replacing the default
+	Template string `json:"template" yaml:"template"`
+}
+
+// FileUpdateConfig names a file the update-files command writes the
+// version into and the writer that understands its format
+type FileUpdateConfig struct {
+	// Path is relative to the repository root
+	Path    string            `json:"path" yaml:"path"`
+	Writer  string            `json:"writer" yaml:"writer"`
+	Options map[string]string `json:"options" yaml:"options"`
+	// Create writes the file when it does not exist yet
+	Create bool `json:"create" yaml:"create"`
+}
+
+// CacheConfig bounds the calculation cache. MaxAge is a Go duration such
+// as "168h"; MaxSize is a number of bytes with an optional KB, MB or GB
+// suffix.
+type CacheConfig struct {
+	MaxAge  string `json:"max-age" yaml:"max-age"`
+	MaxSize string `json:"max-size" yaml:"max-size"`
+}
+
+// Config returns the effective configuration used by the client
+func (c *Client) Config() *Config {
+	return newConfig(c.gv.Config())
+}
+
+// Calculate calculates the version using the client's options
+func (c *Client) Calculate() (*Result, error) {
+	return c.CalculateContext(context.Background())
+}
+
+// CalculateContext is like Calculate but fails once ctx is done, killing
+// the git commands still running
+func (c *Client) CalculateContext(ctx context.Context) (*Result, error) {
+	diagnostics, err := c.gv.ExplainContext(ctx, c.opts.internal())
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	return &Result{
+		Variables:   *newVariables(c.gv.Variables(diagnostics)),
+		APIVersion:  APIVersion,
+		client:      c,
+		diagnostics: diagnostics,
+	}, nil
+}
+
+// BranchState classifies a branch in a branch report
+type BranchState string
+
+// Branch states reported by Client.BranchReport
+const (
+	// BranchActive branches have commits not yet merged or released
+	BranchActive BranchState = "active"
+	// BranchMerged branches are fully merged into main or develop
+	BranchMerged BranchState = "merged"
+	// BranchReleased branches are contained in a version tag
+	BranchReleased BranchState = "released"
+)
+
+// BranchStatus describes a branch's merge and release state
+type BranchStatus struct {
+	Branch    string      `json:"Branch"`
+	ConfigKey string      `json:"ConfigKey"` // "" for the built-in fallback
+	SHA       string      `json:"SHA"`
+	State     BranchState `json:"State"`
+	// MergedInto is the first target branch whose merge-base with the
+	// branch is the branch tip
+	MergedInto string `json:"MergedInto,omitempty"`
+	// ReleasedIn is the oldest version tag containing the branch tip
+	ReleasedIn string `json:"ReleasedIn,omitempty"`
+	// SafeToDelete is set for merged or released branches whose
+	// configuration marks them as release branches (release and hotfix)
+	SafeToDelete bool `json:"SafeToDelete"`
+}
+
+// BranchReport lists the repository's branches with their merge and release
+// state, flagging release and hotfix branches that are safe to delete.
+func (c *Client) BranchReport() ([]*BranchStatus, error) {
+	report, err := c.gv.BranchReport()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	statuses := make([]*BranchStatus, len(report))
+	for i, status := range report {
+		statuses[i] = newBranchStatus(status)
+	}
+	return statuses, nil
+}
+
+// Note returns the calculation recorded on the commit rev names, or nil
+// when none was recorded.
+func (c *Client) Note(rev string) (*Note, error) {
+	note, err := c.gv.ReadNote(rev)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return newNote(note), nil
+}
+
+// Notes returns every recorded calculation, oldest first
+func (c *Client) Notes() ([]*Note, error) {
+	notes, err := c.gv.Notes()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	converted := make([]*Note, len(notes))
+	for i, note := range notes {
+		converted[i] = newNote(note)
+	}
+	return converted, nil
+}
+
+// Calculate is a convenience wrapper around New and Client.Calculate
+func Calculate(opts Options) (*Result, error) {
+	client, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	return client.Calculate()
+}
+
+// CalculateContext is a convenience wrapper around New and
+// Client.CalculateContext
+func CalculateContext(ctx context.Context, opts Options) (*Result, error) {
+	client, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	return client.CalculateContext(ctx)
+}
+
+// CalculateProjects calculates the version of every project of a monorepo
+// in one invocation; see gitversion.NewProjects. Results are keyed by the
+// component of each project's configuration, or by its directory when it
+// has none.
+func CalculateProjects(opts Options) (map[string]*Result, error) {
+	return CalculateProjectsContext(context.Background(), opts)
+}
+
+// CalculateProjectsContext is like CalculateProjects but fails once ctx is
+// done
+func CalculateProjectsContext(ctx context.Context, opts Options) (map[string]*Result, error) {
+	projects, err := gitversion.NewProjects(opts.internal())
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	order := make([]string, 0, len(projects))
+	for dir := range projects {
+		order = append(order, dir)
+	}
+	sort.Strings(order)
+
+	results := make(map[string]*Result, len(projects))
+	dirs := make(map[string]string, len(projects))
+	task := progress.Start(opts.Progress, "projects", len(order))
+	for _, dir := range order {
+		gv := projects[dir]
+		key := strings.Trim(gv.Config().Component, "/")
+		if key == "" {
+			key = dir
+		}
+		if other, ok := dirs[key]; ok {
+			err := fmt.Errorf("projects %s and %s are both named %s", other, dir, key)
+			task.Finish(err)
+			return nil, err
+		}
+		dirs[key] = dir
+
+		projectOpts := opts
+		projectOpts.Project = dir
+		client := &Client{gv: gv, opts: projectOpts}
+		if results[key], err = client.CalculateContext(ctx); err != nil {
+			err = fmt.Errorf("project %s: %w", dir, err)
+			task.Finish(err)
+			return nil, err
+		}
+		task.Step(key)
+	}
+	task.Finish(nil)
+	return results, nil
+}
@@ -0,0 +1,794 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/internal/version"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	"github.com/VirtuallyScott/gitversion-go/pkg/gitversion"
+	"github.com/VirtuallyScott/gitversion-go/pkg/progress"
+	"github.com/VirtuallyScott/gitversion-go/pkg/releasenotes"
+	"github.com/VirtuallyScott/gitversion-go/pkg/semver"
+)
+
+func TestCalculate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test that requires a git repository in short mode")
+	}
+
+	result, err := Calculate(Options{Workflow: "gitflow"})
+	if err != nil {
+		t.Skipf("Not running inside a git repository: %v", err)
+	}
+
+	if result.APIVersion != APIVersion {
+		t.Errorf("APIVersion = %s, want %s", result.APIVersion, APIVersion)
+	}
+
+	text, err := result.Format(Text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != result.String() {
+		t.Errorf("Format(Text) = %s, want %s", text, result.String())
+	}
+
+	if result.Diagnostics() == nil || result.Diagnostics().Selected == nil {
+		t.Errorf("Expected diagnostics with a selected base version")
+	}
+}
+
+func TestClientMemoizesOneCalculation(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q")
+	runGit("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial commit")
+
+	client, err := New(Options{Dir: dir, NoCache: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	first, err := client.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	// The formatter asks what the calculation already did
+	commands := git.CommandsRun()
+	if _, err := first.Format(Describe); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if ran := git.CommandsRun() - commands; ran != 0 {
+		t.Errorf("Format() ran %d git commands, want none", ran)
+	}
+
+	// The next calculation starts afresh
+	runGit("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "second commit")
+	second, err := client.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if second.Sha == first.Sha {
+		t.Errorf("second Calculate() = %.7s, want the new commit", second.Sha)
+	}
+}
+
+func TestCalculateContext(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CalculateContext(ctx, Options{Dir: dir, NoCache: true}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CalculateContext() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+
+	var records bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&records, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	// The outcome on an unborn branch does not matter, only what is logged
+	Calculate(Options{Dir: dir, NoCache: true, TargetBranch: "main", Logger: logger})
+	if !strings.Contains(records.String(), `"msg":"calculating version","branch":"main"`) {
+		t.Errorf("Logger received %q, want the calculation record", records.String())
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", dir},
+		{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial commit"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	calculate := func(level slog.Level) string {
+		t.Helper()
+		var records bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&records, &slog.HandlerOptions{Level: level}))
+		if _, err := Calculate(Options{Dir: dir, NoCache: true, Logger: logger}); err != nil {
+			t.Fatalf("Calculate() error = %v", err)
+		}
+		return records.String()
+	}
+
+	// Info explains the calculation, debug adds the git commands
+	info := calculate(slog.LevelInfo)
+	if !strings.Contains(info, `"msg":"selected base version"`) || strings.Contains(info, `"msg":"running git"`) {
+		t.Errorf("info records = %q, want the selection without git commands", info)
+	}
+	if debug := calculate(slog.LevelDebug); !strings.Contains(debug, `"msg":"running git"`) {
+		t.Errorf("debug records = %q, want the git commands", debug)
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	outside := t.TempDir()
+	if err := exec.Command("git", "-C", outside, "rev-parse", "--git-dir").Run(); err == nil {
+		t.Skip("the temporary directory is inside a git repository")
+	}
+	if _, err := Calculate(Options{Dir: outside, NoCache: true}); !errors.Is(err, ErrNotARepository) {
+		t.Errorf("Calculate() outside a repository error = %v, want ErrNotARepository", err)
+	}
+
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+
+	_, err := Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"assembly-informational-format={Missing}"}})
+	var configErr *ConfigError
+	if !errors.Is(err, ErrInvalidConfig) || !errors.As(err, &configErr) || configErr.Field != "assembly-informational-format" {
+		t.Errorf("Calculate() with an invalid format error = %v, want a ConfigError of assembly-informational-format", err)
+	}
+
+	// An unborn branch still has a version, but nothing to tag
+	result, err := Calculate(Options{Dir: dir, NoCache: true})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if _, err := result.CreateTag(TagOptions{AllowPrerelease: true}); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("CreateTag() before the first commit error = %v, want ErrNoCommits", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	runGit := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	origin := t.TempDir()
+	runGit(origin, "init", "-q", "-b", "main")
+	runGit(origin, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(origin, "tag", "v1.0.0")
+	clone := t.TempDir()
+	runGit(clone, "clone", "-q", "file://"+origin, ".")
+	// Released after the clone was made
+	runGit(origin, "tag", "v2.0.0")
+
+	result, err := Calculate(Options{Dir: clone, NoCache: true})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "1.") {
+		t.Errorf("Calculate() without Fetch = %s, want a 1.x version from the stale tags", result.MajorMinorPatch)
+	}
+
+	var fetches []progress.Event
+	reporter := progress.Func(func(event progress.Event) {
+		if event.Operation == "fetch" {
+			fetches = append(fetches, event)
+		}
+	})
+	result, err = Calculate(Options{Dir: clone, NoCache: true, Fetch: true, Progress: reporter})
+	if err != nil {
+		t.Fatalf("Calculate() with Fetch error = %v", err)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "2.") {
+		t.Errorf("Calculate() with Fetch = %s, want a 2.x version from the fetched tag", result.MajorMinorPatch)
+	}
+	if len(fetches) == 0 || !fetches[len(fetches)-1].Done {
+		t.Errorf("Calculate() with Fetch reported %v, want the fetch to start and finish", fetches)
+	}
+
+	if _, err := Calculate(Options{Dir: clone, NoCache: true, Fetch: true, Remote: "missing"}); err == nil {
+		t.Error("Calculate() fetching from a missing remote succeeded")
+	}
+}
+
+func TestRemoteName(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	runGit := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	upstream := t.TempDir()
+	runGit(upstream, "init", "-q", "-b", "main")
+	runGit(upstream, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(upstream, "tag", "v1.0.0")
+	fork := t.TempDir()
+	runGit(fork, "clone", "-q", "--bare", "file://"+upstream, ".")
+	clone := t.TempDir()
+	runGit(clone, "clone", "-q", "file://"+fork, ".")
+	runGit(clone, "remote", "add", "upstream", "file://"+upstream)
+	// Released upstream only, after the fork was made
+	runGit(upstream, "tag", "v2.0.0")
+
+	configFile := filepath.Join(t.TempDir(), "GitVersion.yml")
+	if err := os.WriteFile(configFile, []byte("remote-name: upstream\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The flag overrides the configuration
+	client, err := New(Options{Dir: clone, ConfigFiles: []string{configFile}, NoCache: true, Fetch: true, Remote: "origin"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if remote := client.Remote(); remote != "origin" {
+		t.Errorf("Remote() = %q, want origin", remote)
+	}
+	result, err := client.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "1.") {
+		t.Errorf("Calculate() fetching from the fork = %s, want a 1.x version", result.MajorMinorPatch)
+	}
+
+	client, err = New(Options{Dir: clone, ConfigFiles: []string{configFile}, NoCache: true, Fetch: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if remote := client.Remote(); remote != "upstream" {
+		t.Errorf("Remote() = %q, want upstream from remote-name", remote)
+	}
+	result, err = client.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "2.") {
+		t.Errorf("Calculate() fetching from upstream = %s, want a 2.x version", result.MajorMinorPatch)
+	}
+}
+
+func TestTagSelection(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit(nil, "init", "-q", "-b", "main")
+	runGit(nil, "commit", "-q", "--allow-empty", "-m", "release")
+	runGit([]string{"GIT_COMMITTER_DATE=2024-03-01T12:00:00+0000"}, "tag", "-a", "v2.0.0", "-m", "release 2.0.0")
+	runGit(nil, "commit", "-q", "--allow-empty", "-m", "experiment")
+	runGit(nil, "tag", "v3.0.0")
+
+	result, err := Calculate(Options{Dir: dir, NoCache: true})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "3.") || result.VersionSourceTagDate != "" {
+		t.Errorf("Calculate() = %s with tag date %q, want a 3.x version from the lightweight tag without a date", result.MajorMinorPatch, result.VersionSourceTagDate)
+	}
+
+	result, err = Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"tag-selection=AnnotatedOnly"}})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "2.") {
+		t.Errorf("Calculate() with AnnotatedOnly = %s, want a 2.x version from the annotated tag", result.MajorMinorPatch)
+	}
+	if want := "2024-03-01 12:00:00 +0000"; result.VersionSourceTagDate != want {
+		t.Errorf("VersionSourceTagDate = %q, want %q", result.VersionSourceTagDate, want)
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", "-b", "main")
+	runGit("commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit("tag", "v2.0.0")
+	runGit("branch", "hotfix")
+	runGit("commit", "-q", "--allow-empty", "-m", "feature")
+	runGit("tag", "v3.0.0")
+	runGit("commit", "-q", "--allow-empty", "-m", "another feature")
+	runGit("commit", "-q", "--allow-empty", "-m", "yet another feature")
+	runGit("checkout", "-q", "hotfix")
+	runGit("commit", "-q", "--allow-empty", "-m", "fix")
+	runGit("tag", "v2.0.1")
+	runGit("checkout", "-q", "main")
+	runGit("merge", "-q", "--no-ff", "-m", "Merge hotfix", "hotfix")
+
+	result, err := Calculate(Options{Dir: dir, NoCache: true})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.Describe, "v2.0.1-") {
+		t.Errorf("Describe = %s, want the nearest hotfix tag v2.0.1", result.Describe)
+	}
+
+	result, err = Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"latest-tag=Highest"}})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !strings.HasPrefix(result.Describe, "v3.0.0-") {
+		t.Errorf("Describe with latest-tag Highest = %s, want the highest tag v3.0.0", result.Describe)
+	}
+}
+
+func TestAllowDeepen(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	runGit := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	origin := t.TempDir()
+	runGit(origin, "init", "-q", "-b", "main")
+	commit := func(message string) {
+		runGit(origin, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", message)
+	}
+	commit("release")
+	runGit(origin, "tag", "v3.0.0")
+	// More than the first step of deepening
+	for i := 0; i < 70; i++ {
+		commit("fix: change")
+	}
+	clone := t.TempDir()
+	// As actions/checkout clones
+	runGit(clone, "clone", "-q", "--depth", "1", "--no-tags", "file://"+origin, ".")
+
+	result, err := Calculate(Options{Dir: clone, NoCache: true})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if !result.Diagnostics().Shallow {
+		t.Error("Expected the diagnostics of a shallow clone to say so")
+	}
+	if strings.HasPrefix(result.MajorMinorPatch, "3.") {
+		t.Errorf("Calculate() in a depth 1 clone = %s, expected v3.0.0 to be out of reach", result.MajorMinorPatch)
+	}
+
+	var steps []string
+	reporter := progress.Func(func(event progress.Event) {
+		if event.Operation == "deepen" && event.Message != "" {
+			steps = append(steps, event.Message)
+		}
+	})
+	result, err = Calculate(Options{Dir: clone, NoCache: true, AllowDeepen: true, Progress: reporter})
+	if err != nil {
+		t.Fatalf("Calculate() with AllowDeepen error = %v", err)
+	}
+	if len(steps) < 2 {
+		t.Errorf("Calculate() with AllowDeepen reported steps %q, want one per deepening", steps)
+	}
+	if !strings.HasPrefix(result.MajorMinorPatch, "3.0.") || result.Diagnostics().Shallow {
+		t.Errorf("Calculate() with AllowDeepen = %s (shallow: %t), want 3.0.x from the full history",
+			result.MajorMinorPatch, result.Diagnostics().Shallow)
+	}
+}
+
+func TestLinkedWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	runGit := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	mainDir := t.TempDir()
+	runGit(mainDir, "init", "-q", "-b", "main")
+	runGit(mainDir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit(mainDir, "tag", "v2.0.0")
+	linked := filepath.Join(t.TempDir(), "linked")
+	runGit(mainDir, "worktree", "add", "-q", "-b", "feature/x", linked)
+
+	result, err := Calculate(Options{Dir: linked})
+	if err != nil {
+		t.Fatalf("Calculate() in a linked worktree error = %v", err)
+	}
+	if branch := result.Diagnostics().Branch; branch != "feature/x" || !strings.HasPrefix(result.MajorMinorPatch, "2.") {
+		t.Errorf("Calculate() in a linked worktree = %s on %s, want 2.x on feature/x", result.MajorMinorPatch, branch)
+	}
+
+	// The worktrees share the cache of the main git directory
+	cacheDir, err := gitversion.CacheDir(linked)
+	if err != nil {
+		t.Fatalf("CacheDir() error = %v", err)
+	}
+	mainCacheDir, _ := gitversion.CacheDir(mainDir)
+	if cacheDir != mainCacheDir {
+		t.Errorf("CacheDir() of the worktree = %s, want %s", cacheDir, mainCacheDir)
+	}
+	if entries, err := gitversion.ListCache(cacheDir); err != nil || len(entries) != 1 || entries[0].Branch != "feature/x" {
+		t.Errorf("ListCache() = %v, %v; want the calculation of feature/x", entries, err)
+	}
+}
+
+func TestCommitScopes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit("init", "-q", "-b", "main")
+	writeFile("GitVersion.yml", "projects: [api, web]\ncommit-scopes:\n  api: api\n  ui: web\n")
+	writeFile("api/GitVersion.yml", "component: api\n")
+	writeFile("web/GitVersion.yml", "component: web\n")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial commit")
+	runGit("tag", "api/v2.0.0")
+	runGit("tag", "web/v3.0.0")
+	runGit("commit", "-q", "--allow-empty", "-m", "feat(api): pagination")
+	runGit("commit", "-q", "--allow-empty", "-m", "fix(ui): dark mode")
+	runGit("commit", "-q", "--allow-empty", "-m", "fix(ui): contrast")
+
+	var projects []string
+	reporter := progress.Func(func(event progress.Event) {
+		if event.Operation == "projects" && event.Message != "" {
+			projects = append(projects, event.Message)
+		}
+	})
+	results, err := CalculateProjects(Options{Dir: dir, NoCache: true, Progress: reporter})
+	if err != nil {
+		t.Fatalf("CalculateProjects() error = %v", err)
+	}
+	if strings.Join(projects, ",") != "api,web" {
+		t.Errorf("CalculateProjects() reported projects %q, want api and web", projects)
+	}
+	for component, want := range map[string]int{"api": 1, "web": 2} {
+		result := results[component]
+		if result == nil {
+			t.Fatalf("CalculateProjects() = %v, want a result for %s", results, component)
+		}
+		if result.CommitsSinceVersionSource != want {
+			t.Errorf("%s: CommitsSinceVersionSource = %d, want %d", component, result.CommitsSinceVersionSource, want)
+		}
+	}
+}
+
+func TestTagPrefix(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", "-b", "main")
+	runGit("commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit("tag", "release-2.2.0")
+	runGit("commit", "-q", "--allow-empty", "-m", "fix")
+	runGit("tag", "v0.5.0")
+	runGit("commit", "-q", "--allow-empty", "-m", "another fix")
+
+	for _, latest := range []string{"Nearest", "Highest"} {
+		result, err := Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"tag-prefix=release-", "latest-tag=" + latest}})
+		if err != nil {
+			t.Fatalf("Calculate() error = %v", err)
+		}
+		if !strings.HasPrefix(result.MajorMinorPatch, "2.2.") {
+			t.Errorf("latest-tag %s: Calculate() = %s, want a 2.2.x version from release-2.2.0", latest, result.MajorMinorPatch)
+		}
+	}
+
+	// Created tags get the prefix too, so they are read back
+	runGit("config", "user.name", "Test")
+	runGit("config", "user.email", "test@example.com")
+	client, err := New(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"tag-prefix=release-"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := client.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	tagResult, err := result.CreateTag(TagOptions{AllowPrerelease: true})
+	if err != nil {
+		t.Fatalf("CreateTag() error = %v", err)
+	}
+	if want := "release-" + result.MajorMinorPatch + result.PreReleaseTagWithDash; tagResult.Tag != want || result.TagName() != want {
+		t.Errorf("CreateTag() = %s, TagName() = %s, want %s", tagResult.Tag, result.TagName(), want)
+	}
+	runGit("commit", "-q", "--allow-empty", "-m", "after the release")
+	again, err := Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"tag-prefix=release-"}})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if again.VersionSourceSha != result.Sha {
+		t.Errorf("after tagging %s the version source is %.7s, want the tagged commit %.7s", tagResult.Tag, again.VersionSourceSha, result.Sha)
+	}
+
+	if _, err := Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"tag-prefix=["}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Calculate() with an invalid tag-prefix error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestApplyTagOnAnotherCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", "-b", "main")
+	runGit("commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit("commit", "-q", "--allow-empty", "-m", "fix")
+
+	result, err := Calculate(Options{Dir: dir, NoCache: true, OverrideConfig: []string{"branches.main.auto-tag=true"}})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	// Someone else already released this version from the previous commit
+	runGit("tag", result.TagName(), "HEAD~1")
+
+	if tagResult, err := result.ApplyTag(); err == nil {
+		t.Errorf("ApplyTag() = %+v, want an error for %s on another commit", tagResult, result.TagName())
+	}
+	if _, err := result.CreateTag(TagOptions{AllowPrerelease: true}); err == nil {
+		t.Errorf("CreateTag() should fail for %s on another commit", result.TagName())
+	}
+}
+
+// TestTypesMirrorPkgGitversion catches fields added to pkg/gitversion that
+// the conversions at the API boundary do not copy yet
+func TestTypesMirrorPkgGitversion(t *testing.T) {
+	tests := []struct {
+		ours, theirs interface{}
+	}{
+		{Options{}, gitversion.Options{}},
+		{Variables{}, gitversion.JSONOutput{}},
+		{BranchConfig{}, gitversion.BranchConfigOutput{}},
+		{Diagnostics{}, gitversion.Diagnostics{}},
+		{BaseVersion{}, version.BaseVersion{}},
+		{TagResult{}, gitversion.TagResult{}},
+		{TagOptions{}, gitversion.TagOptions{}},
+		{Note{}, gitversion.Note{}},
+		{BranchStatus{}, gitversion.BranchStatus{}},
+		{BranchConfiguration{}, config.BranchConfiguration{}},
+		{PreventIncrement{}, config.PreventIncrementConfiguration{}},
+		{Version{}, semver.Version{}},
+		{ReleaseNotes{}, releasenotes.Notes{}},
+		{ReleaseNotesSection{}, releasenotes.Section{}},
+		{ReleaseNotesEntry{}, releasenotes.Entry{}},
+		{PullRequest{}, releasenotes.PullRequest{}},
+		{PublisherConfig{}, config.PublisherConfig{}},
+		{ReleaseNotesConfig{}, config.ReleaseNotesConfig{}},
+		{FileUpdateConfig{}, config.FileUpdateConfig{}},
+		{CacheConfig{}, config.CacheConfig{}},
+	}
+	for _, tt := range tests {
+		ours, theirs := reflect.TypeOf(tt.ours), reflect.TypeOf(tt.theirs)
+		if ours.NumField() != theirs.NumField() {
+			t.Errorf("%s has %d fields, %s has %d", ours, ours.NumField(), theirs, theirs.NumField())
+			continue
+		}
+		for i := 0; i < ours.NumField(); i++ {
+			our, their := ours.Field(i), theirs.Field(i)
+			if our.Name != their.Name || our.Tag != their.Tag {
+				t.Errorf("%s field %s `%s`, want %s `%s` like %s", ours, our.Name, our.Tag, their.Name, their.Tag, theirs)
+			}
+		}
+	}
+}
+
+func TestDiagnosticsRoundTrip(t *testing.T) {
+	diagnostics := &gitversion.Diagnostics{
+		Branch:     "release/1.2.0",
+		BranchType: version.Release,
+		BranchConfig: &config.BranchConfiguration{
+			Mode:             config.DeploymentContinuousDelivery,
+			Label:            "beta",
+			Increment:        config.IncrementMinor,
+			PreventIncrement: &config.PreventIncrementConfiguration{WhenCurrentCommitTagged: true},
+			SourceBranches:   []string{"develop", "main"},
+			AutoTag:          true,
+		},
+		Candidates: []*version.BaseVersion{{SemanticVersion: &semver.Version{Major: 1, Minor: 2}, Source: "branch name"}},
+		Version:    &semver.Version{Major: 1, Minor: 2, PreRelease: "beta.1", Build: "3"},
+	}
+	got := newDiagnostics(diagnostics)
+	if !reflect.DeepEqual(got.internal(), diagnostics) {
+		t.Errorf("round trip = %+v, want %+v", got.internal(), diagnostics)
+	}
+
+	// The JSON of --explain stays as it was before the copy
+	ours, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	theirs, err := json.Marshal(diagnostics)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(ours) != string(theirs) {
+		t.Errorf("JSON = %s, want %s", ours, theirs)
+	}
+}
+
+func TestReleaseNotesRoundTrip(t *testing.T) {
+	notes := releasenotes.New("1.2.0", "v1.2.0", "v1.1.0", "2024-05-01", "https://github.com/owner/repo", []releasenotes.Commit{
+		{SHA: "abc1234567", Subject: "feat(api): add widgets (#12)"},
+		{SHA: "def7654321", Subject: "fix!: drop v0 paths"},
+	})
+	if got := newReleaseNotes(notes).internal(); !reflect.DeepEqual(got, notes) {
+		t.Errorf("round trip = %+v, want %+v", got, notes)
+	}
+
+	ours, err := newReleaseNotes(notes).Render(releasenotes.DefaultTemplate)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if theirs, _ := releasenotes.Render(notes, releasenotes.DefaultTemplate); ours != theirs {
+		t.Errorf("Render() = %q, want %q", ours, theirs)
+	}
+}
+
+func TestVariablesRoundTrip(t *testing.T) {
+	number := 3
+	output := &gitversion.JSONOutput{
+		Major:            1,
+		Minor:            2,
+		PreReleaseNumber: &number,
+		FullSemVer:       "1.2.0-beta.3+4",
+		BranchConfig:     &gitversion.BranchConfigOutput{Key: "release", Label: "beta", PreReleaseWeight: 30000},
+		Partial:          true,
+	}
+	if got := newVariables(output).internal(); !reflect.DeepEqual(got, output) {
+		t.Errorf("round trip = %+v, want %+v", got, output)
+	}
+
+	variables := newVariables(output)
+	if value, err := variables.Variable("fullsemver"); err != nil || value != "1.2.0-beta.3+4" {
+		t.Errorf("Variable(fullsemver) = %q, %v, want 1.2.0-beta.3+4", value, err)
+	}
+	if expanded, err := variables.Expand("{Major}.{Minor}-{PreReleaseNumber}"); err != nil || expanded != "1.2-3" {
+		t.Errorf("Expand() = %q, %v, want 1.2-3", expanded, err)
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	formats := map[OutputFormat]gitversion.OutputFormat{
+		Text:               gitversion.Text,
+		JSON:               gitversion.JSON,
+		AssemblySemVer:     gitversion.AssemblySemVer,
+		AssemblySemFileVer: gitversion.AssemblySemFileVer,
+		BuildServer:        gitversion.BuildServer,
+		GitHubActions:      gitversion.GitHubActions,
+		Describe:           gitversion.Describe,
+		Env:                gitversion.Env,
+		Shell:              gitversion.Shell,
+		GoLdflags:          gitversion.GoLdflags,
+		Docker:             gitversion.Docker,
+	}
+	for ours, theirs := range formats {
+		if string(ours) != string(theirs) {
+			t.Errorf("format %q, want %q", ours, theirs)
+		}
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	commandErr := &git.CommandError{Args: []string{"tag", "v1.0.0"}, Stderr: "fatal: tag 'v1.0.0' already exists", Err: errors.New("exit status 128")}
+	err := fmt.Errorf("project api: %w", convertError(fmt.Errorf("failed to create tag: %w", commandErr)))
+
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) || gitErr.Stderr != commandErr.Stderr || gitErr.Args[0] != "tag" {
+		t.Errorf("errors.As(%v) = %+v, want the git command", err, gitErr)
+	}
+	if err.Error() != "project api: failed to create tag: "+commandErr.Error() || gitErr.Error() != commandErr.Error() {
+		t.Errorf("Error() = %q, want the message of pkg/gitversion", err)
+	}
+
+	if err := convertError(fmt.Errorf("tagging: %w", gitversion.ErrNoCommits)); !errors.Is(err, ErrNoCommits) || errors.Is(err, ErrNotARepository) {
+		t.Errorf("errors.Is(%v) does not tell ErrNoCommits apart", err)
+	}
+	if convertError(nil) != nil {
+		t.Error("convertError(nil) != nil")
+	}
+}
@@ -0,0 +1,191 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Event describes a single update from a long running operation
+type Event struct {
+	Operation string
+	Message   string
+	Current   int
+	Total     int // zero when the amount of work is unknown
+	Done      bool
+	Err       error
+}
+
+// Percent returns the completion percentage, or -1 when the total is unknown
+func (e Event) Percent() int {
+	if e.Total <= 0 {
+		return -1
+	}
+	return e.Current * 100 / e.Total
+}
+
+// Reporter receives progress events
+type Reporter interface {
+	Report(event Event)
+}
+
+// Func adapts a plain callback into a Reporter for library consumers
+type Func func(event Event)
+
+func (f Func) Report(event Event) {
+	f(event)
+}
+
+type nopReporter struct{}
+
+func (nopReporter) Report(Event) {}
+
+// Nop discards all events
+var Nop Reporter = nopReporter{}
+
+// Mode selects how progress is rendered on the command line
+type Mode string
+
+const (
+	ModeAuto  Mode = "auto"
+	ModePlain Mode = "plain"
+	ModeNone  Mode = "none"
+)
+
+// NewForMode returns a reporter for the given mode writing to f.
+// Auto mode renders a spinner on terminals and stays silent otherwise.
+func NewForMode(mode Mode, f *os.File) (Reporter, error) {
+	switch mode {
+	case ModeAuto, "":
+		if isTerminal(f) {
+			return NewTerminal(f), nil
+		}
+		return Nop, nil
+	case ModePlain:
+		return NewPlain(f), nil
+	case ModeNone:
+		return Nop, nil
+	default:
+		return nil, fmt.Errorf("unknown progress mode: %s", mode)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// PlainReporter writes one line per event, suitable for CI logs
+type PlainReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewPlain(w io.Writer) *PlainReporter {
+	return &PlainReporter{w: w}
+}
+
+func (p *PlainReporter) Report(event Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "[PROGRESS] %s\n", formatEvent(event))
+}
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// TerminalReporter redraws a single status line with a spinner
+type TerminalReporter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	frame int
+}
+
+func NewTerminal(w io.Writer) *TerminalReporter {
+	return &TerminalReporter{w: w}
+}
+
+func (t *TerminalReporter) Report(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if event.Done {
+		// Clear the status line; failures stay visible
+		fmt.Fprint(t.w, "\r\033[K")
+		if event.Err != nil {
+			fmt.Fprintf(t.w, "%s\n", formatEvent(event))
+		}
+		return
+	}
+
+	frame := spinnerFrames[t.frame%len(spinnerFrames)]
+	t.frame++
+	fmt.Fprintf(t.w, "\r\033[K%s %s", frame, formatEvent(event))
+}
+
+func formatEvent(event Event) string {
+	var b strings.Builder
+	b.WriteString(event.Operation)
+	if event.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(event.Message)
+	}
+	if pct := event.Percent(); pct >= 0 {
+		fmt.Fprintf(&b, " [%d/%d %d%%]", event.Current, event.Total, pct)
+	}
+	if event.Done {
+		if event.Err != nil {
+			fmt.Fprintf(&b, " failed: %v", event.Err)
+		} else {
+			b.WriteString(" done")
+		}
+	}
+	return b.String()
+}
+
+// Task tracks the progress of a single operation
+type Task struct {
+	reporter  Reporter
+	operation string
+	current   int
+	total     int
+}
+
+// Start begins reporting an operation with the given amount of work
+func Start(reporter Reporter, operation string, total int) *Task {
+	if reporter == nil {
+		reporter = Nop
+	}
+	task := &Task{reporter: reporter, operation: operation, total: total}
+	reporter.Report(Event{Operation: operation, Total: total})
+	return task
+}
+
+// Step marks one unit of work as completed
+func (t *Task) Step(message string) {
+	t.current++
+	t.reporter.Report(Event{
+		Operation: t.operation,
+		Message:   message,
+		Current:   t.current,
+		Total:     t.total,
+	})
+}
+
+// Finish reports completion of the operation
+func (t *Task) Finish(err error) {
+	t.reporter.Report(Event{
+		Operation: t.operation,
+		Current:   t.current,
+		Total:     t.total,
+		Done:      true,
+		Err:       err,
+	})
+}
@@ -0,0 +1,74 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTaskReportsEvents(t *testing.T) {
+	var events []Event
+	task := Start(Func(func(e Event) { events = append(events, e) }), "fetch", 2)
+	task.Step("origin")
+	task.Step("upstream")
+	task.Finish(nil)
+
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(events))
+	}
+	if events[2].Current != 2 || events[2].Message != "upstream" {
+		t.Errorf("Unexpected step event: %+v", events[2])
+	}
+	if !events[3].Done || events[3].Err != nil {
+		t.Errorf("Expected successful done event, got %+v", events[3])
+	}
+	if events[2].Percent() != 100 {
+		t.Errorf("Percent() = %d, want 100", events[2].Percent())
+	}
+}
+
+func TestStartWithNilReporter(t *testing.T) {
+	task := Start(nil, "noop", 0)
+	task.Step("step")
+	task.Finish(nil)
+}
+
+func TestPlainReporter(t *testing.T) {
+	var buf bytes.Buffer
+	task := Start(NewPlain(&buf), "unshallow", 4)
+	task.Step("deepen 50")
+	task.Finish(errors.New("timeout"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	if lines[1] != "[PROGRESS] unshallow: deepen 50 [1/4 25%]" {
+		t.Errorf("Unexpected step line: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "failed: timeout") {
+		t.Errorf("Expected failure in final line, got %q", lines[2])
+	}
+}
+
+func TestTerminalReporterClearsLine(t *testing.T) {
+	var buf bytes.Buffer
+	task := Start(NewTerminal(&buf), "fetch", 0)
+	task.Step("origin")
+	task.Finish(nil)
+
+	output := buf.String()
+	if !strings.HasSuffix(output, "\r\033[K") {
+		t.Errorf("Expected status line to be cleared, got %q", output)
+	}
+	if strings.Contains(output, "\n") {
+		t.Errorf("Terminal reporter should not emit newlines on success, got %q", output)
+	}
+}
+
+func TestNewForModeInvalid(t *testing.T) {
+	if _, err := NewForMode(Mode("fancy"), nil); err == nil {
+		t.Errorf("Expected error for unknown mode")
+	}
+}
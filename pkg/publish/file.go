@@ -0,0 +1,55 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// filePublisher writes the version to a file as JSON or plain text
+type filePublisher struct {
+	path   string
+	format string
+}
+
+func newFilePublisher(options map[string]string) (Publisher, error) {
+	format := options["format"]
+	if format == "" {
+		format = "json"
+	}
+	return &filePublisher{path: options["path"], format: format}, nil
+}
+
+func (p *filePublisher) Name() string {
+	return "file"
+}
+
+func (p *filePublisher) Validate() error {
+	if p.path == "" {
+		return fmt.Errorf("path option is required")
+	}
+	if p.format != "json" && p.format != "text" {
+		return fmt.Errorf("unsupported format: %s (json|text)", p.format)
+	}
+	return nil
+}
+
+func (p *filePublisher) Publish(ctx context.Context, result *Result) error {
+	var data []byte
+	if p.format == "text" {
+		data = []byte(result.Variables.FullSemVer + "\n")
+	} else {
+		var err error
+		data, err = json.MarshalIndent(result.Variables, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		data = append(data, '\n')
+	}
+
+	if err := os.WriteFile(p.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.path, err)
+	}
+	return nil
+}
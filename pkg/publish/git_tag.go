@@ -0,0 +1,60 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+)
+
+// gitTagPublisher creates the version tag in the local repository
+type gitTagPublisher struct {
+	repo    *git.Repository
+	message string
+	force   bool
+}
+
+func newGitTagPublisher(options map[string]string) (Publisher, error) {
+	return &gitTagPublisher{
+		repo:    git.NewRepository(),
+		message: options["message"],
+		force:   options["force"] == "true",
+	}, nil
+}
+
+func (p *gitTagPublisher) Name() string {
+	return "git-tag"
+}
+
+func (p *gitTagPublisher) Validate() error {
+	if !p.repo.IsRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+	return nil
+}
+
+func (p *gitTagPublisher) Publish(ctx context.Context, result *Result) error {
+	if !result.AutoTag && !p.force {
+		return fmt.Errorf("tag creation is not enabled for branch %s", result.Variables.BranchName)
+	}
+	if p.repo.TagExists(result.Tag) {
+		head, err := p.repo.GetSHA()
+		if err != nil {
+			return err
+		}
+		tagged, err := p.repo.GetCommitSHAForTag(result.Tag)
+		if err != nil {
+			return err
+		}
+		if tagged != head {
+			return fmt.Errorf("tag %s already exists on commit %.7s", result.Tag, tagged)
+		}
+		return nil
+	}
+
+	message := p.message
+	if message == "" {
+		message = fmt.Sprintf("Release %s", result.Tag)
+	}
+	return p.repo.CreateTag(result.Tag, message)
+}
@@ -0,0 +1,80 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// giteaReleasePublisher creates a release on a Gitea or Forgejo instance.
+// Both serve the same release API, so one publisher covers them.
+type giteaReleasePublisher struct {
+	url        string
+	repository string
+	tokenEnv   string
+	client     *http.Client
+}
+
+func newGiteaReleasePublisher(options map[string]string) (Publisher, error) {
+	p := &giteaReleasePublisher{
+		url:        strings.TrimSuffix(options["url"], "/"),
+		repository: options["repository"],
+		tokenEnv:   options["token-env"],
+		client:     &http.Client{Timeout: httpTimeout},
+	}
+	// Gitea and Forgejo Actions set the GitHub compatible variables
+	if p.url == "" {
+		p.url = strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/")
+	}
+	if p.repository == "" {
+		p.repository = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if p.tokenEnv == "" {
+		p.tokenEnv = "GITEA_TOKEN"
+	}
+	return p, nil
+}
+
+func (p *giteaReleasePublisher) Name() string {
+	return "gitea-release"
+}
+
+func (p *giteaReleasePublisher) Validate() error {
+	if p.url == "" {
+		return fmt.Errorf("url option must be set to the instance URL")
+	}
+	if !strings.Contains(p.repository, "/") {
+		return fmt.Errorf("repository option must be owner/name")
+	}
+	if os.Getenv(p.tokenEnv) == "" {
+		return fmt.Errorf("environment variable %s is not set", p.tokenEnv)
+	}
+	return nil
+}
+
+func (p *giteaReleasePublisher) Publish(ctx context.Context, result *Result) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"tag_name":         result.Tag,
+		"target_commitish": result.Variables.Sha,
+		"name":             result.Tag,
+		"prerelease":       result.Variables.PreReleaseTag != "",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/repos/%s/releases", p.url, p.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "token "+os.Getenv(p.tokenEnv))
+
+	return doRequest(p.client, req, p.Name())
+}
@@ -0,0 +1,75 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// githubReleasePublisher creates a GitHub release for the version tag
+type githubReleasePublisher struct {
+	repository string
+	tokenEnv   string
+	apiURL     string
+	client     *http.Client
+}
+
+func newGitHubReleasePublisher(options map[string]string) (Publisher, error) {
+	p := &githubReleasePublisher{
+		repository: options["repository"],
+		tokenEnv:   options["token-env"],
+		apiURL:     strings.TrimSuffix(options["api-url"], "/"),
+		client:     &http.Client{Timeout: httpTimeout},
+	}
+	if p.repository == "" {
+		p.repository = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if p.tokenEnv == "" {
+		p.tokenEnv = "GITHUB_TOKEN"
+	}
+	if p.apiURL == "" {
+		p.apiURL = "https://api.github.com"
+	}
+	return p, nil
+}
+
+func (p *githubReleasePublisher) Name() string {
+	return "github-release"
+}
+
+func (p *githubReleasePublisher) Validate() error {
+	if !strings.Contains(p.repository, "/") {
+		return fmt.Errorf("repository option must be owner/name")
+	}
+	if os.Getenv(p.tokenEnv) == "" {
+		return fmt.Errorf("environment variable %s is not set", p.tokenEnv)
+	}
+	return nil
+}
+
+func (p *githubReleasePublisher) Publish(ctx context.Context, result *Result) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"tag_name":         result.Tag,
+		"target_commitish": result.Variables.Sha,
+		"name":             result.Tag,
+		"prerelease":       result.Variables.PreReleaseTag != "",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/releases", p.apiURL, p.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+os.Getenv(p.tokenEnv))
+
+	return doRequest(p.client, req, p.Name())
+}
@@ -0,0 +1,126 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+)
+
+// Result is the calculated version handed to publishers
+type Result struct {
+	Variables *v1.Variables
+	Tag       string
+	// AutoTag reports whether the branch configuration allows tag creation
+	AutoTag bool
+}
+
+// Publisher publishes a calculated version somewhere: a tag, a release,
+// a file or a remote endpoint.
+type Publisher interface {
+	Name() string
+	// Validate checks the publisher's configuration before anything is published
+	Validate() error
+	Publish(ctx context.Context, result *Result) error
+}
+
+// Factory creates a publisher from its configured options
+type Factory func(options map[string]string) (Publisher, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a publisher available under name for use in the
+// publishers section of the configuration.
+func Register(name string, factory Factory) error {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		return fmt.Errorf("publisher name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("publisher %s has no factory", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[key]; exists {
+		return fmt.Errorf("publisher %s is already registered", name)
+	}
+	registry[key] = factory
+	return nil
+}
+
+// Registered returns the names of all registered publishers, sorted
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the publisher registered under name
+func New(name string, options map[string]string) (Publisher, error) {
+	registryMu.RLock()
+	factory, exists := registry[strings.ToLower(strings.TrimSpace(name))]
+	registryMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown publisher: %s", name)
+	}
+	if options == nil {
+		options = map[string]string{}
+	}
+	return factory(options)
+}
+
+// Build creates and validates every configured publisher
+func Build(configs []config.PublisherConfig) ([]Publisher, error) {
+	var publishers []Publisher
+	for _, cfg := range configs {
+		publisher, err := New(cfg.Name, cfg.Options)
+		if err != nil {
+			return nil, err
+		}
+		if err := publisher.Validate(); err != nil {
+			return nil, fmt.Errorf("publisher %s: %w", publisher.Name(), err)
+		}
+		publishers = append(publishers, publisher)
+	}
+	return publishers, nil
+}
+
+// Run validates all configured publishers and then publishes the result with
+// each of them in order. Nothing is published if any configuration is invalid.
+func Run(ctx context.Context, configs []config.PublisherConfig, result *Result) error {
+	publishers, err := Build(configs)
+	if err != nil {
+		return err
+	}
+
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, result); err != nil {
+			return fmt.Errorf("publisher %s failed: %w", publisher.Name(), err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register("git-tag", newGitTagPublisher)
+	Register("github-release", newGitHubReleasePublisher)
+	Register("gitea-release", newGiteaReleasePublisher)
+	Register("file", newFilePublisher)
+	Register("webhook", newWebhookPublisher)
+}
@@ -0,0 +1,302 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/git"
+	"github.com/VirtuallyScott/gitversion-go/pkg/config"
+	v1 "github.com/VirtuallyScott/gitversion-go/pkg/gitversion/v1"
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+func testResult() *Result {
+	return &Result{
+		Variables: &v1.Variables{
+			Major:         1,
+			Minor:         2,
+			Patch:         3,
+			PreReleaseTag: "beta.1",
+			FullSemVer:    "1.2.3-beta.1+4",
+			BranchName:    "release/1.2.3",
+			Sha:           "abc1234567890def",
+		},
+		Tag: "v1.2.3-beta.1",
+	}
+}
+
+type recordingPublisher struct {
+	published *bool
+}
+
+func (p *recordingPublisher) Name() string    { return "recording" }
+func (p *recordingPublisher) Validate() error { return nil }
+func (p *recordingPublisher) Publish(ctx context.Context, result *Result) error {
+	*p.published = true
+	return nil
+}
+
+func TestRegisterAndRun(t *testing.T) {
+	published := false
+	err := Register("recording", func(options map[string]string) (Publisher, error) {
+		return &recordingPublisher{published: &published}, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := Register("Recording", func(map[string]string) (Publisher, error) { return nil, nil }); err == nil {
+		t.Errorf("Expected error for duplicate registration")
+	}
+
+	if err := Run(context.Background(), []config.PublisherConfig{{Name: "recording"}}, testResult()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !published {
+		t.Errorf("Expected custom publisher to be invoked")
+	}
+}
+
+func TestRunValidatesBeforePublishing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "version.txt")
+	configs := []config.PublisherConfig{
+		{Name: "file", Options: map[string]string{"path": path, "format": "text"}},
+		{Name: "webhook"}, // missing url
+	}
+
+	if err := Run(context.Background(), configs, testResult()); err == nil {
+		t.Fatalf("Expected validation error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Nothing should be published when validation fails")
+	}
+}
+
+func TestUnknownPublisher(t *testing.T) {
+	if _, err := New("carrier-pigeon", nil); err == nil {
+		t.Errorf("Expected error for unknown publisher")
+	}
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "version.json")
+	configs := []config.PublisherConfig{{Name: "file", Options: map[string]string{"path": path}}}
+
+	if err := Run(context.Background(), configs, testResult()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	var variables v1.Variables
+	if err := json.Unmarshal(data, &variables); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if variables.FullSemVer != "1.2.3-beta.1+4" {
+		t.Errorf("FullSemVer = %s, want 1.2.3-beta.1+4", variables.FullSemVer)
+	}
+}
+
+func TestGitTagPublisher(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q")
+	runGit("commit", "-q", "--allow-empty", "-m", "initial commit")
+	runGit("tag", "v1.2.3-beta.1")
+	publisher := &gitTagPublisher{repo: git.NewRepositoryAt(dir), force: true}
+
+	// The tag is already on HEAD
+	if err := publisher.Publish(context.Background(), testResult()); err != nil {
+		t.Errorf("Publish() with the tag on HEAD error = %v", err)
+	}
+
+	runGit("commit", "-q", "--allow-empty", "-m", "fix")
+	if err := publisher.Publish(context.Background(), testResult()); err == nil {
+		t.Error("Publish() with the tag on another commit should fail")
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var received map[string]interface{}
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_WEBHOOK_SECRET", "s3cret")
+	configs := []config.PublisherConfig{{
+		Name:    "webhook",
+		Options: map[string]string{"url": server.URL, "secret-env": "TEST_WEBHOOK_SECRET"},
+	}}
+
+	if err := Run(context.Background(), configs, testResult()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received["Tag"] != "v1.2.3-beta.1" {
+		t.Errorf("Tag = %v, want v1.2.3-beta.1", received["Tag"])
+	}
+	if authorization != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want bearer secret", authorization)
+	}
+}
+
+func TestGitHubReleasePublisher(t *testing.T) {
+	var path string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "token")
+	configs := []config.PublisherConfig{{
+		Name: "github-release",
+		Options: map[string]string{
+			"repository": "owner/repo",
+			"token-env":  "TEST_GITHUB_TOKEN",
+			"api-url":    server.URL,
+		},
+	}}
+
+	if err := Run(context.Background(), configs, testResult()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/repos/owner/repo/releases" {
+		t.Errorf("Request path = %s, want /repos/owner/repo/releases", path)
+	}
+	if payload["prerelease"] != true {
+		t.Errorf("Expected prerelease release, got %v", payload["prerelease"])
+	}
+}
+
+func TestGitHubReleasePublisherRequiresToken(t *testing.T) {
+	t.Setenv("TEST_MISSING_TOKEN", "")
+	publisher, _ := New("github-release", map[string]string{"repository": "owner/repo", "token-env": "TEST_MISSING_TOKEN"})
+	if err := publisher.Validate(); err == nil || !strings.Contains(err.Error(), "TEST_MISSING_TOKEN") {
+		t.Errorf("Expected missing token error, got %v", err)
+	}
+}
+
+func TestGiteaReleasePublisher(t *testing.T) {
+	var path, authorization string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GITEA_TOKEN", "secret")
+	configs := []config.PublisherConfig{{
+		Name: "gitea-release",
+		Options: map[string]string{
+			"url":        server.URL + "/",
+			"repository": "owner/repo",
+			"token-env":  "TEST_GITEA_TOKEN",
+		},
+	}}
+
+	if err := Run(context.Background(), configs, testResult()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/api/v1/repos/owner/repo/releases" {
+		t.Errorf("Request path = %s, want /api/v1/repos/owner/repo/releases", path)
+	}
+	if authorization != "token secret" {
+		t.Errorf("Authorization = %q, want %q", authorization, "token secret")
+	}
+	if payload["tag_name"] != "v1.2.3-beta.1" || payload["prerelease"] != true {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+}
+
+func TestGiteaReleasePublisherDefaultsFromActions(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITEA_TOKEN", "secret")
+	publisher, _ := New("gitea-release", nil)
+	if err := publisher.Validate(); err == nil || !strings.Contains(err.Error(), "url") {
+		t.Errorf("Expected missing url error, got %v", err)
+	}
+
+	t.Setenv("GITHUB_SERVER_URL", "https://codeberg.org")
+	publisher, _ = New("gitea-release", nil)
+	if err := publisher.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestWebhookPublisherRetries(t *testing.T) {
+	retry.SetPolicy("webhook", retry.Policy{MaxAttempts: 3, InitialDelay: time.Millisecond})
+	defer retry.ResetPolicies()
+
+	var requests int
+	var lastBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewDecoder(r.Body).Decode(&lastBody)
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	configs := []config.PublisherConfig{{Name: "webhook", Options: map[string]string{"url": server.URL}}}
+	if err := Run(context.Background(), configs, testResult()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if lastBody["Tag"] != "v1.2.3-beta.1" {
+		t.Errorf("Expected request body to be resent, got %v", lastBody)
+	}
+}
+
+func TestWebhookPublisherDoesNotRetryClientErrors(t *testing.T) {
+	retry.SetPolicy("webhook", retry.Policy{MaxAttempts: 3, InitialDelay: time.Millisecond})
+	defer retry.ResetPolicies()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	configs := []config.PublisherConfig{{Name: "webhook", Options: map[string]string{"url": server.URL}}}
+	if err := Run(context.Background(), configs, testResult()); err == nil {
+		t.Fatalf("Expected error for unauthorized response")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
@@ -0,0 +1,98 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/VirtuallyScott/gitversion-go/pkg/retry"
+)
+
+// httpTimeout bounds every request made by the HTTP based publishers
+const httpTimeout = 30 * time.Second
+
+// webhookPublisher posts the version variables as JSON to a URL
+type webhookPublisher struct {
+	url       string
+	secretEnv string
+	client    *http.Client
+}
+
+func newWebhookPublisher(options map[string]string) (Publisher, error) {
+	return &webhookPublisher{
+		url:       options["url"],
+		secretEnv: options["secret-env"],
+		client:    &http.Client{Timeout: httpTimeout},
+	}, nil
+}
+
+func (p *webhookPublisher) Name() string {
+	return "webhook"
+}
+
+func (p *webhookPublisher) Validate() error {
+	if p.url == "" {
+		return fmt.Errorf("url option is required")
+	}
+	if p.secretEnv != "" && os.Getenv(p.secretEnv) == "" {
+		return fmt.Errorf("environment variable %s is not set", p.secretEnv)
+	}
+	return nil
+}
+
+func (p *webhookPublisher) Publish(ctx context.Context, result *Result) error {
+	payload, err := json.Marshal(struct {
+		Tag       string      `json:"Tag"`
+		Variables interface{} `json:"Variables"`
+	}{Tag: result.Tag, Variables: result.Variables})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.secretEnv != "" {
+		req.Header.Set("Authorization", "Bearer "+os.Getenv(p.secretEnv))
+	}
+
+	return doRequest(p.client, req, p.Name())
+}
+
+// doRequest sends req under the retry policy of operation and turns non-2xx
+// responses into errors. Network errors, 429 and 5xx responses are retried.
+func doRequest(client *http.Client, req *http.Request, operation string) error {
+	return retry.Do(req.Context(), operation, func(ctx context.Context) error {
+		attempt := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.Permanent(err)
+			}
+			attempt.Body = body
+		}
+
+		resp, err := client.Do(attempt)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			err := fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, bytes.TrimSpace(body))
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+				return err
+			}
+			return retry.Permanent(err)
+		}
+		return nil
+	})
+}
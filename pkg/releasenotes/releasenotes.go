@@ -0,0 +1,205 @@
+// Package releasenotes renders release notes from the commits of a release
+// through a Go text/template, so teams can match their own changelog format.
+package releasenotes
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
+)
+
+// Commit is a commit that goes into the notes
+type Commit struct {
+	SHA     string
+	Subject string
+	Body    string
+}
+
+// Entry is a commit as rendered in the notes. Conventional commit subjects
+// are split into type, scope and description.
+type Entry struct {
+	SHA      string
+	ShortSHA string
+	// Type and Scope are empty for subjects that are no conventional commit
+	Type  string
+	Scope string
+	// Subject is the description without type, scope and squash merge
+	// suffix, e.g. "add widgets" for "feat(api): add widgets (#12)"
+	Subject  string
+	Breaking bool
+	// URL links the commit when a repository URL is configured
+	URL          string
+	PullRequests []PullRequest
+}
+
+// PullRequest is a "#123" reference in a commit subject
+type PullRequest struct {
+	Number int
+	URL    string
+}
+
+// Section groups entries under a heading
+type Section struct {
+	Title   string
+	Entries []*Entry
+}
+
+// Notes is the data handed to the template
+type Notes struct {
+	// Version is the semantic version without build metadata
+	Version     string
+	Tag         string
+	PreviousTag string
+	// Date is the commit date of the release, YYYY-MM-DD
+	Date          string
+	RepositoryURL string
+	// CompareURL links the changes since PreviousTag, when both are known
+	CompareURL string
+	// Sections holds only non-empty sections, in a fixed order
+	Sections []*Section
+	// Entries holds every commit, newest first
+	Entries []*Entry
+}
+
+// sections maps conventional commit types to section titles. Breaking
+// changes are listed under their own section first; types not listed here
+// end up under "Other Changes".
+var sections = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+}
+
+var (
+	conventionalPattern = lazyregexp.New(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	pullRequestPattern  = lazyregexp.New(`#(\d+)\b`)
+	// squashSuffixPattern matches the " (#123)" squash merges append
+	squashSuffixPattern = lazyregexp.New(`\s*\(#\d+\)$`)
+)
+
+// New builds the notes for a release. repositoryURL, such as
+// https://github.com/owner/repo, enables commit, pull request and compare
+// links; it may be empty.
+func New(version, tag, previousTag, date, repositoryURL string, commits []Commit) *Notes {
+	repositoryURL = strings.TrimSuffix(repositoryURL, "/")
+	notes := &Notes{
+		Version:       version,
+		Tag:           tag,
+		PreviousTag:   previousTag,
+		Date:          date,
+		RepositoryURL: repositoryURL,
+	}
+	if repositoryURL != "" && previousTag != "" && tag != "" {
+		notes.CompareURL = fmt.Sprintf("%s/compare/%s...%s", repositoryURL, previousTag, tag)
+	}
+
+	for _, commit := range commits {
+		notes.Entries = append(notes.Entries, newEntry(commit, repositoryURL))
+	}
+
+	breaking := &Section{Title: "Breaking Changes"}
+	grouped := make([]*Section, len(sections))
+	for i, s := range sections {
+		grouped[i] = &Section{Title: s.title}
+	}
+	other := &Section{Title: "Other Changes"}
+
+	for _, entry := range notes.Entries {
+		if entry.Breaking {
+			breaking.Entries = append(breaking.Entries, entry)
+			continue
+		}
+		section := other
+		for i, s := range sections {
+			for _, t := range s.types {
+				if entry.Type == t {
+					section = grouped[i]
+				}
+			}
+		}
+		section.Entries = append(section.Entries, entry)
+	}
+
+	for _, section := range append(append([]*Section{breaking}, grouped...), other) {
+		if len(section.Entries) > 0 {
+			notes.Sections = append(notes.Sections, section)
+		}
+	}
+	return notes
+}
+
+func newEntry(commit Commit, repositoryURL string) *Entry {
+	entry := &Entry{SHA: commit.SHA, ShortSHA: commit.SHA, Subject: commit.Subject}
+	if len(entry.ShortSHA) > 7 {
+		entry.ShortSHA = entry.ShortSHA[:7]
+	}
+	if matches := conventionalPattern.FindStringSubmatch(commit.Subject); matches != nil {
+		entry.Type = strings.ToLower(matches[1])
+		entry.Scope = matches[2]
+		entry.Breaking = matches[3] == "!"
+		entry.Subject = matches[4]
+	}
+	if strings.Contains(commit.Body, "BREAKING CHANGE") {
+		entry.Breaking = true
+	}
+
+	if repositoryURL != "" {
+		entry.URL = repositoryURL + "/commit/" + commit.SHA
+	}
+	for _, match := range pullRequestPattern.FindAllStringSubmatch(entry.Subject) {
+		number, _ := strconv.Atoi(match[1])
+		pr := PullRequest{Number: number}
+		if repositoryURL != "" {
+			pr.URL = fmt.Sprintf("%s/pull/%d", repositoryURL, number)
+		}
+		entry.PullRequests = append(entry.PullRequests, pr)
+	}
+	// The reference is kept in PullRequests, where templates can link it
+	entry.Subject = squashSuffixPattern.ReplaceAllString(entry.Subject, "")
+	return entry
+}
+
+// DefaultTemplate renders Markdown with one section per change type
+const DefaultTemplate = `## {{if .CompareURL}}[{{.Version}}]({{.CompareURL}}){{else}}{{.Version}}{{end}}{{if .Date}} ({{.Date}}){{end}}
+{{range .Sections}}
+### {{.Title}}
+{{range .Entries}}
+- {{if .Scope}}**{{.Scope}}:** {{end}}{{.Subject}}
+{{- range .PullRequests}} ({{if .URL}}[#{{.Number}}]({{.URL}}){{else}}#{{.Number}}{{end}}){{end}}
+{{- if .URL}} ([{{.ShortSHA}}]({{.URL}})){{else}} ({{.ShortSHA}}){{end}}
+{{- end}}
+{{end}}`
+
+// Render renders notes with the Go template text
+func Render(notes *Notes, text string) (string, error) {
+	tmpl, err := template.New("release-notes").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid release notes template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, notes); err != nil {
+		return "", fmt.Errorf("failed to render release notes: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// LoadTemplate returns the template in path, or DefaultTemplate when path
+// is empty
+func LoadTemplate(path string) (string, error) {
+	if path == "" {
+		return DefaultTemplate, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read release notes template: %w", err)
+	}
+	return string(data), nil
+}
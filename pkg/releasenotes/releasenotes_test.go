@@ -0,0 +1,94 @@
+package releasenotes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testCommits = []Commit{
+	{SHA: "1111111aaaaaaa", Subject: "docs: update readme"},
+	{SHA: "2222222bbbbbbb", Subject: "refactor!: drop v1 API"},
+	{SHA: "3333333ccccccc", Subject: "fix(cli): handle empty config (#42)"},
+	{SHA: "4444444ddddddd", Subject: "feat: add widgets", Body: "BREAKING CHANGE: widgets replace gadgets"},
+	{SHA: "5555555eeeeeee", Subject: "feat(api): paginate results"},
+	{SHA: "6666666fffffff", Subject: "Update dependencies"},
+}
+
+func TestNew(t *testing.T) {
+	notes := New("1.2.0", "v1.2.0", "v1.1.0", "2025-01-15", "https://github.com/owner/repo/", testCommits)
+
+	if notes.CompareURL != "https://github.com/owner/repo/compare/v1.1.0...v1.2.0" {
+		t.Errorf("CompareURL = %s", notes.CompareURL)
+	}
+
+	var titles []string
+	for _, section := range notes.Sections {
+		var subjects []string
+		for _, entry := range section.Entries {
+			subjects = append(subjects, entry.Subject)
+		}
+		titles = append(titles, section.Title+": "+strings.Join(subjects, ", "))
+	}
+	expected := []string{
+		"Breaking Changes: drop v1 API, add widgets",
+		"Features: paginate results",
+		"Bug Fixes: handle empty config",
+		"Other Changes: update readme, Update dependencies",
+	}
+	if strings.Join(titles, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Sections =\n%s\nwant\n%s", strings.Join(titles, "\n"), strings.Join(expected, "\n"))
+	}
+
+	fix := notes.Entries[2]
+	if fix.Type != "fix" || fix.Scope != "cli" || fix.ShortSHA != "3333333" {
+		t.Errorf("fix entry = %+v", fix)
+	}
+	if fix.URL != "https://github.com/owner/repo/commit/3333333ccccccc" {
+		t.Errorf("fix URL = %s", fix.URL)
+	}
+	if len(fix.PullRequests) != 1 || fix.PullRequests[0].Number != 42 || fix.PullRequests[0].URL != "https://github.com/owner/repo/pull/42" {
+		t.Errorf("fix PullRequests = %+v", fix.PullRequests)
+	}
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	notes := New("1.2.0", "v1.2.0", "", "2025-01-15", "", testCommits[2:3])
+
+	rendered, err := Render(notes, DefaultTemplate)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	expected := "## 1.2.0 (2025-01-15)\n\n### Bug Fixes\n\n- **cli:** handle empty config (#42) (3333333)\n"
+	if rendered != expected {
+		t.Errorf("Render() =\n%q\nwant\n%q", rendered, expected)
+	}
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.tmpl")
+	custom := "Release {{.Tag}}\n{{range .Entries}}* {{.Subject}}\n{{end}}"
+	if err := os.WriteFile(path, []byte(custom), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	text, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	rendered, err := Render(New("1.2.0", "v1.2.0", "", "", "", testCommits[4:]), text)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if rendered != "Release v1.2.0\n* paginate results\n* Update dependencies\n" {
+		t.Errorf("Render() = %q", rendered)
+	}
+
+	if _, err := Render(&Notes{}, "{{.Unknown}}"); err == nil {
+		t.Errorf("Expected an error for an unknown field")
+	}
+	if _, err := LoadTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Errorf("Expected an error for a missing template file")
+	}
+}
@@ -0,0 +1,199 @@
+// Package retry retries remote operations with exponential backoff and
+// jitter. Limits are looked up per operation from a central table so the
+// configuration file can tune fetches and API calls in one place.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Policy controls how often and how fast an operation is retried
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 1 are treated as 1.
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Multiplier grows the delay after each failed attempt
+	Multiplier float64
+	// Jitter is the fraction of each delay that is randomized, between
+	// 0 (fixed delays) and 1 (anywhere from zero to the full delay).
+	Jitter float64
+}
+
+// DefaultPolicy is used for operations without a configured policy
+var DefaultPolicy = Policy{
+	MaxAttempts:  3,
+	InitialDelay: 500 * time.Millisecond,
+	MaxDelay:     10 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.5,
+}
+
+// Default is the operation name whose policy applies to all operations
+// without one of their own.
+const Default = "default"
+
+var (
+	policiesMu sync.RWMutex
+	policies   = make(map[string]Policy)
+)
+
+// SetPolicy configures the policy for an operation, or for all
+// operations without their own policy when operation is Default.
+func SetPolicy(operation string, policy Policy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies[operation] = policy
+}
+
+// ResetPolicies removes all configured policies
+func ResetPolicies() {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies = make(map[string]Policy)
+}
+
+// PolicyFor returns the policy configured for operation, falling back to
+// the Default operation and then to DefaultPolicy.
+func PolicyFor(operation string) Policy {
+	policiesMu.RLock()
+	defer policiesMu.RUnlock()
+	if policy, ok := policies[operation]; ok {
+		return policy
+	}
+	if policy, ok := policies[Default]; ok {
+		return policy
+	}
+	return DefaultPolicy
+}
+
+// Policies are the policies of a configuration by operation, with the
+// Default entry applying to operations without their own
+type Policies map[string]Policy
+
+// For returns the policy of operation, falling back to the Default entry
+// and then to DefaultPolicy
+func (p Policies) For(operation string) Policy {
+	if policy, ok := p[operation]; ok {
+		return policy
+	}
+	if policy, ok := p[Default]; ok {
+		return policy
+	}
+	return DefaultPolicy
+}
+
+type policiesKey struct{}
+
+// WithPolicies returns a copy of ctx carrying policies. Do looks operations
+// up in them instead of the policies set with SetPolicy.
+func WithPolicies(ctx context.Context, policies Policies) context.Context {
+	return context.WithValue(ctx, policiesKey{}, policies)
+}
+
+// policyFor returns the policy of operation from ctx, else from the
+// policies set with SetPolicy
+func policyFor(ctx context.Context, operation string) Policy {
+	if policies, ok := ctx.Value(policiesKey{}).(Policies); ok {
+		return policies.For(operation)
+	}
+	return PolicyFor(operation)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. an authentication
+// failure. Do returns the wrapped error immediately.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Do runs fn until it succeeds, returns a permanent error, the attempts
+// configured for operation are used up or ctx is done. The policy comes
+// from the policies of ctx when it carries any.
+func Do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	return DoWithPolicy(ctx, policyFor(ctx, operation), fn)
+}
+
+// DoWithPolicy is like Do with an explicit policy
+func DoWithPolicy(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= attempts {
+			break
+		}
+
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+
+	if attempts > 1 {
+		return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+	}
+	return err
+}
+
+// delay returns the wait before the attempt following the given one
+func (p Policy) delay(attempt int) time.Duration {
+	delay := float64(p.InitialDelay)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= delay * jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,135 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var fastPolicy = Policy{MaxAttempts: 3, InitialDelay: time.Millisecond, Multiplier: 2}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := DoWithPolicy(context.Background(), fastPolicy, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoGivesUp(t *testing.T) {
+	cause := errors.New("connection reset")
+	calls := 0
+	err := DoWithPolicy(context.Background(), fastPolicy, func(ctx context.Context) error {
+		calls++
+		return cause
+	})
+	if !errors.Is(err, cause) {
+		t.Errorf("Expected wrapped cause, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	cause := errors.New("401 Unauthorized")
+	calls := 0
+	err := DoWithPolicy(context.Background(), fastPolicy, func(ctx context.Context) error {
+		calls++
+		return Permanent(cause)
+	})
+	if err != cause {
+		t.Errorf("Expected unwrapped cause, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestDoHonorsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := Policy{MaxAttempts: 5, InitialDelay: time.Hour}
+
+	err := DoWithPolicy(ctx, policy, func(ctx context.Context) error {
+		cancel()
+		return errors.New("timeout")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPolicyDelay(t *testing.T) {
+	policy := Policy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 3}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 300 * time.Millisecond},
+		{3, 900 * time.Millisecond},
+		{4, time.Second},
+		{50, time.Second},
+	}
+	for _, tt := range tests {
+		if d := policy.delay(tt.attempt); d != tt.expected {
+			t.Errorf("delay(%d) = %s, want %s", tt.attempt, d, tt.expected)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := policy.delay(1); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("Jittered delay %s out of range", d)
+		}
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	defer ResetPolicies()
+
+	if PolicyFor("fetch") != DefaultPolicy {
+		t.Errorf("Expected DefaultPolicy without configuration")
+	}
+
+	SetPolicy(Default, fastPolicy)
+	fetch := Policy{MaxAttempts: 5}
+	SetPolicy("fetch", fetch)
+
+	if PolicyFor("fetch") != fetch {
+		t.Errorf("Expected operation policy for fetch")
+	}
+	if PolicyFor("webhook") != fastPolicy {
+		t.Errorf("Expected configured default policy for webhook")
+	}
+}
+
+func TestDoUsesPoliciesOfContext(t *testing.T) {
+	defer ResetPolicies()
+	SetPolicy("fetch", Policy{MaxAttempts: 5, InitialDelay: time.Millisecond})
+
+	if (Policies{}).For("fetch") != DefaultPolicy {
+		t.Errorf("Expected DefaultPolicy from empty policies")
+	}
+
+	ctx := WithPolicies(context.Background(), Policies{Default: {MaxAttempts: 2, InitialDelay: time.Millisecond}})
+	calls := 0
+	Do(ctx, "fetch", func(ctx context.Context) error {
+		calls++
+		return errors.New("connection reset")
+	})
+	if calls != 2 {
+		t.Errorf("Expected the 2 attempts of the context's policies, got %d", calls)
+	}
+}
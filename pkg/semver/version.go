@@ -2,8 +2,11 @@ package semver
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+
+	"github.com/VirtuallyScott/gitversion-go/internal/lazyregexp"
 )
 
 type Version struct {
@@ -14,7 +17,7 @@ type Version struct {
 	Build      string
 }
 
-var semverPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([a-zA-Z0-9.-]+))?(?:\+([a-zA-Z0-9.+-]+))?$`)
+var semverPattern = lazyregexp.New(`^v?(\d+)\.(\d+)\.(\d+)(?:-([a-zA-Z0-9.-]+))?(?:\+([a-zA-Z0-9.+-]+))?$`)
 
 func Parse(version string) (*Version, error) {
 	matches := semverPattern.FindStringSubmatch(version)
@@ -120,11 +123,55 @@ func (v *Version) Compare(other *Version) int {
 		return 0
 	}
 
-	// Compare prerelease strings lexicographically
-	if v.PreRelease < other.PreRelease {
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// comparePreRelease compares dot-separated prerelease identifiers using
+// SemVer 2.0.0 precedence: numeric identifiers compare numerically and
+// sort before alphanumeric ones, which compare bytewise.
+func comparePreRelease(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				if aNum < bNum {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	// A larger set of identifiers has higher precedence when all others are equal
+	switch {
+	case len(aParts) < len(bParts):
 		return -1
+	case len(aParts) > len(bParts):
+		return 1
 	}
-	return 1
+	return 0
+}
+
+// Sort orders versions by ascending precedence. Versions with equal
+// precedence keep their relative order so results are deterministic.
+func Sort(versions []*Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) < 0
+	})
 }
 
 // GreaterThan returns true if this version is greater than the other version
@@ -143,6 +190,50 @@ func (v *Version) Copy() *Version {
 	}
 }
 
+// PreReleaseLabel returns the prerelease without its trailing numeric
+// identifier, e.g. "beta" for 1.2.0-beta.4.
+func (v *Version) PreReleaseLabel() string {
+	if i := strings.LastIndex(v.PreRelease, "."); i != -1 {
+		if _, err := strconv.Atoi(v.PreRelease[i+1:]); err == nil {
+			return v.PreRelease[:i]
+		}
+	}
+	return v.PreRelease
+}
+
+// PreReleaseNumber returns the trailing numeric identifier of the
+// prerelease, e.g. 4 for 1.2.0-beta.4. ok is false when there is none.
+func (v *Version) PreReleaseNumber() (number int, ok bool) {
+	i := strings.LastIndex(v.PreRelease, ".")
+	if i == -1 {
+		return 0, false
+	}
+	number, err := strconv.Atoi(v.PreRelease[i+1:])
+	return number, err == nil
+}
+
+// NuGetPadding is the width prerelease numbers are zero-padded to in NuGet
+// versions, GitVersion's legacy-semver-padding default
+const NuGetPadding = 4
+
+var nuGetUnsafePattern = lazyregexp.New(`[^a-zA-Z0-9-]`)
+
+// NuGetVersion returns the version in NuGet's legacy SemVer 1.0 form, e.g.
+// 1.2.0-beta0004 for 1.2.0-beta.4+7. Build metadata is dropped and the
+// prerelease may not contain dots, so its number is zero-padded to padding
+// digits instead: legacy feeds compare prereleases as strings, and beta0010
+// has to sort after beta0009.
+func (v *Version) NuGetVersion(padding int) string {
+	if v.PreRelease == "" {
+		return v.MajorMinorPatch()
+	}
+	preRelease := nuGetUnsafePattern.ReplaceAllString(v.PreReleaseLabel(), "")
+	if number, ok := v.PreReleaseNumber(); ok {
+		preRelease += fmt.Sprintf("%0*d", padding, number)
+	}
+	return v.MajorMinorPatch() + "-" + preRelease
+}
+
 func (v *Version) AssemblySemVer() string {
 	return fmt.Sprintf("%d.%d.%d.0", v.Major, v.Minor, v.Patch)
 }
@@ -155,6 +246,45 @@ func (v *Version) MajorMinorPatch() string {
 	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
 }
 
+var branchNameUnsafePattern = lazyregexp.New(`[^a-zA-Z0-9]`)
+
 func SanitizeBranchName(branch string) string {
-	return regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(branch, "-")
+	return branchNameUnsafePattern.ReplaceAllString(branch, "-")
+}
+
+var (
+	semverUnsafePattern    = lazyregexp.New(`[^a-zA-Z0-9-]`)
+	dashRunPattern         = lazyregexp.New(`-{2,}`)
+	dockerTagUnsafePattern = lazyregexp.New(`[^a-zA-Z0-9_.-]`)
+)
+
+// EscapeBranchName escapes branch the way GitVersion's EscapedBranchName
+// does: every character not allowed in a semver identifier, path
+// separators included, becomes a dash, runs of dashes collapse into one,
+// and case is preserved, so feature/JIRA_12 becomes feature-JIRA-12.
+// Replacing rather than dropping keeps release/1.2 and release/12 apart.
+func EscapeBranchName(branch string) string {
+	branch = semverUnsafePattern.ReplaceAllString(branch, "-")
+	return dashRunPattern.ReplaceAllString(branch, "-")
+}
+
+// maxDockerTagLength is the longest tag a Docker registry accepts
+const maxDockerTagLength = 128
+
+// DockerTag turns s, such as a version or branch name, into a valid Docker
+// image tag: anything outside [A-Za-z0-9_.-] becomes a dash, so the + of
+// build metadata does, the tag may not start with a period or dash, and it
+// is cut to 128 characters.
+func DockerTag(s string) string {
+	tag := dockerTagUnsafePattern.ReplaceAllString(s, "-")
+	tag = strings.TrimLeft(tag, ".-")
+	if len(tag) > maxDockerTagLength {
+		tag = tag[:maxDockerTagLength]
+	}
+	return tag
+}
+
+// DockerTagBranchName is the DockerTag of branch
+func DockerTagBranchName(branch string) string {
+	return DockerTag(branch)
 }
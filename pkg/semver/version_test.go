@@ -1,6 +1,7 @@
 package semver
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -176,6 +177,112 @@ func TestAssemblyVersions(t *testing.T) {
 	}
 }
 
+func TestComparePreReleasePrecedence(t *testing.T) {
+	// Ordered by ascending precedence as in the SemVer 2.0.0 specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.2",
+		"1.0.0-alpha.10",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+	}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		lower, _ := Parse(ordered[i])
+		higher, _ := Parse(ordered[i+1])
+		if lower.Compare(higher) >= 0 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+		if higher.Compare(lower) <= 0 {
+			t.Errorf("Expected %s > %s", ordered[i+1], ordered[i])
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	inputs := []string{"1.10.0", "1.2.0", "1.0.0-alpha.10", "1.0.0-alpha.2", "0.9.0"}
+	var versions []*Version
+	for _, input := range inputs {
+		v, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		versions = append(versions, v)
+	}
+
+	Sort(versions)
+
+	expected := []string{"0.9.0", "1.0.0-alpha.2", "1.0.0-alpha.10", "1.2.0", "1.10.0"}
+	for i, v := range versions {
+		if v.String() != expected[i] {
+			t.Errorf("Sort()[%d] = %s, want %s", i, v.String(), expected[i])
+		}
+	}
+}
+
+func TestPreReleaseLabelAndNumber(t *testing.T) {
+	tests := []struct {
+		preRelease string
+		label      string
+		number     int
+		hasNumber  bool
+	}{
+		{"beta.4", "beta", 4, true},
+		{"rc.12", "rc", 12, true},
+		{"beta", "beta", 0, false},
+		{"alpha.beta", "alpha.beta", 0, false},
+		{"feature-x.1", "feature-x", 1, true},
+		{"", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		v := &Version{Major: 1, PreRelease: tt.preRelease}
+		if got := v.PreReleaseLabel(); got != tt.label {
+			t.Errorf("PreReleaseLabel(%q) = %q, want %q", tt.preRelease, got, tt.label)
+		}
+		number, ok := v.PreReleaseNumber()
+		if number != tt.number || ok != tt.hasNumber {
+			t.Errorf("PreReleaseNumber(%q) = %d, %t; want %d, %t", tt.preRelease, number, ok, tt.number, tt.hasNumber)
+		}
+	}
+}
+
+func TestNuGetVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"1.2.3+7", "1.2.3"},
+		{"1.2.3-alpha.5+10", "1.2.3-alpha0005"},
+		{"1.2.3-beta", "1.2.3-beta"},
+		{"1.2.3-feature.login.12", "1.2.3-featurelogin0012"},
+		{"1.2.3-rc.12345", "1.2.3-rc12345"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.version)
+		if err != nil {
+			t.Fatalf("Parse(%s) failed: %v", tt.version, err)
+		}
+		if got := v.NuGetVersion(NuGetPadding); got != tt.expected {
+			t.Errorf("NuGetVersion(%s) = %s, want %s", tt.version, got, tt.expected)
+		}
+	}
+
+	// Padded numbers keep string order and numeric order in step
+	nine := &Version{Major: 1, PreRelease: "alpha.9"}
+	ten := &Version{Major: 1, PreRelease: "alpha.10"}
+	if nine.NuGetVersion(NuGetPadding) >= ten.NuGetVersion(NuGetPadding) {
+		t.Errorf("%s should sort before %s", nine.NuGetVersion(NuGetPadding), ten.NuGetVersion(NuGetPadding))
+	}
+}
+
 func TestSanitizeBranchName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -208,3 +315,56 @@ func TestSanitizeBranchName(t *testing.T) {
 		})
 	}
 }
+
+func TestEscapeBranchName(t *testing.T) {
+	tests := []struct {
+		branch    string
+		escaped   string
+		dockerTag string
+	}{
+		{"develop", "develop", "develop"},
+		{"feature/user-auth", "feature-user-auth", "feature-user-auth"},
+		{"feature/JIRA_12 Login", "feature-JIRA-12-Login", "feature-JIRA_12-Login"},
+		{"release/1.2", "release-1-2", "release-1.2"},
+		{"-hotfix/x@y", "-hotfix-x-y", "hotfix-x-y"},
+		{"feature//a--b_-c", "feature-a-b-c", "feature--a--b_-c"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeBranchName(tt.branch); got != tt.escaped {
+			t.Errorf("EscapeBranchName(%q) = %q, want %q", tt.branch, got, tt.escaped)
+		}
+		if got := DockerTagBranchName(tt.branch); got != tt.dockerTag {
+			t.Errorf("DockerTagBranchName(%q) = %q, want %q", tt.branch, got, tt.dockerTag)
+		}
+	}
+
+	// Distinct branches must not share an escaped name
+	if a, b := EscapeBranchName("release/1.2"), EscapeBranchName("release/12"); a == b {
+		t.Errorf("release/1.2 and release/12 both escape to %q", a)
+	}
+
+	long := DockerTagBranchName("feature/" + strings.Repeat("x", 200))
+	if len(long) != 128 {
+		t.Errorf("DockerTagBranchName length = %d, want 128", len(long))
+	}
+}
+
+func TestDockerTag(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"1.2.3-beta.4+5", "1.2.3-beta.4-5"},
+		{"1.2.3-feature_x+Branch.feature/x", "1.2.3-feature_x-Branch.feature-x"},
+		{".hidden", "hidden"},
+		{strings.Repeat("1", 200), strings.Repeat("1", 128)},
+	}
+
+	for _, tt := range tests {
+		if got := DockerTag(tt.version); got != tt.expected {
+			t.Errorf("DockerTag(%q) = %q, want %q", tt.version, got, tt.expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -179,6 +180,358 @@ func TestGitVersionCLI(t *testing.T) {
 	}
 }
 
+func TestQuietMode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	repoDir := t.TempDir()
+	initGit(t, repoDir)
+	createCommit(t, repoDir, "Initial commit")
+
+	run := func(args ...string) (string, string) {
+		t.Helper()
+		var stdout, stderr strings.Builder
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = repoDir
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("gitversion %v failed: %v\n%s", args, err, stderr.String())
+		}
+		return stdout.String(), stderr.String()
+	}
+
+	// --record-note reports on stderr; quiet silences it and stdout holds
+	// only the value
+	stdout, stderr := run("--show-variable", "MajorMinorPatch", "--record-note", "--progress", "plain")
+	if stdout != "1.0.1\n" {
+		t.Errorf("stdout = %q, want only the variable", stdout)
+	}
+	if !strings.Contains(stderr, "[INFO] Recorded") {
+		t.Errorf("stderr should report the recorded note, got %q", stderr)
+	}
+
+	stdout, stderr = run("-q", "--show-variable", "MajorMinorPatch", "--record-note", "--progress", "plain")
+	if stdout != "1.0.1\n" {
+		t.Errorf("quiet stdout = %q, want only the variable", stdout)
+	}
+	if stderr != "" {
+		t.Errorf("quiet stderr = %q, want nothing", stderr)
+	}
+}
+
+func TestTagCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	repoDir := t.TempDir()
+	initGit(t, repoDir)
+	createCommit(t, repoDir, "Initial commit")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		return strings.TrimSpace(string(output)), err
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	tag, err := run("tag", "--prefix", "release-")
+	if err != nil {
+		t.Fatalf("gitversion tag failed: %v", err)
+	}
+	if tag != "release-1.0.1" {
+		t.Errorf("tag = %q, want release-1.0.1", tag)
+	}
+	if kind := git("cat-file", "-t", tag); kind != "tag" {
+		t.Errorf("%s is a %s, want an annotated tag", tag, kind)
+	}
+
+	// Tagging the same commit again is not an error
+	if again, err := run("tag", "--prefix", "release-"); err != nil || again != tag {
+		t.Errorf("second gitversion tag = %q, %v; want %q", again, err, tag)
+	}
+
+	createBranch(t, repoDir, "feature/login")
+	createCommit(t, repoDir, "feat: login")
+	if _, err := run("tag"); err == nil {
+		t.Errorf("Expected gitversion tag to refuse a prerelease version")
+	}
+	tag, err = run("tag", "--allow-prerelease")
+	if err != nil {
+		t.Fatalf("gitversion tag --allow-prerelease failed: %v", err)
+	}
+	if !strings.HasPrefix(tag, "v1.") || !strings.Contains(tag, "-login.") {
+		t.Errorf("prerelease tag = %q, want a v1.x.y-login.N tag", tag)
+	}
+	if git("tag", "--points-at", "HEAD") != tag {
+		t.Errorf("%s should point at HEAD", tag)
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	repoDir := t.TempDir()
+	initGit(t, repoDir)
+	createCommit(t, repoDir, "Initial commit")
+	cacheDir := filepath.Join(repoDir, ".git", "gitversion_cache")
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(binaryPath, append([]string{"-q", "--show-variable", "SemVer"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("gitversion %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	entries := func() []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	semVer := run()
+	cached := entries()
+	if len(cached) != 1 {
+		t.Fatalf("cache entries = %v, want one", cached)
+	}
+
+	// Doctor the entry to tell a cached answer from a calculated one
+	data, err := os.ReadFile(cached[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	doctored := strings.Replace(string(data), `"SemVer": "`+semVer+`"`, `"SemVer": "9.9.9"`, 1)
+	if err := os.WriteFile(cached[0], []byte(doctored), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(); got != "9.9.9" {
+		t.Errorf("repeat run = %s, want the cached 9.9.9", got)
+	}
+	if got := run("--no-cache"); got != semVer {
+		t.Errorf("--no-cache = %s, want %s", got, semVer)
+	}
+
+	// New refs, options or configuration miss the cache
+	createTag(t, repoDir, "v2.0.0")
+	if got := run(); got == "9.9.9" || !strings.HasPrefix(got, "2.0.") {
+		t.Errorf("after tagging = %s, want a 2.0.x version", got)
+	}
+	run("--next-version", "3.0.0")
+	run("--override-config", "tag-prefix=release-")
+	if got := len(entries()); got != 4 {
+		t.Errorf("cache entries = %d, want 4", got)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	repoDir := t.TempDir()
+	initGit(t, repoDir)
+	createCommit(t, repoDir, "Initial commit")
+	outside := t.TempDir()
+	if exec.Command("git", "-C", outside, "rev-parse", "--git-dir").Run() == nil {
+		t.Skip("the temporary directory is inside a git repository")
+	}
+
+	exitCode := func(dir string, args ...string) int {
+		t.Helper()
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = dir
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		if err != nil {
+			t.Fatalf("gitversion %v failed: %v", args, err)
+		}
+		return 0
+	}
+
+	if code := exitCode(repoDir, "--require-stable"); code != 0 {
+		t.Errorf("--require-stable on main exited %d, want 0", code)
+	}
+	if code := exitCode(outside); code != 3 {
+		t.Errorf("outside a repository exited %d, want 3", code)
+	}
+	if code := exitCode(repoDir, "--override-config", "assembly-versioning-scheme=Bogus"); code != 4 {
+		t.Errorf("invalid configuration exited %d, want 4", code)
+	}
+
+	createBranch(t, repoDir, "feature/login")
+	createCommit(t, repoDir, "feat: login")
+	if code := exitCode(repoDir, "--require-stable"); code != 6 {
+		t.Errorf("--require-stable on a feature branch exited %d, want 6", code)
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	repoDir := t.TempDir()
+	initGit(t, repoDir)
+	createCommit(t, repoDir, "Initial commit")
+
+	var stderr strings.Builder
+	cmd := exec.Command(binaryPath, "--error-format", "json", "--override-config", "assembly-versioning-scheme=Bogus")
+	cmd.Dir = repoDir
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		t.Fatalf("Expected an invalid configuration to fail")
+	}
+
+	var record struct {
+		Code     string
+		ExitCode int
+		Message  string
+		Hint     string
+		Field    string
+	}
+	if err := json.Unmarshal([]byte(stderr.String()), &record); err != nil {
+		t.Fatalf("stderr is not a JSON error: %v\n%s", err, stderr.String())
+	}
+	if record.Code != "invalid-config" || record.ExitCode != 4 || record.Field != "assembly-versioning-scheme" ||
+		record.Message == "" || record.Hint == "" {
+		t.Errorf("error = %+v, want an invalid-config error of assembly-versioning-scheme with a hint", record)
+	}
+}
+
+func TestDocsCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	docsDir := t.TempDir()
+	cmd := exec.Command(binaryPath, "docs", "--man", "--markdown", "--dir", docsDir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gitversion docs failed: %v\n%s", err, output)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(docsDir, name))
+		if err != nil {
+			t.Fatalf("gitversion docs did not write %s: %v", name, err)
+		}
+		return string(data)
+	}
+	// The pages list the flags each command defines
+	if page := read("gitversion-tag.1"); !strings.Contains(page, `.B \-\-allow\-prerelease`) {
+		t.Errorf("gitversion-tag.1 should document --allow-prerelease:\n%s", page)
+	}
+	if page := read("gitversion-cache-clear.md"); !strings.Contains(page, "`--older-than duration`") {
+		t.Errorf("gitversion-cache-clear.md should document --older-than:\n%s", page)
+	}
+	if page := read("gitversion.md"); !strings.Contains(page, "`--require-stable`") || !strings.Contains(page, "(gitversion-tag.md)") {
+		t.Errorf("gitversion.md should document the calculate flags and link the commands:\n%s", page)
+	}
+}
+
+func TestApplySkipsBranchesWithoutAutoTag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	repoDir := t.TempDir()
+	initGit(t, repoDir)
+	createCommit(t, repoDir, "Initial commit")
+	createBranch(t, repoDir, "feature/login")
+	createCommit(t, repoDir, "feat: login")
+
+	cmd := exec.Command(binaryPath, "--apply", "--show-variable", "FullSemVer")
+	cmd.Dir = repoDir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("gitversion --apply on a feature branch failed: %v\n%s", err, stderr.String())
+	}
+	if version := strings.TrimSpace(string(output)); !strings.Contains(version, "-login.") {
+		t.Errorf("gitversion --apply printed %q, want the feature branch version", version)
+	}
+	if !strings.Contains(stderr.String(), "[INFO] Skipping the tag: auto-tag is off for branch feature/login") {
+		t.Errorf("stderr = %q, want the skip reported", stderr.String())
+	}
+
+	tags := exec.Command("git", "tag")
+	tags.Dir = repoDir
+	if out, err := tags.Output(); err != nil || len(out) != 0 {
+		t.Errorf("git tag = %q, %v; want no tag created", out, err)
+	}
+}
+
+func TestCommitMessageIncrements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	binaryPath := buildStatic(t)
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"breaking change footer", "fix: parse dates\n\nBREAKING CHANGE: local times rejected", "2.0.0"},
+		{"breaking marker", "feat(api)!: drop v1 endpoints", "2.0.0"},
+		{"feature", "feat: search", "1.1.0"},
+		{"bump message", "chore: settings\n\n+semver: minor", "1.1.0"},
+		{"breaking change in prose", "fix: tidy\n\nThis is not a breaking change.", "1.0.1"},
+		{"bump message in prose", "docs: bumps\n\nWrite +semver: major in a message to bump the major version.", "1.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			initGit(t, repoDir)
+			createCommit(t, repoDir, "Initial commit")
+			createTag(t, repoDir, "v1.0.0")
+			createCommit(t, repoDir, tt.message)
+
+			cmd := exec.Command(binaryPath, "--show-variable", "MajorMinorPatch")
+			cmd.Dir = repoDir
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("gitversion failed: %v\n%s", err, output)
+			}
+			if got := strings.TrimSpace(string(output)); got != tt.want {
+				t.Errorf("MajorMinorPatch after %q = %s, want %s", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
 func initGit(t *testing.T, dir string) {
 	commands := [][]string{
 		{"git", "init"},
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// startupBudget bounds the CLI cold start. gitversion runs at the start of
+// every CI job, so its fixed cost has to stay negligible.
+const startupBudget = 10 * time.Millisecond
+
+// startupBudgetEnv opts in to TestStartupBudget. Wall-clock budgets fail on
+// loaded or slow machines, so the check only runs where it is asked for,
+// as make test-startup does.
+const startupBudgetEnv = "GITVERSION_TEST_STARTUP"
+
+// buildStatic builds the CLI with the static build profile of make build-static
+func buildStatic(tb testing.TB) string {
+	tb.Helper()
+
+	projectRoot, err := filepath.Abs("..")
+	if err != nil {
+		tb.Fatalf("Failed to get project root: %v", err)
+	}
+	binaryPath := filepath.Join(tb.TempDir(), "gitversion")
+
+	buildCmd := exec.Command("go", "build", "-trimpath", "-ldflags", "-s -w", "-o", binaryPath, "./gitversion")
+	buildCmd.Dir = projectRoot
+	buildCmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		tb.Fatalf("Failed to build binary: %v\n%s", err, output)
+	}
+	return binaryPath
+}
+
+func TestStartupBudget(t *testing.T) {
+	if os.Getenv(startupBudgetEnv) == "" {
+		t.Skipf("Skipping startup measurement; set %s=1 to run it", startupBudgetEnv)
+	}
+	binary := buildStatic(t)
+
+	// The median of several runs smooths out scheduler noise
+	const runs = 21
+	durations := make([]time.Duration, 0, runs)
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		if err := exec.Command(binary, "--version").Run(); err != nil {
+			t.Fatalf("gitversion --version failed: %v", err)
+		}
+		durations = append(durations, time.Since(start))
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	if median := durations[runs/2]; median > startupBudget {
+		t.Errorf("Median startup time %v exceeds budget %v", median, startupBudget)
+	}
+}
+
+func BenchmarkStartup(b *testing.B) {
+	binary := buildStatic(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := exec.Command(binary, "--version").Run(); err != nil {
+			b.Fatalf("gitversion --version failed: %v", err)
+		}
+	}
+}